@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+// GET /api/admin/sessions
+func (hs *HTTPServer) SearchUserSessions(c *models.ReqContext) response.Response {
+	query := models.SearchUserTokensQuery{
+		Filter: models.UserTokenFilter{
+			UserId:    c.QueryInt64("userId"),
+			ClientIp:  c.Query("ip"),
+			UserAgent: c.Query("userAgent"),
+			SeenFrom:  c.QueryInt64("seenFrom"),
+			SeenTo:    c.QueryInt64("seenTo"),
+		},
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+	}
+
+	result, err := hs.AuthTokenService.SearchTokens(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search user sessions", err)
+	}
+
+	parser := uaparser.NewFromSaved()
+	sessions := make([]*dtos.UserSession, 0, len(result.Tokens))
+	for _, token := range result.Tokens {
+		isActive := c.UserToken != nil && c.UserToken.Id == token.Id
+		session := &dtos.UserSession{
+			UserToken: *newUserTokenDTO(parser, &token.UserToken, isActive),
+			UserId:    token.UserId,
+			Login:     token.Login,
+			Email:     token.Email,
+		}
+		// The sessions are filtered by the last time they were used.
+		if token.RotatedAt > token.SeenAt {
+			session.SeenAt = time.Unix(token.RotatedAt, 0)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return response.JSON(http.StatusOK, dtos.SearchUserSessionsResult{
+		TotalCount: result.TotalCount,
+		Sessions:   sessions,
+		Page:       query.Page,
+		PerPage:    query.PerPage,
+	})
+}
+
+// POST /api/admin/sessions/revoke
+func (hs *HTTPServer) RevokeUserSessions(c *models.ReqContext, filter models.UserTokenFilter) response.Response {
+	if filter.IsEmpty() {
+		return response.Error(http.StatusBadRequest, "At least one filter is required", nil)
+	}
+	// The admin would sign themselves out.
+	if c.UserToken != nil {
+		filter.ExcludeTokenId = c.UserToken.Id
+	}
+
+	count, err := hs.AuthTokenService.RevokeTokens(c.Req.Context(), &filter)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to revoke user sessions", err)
+	}
+	audit.SetAction(c.Req.Context(), "user.sessions.revoke", "user", strconv.FormatInt(filter.UserId, 10))
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "User sessions revoked",
+		"count":   count,
+	})
+}
+
+// GET /api/orgs/:orgId/session-policy
+func (hs *HTTPServer) GetOrgSessionPolicy(c *models.ReqContext) response.Response {
+	policy, err := hs.AuthTokenService.GetSessionPolicy(c.Req.Context(), c.ParamsInt64(":orgId"))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get session policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// PUT /api/orgs/:orgId/session-policy
+func (hs *HTTPServer) UpdateOrgSessionPolicy(c *models.ReqContext, policy models.SessionPolicy) response.Response {
+	policy.OrgId = c.ParamsInt64(":orgId")
+
+	if err := hs.AuthTokenService.SetSessionPolicy(c.Req.Context(), &policy); err != nil {
+		if errors.Is(err, auth.ErrInvalidSessionPolicy) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to update session policy", err)
+	}
+	audit.SetAction(c.Req.Context(), "org.session-policy.update", "org", strconv.FormatInt(policy.OrgId, 10))
+
+	return response.Success("Session policy updated")
+}
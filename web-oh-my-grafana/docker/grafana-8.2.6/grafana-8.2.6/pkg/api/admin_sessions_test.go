@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminSessionsAPIEndpoint(t *testing.T) {
+	t.Run("Lists the sessions with their device", func(t *testing.T) {
+		sc, tokenService := adminSessionsScenario(t, "/api/admin/sessions")
+		var query *models.SearchUserTokensQuery
+		tokenService.SearchTokensProvider = func(ctx context.Context, q *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+			query = q
+			return &models.SearchUserTokensResult{
+				TotalCount: 1,
+				Tokens: []*models.UserTokenSearchHit{{
+					UserToken: models.UserToken{
+						Id:        3,
+						UserId:    2,
+						ClientIp:  "10.0.0.1",
+						UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/94.0.4606.71 Safari/537.36",
+						CreatedAt: 1633000000,
+						RotatedAt: 1633003600,
+					},
+					Login: "alice",
+					Email: "alice@example.com",
+				}},
+			}, nil
+		}
+		sc.m.Get("/api/admin/sessions", routing.Wrap(func(c *models.ReqContext) response.Response {
+			return adminSessionsServer(tokenService).SearchUserSessions(c)
+		}))
+
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{"ip": "10.0.0.1", "userAgent": "Chrome", "seenFrom": "1633000000000", "perpage": "10"}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, models.UserTokenFilter{ClientIp: "10.0.0.1", UserAgent: "Chrome", SeenFrom: 1633000000000}, query.Filter)
+		assert.Equal(t, 10, query.PerPage)
+
+		result := sc.ToJSON()
+		assert.Equal(t, 1, result.Get("totalCount").MustInt())
+		session := result.Get("sessions").GetIndex(0)
+		assert.Equal(t, "alice", session.Get("login").MustString())
+		assert.Equal(t, 2, session.Get("userId").MustInt())
+		assert.Equal(t, "Chrome", session.Get("browser").MustString())
+		assert.Equal(t, "Mac OS X", session.Get("os").MustString())
+		seenAt, err := time.Parse(time.RFC3339, session.Get("seenAt").MustString())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1633003600), seenAt.Unix())
+	})
+
+	t.Run("Revokes the filtered sessions except the current one", func(t *testing.T) {
+		sc, tokenService := adminSessionsScenario(t, "/api/admin/sessions/revoke")
+		var revoked *models.UserTokenFilter
+		tokenService.RevokeTokensProvider = func(ctx context.Context, filter *models.UserTokenFilter) (int64, error) {
+			revoked = filter
+			return 4, nil
+		}
+		sc.m.Post("/api/admin/sessions/revoke", routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.UserToken = &models.UserToken{Id: 7}
+			filter := models.UserTokenFilter{ClientIp: c.Query("ip")}
+			return adminSessionsServer(tokenService).RevokeUserSessions(c, filter)
+		}))
+
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{"ip": "10.0.0.1"}).exec()
+		require.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, &models.UserTokenFilter{ClientIp: "10.0.0.1", ExcludeTokenId: 7}, revoked)
+		assert.Equal(t, 4, sc.ToJSON().Get("count").MustInt())
+
+		revoked = nil
+		sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+		assert.Nil(t, revoked)
+	})
+
+	t.Run("Rejects negative session policy limits", func(t *testing.T) {
+		sc, tokenService := adminSessionsScenario(t, "/api/orgs/2/session-policy")
+		tokenService.SetSessionPolicyProvider = func(ctx context.Context, policy *models.SessionPolicy) error {
+			assert.Equal(t, int64(2), policy.OrgId)
+			return auth.ErrInvalidSessionPolicy
+		}
+		sc.m.Put("/api/orgs/:orgId/session-policy", routing.Wrap(func(c *models.ReqContext) response.Response {
+			return adminSessionsServer(tokenService).UpdateOrgSessionPolicy(c, models.SessionPolicy{IdleTimeoutSeconds: -1})
+		}))
+
+		sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+		assert.Equal(t, 400, sc.resp.Code)
+	})
+}
+
+func adminSessionsScenario(t *testing.T, url string) (*scenarioContext, *auth.FakeUserAuthTokenService) {
+	t.Helper()
+
+	sc := setupScenarioContext(t, url)
+	tokenService := auth.NewFakeUserAuthTokenService()
+	sc.userAuthTokenService = tokenService
+	return sc, tokenService
+}
+
+func adminSessionsServer(tokenService *auth.FakeUserAuthTokenService) *HTTPServer {
+	return &HTTPServer{Bus: bus.GetBus(), AuthTokenService: tokenService}
+}
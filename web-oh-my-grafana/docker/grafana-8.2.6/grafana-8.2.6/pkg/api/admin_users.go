@@ -20,6 +20,8 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreat
 		Password: form.Password,
 		Name:     form.Name,
 		OrgId:    form.OrgId,
+
+		PasswordChangeRequired: form.PasswordChangeRequired,
 	}
 
 	if len(cmd.Login) == 0 {
@@ -33,6 +35,10 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreat
 		return response.Error(400, "Password is missing or too short", nil)
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), nil, cmd.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
 	user, err := hs.Login.CreateUser(cmd)
 	if err != nil {
 		if errors.Is(err, models.ErrOrgNotFound) {
@@ -56,7 +62,7 @@ func (hs *HTTPServer) AdminCreateUser(c *models.ReqContext, form dtos.AdminCreat
 	return response.JSON(200, result)
 }
 
-func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPasswordForm) response.Response {
+func (hs *HTTPServer) AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPasswordForm) response.Response {
 	userID := c.ParamsInt64(":id")
 
 	if len(form.Password) < 4 {
@@ -69,6 +75,10 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 		return response.Error(500, "Could not read user from database", err)
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), userQuery.Result, form.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
 	passwordHashed, err := util.EncodePassword(form.Password, userQuery.Result.Salt)
 	if err != nil {
 		return response.Error(500, "Could not encode password", err)
@@ -77,6 +87,8 @@ func AdminUpdateUserPassword(c *models.ReqContext, form dtos.AdminUpdateUserPass
 	cmd := models.ChangeUserPasswordCommand{
 		UserId:      userID,
 		NewPassword: passwordHashed,
+
+		PasswordChangeRequired: form.PasswordChangeRequired,
 	}
 
 	if err := bus.Dispatch(&cmd); err != nil {
@@ -107,7 +119,7 @@ func AdminDeleteUser(c *models.ReqContext) response.Response {
 
 	cmd := models.DeleteUserCommand{UserId: userID}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -128,7 +140,7 @@ func (hs *HTTPServer) AdminDisableUser(c *models.ReqContext) response.Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: true}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -154,7 +166,7 @@ func AdminEnableUser(c *models.ReqContext) response.Response {
 	}
 
 	disableCmd := models.DisableUserCommand{UserId: userID, IsDisabled: false}
-	if err := bus.Dispatch(&disableCmd); err != nil {
+	if err := bus.DispatchCtx(c.Req.Context(), &disableCmd); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			return response.Error(404, models.ErrUserNotFound.Error(), nil)
 		}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
@@ -464,8 +465,9 @@ func adminCreateUserScenario(t *testing.T, desc string, url string, routePattern
 		t.Cleanup(bus.ClearBusHandlers)
 
 		hs := HTTPServer{
-			Bus:   bus.GetBus(),
-			Login: fakeLoginService{expected: cmd},
+			Bus:                   bus.GetBus(),
+			Login:                 fakeLoginService{expected: cmd},
+			PasswordPolicyService: passwordpolicy.NewFakePasswordPolicyService(),
 		}
 
 		sc := setupScenarioContext(t, url)
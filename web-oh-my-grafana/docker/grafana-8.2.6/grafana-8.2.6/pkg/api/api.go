@@ -39,8 +39,17 @@ func (hs *HTTPServer) registerRoutes() {
 
 	// not logged in views
 	r.Get("/logout", hs.Logout)
+	r.Get("/logout/saml", hs.SAMLLogout)
 	r.Post("/login", quota("session"), bind(dtos.LoginCommand{}), routing.Wrap(hs.LoginPost))
+	r.Post("/login/2fa", quota("session"), bind(dtos.TwoFactorCodeForm{}), routing.Wrap(hs.LoginTwoFactor))
+	r.Post("/login/2fa/enroll", routing.Wrap(hs.LoginTwoFactorEnroll))
+	r.Post("/login/password", quota("session"), bind(dtos.LoginChangePasswordForm{}), routing.Wrap(hs.LoginChangePassword))
+	r.Get("/login/saml", quota("session"), hs.SAMLLogin)
 	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
+	r.Get("/saml/metadata", hs.SAMLMetadata)
+	r.Post("/saml/acs", quota("session"), hs.SAMLACS)
+	r.Get("/saml/slo", hs.SAMLSingleLogout)
+	r.Post("/saml/slo", hs.SAMLSingleLogout)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
 
@@ -126,7 +135,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/user/password/reset", hs.Index)
 
 	r.Post("/api/user/password/send-reset-email", bind(dtos.SendResetPasswordEmailForm{}), routing.Wrap(SendResetPasswordEmail))
-	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), routing.Wrap(ResetPassword))
+	r.Post("/api/user/password/reset", bind(dtos.ResetUserPasswordForm{}), routing.Wrap(hs.ResetPassword))
 
 	// dashboard snapshots
 	r.Get("/dashboard/snapshot/*", reqNoAuth, hs.Index)
@@ -151,7 +160,7 @@ func (hs *HTTPServer) registerRoutes() {
 			userRoute.Post("/stars/dashboard/:id", routing.Wrap(StarDashboard))
 			userRoute.Delete("/stars/dashboard/:id", routing.Wrap(UnstarDashboard))
 
-			userRoute.Put("/password", bind(models.ChangeUserPasswordCommand{}), routing.Wrap(ChangeUserPassword))
+			userRoute.Put("/password", bind(models.ChangeUserPasswordCommand{}), routing.Wrap(hs.ChangeUserPassword))
 			userRoute.Get("/quotas", routing.Wrap(GetUserQuotas))
 			userRoute.Put("/helpflags/:id", routing.Wrap(SetHelpFlag))
 			// For dev purpose
@@ -162,6 +171,11 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", routing.Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", bind(models.RevokeAuthTokenCmd{}), routing.Wrap(hs.RevokeUserAuthToken))
+
+			userRoute.Get("/2fa", routing.Wrap(hs.GetUserTwoFactorStatus))
+			userRoute.Post("/2fa/enroll", routing.Wrap(hs.EnrollUserTwoFactor))
+			userRoute.Post("/2fa/enable", bind(dtos.TwoFactorCodeForm{}), routing.Wrap(hs.EnableUserTwoFactor))
+			userRoute.Post("/2fa/disable", bind(dtos.TwoFactorCodeForm{}), routing.Wrap(hs.DisableUserTwoFactor))
 		}, reqSignedInNoAnonymous)
 
 		// users (admin permission required)
@@ -187,6 +201,9 @@ func (hs *HTTPServer) registerRoutes() {
 			teamsRoute.Post("/:teamId/members", bind(models.AddTeamMemberCommand{}), routing.Wrap(hs.AddTeamMember))
 			teamsRoute.Put("/:teamId/members/:userId", bind(models.UpdateTeamMemberCommand{}), routing.Wrap(hs.UpdateTeamMember))
 			teamsRoute.Delete("/:teamId/members/:userId", routing.Wrap(hs.RemoveTeamMember))
+			teamsRoute.Get("/:teamId/groups", routing.Wrap(hs.GetTeamGroups))
+			teamsRoute.Post("/:teamId/groups", bind(models.AddTeamGroupCommand{}), routing.Wrap(hs.AddTeamGroup))
+			teamsRoute.Delete("/:teamId/groups/*", routing.Wrap(hs.RemoveTeamGroup))
 			teamsRoute.Get("/:teamId/preferences", routing.Wrap(hs.GetTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", bind(dtos.UpdatePrefsCmd{}), routing.Wrap(hs.UpdateTeamPreferences))
 		}, reqCanAccessTeams)
@@ -201,6 +218,7 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
 			orgRoute.Get("/", routing.Wrap(GetOrgCurrent))
 			orgRoute.Get("/quotas", routing.Wrap(GetOrgQuotas))
+			orgRoute.Get("/quotas/status", routing.Wrap(hs.GetOrgQuotaStatus))
 		})
 
 		// current org
@@ -222,6 +240,10 @@ func (hs *HTTPServer) registerRoutes() {
 			// prefs
 			orgRoute.Get("/preferences", reqOrgAdmin, routing.Wrap(GetOrgPreferences))
 			orgRoute.Put("/preferences", reqOrgAdmin, bind(dtos.UpdatePrefsCmd{}), routing.Wrap(UpdateOrgPreferences))
+
+			// authentication policy
+			orgRoute.Get("/auth-policy", reqOrgAdmin, routing.Wrap(hs.GetOrgAuthPolicy))
+			orgRoute.Put("/auth-policy", reqOrgAdmin, bind(dtos.UpdateOrgAuthPolicyForm{}), routing.Wrap(hs.UpdateOrgAuthPolicy))
 		})
 
 		// current org without requirement of user to be org admin
@@ -247,6 +269,8 @@ func (hs *HTTPServer) registerRoutes() {
 			orgsRoute.Delete("/users/:userId", reqGrafanaAdmin, routing.Wrap(RemoveOrgUser))
 			orgsRoute.Get("/quotas", reqGrafanaAdmin, routing.Wrap(GetOrgQuotas))
 			orgsRoute.Put("/quotas/:target", reqGrafanaAdmin, bind(models.UpdateOrgQuotaCmd{}), routing.Wrap(UpdateOrgQuota))
+			orgsRoute.Get("/session-policy", reqGrafanaAdmin, routing.Wrap(hs.GetOrgSessionPolicy))
+			orgsRoute.Put("/session-policy", reqGrafanaAdmin, bind(models.SessionPolicy{}), routing.Wrap(hs.UpdateOrgSessionPolicy))
 		})
 
 		// orgs (admin routes)
@@ -261,6 +285,20 @@ func (hs *HTTPServer) registerRoutes() {
 			keysRoute.Delete("/:id", routing.Wrap(DeleteAPIKey))
 		}, reqOrgAdmin)
 
+		// service accounts
+		apiRoute.Group("/serviceaccounts", func(saRoute routing.RouteRegister) {
+			saRoute.Get("/", routing.Wrap(hs.GetServiceAccounts))
+			saRoute.Post("/", bind(models.CreateServiceAccountCommand{}), routing.Wrap(hs.CreateServiceAccount))
+			saRoute.Get("/:serviceAccountId", routing.Wrap(hs.GetServiceAccount))
+			saRoute.Patch("/:serviceAccountId", bind(models.UpdateServiceAccountCommand{}), routing.Wrap(hs.UpdateServiceAccount))
+			saRoute.Delete("/:serviceAccountId", routing.Wrap(hs.DeleteServiceAccount))
+			saRoute.Get("/:serviceAccountId/tokens", routing.Wrap(hs.GetServiceAccountTokens))
+			saRoute.Post("/:serviceAccountId/tokens", quota("api_key"), bind(dtos.AddServiceAccountTokenForm{}), routing.Wrap(hs.CreateServiceAccountToken))
+			saRoute.Delete("/:serviceAccountId/tokens/:tokenId", routing.Wrap(hs.DeleteServiceAccountToken))
+			saRoute.Get("/:serviceAccountId/permissions", routing.Wrap(hs.GetServiceAccountPermissions))
+			saRoute.Put("/:serviceAccountId/permissions", bind(dtos.UpdateServiceAccountPermissionsForm{}), routing.Wrap(hs.UpdateServiceAccountPermissions))
+		}, reqOrgAdmin)
+
 		// Preferences
 		apiRoute.Group("/preferences", func(prefRoute routing.RouteRegister) {
 			prefRoute.Post("/set-home-dash", bind(models.SavePreferencesCommand{}), routing.Wrap(SetHomeDashboard))
@@ -269,10 +307,11 @@ func (hs *HTTPServer) registerRoutes() {
 		// Data sources
 		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
 			datasourceRoute.Get("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourcesAll)), routing.Wrap(hs.GetDataSources))
-			datasourceRoute.Post("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesCreate)), quota("data_source"), bind(models.AddDataSourceCommand{}), routing.Wrap(AddDataSource))
+			datasourceRoute.Post("/", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesCreate)), quota("data_source"), bind(models.AddDataSourceCommand{}), routing.Wrap(hs.AddDataSource))
 			datasourceRoute.Put("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesWrite, ScopeDatasourceID)), bind(models.UpdateDataSourceCommand{}), routing.Wrap(hs.UpdateDataSource))
 			datasourceRoute.Delete("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceID)), routing.Wrap(hs.DeleteDataSourceById))
 			datasourceRoute.Delete("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceUID)), routing.Wrap(hs.DeleteDataSourceByUID))
+			datasourceRoute.Delete("/uid/:uid/cache", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesWrite, ScopeDatasourceUID)), routing.Wrap(hs.FlushDataSourceCacheByUID))
 			datasourceRoute.Delete("/name/:name", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesDelete, ScopeDatasourceName)), routing.Wrap(hs.DeleteDataSourceByName))
 			datasourceRoute.Get("/:id", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceID)), routing.Wrap(GetDataSourceById))
 			datasourceRoute.Get("/uid/:uid", authorize(reqOrgAdmin, ac.EvalPermission(ActionDatasourcesRead, ScopeDatasourceUID)), routing.Wrap(GetDataSourceByUID))
@@ -301,10 +340,10 @@ func (hs *HTTPServer) registerRoutes() {
 		}, reqOrgAdmin)
 
 		apiRoute.Get("/frontend/settings/", hs.GetFrontendSettings)
-		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/:id/resources", hs.CallDatasourceResource)
-		apiRoute.Any("/datasources/:id/resources/*", hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, quota("data_source_query"), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, quota("data_source_query"), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/:id/resources", quota("data_source_query"), hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/:id/resources/*", quota("data_source_query"), hs.CallDatasourceResource)
 		apiRoute.Any("/datasources/:id/health", routing.Wrap(hs.CheckDatasourceHealth))
 
 		// Folders
@@ -317,6 +356,8 @@ func (hs *HTTPServer) registerRoutes() {
 				folderUidRoute.Get("/", routing.Wrap(hs.GetFolderByUID))
 				folderUidRoute.Put("/", bind(models.UpdateFolderCommand{}), routing.Wrap(hs.UpdateFolder))
 				folderUidRoute.Delete("/", routing.Wrap(hs.DeleteFolder))
+				folderUidRoute.Get("/quotas", reqOrgAdmin, routing.Wrap(hs.GetFolderQuotas))
+				folderUidRoute.Put("/quotas/:target", reqOrgAdmin, bind(models.UpdateFolderQuotaCmd{}), routing.Wrap(hs.UpdateFolderQuota))
 
 				folderUidRoute.Group("/permissions", func(folderPermissionRoute routing.RouteRegister) {
 					folderPermissionRoute.Get("/", routing.Wrap(hs.GetFolderPermissionList))
@@ -371,10 +412,10 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/", routing.Wrap(Search))
 
 		// metrics
-		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
+		apiRoute.Post("/tsdb/query", quota("data_source_query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetrics))
 
 		// DataSource w/ expressions
-		apiRoute.Post("/ds/query", bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
+		apiRoute.Post("/ds/query", quota("data_source_query"), bind(dtos.MetricRequest{}), routing.Wrap(hs.QueryMetricsV2))
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), routing.Wrap(hs.AlertTest))
@@ -391,12 +432,12 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Group("/alert-notifications", func(alertNotifications routing.RouteRegister) {
 			alertNotifications.Get("/", routing.Wrap(GetAlertNotifications))
 			alertNotifications.Post("/test", bind(dtos.NotificationTestCommand{}), routing.Wrap(NotificationTest))
-			alertNotifications.Post("/", bind(models.CreateAlertNotificationCommand{}), routing.Wrap(CreateAlertNotification))
-			alertNotifications.Put("/:notificationId", bind(models.UpdateAlertNotificationCommand{}), routing.Wrap(UpdateAlertNotification))
+			alertNotifications.Post("/", bind(models.CreateAlertNotificationCommand{}), routing.Wrap(hs.CreateAlertNotification))
+			alertNotifications.Put("/:notificationId", bind(models.UpdateAlertNotificationCommand{}), routing.Wrap(hs.UpdateAlertNotification))
 			alertNotifications.Get("/:notificationId", routing.Wrap(GetAlertNotificationByID))
 			alertNotifications.Delete("/:notificationId", routing.Wrap(DeleteAlertNotification))
 			alertNotifications.Get("/uid/:uid", routing.Wrap(GetAlertNotificationByUID))
-			alertNotifications.Put("/uid/:uid", bind(models.UpdateAlertNotificationWithUidCommand{}), routing.Wrap(UpdateAlertNotificationByUID))
+			alertNotifications.Put("/uid/:uid", bind(models.UpdateAlertNotificationWithUidCommand{}), routing.Wrap(hs.UpdateAlertNotificationByUID))
 			alertNotifications.Delete("/uid/:uid", routing.Wrap(DeleteAlertNotificationByUID))
 		}, reqEditorRole)
 
@@ -442,13 +483,18 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// short urls
 		apiRoute.Post("/short-urls", bind(dtos.CreateShortURLCmd{}), routing.Wrap(hs.createShortURL))
-	}, reqSignedIn)
+	}, reqSignedIn, quota("api_request"))
 
 	// admin api
 	r.Group("/api/admin", func(adminRoute routing.RouteRegister) {
 		adminRoute.Get("/settings", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionSettingsRead)), routing.Wrap(hs.AdminGetSettings))
 		adminRoute.Get("/stats", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionServerStatsRead)), routing.Wrap(AdminGetStats))
 		adminRoute.Post("/pause-all-alerts", reqGrafanaAdmin, bind(dtos.PauseAllAlertsCommand{}), routing.Wrap(PauseAllAlerts))
+		adminRoute.Get("/dashboards/stale", reqGrafanaAdmin, routing.Wrap(hs.AdminGetStaleDashboards))
+		adminRoute.Get("/audit", reqGrafanaAdmin, routing.Wrap(hs.SearchAuditEvents))
+
+		adminRoute.Get("/sessions", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenList, ac.ScopeGlobalUsersAll)), routing.Wrap(hs.SearchUserSessions))
+		adminRoute.Post("/sessions/revoke", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersAuthTokenUpdate, ac.ScopeGlobalUsersAll)), bind(models.UserTokenFilter{}), routing.Wrap(hs.RevokeUserSessions))
 
 		adminRoute.Post("/provisioning/dashboards/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersDashboards)), routing.Wrap(hs.AdminProvisioningReloadDashboards))
 		adminRoute.Post("/provisioning/plugins/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersPlugins)), routing.Wrap(hs.AdminProvisioningReloadPlugins))
@@ -456,6 +502,7 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Post("/provisioning/notifications/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ActionProvisioningReload, ScopeProvisionersNotifications)), routing.Wrap(hs.AdminProvisioningReloadNotifications))
 
 		adminRoute.Post("/ldap/reload", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPConfigReload)), routing.Wrap(hs.ReloadLDAPCfg))
+		adminRoute.Get("/ldap/sync/report", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPUsersRead)), routing.Wrap(hs.GetLDAPSyncReport))
 		adminRoute.Post("/ldap/sync/:id", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(hs.PostSyncUserWithLDAP))
 		adminRoute.Get("/ldap/:username", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPUsersRead)), routing.Wrap(hs.GetUserFromLDAP))
 		adminRoute.Get("/ldap/status", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(hs.GetLDAPStatus))
@@ -466,11 +513,12 @@ func (hs *HTTPServer) registerRoutes() {
 		userIDScope := ac.Scope("global", "users", ac.Parameter(":id"))
 
 		adminUserRoute.Post("/", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersCreate)), bind(dtos.AdminCreateUserForm{}), routing.Wrap(hs.AdminCreateUser))
-		adminUserRoute.Put("/:id/password", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersPasswordUpdate, userIDScope)), bind(dtos.AdminUpdateUserPasswordForm{}), routing.Wrap(AdminUpdateUserPassword))
+		adminUserRoute.Put("/:id/password", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersPasswordUpdate, userIDScope)), bind(dtos.AdminUpdateUserPasswordForm{}), routing.Wrap(hs.AdminUpdateUserPassword))
 		adminUserRoute.Put("/:id/permissions", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersPermissionsUpdate, userIDScope)), bind(dtos.AdminUpdateUserPermissionsForm{}), routing.Wrap(hs.AdminUpdateUserPermissions))
 		adminUserRoute.Delete("/:id", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersDelete, userIDScope)), routing.Wrap(AdminDeleteUser))
 		adminUserRoute.Post("/:id/disable", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersDisable, userIDScope)), routing.Wrap(hs.AdminDisableUser))
 		adminUserRoute.Post("/:id/enable", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersEnable, userIDScope)), routing.Wrap(AdminEnableUser))
+		adminUserRoute.Delete("/:id/2fa", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersWrite, userIDScope)), routing.Wrap(hs.AdminResetUserTwoFactor))
 		adminUserRoute.Get("/:id/quotas", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersQuotasList, userIDScope)), routing.Wrap(GetUserQuotas))
 		adminUserRoute.Put("/:id/quotas/:target", authorize(reqGrafanaAdmin, ac.EvalPermission(ac.ActionUsersQuotasUpdate, userIDScope)), bind(models.UpdateUserQuotaCmd{}), routing.Wrap(UpdateUserQuota))
 
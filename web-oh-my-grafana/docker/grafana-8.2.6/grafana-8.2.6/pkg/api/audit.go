@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/admin/audit
+func (hs *HTTPServer) SearchAuditEvents(c *models.ReqContext) response.Response {
+	query := models.SearchAuditEventsQuery{
+		OrgId:        c.QueryInt64("orgId"),
+		ActorType:    c.Query("actorType"),
+		ActorLogin:   c.Query("actor"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resourceType"),
+		ResourceUid:  c.Query("resourceUid"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	if err := hs.AuditService.Search(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to search audit events", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	macaron "gopkg.in/macaron.v1"
 
@@ -100,6 +101,12 @@ func (hs *HTTPServer) GetDashboard(c *models.ReqContext) response.Response {
 	canSave, _ := guardian.CanSave()
 	canAdmin, _ := guardian.CanAdmin()
 
+	if hs.DashboardUsageService != nil {
+		if err := hs.DashboardUsageService.RecordView(c.Req.Context(), c.OrgId, dash.Id, c.UserId); err != nil {
+			hs.log.Warn("Failed to record dashboard view", "dashboardId", dash.Id, "err", err)
+		}
+	}
+
 	isStarred, err := isDashboardStarredByUser(c, dash.Id)
 	if err != nil {
 		return response.Error(500, "Error while checking if dashboard was starred by user", err)
@@ -261,6 +268,7 @@ func (hs *HTTPServer) deleteDashboard(c *models.ReqContext) response.Response {
 
 		return response.Error(500, "Failed to delete dashboard", err)
 	}
+	audit.SetAction(c.Req.Context(), "dashboard.delete", "dashboard", dash.Uid)
 
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.OrgId, c.ToUserDisplayDTO(), dash.Uid)
@@ -305,6 +313,26 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		}
 	}
 
+	if cmd.FolderId > 0 {
+		movedToFolder := false
+		if !newDashboard {
+			query := models.GetDashboardQuery{Id: dash.Id, OrgId: c.OrgId}
+			if err := bus.DispatchCtx(ctx, &query); err == nil {
+				movedToFolder = query.Result.FolderId != cmd.FolderId
+			}
+		}
+
+		if newDashboard || movedToFolder {
+			limitReached, err := hs.QuotaService.FolderQuotaReached(c, "dashboard", cmd.FolderId)
+			if err != nil {
+				return response.Error(500, "failed to get quota", err)
+			}
+			if limitReached {
+				return response.Error(403, "Folder quota reached", nil)
+			}
+		}
+	}
+
 	svc := dashboards.NewProvisioningService(hs.SQLStore)
 	provisioningData, err := svc.GetProvisionedDashboardDataByDashboardID(dash.Id)
 	if err != nil {
@@ -330,6 +358,14 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		Overwrite: cmd.Overwrite,
 	}
 
+	var before *simplejson.Json
+	if audit.IsRecording(ctx) && !newDashboard {
+		query := models.GetDashboardQuery{Id: dash.Id, OrgId: c.OrgId}
+		if err := bus.DispatchCtx(ctx, &query); err == nil {
+			before = query.Result.Data
+		}
+	}
+
 	dashSvc := dashboards.NewService(hs.SQLStore)
 	dashboard, err := dashSvc.SaveDashboard(dashItem, allowUiUpdate)
 
@@ -361,6 +397,9 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 		return hs.dashboardSaveErrorToApiResponse(err)
 	}
 
+	audit.SetAction(ctx, "dashboard.save", "dashboard", dashboard.Uid)
+	audit.SetChanges(ctx, before, dashboard.Data)
+
 	if hs.Cfg.EditorsCanAdmin && newDashboard {
 		inFolder := cmd.FolderId > 0
 		err := dashSvc.MakeUserAdmin(ctx, cmd.OrgId, cmd.UserId, dashboard.Id, !inFolder)
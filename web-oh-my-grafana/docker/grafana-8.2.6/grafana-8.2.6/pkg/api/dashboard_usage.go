@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const defaultStaleDashboardDays = 90
+
+// AdminGetStaleDashboards lists the dashboards of the current organization
+// that haven't been viewed for the given number of days.
+func (hs *HTTPServer) AdminGetStaleDashboards(c *models.ReqContext) response.Response {
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultStaleDashboardDays
+	}
+	if days < 0 {
+		return response.Error(http.StatusBadRequest, "days must be a positive number", nil)
+	}
+
+	if !hs.Cfg.DashboardUsageTrackingEnabled {
+		return response.Error(http.StatusNotFound, "Dashboard usage tracking is disabled", nil)
+	}
+
+	query := models.GetStaleDashboardsQuery{
+		OrgId:     c.OrgId,
+		OlderThan: time.Now().AddDate(0, 0, -days),
+		Limit:     c.QueryInt("limit"),
+	}
+	if err := hs.DashboardUsageService.GetStaleDashboards(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get stale dashboards", err)
+	}
+
+	return response.JSON(http.StatusOK, query.Result)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	macaron "gopkg.in/macaron.v1"
 
@@ -106,11 +108,14 @@ func (hs *HTTPServer) DeleteDataSourceById(c *models.ReqContext) response.Respon
 
 	cmd := &models.DeleteDataSourceCommand{ID: id, OrgID: c.OrgId}
 
-	err = bus.Dispatch(cmd)
+	err = bus.DispatchCtx(c.Req.Context(), cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	audit.SetAction(c.Req.Context(), "datasource.delete", "datasource", ds.Uid)
+	audit.SetChanges(c.Req.Context(), convertModelToDtos(ds), nil)
+
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
 	return response.Success("Data source deleted")
@@ -153,16 +158,44 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *models.ReqContext) response.Respo
 
 	cmd := &models.DeleteDataSourceCommand{UID: uid, OrgID: c.OrgId}
 
-	err = bus.Dispatch(cmd)
+	err = bus.DispatchCtx(c.Req.Context(), cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	audit.SetChanges(c.Req.Context(), convertModelToDtos(ds), nil)
+
 	hs.Live.HandleDatasourceDelete(c.OrgId, ds.Uid)
 
 	return response.Success("Data source deleted")
 }
 
+// FlushDataSourceCacheByUID removes the cached query responses of a data source.
+// DELETE /api/datasources/uid/:uid/cache
+func (hs *HTTPServer) FlushDataSourceCacheByUID(c *models.ReqContext) response.Response {
+	uid := macaron.Params(c.Req)[":uid"]
+
+	if uid == "" {
+		return response.Error(400, "Missing datasource uid", nil)
+	}
+
+	ds, err := getRawDataSourceByUID(uid, c.OrgId)
+	if err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return response.Error(404, "Data source not found", nil)
+		}
+		return response.Error(500, "Failed to query datasource", err)
+	}
+
+	if err := hs.QueryCacheService.Flush(ds); err != nil {
+		return response.Error(500, "Failed to flush data source cache", err)
+	}
+
+	audit.SetAction(c.Req.Context(), "datasource.flush_cache", "datasource", ds.Uid)
+
+	return response.Success("Data source cache flushed")
+}
+
 func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Response {
 	name := macaron.Params(c.Req)[":name"]
 
@@ -183,11 +216,14 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *models.ReqContext) response.Resp
 	}
 
 	cmd := &models.DeleteDataSourceCommand{Name: name, OrgID: c.OrgId}
-	err := bus.Dispatch(cmd)
+	err := bus.DispatchCtx(c.Req.Context(), cmd)
 	if err != nil {
 		return response.Error(500, "Failed to delete datasource", err)
 	}
 
+	audit.SetAction(c.Req.Context(), "datasource.delete", "datasource", getCmd.Result.Uid)
+	audit.SetChanges(c.Req.Context(), convertModelToDtos(getCmd.Result), nil)
+
 	hs.Live.HandleDatasourceDelete(c.OrgId, getCmd.Result.Uid)
 
 	return response.JSON(200, util.DynMap{
@@ -208,14 +244,20 @@ func validateURL(tp string, u string) response.Response {
 	return nil
 }
 
-func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) response.Response {
+func (hs *HTTPServer) AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) response.Response {
 	datasourcesLogger.Debug("Received command to add data source", "url", cmd.Url)
 	cmd.OrgId = c.OrgId
 	if resp := validateURL(cmd.Type, cmd.Url); resp != nil {
 		return resp
 	}
 
-	if err := bus.Dispatch(&cmd); err != nil {
+	var err error
+	cmd.EncryptedSecureJsonData, err = hs.EncryptionService.EncryptJsonData(cmd.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		return response.Error(500, "Failed to add datasource", err)
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, models.ErrDataSourceNameExists) || errors.Is(err, models.ErrDataSourceUidExists) {
 			return response.Error(409, err.Error(), err)
 		}
@@ -224,6 +266,7 @@ func AddDataSource(c *models.ReqContext, cmd models.AddDataSourceCommand) respon
 	}
 
 	ds := convertModelToDtos(cmd.Result)
+	audit.SetChanges(c.Req.Context(), nil, ds)
 	return response.JSON(200, util.DynMap{
 		"message":    "Datasource added",
 		"id":         cmd.Result.Id,
@@ -240,12 +283,25 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 		return resp
 	}
 
-	err := fillWithSecureJSONData(&cmd)
+	err := hs.fillWithSecureJSONData(&cmd)
 	if err != nil {
 		return response.Error(500, "Failed to update datasource", err)
 	}
 
-	err = bus.Dispatch(&cmd)
+	cmd.EncryptedSecureJsonData, err = hs.EncryptionService.EncryptJsonData(cmd.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		return response.Error(500, "Failed to update datasource", err)
+	}
+
+	var before *dtos.DataSource
+	if audit.IsRecording(c.Req.Context()) {
+		if ds, err := getRawDataSourceById(cmd.Id, cmd.OrgId); err == nil {
+			dto := convertModelToDtos(ds)
+			before = &dto
+		}
+	}
+
+	err = bus.DispatchCtx(c.Req.Context(), &cmd)
 	if err != nil {
 		if errors.Is(err, models.ErrDataSourceUpdatingOldVersion) {
 			return response.Error(409, "Datasource has already been updated by someone else. Please reload and try again", err)
@@ -266,6 +322,9 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 	}
 
 	datasourceDTO := convertModelToDtos(query.Result)
+	if before != nil {
+		audit.SetChanges(c.Req.Context(), before, datasourceDTO)
+	}
 
 	hs.Live.HandleDatasourceUpdate(c.OrgId, datasourceDTO.UID)
 
@@ -277,7 +336,7 @@ func (hs *HTTPServer) UpdateDataSource(c *models.ReqContext, cmd models.UpdateDa
 	})
 }
 
-func fillWithSecureJSONData(cmd *models.UpdateDataSourceCommand) error {
+func (hs *HTTPServer) fillWithSecureJSONData(cmd *models.UpdateDataSourceCommand) error {
 	if len(cmd.SecureJsonData) == 0 {
 		return nil
 	}
@@ -291,7 +350,11 @@ func fillWithSecureJSONData(cmd *models.UpdateDataSourceCommand) error {
 		return models.ErrDatasourceIsReadOnly
 	}
 
-	secureJSONData := ds.SecureJsonData.Decrypt()
+	secureJSONData, err := hs.EncryptionService.DecryptJsonData(ds.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		return err
+	}
+
 	for k, v := range secureJSONData {
 		if _, ok := cmd.SecureJsonData[k]; !ok {
 			cmd.SecureJsonData[k] = v
@@ -381,7 +444,7 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 		return
 	}
 
-	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds)
+	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.EncryptionService)
 	if err != nil {
 		c.JsonApiErr(500, "Unable to process datasource instance model", err)
 	}
@@ -445,7 +508,7 @@ func (hs *HTTPServer) CheckDatasourceHealth(c *models.ReqContext) response.Respo
 		return response.Error(500, "Unable to find datasource plugin", err)
 	}
 
-	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds)
+	dsInstanceSettings, err := adapters.ModelToInstanceSettings(ds, hs.EncryptionService)
 	if err != nil {
 		return response.Error(500, "Unable to get datasource model", err)
 	}
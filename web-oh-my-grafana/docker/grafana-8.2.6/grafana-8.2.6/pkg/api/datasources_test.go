@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -78,9 +79,10 @@ func TestAddDataSource_InvalidURL(t *testing.T) {
 	defer bus.ClearBusHandlers()
 
 	sc := setupScenarioContext(t, "/api/datasources")
+	hs := &HTTPServer{EncryptionService: &ossencryption.Service{}}
 
 	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: "Test",
 			Url:  "invalid:url",
 		})
@@ -108,9 +110,10 @@ func TestAddDataSource_URLWithoutProtocol(t *testing.T) {
 	})
 
 	sc := setupScenarioContext(t, "/api/datasources")
+	hs := &HTTPServer{EncryptionService: &ossencryption.Service{}}
 
 	sc.m.Post(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: name,
 			Url:  url,
 		})
@@ -126,9 +129,10 @@ func TestUpdateDataSource_InvalidURL(t *testing.T) {
 	defer bus.ClearBusHandlers()
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
+	hs := &HTTPServer{EncryptionService: &ossencryption.Service{}}
 
 	sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: "Test",
 			Url:  "invalid:url",
 		})
@@ -156,9 +160,10 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 	})
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
+	hs := &HTTPServer{EncryptionService: &ossencryption.Service{}}
 
 	sc.m.Put(sc.url, routing.Wrap(func(c *models.ReqContext) response.Response {
-		return AddDataSource(c, models.AddDataSourceCommand{
+		return hs.AddDataSource(c, models.AddDataSourceCommand{
 			Name: name,
 			Url:  url,
 		})
@@ -411,6 +416,30 @@ func TestAPI_Datasources_AccessControl(t *testing.T) {
 				permissions:  []*accesscontrol.Permission{{Action: "wrong"}},
 			},
 		},
+		{
+			busStubs: []bus.HandlerFunc{getDatasourceStub},
+			accessControlTestCase: accessControlTestCase{
+				expectedCode: http.StatusOK,
+				desc:         "DatasourcesFlushCacheByUID should return 200 for user with correct permissions",
+				url:          fmt.Sprintf("/api/datasources/uid/%v/cache", testDatasource.Uid),
+				method:       http.MethodDelete,
+				permissions: []*accesscontrol.Permission{
+					{
+						Action: ActionDatasourcesWrite,
+						Scope:  fmt.Sprintf("datasources:uid:%v", testDatasource.Uid),
+					},
+				},
+			},
+		},
+		{
+			accessControlTestCase: accessControlTestCase{
+				expectedCode: http.StatusForbidden,
+				desc:         "DatasourcesFlushCacheByUID should return 403 for user without required permissions",
+				url:          fmt.Sprintf("/api/datasources/uid/%v/cache", testDatasource.Uid),
+				method:       http.MethodDelete,
+				permissions:  []*accesscontrol.Permission{{Action: ActionDatasourcesRead, Scope: ScopeDatasourcesAll}},
+			},
+		},
 		{
 			busStubs: []bus.HandlerFunc{getDatasourceStub},
 			accessControlTestCase: accessControlTestCase{
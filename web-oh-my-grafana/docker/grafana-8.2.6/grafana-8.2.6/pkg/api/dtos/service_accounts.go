@@ -0,0 +1,12 @@
+package dtos
+
+import "github.com/grafana/grafana/pkg/services/accesscontrol"
+
+type AddServiceAccountTokenForm struct {
+	Name          string `json:"name" binding:"Required"`
+	SecondsToLive int64  `json:"secondsToLive"`
+}
+
+type UpdateServiceAccountPermissionsForm struct {
+	Permissions []accesscontrol.Permission `json:"permissions"`
+}
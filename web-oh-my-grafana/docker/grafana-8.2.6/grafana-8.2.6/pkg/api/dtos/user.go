@@ -14,15 +14,17 @@ type SignUpStep2Form struct {
 }
 
 type AdminCreateUserForm struct {
-	Email    string `json:"email"`
-	Login    string `json:"login"`
-	Name     string `json:"name"`
-	Password string `json:"password" binding:"Required"`
-	OrgId    int64  `json:"orgId"`
+	Email                  string `json:"email"`
+	Login                  string `json:"login"`
+	Name                   string `json:"name"`
+	Password               string `json:"password" binding:"Required"`
+	OrgId                  int64  `json:"orgId"`
+	PasswordChangeRequired bool   `json:"passwordChangeRequired"`
 }
 
 type AdminUpdateUserPasswordForm struct {
-	Password string `json:"password" binding:"Required"`
+	Password               string `json:"password" binding:"Required"`
+	PasswordChangeRequired bool   `json:"passwordChangeRequired"`
 }
 
 type AdminUpdateUserPermissionsForm struct {
@@ -44,3 +46,12 @@ type UserLookupDTO struct {
 	Login     string `json:"login"`
 	AvatarURL string `json:"avatarUrl"`
 }
+
+type TwoFactorCodeForm struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type LoginChangePasswordForm struct {
+	NewPassword string `json:"newPassword" binding:"Required"`
+	ConfirmNew  string `json:"confirmNew"`
+}
@@ -14,3 +14,18 @@ type UserToken struct {
 	CreatedAt              time.Time `json:"createdAt"`
 	SeenAt                 time.Time `json:"seenAt"`
 }
+
+// UserSession is a session of any user, listed to the Grafana admins.
+type UserSession struct {
+	UserToken
+	UserId int64  `json:"userId"`
+	Login  string `json:"login"`
+	Email  string `json:"email"`
+}
+
+type SearchUserSessionsResult struct {
+	TotalCount int64          `json:"totalCount"`
+	Sessions   []*UserSession `json:"sessions"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
+}
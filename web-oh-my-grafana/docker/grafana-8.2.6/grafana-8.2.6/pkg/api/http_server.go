@@ -34,8 +34,10 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
@@ -48,13 +50,19 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/querycache"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/saml"
 	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/teamsync"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -93,6 +101,14 @@ type HTTPServer struct {
 	PluginManager          plugins.Manager
 	SearchService          *search.SearchService
 	ShortURLService        shorturls.Service
+	DashboardUsageService  dashboardusage.Service
+	TwoFactorService       twofactor.Service
+	AuditService           audit.Service
+	RateLimitService       ratelimit.Service
+	PasswordPolicyService  passwordpolicy.Service
+	TeamSyncService        teamsync.Service
+	QueryCacheService      querycache.Service
+	SAMLService            saml.Service
 	Live                   *live.GrafanaLive
 	LivePushGateway        *pushhttp.Gateway
 	ContextHandler         *contexthandler.ContextHandler
@@ -136,7 +152,11 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 	notificationService *notifications.NotificationService, tracingService *tracing.TracingService,
 	internalMetricsSvc *metrics.InternalMetricsService, quotaService *quota.QuotaService,
 	socialService social.Service, oauthTokenService oauthtoken.OAuthTokenService,
-	encryptionService encryption.Service, searchUsersService searchusers.Service) (*HTTPServer, error) {
+	encryptionService encryption.Service, searchUsersService searchusers.Service,
+	dashboardUsageService dashboardusage.Service, twoFactorService twofactor.Service,
+	auditService audit.Service, samlService saml.Service,
+	rateLimitService ratelimit.Service, passwordPolicyService passwordpolicy.Service,
+	teamSyncService teamsync.Service, queryCacheService querycache.Service) (*HTTPServer, error) {
 	macaron.Env = cfg.Env
 	m := macaron.New()
 
@@ -160,6 +180,14 @@ func ProvideHTTPServer(opts ServerOptions, cfg *setting.Cfg, routeRegister routi
 		AuthTokenService:       userTokenService,
 		cleanUpService:         cleanUpService,
 		ShortURLService:        shortURLService,
+		DashboardUsageService:  dashboardUsageService,
+		TwoFactorService:       twoFactorService,
+		AuditService:           auditService,
+		RateLimitService:       rateLimitService,
+		PasswordPolicyService:  passwordPolicyService,
+		TeamSyncService:        teamSyncService,
+		QueryCacheService:      queryCacheService,
+		SAMLService:            samlService,
 		RemoteCacheService:     remoteCache,
 		ProvisioningService:    provisioningService,
 		Login:                  loginService,
@@ -431,6 +459,8 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.Use(hs.ContextHandler.Middleware)
 	m.Use(middleware.OrgRedirect(hs.Cfg))
+	m.Use(hs.RateLimitService.Middleware)
+	m.Use(hs.AuditService.Middleware)
 
 	// needs to be after context handler
 	if hs.Cfg.EnforceDomain {
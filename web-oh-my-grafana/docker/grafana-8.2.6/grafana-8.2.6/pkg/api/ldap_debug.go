@@ -226,6 +226,21 @@ func (hs *HTTPServer) PostSyncUserWithLDAP(c *models.ReqContext) response.Respon
 	return response.Success("User synced successfully")
 }
 
+// GetLDAPSyncReport reports the changes the background LDAP sync would make to the users who logged in with LDAP,
+// without making them.
+func (hs *HTTPServer) GetLDAPSyncReport(c *models.ReqContext) response.Response {
+	if !ldap.IsEnabled() {
+		return response.Error(http.StatusBadRequest, "LDAP is not enabled", nil)
+	}
+
+	report, err := hs.TeamSyncService.SyncLDAPUsers(c.Req.Context(), true)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to sync the users with LDAP", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
 // GetUserFromLDAP finds an user based on a username in LDAP. This helps illustrate how would the particular user be mapped in Grafana when synced.
 func (hs *HTTPServer) GetUserFromLDAP(c *models.ReqContext) response.Response {
 	if !ldap.IsEnabled() {
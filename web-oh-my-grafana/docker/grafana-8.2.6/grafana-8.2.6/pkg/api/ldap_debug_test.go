@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/ldap"
 	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/services/teamsync"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,6 +46,11 @@ func (m *LDAPMock) Users(logins []string) ([]*models.ExternalUserInfo, error) {
 	return s, nil
 }
 
+func (m *LDAPMock) UsersWithServerErrors(logins []string) ([]*models.ExternalUserInfo, []*multildap.ServerStatus, error) {
+	s := []*models.ExternalUserInfo{}
+	return s, nil, nil
+}
+
 func (m *LDAPMock) User(login string) (*models.ExternalUserInfo, ldap.ServerConfig, error) {
 	return userSearchResult, userSearchConfig, userSearchError
 }
@@ -582,6 +588,67 @@ func TestPostSyncUserWithLDAPAPIEndpoint_WhenUserNotInLDAP(t *testing.T) {
 // Access control tests for ldap endpoints
 // ***
 
+// ***
+// GetLDAPSyncReport tests
+// ***
+
+func TestGetLDAPSyncReportAPIEndpoint(t *testing.T) {
+	sc := setupScenarioContext(t, "/api/admin/ldap/sync/report")
+
+	ldap := setting.LDAPEnabled
+	setting.LDAPEnabled = true
+	t.Cleanup(func() { setting.LDAPEnabled = ldap })
+
+	isAdmin := false
+	teamSyncService := teamsync.NewFakeTeamSyncService()
+	teamSyncService.Report.Synced = 2
+	teamSyncService.Report.Users = []*teamsync.LDAPSyncUserChange{
+		{UserID: 2, Login: "roel", Action: teamsync.LDAPSyncActionDisable, Reason: "not found in LDAP"},
+		{
+			UserID: 3, Login: "ldap-daniel", Action: teamsync.LDAPSyncActionUpdate,
+			OrgRoles: []*teamsync.LDAPSyncOrgRoleChange{
+				{OrgID: 1, Previous: models.ROLE_VIEWER, Role: models.ROLE_ADMIN},
+				{OrgID: 2, Previous: models.ROLE_EDITOR},
+			},
+			IsGrafanaAdmin: &isAdmin,
+		},
+	}
+	hs := &HTTPServer{Cfg: setting.NewCfg(), TeamSyncService: teamSyncService}
+
+	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.GetLDAPSyncReport(c)
+	})
+	sc.m.Get("/api/admin/ldap/sync/report", sc.defaultHandler)
+
+	sc.fakeReqNoAssertions(http.MethodGet, "/api/admin/ldap/sync/report").exec()
+
+	require.Equal(t, http.StatusOK, sc.resp.Code)
+	assert.Equal(t, []bool{true}, teamSyncService.DryRuns)
+
+	expected := `
+	{
+		"dryRun": true,
+		"started": "0001-01-01T00:00:00Z",
+		"duration": "",
+		"synced": 2,
+		"unchanged": 0,
+		"skipped": 0,
+		"users": [
+			{ "userId": 2, "login": "roel", "action": "disable", "reason": "not found in LDAP" },
+			{
+				"userId": 3, "login": "ldap-daniel", "action": "update",
+				"orgRoles": [
+					{ "orgId": 1, "previous": "Viewer", "role": "Admin" },
+					{ "orgId": 2, "previous": "Editor", "role": "" }
+				],
+				"isGrafanaAdmin": false
+			}
+		]
+	}
+	`
+	assert.JSONEq(t, expected, sc.resp.Body.String())
+}
+
 func TestLDAP_AccessControl(t *testing.T) {
 	tests := []accessControlTestCase{
 		{
@@ -656,6 +723,24 @@ func TestLDAP_AccessControl(t *testing.T) {
 				{Action: "wrong"},
 			},
 		},
+		{
+			url:          "/api/admin/ldap/sync/report",
+			method:       http.MethodGet,
+			desc:         "GetLDAPSyncReport should return 200 for user with required permissions",
+			expectedCode: http.StatusOK,
+			permissions: []*accesscontrol.Permission{
+				{Action: accesscontrol.ActionLDAPUsersRead},
+			},
+		},
+		{
+			url:          "/api/admin/ldap/sync/report",
+			method:       http.MethodGet,
+			desc:         "GetLDAPSyncReport should return 403 for user without required permissions",
+			expectedCode: http.StatusForbidden,
+			permissions: []*accesscontrol.Permission{
+				{Action: "wrong"},
+			},
+		},
 	}
 
 	for _, test := range tests {
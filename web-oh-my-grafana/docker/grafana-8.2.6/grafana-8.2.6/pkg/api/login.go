@@ -223,6 +223,23 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) res
 
 	user = authQuery.User
 
+	// two-factor authentication only applies to users logging in with
+	// their Grafana password
+	if authModule == "grafana" {
+		status, err := hs.TwoFactorService.GetStatus(c.Req.Context(), user.Id)
+		if err != nil {
+			resp = response.Error(http.StatusInternalServerError, "Error while trying to authenticate user", err)
+			return resp
+		}
+		if status.Enabled || status.Required {
+			resp = hs.startTwoFactorLogin(c, user, !status.Enabled)
+			return resp
+		}
+		if resp = hs.startPasswordChangeLogin(c, user); resp != nil {
+			return resp
+		}
+	}
+
 	err = hs.loginUserWithUser(user, c)
 	if err != nil {
 		var createTokenErr *models.CreateTokenErr
@@ -234,6 +251,14 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) res
 		return resp
 	}
 
+	metrics.MApiLoginPost.Inc()
+	resp = response.JSON(http.StatusOK, hs.loginResult(c))
+	return resp
+}
+
+// loginResult returns the response body of a successful login, including
+// where the user should be redirected to.
+func (hs *HTTPServer) loginResult(c *models.ReqContext) map[string]interface{} {
 	result := map[string]interface{}{
 		"message": "Logged in",
 	}
@@ -247,9 +272,7 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) res
 		cookies.DeleteCookie(c.Resp, "redirect_to", hs.CookieOptionsFromCfg)
 	}
 
-	metrics.MApiLoginPost.Inc()
-	resp = response.JSON(http.StatusOK, result)
-	return resp
+	return result
 }
 
 func (hs *HTTPServer) loginUserWithUser(user *models.User, c *models.ReqContext) error {
@@ -283,13 +306,22 @@ func (hs *HTTPServer) Logout(c *models.ReqContext) {
 		return
 	}
 
+	hs.revokeSession(c)
+	hs.redirectAfterLogout(c)
+}
+
+// revokeSession revokes the auth token of the request and deletes the session
+// cookie.
+func (hs *HTTPServer) revokeSession(c *models.ReqContext) {
 	err := hs.AuthTokenService.RevokeToken(c.Req.Context(), c.UserToken, false)
 	if err != nil && !errors.Is(err, models.ErrUserTokenNotFound) {
 		hs.log.Error("failed to revoke auth token", "error", err)
 	}
 
 	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+}
 
+func (hs *HTTPServer) redirectAfterLogout(c *models.ReqContext) {
 	if setting.SignoutRedirectUrl != "" {
 		c.Redirect(setting.SignoutRedirectUrl)
 	} else {
@@ -343,11 +375,11 @@ func (hs *HTTPServer) RedirectResponseWithError(ctx *models.ReqContext, err erro
 }
 
 func (hs *HTTPServer) samlEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "enabled").MustBool(false) && hs.License.HasValidLicense()
+	return hs.SAMLService.IsEnabled()
 }
 
 func (hs *HTTPServer) samlSingleLogoutEnabled() bool {
-	return hs.SettingsProvider.KeyValue("auth.saml", "single_logout").MustBool(false) && hs.samlEnabled()
+	return hs.SAMLService.IsSingleLogoutEnabled()
 }
 
 func getLoginExternalError(err error) string {
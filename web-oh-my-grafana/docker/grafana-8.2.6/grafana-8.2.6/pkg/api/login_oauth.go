@@ -192,6 +192,12 @@ func (hs *HTTPServer) OAuthLogin(ctx *models.ReqContext) {
 	hs.HooksService.RunLoginHook(&loginInfo, ctx)
 	metrics.MApiLoginOAuth.Inc()
 
+	hs.redirectAfterLogin(ctx)
+}
+
+// redirectAfterLogin redirects external logins to the page of the redirect_to
+// cookie or the home page.
+func (hs *HTTPServer) redirectAfterLogin(ctx *models.ReqContext) {
 	if redirectTo, err := url.QueryUnescape(ctx.GetCookie("redirect_to")); err == nil && len(redirectTo) > 0 {
 		if err := hs.ValidateRedirectTo(redirectTo); err == nil {
 			cookies.DeleteCookie(ctx.Resp, "redirect_to", hs.CookieOptionsFromCfg)
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/login"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const samlRequestIDCookieName = "saml_request_id"
+
+// samlCookieOptions returns the options of the cookie tracking the ID of the
+// authentication request, which is sent with the cross-site POST of the IdP to
+// the ACS endpoint.
+func (hs *HTTPServer) samlCookieOptions() cookies.CookieOptions {
+	options := hs.CookieOptionsFromCfg()
+	if options.Secure {
+		options.SameSiteDisabled = false
+		options.SameSiteMode = http.SameSiteNoneMode
+	} else {
+		// Browsers only accept SameSite=None for secure cookies.
+		options.SameSiteDisabled = true
+	}
+	return options
+}
+
+// GET /saml/metadata
+func (hs *HTTPServer) SAMLMetadata(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML authentication is not enabled", nil)
+		return
+	}
+
+	metadata, err := hs.SAMLService.Metadata()
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to build the SAML metadata", err)
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "application/xml")
+	c.Resp.WriteHeader(http.StatusOK)
+	if _, err := c.Resp.Write(metadata); err != nil {
+		hs.log.Error("Failed to write the SAML metadata", "err", err)
+	}
+}
+
+// GET /login/saml
+func (hs *HTTPServer) SAMLLogin(c *models.ReqContext) {
+	if !hs.SAMLService.IsEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML authentication is not enabled", nil)
+		return
+	}
+
+	redirectURL, requestID, err := hs.SAMLService.AuthnRequestURL()
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to create the SAML authentication request", err)
+		return
+	}
+
+	cookies.WriteCookie(c.Resp, samlRequestIDCookieName, requestID, hs.Cfg.OAuthCookieMaxAge, hs.samlCookieOptions)
+	c.Redirect(redirectURL)
+}
+
+// POST /saml/acs
+func (hs *HTTPServer) SAMLACS(c *models.ReqContext) {
+	loginInfo := models.LoginInfo{
+		AuthModule: models.AuthModuleSAML,
+	}
+	if !hs.SAMLService.IsEnabled() {
+		hs.handleOAuthLoginError(c, loginInfo, LoginError{
+			HttpStatus:    http.StatusNotFound,
+			PublicMessage: "SAML authentication is not enabled",
+		})
+		return
+	}
+
+	var possibleRequestIDs []string
+	if requestID := c.GetCookie(samlRequestIDCookieName); requestID != "" {
+		possibleRequestIDs = []string{requestID}
+	}
+	cookies.DeleteCookie(c.Resp, samlRequestIDCookieName, hs.samlCookieOptions)
+
+	extUser, err := hs.SAMLService.ParseResponse(c.Req, possibleRequestIDs)
+	if err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+	loginInfo.ExternalUser = *extUser
+
+	cmd := &models.UpsertUserCommand{
+		ReqContext:    c,
+		ExternalUser:  extUser,
+		SignupAllowed: hs.Cfg.SAML.AllowSignUp,
+	}
+	if err := bus.Dispatch(cmd); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+	loginInfo.User = cmd.Result
+
+	// Do not expose disabled status,
+	// just show incorrect user credentials error (see #17947)
+	if loginInfo.User.IsDisabled {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, login.ErrInvalidCredentials)
+		return
+	}
+
+	if err := hs.loginUserWithUser(loginInfo.User, c); err != nil {
+		hs.handleOAuthLoginErrorWithRedirect(c, loginInfo, err)
+		return
+	}
+
+	loginInfo.HTTPStatus = http.StatusOK
+	hs.HooksService.RunLoginHook(&loginInfo, c)
+	metrics.MApiLoginSAML.Inc()
+
+	hs.redirectAfterLogin(c)
+}
+
+// GET /logout/saml
+// SAMLLogout ends the session of the user and, when they logged in with SAML,
+// asks the IdP to end their other sessions.
+func (hs *HTTPServer) SAMLLogout(c *models.ReqContext) {
+	if !hs.SAMLService.IsSingleLogoutEnabled() {
+		hs.Logout(c)
+		return
+	}
+
+	authInfo := &models.GetAuthInfoQuery{UserId: c.UserId, AuthModule: models.AuthModuleSAML}
+	authInfoErr := bus.Dispatch(authInfo)
+
+	hs.revokeSession(c)
+
+	if authInfoErr != nil {
+		if !errors.Is(authInfoErr, models.ErrUserNotFound) {
+			hs.log.Error("Failed to get the SAML NameID of the user", "err", authInfoErr)
+		}
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	logoutURL, err := hs.SAMLService.LogoutRequestURL(authInfo.Result.AuthId)
+	if err != nil {
+		hs.log.Error("Failed to create the SAML logout request", "err", err)
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	c.Redirect(logoutURL)
+}
+
+// GET|POST /saml/slo
+// SAMLSingleLogout handles the logout requests of the IdP, and its answers to
+// the logout requests of Grafana.
+func (hs *HTTPServer) SAMLSingleLogout(c *models.ReqContext) {
+	if !hs.SAMLService.IsSingleLogoutEnabled() {
+		c.Handle(hs.Cfg, http.StatusNotFound, "SAML single logout is not enabled", nil)
+		return
+	}
+
+	if c.Req.FormValue("SAMLResponse") != "" {
+		if err := hs.SAMLService.ValidateLogoutResponse(c.Req); err != nil {
+			hs.log.Warn("Invalid SAML logout response", "err", err)
+		}
+		hs.redirectAfterLogout(c)
+		return
+	}
+
+	logoutRequest, err := hs.SAMLService.ParseLogoutRequest(c.Req)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusBadRequest, "Invalid SAML logout request", err)
+		return
+	}
+
+	authInfo := &models.GetAuthInfoQuery{AuthModule: models.AuthModuleSAML, AuthId: logoutRequest.NameID.Value}
+	err = bus.Dispatch(authInfo)
+	switch {
+	case err == nil:
+		if err := hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), authInfo.Result.UserId); err != nil {
+			c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to revoke the sessions of the user", err)
+			return
+		}
+		hs.log.Info("Successful SAML single logout", "userId", authInfo.Result.UserId)
+	case errors.Is(err, models.ErrUserNotFound):
+		hs.log.Debug("No user to log out for the SAML NameID", "nameID", logoutRequest.NameID.Value)
+	default:
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to find the user to log out", err)
+		return
+	}
+	cookies.WriteSessionCookie(c, hs.Cfg, "", -1)
+
+	logoutURL, err := hs.SAMLService.LogoutResponseURL(logoutRequest.ID)
+	if err != nil {
+		c.Handle(hs.Cfg, http.StatusInternalServerError, "Failed to create the SAML logout response", err)
+		return
+	}
+
+	c.Redirect(logoutURL)
+}
@@ -21,9 +21,13 @@ import (
 	"github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/saml"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -109,8 +113,9 @@ func TestLoginErrorCookieAPIEndpoint(t *testing.T) {
 		Cfg:               cfg,
 		SettingsProvider:  &setting.OSSImpl{Cfg: cfg},
 		License:           &licensing.OSSLicensingService{},
+		SAMLService:       saml.NewFakeSAMLService(),
 		SocialService:     &mockSocialService{},
-		EncryptionService: ossencryption.ProvideService(),
+		EncryptionService: &ossencryption.Service{},
 	}
 
 	sc.defaultHandler = routing.Wrap(func(w http.ResponseWriter, c *models.ReqContext) {
@@ -157,6 +162,7 @@ func TestLoginViewRedirect(t *testing.T) {
 		Cfg:              cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
 		License:          &licensing.OSSLicensingService{},
+		SAMLService:      saml.NewFakeSAMLService(),
 		SocialService:    &mockSocialService{},
 	}
 	hs.Cfg.CookieSecure = true
@@ -327,11 +333,15 @@ func TestLoginPostRedirect(t *testing.T) {
 	fakeViewIndex(t)
 	sc := setupScenarioContext(t, "/login")
 	hs := &HTTPServer{
-		log:              &FakeLogger{},
-		Cfg:              setting.NewCfg(),
-		HooksService:     &hooks.HooksService{},
-		License:          &licensing.OSSLicensingService{},
-		AuthTokenService: auth.NewFakeUserAuthTokenService(),
+		log:                   &FakeLogger{},
+		Cfg:                   setting.NewCfg(),
+		HooksService:          &hooks.HooksService{},
+		License:               &licensing.OSSLicensingService{},
+		SAMLService:           saml.NewFakeSAMLService(),
+		AuthTokenService:      auth.NewFakeUserAuthTokenService(),
+		TwoFactorService:      twofactor.NewFakeTwoFactorService(),
+		PasswordPolicyService: passwordpolicy.NewFakePasswordPolicyService(),
+		AuditService:          audit.NewFakeAuditService(),
 	}
 	hs.Cfg.CookieSecure = true
 
@@ -498,6 +508,7 @@ func TestLoginOAuthRedirect(t *testing.T) {
 		Cfg:              cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: cfg},
 		License:          &licensing.OSSLicensingService{},
+		SAMLService:      saml.NewFakeSAMLService(),
 		SocialService:    mock,
 	}
 
@@ -521,9 +532,10 @@ func TestLoginInternal(t *testing.T) {
 	fakeViewIndex(t)
 	sc := setupScenarioContext(t, "/login")
 	hs := &HTTPServer{
-		Cfg:     setting.NewCfg(),
-		License: &licensing.OSSLicensingService{},
-		log:     log.New("test"),
+		Cfg:         setting.NewCfg(),
+		License:     &licensing.OSSLicensingService{},
+		SAMLService: saml.NewFakeSAMLService(),
+		log:         log.New("test"),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) {
@@ -572,6 +584,7 @@ func setupAuthProxyLoginTest(t *testing.T, enableLoginToken bool) *scenarioConte
 		Cfg:              sc.cfg,
 		SettingsProvider: &setting.OSSImpl{Cfg: sc.cfg},
 		License:          &licensing.OSSLicensingService{},
+		SAMLService:      saml.NewFakeSAMLService(),
 		AuthTokenService: auth.NewFakeUserAuthTokenService(),
 		log:              log.New("hello"),
 		SocialService:    &mockSocialService{},
@@ -606,11 +619,15 @@ func TestLoginPostRunLokingHook(t *testing.T) {
 	sc := setupScenarioContext(t, "/login")
 	hookService := &hooks.HooksService{}
 	hs := &HTTPServer{
-		log:              log.New("test"),
-		Cfg:              setting.NewCfg(),
-		License:          &licensing.OSSLicensingService{},
-		AuthTokenService: auth.NewFakeUserAuthTokenService(),
-		HooksService:     hookService,
+		log:                   log.New("test"),
+		Cfg:                   setting.NewCfg(),
+		License:               &licensing.OSSLicensingService{},
+		SAMLService:           saml.NewFakeSAMLService(),
+		AuthTokenService:      auth.NewFakeUserAuthTokenService(),
+		HooksService:          hookService,
+		TwoFactorService:      twofactor.NewFakeTwoFactorService(),
+		PasswordPolicyService: passwordpolicy.NewFakePasswordPolicyService(),
+		AuditService:          audit.NewFakeAuditService(),
 	}
 
 	sc.defaultHandler = routing.Wrap(func(w http.ResponseWriter, c *models.ReqContext) response.Response {
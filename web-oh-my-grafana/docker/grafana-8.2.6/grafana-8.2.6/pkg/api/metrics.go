@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querycache"
 )
 
 // QueryMetricsV2 returns query metrics.
@@ -82,10 +83,12 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		return response.Error(http.StatusForbidden, "Access denied", err)
 	}
 
-	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
+	ctx, cacheStatus := querycache.WithStatus(c.Req.Context())
+	resp, err := hs.DataService.HandleRequest(ctx, ds, request)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Metric request error", err)
 	}
+	setCacheStatusHeader(c, cacheStatus)
 
 	// This is insanity... but ¯\_(ツ)_/¯, the current query path looks like:
 	//  encodeJson( decodeBase64( encodeBase64( decodeArrow( encodeArrow(frame)) ) )
@@ -97,6 +100,14 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 	return toMacronResponse(qdr)
 }
 
+// setCacheStatusHeader tells whether the response of a data source query
+// came from the query cache.
+func setCacheStatusHeader(c *models.ReqContext, status *querycache.Status) {
+	if status.Value == querycache.StatusHit || status.Value == querycache.StatusMiss {
+		c.Resp.Header().Set("X-Cache", status.Value)
+	}
+}
+
 func toMacronResponse(qdr *backend.QueryDataResponse) response.Response {
 	statusCode := http.StatusOK
 	for _, res := range qdr.Responses {
@@ -206,10 +217,12 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		})
 	}
 
-	resp, err := hs.DataService.HandleRequest(c.Req.Context(), ds, request)
+	ctx, cacheStatus := querycache.WithStatus(c.Req.Context())
+	resp, err := hs.DataService.HandleRequest(ctx, ds, request)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Metric request error", err)
 	}
+	setCacheStatusHeader(c, cacheStatus)
 
 	statusCode := http.StatusOK
 	for _, res := range resp.Results {
@@ -187,6 +187,10 @@ func (hs *HTTPServer) CompleteInvite(c *models.ReqContext, completeInvite dtos.C
 		SkipOrgSetup: true,
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), nil, cmd.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
 	user, err := hs.Login.CreateUser(cmd)
 	if err != nil {
 		if errors.Is(err, models.ErrUserAlreadyExists) {
@@ -34,8 +34,7 @@ func SendResetPasswordEmail(c *models.ReqContext, form dtos.SendResetPasswordEma
 	return response.Success("Email sent")
 }
 
-func ResetPassword(c *models.ReqContext, form dtos.ResetUserPasswordForm) response.Response {
-	return response.Success("Failed to change user password")
+func (hs *HTTPServer) ResetPassword(c *models.ReqContext, form dtos.ResetUserPasswordForm) response.Response {
 	query := models.ValidateResetPasswordCodeQuery{Code: form.Code}
 
 	if err := bus.Dispatch(&query); err != nil {
@@ -49,6 +48,11 @@ func ResetPassword(c *models.ReqContext, form dtos.ResetUserPasswordForm) respon
 		return response.Error(400, "Passwords do not match", nil)
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), query.Result, form.NewPassword); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+	return response.Success("Failed to change user password")
+
 	cmd := models.ChangeUserPasswordCommand{}
 	cmd.UserId = query.Result.Id
 	var err error
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	passwordChangeLoginCookieName = "grafana_password_change"
+	passwordChangeLoginKeyPrefix  = "password-change-login-%s"
+	passwordChangeLoginTimeout    = 10 * time.Minute
+)
+
+func init() {
+	remotecache.Register(&PasswordChangeLogin{})
+}
+
+// PasswordChangeLogin is a login waiting for the user to change their
+// expired password, kept in the remote cache so that it works across
+// instances.
+type PasswordChangeLogin struct {
+	UserID int64
+}
+
+// startPasswordChangeLogin is called once a user has been authenticated
+// with their Grafana password. When the password has to be changed, no
+// session is created until LoginChangePassword receives a new password and
+// the response asking for it is returned.
+func (hs *HTTPServer) startPasswordChangeLogin(c *models.ReqContext, user *models.User) *response.NormalResponse {
+	required, err := hs.PasswordPolicyService.ChangeRequired(c.Req.Context(), user)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+	if !required {
+		return nil
+	}
+
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+
+	err = hs.RemoteCacheService.Set(fmt.Sprintf(passwordChangeLoginKeyPrefix, token), &PasswordChangeLogin{
+		UserID: user.Id,
+	}, passwordChangeLoginTimeout)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+	cookies.WriteCookie(c.Resp, passwordChangeLoginCookieName, token, int(passwordChangeLoginTimeout.Seconds()), hs.CookieOptionsFromCfg)
+
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"message":                "Password change required",
+		"passwordChangeRequired": true,
+	})
+}
+
+func (hs *HTTPServer) getPasswordChangeLogin(c *models.ReqContext) (string, *models.User, error) {
+	token := c.GetCookie(passwordChangeLoginCookieName)
+	if token == "" {
+		return "", nil, models.ErrPasswordChangeNotFound
+	}
+
+	value, err := hs.RemoteCacheService.Get(fmt.Sprintf(passwordChangeLoginKeyPrefix, token))
+	if err != nil {
+		return "", nil, models.ErrPasswordChangeNotFound
+	}
+	pending, ok := value.(*PasswordChangeLogin)
+	if !ok {
+		return "", nil, models.ErrPasswordChangeNotFound
+	}
+
+	query := models.GetUserByIdQuery{Id: pending.UserID}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return "", nil, err
+	}
+	if query.Result.IsDisabled {
+		return "", nil, models.ErrPasswordChangeNotFound
+	}
+
+	return token, query.Result, nil
+}
+
+// LoginChangePassword completes a login started by LoginPost or
+// LoginTwoFactor by changing the password the user had to change.
+func (hs *HTTPServer) LoginChangePassword(c *models.ReqContext, form dtos.LoginChangePasswordForm) response.Response {
+	token, user, err := hs.getPasswordChangeLogin(c)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, "Password change expired, please log in again", err)
+	}
+
+	if form.NewPassword != form.ConfirmNew {
+		return response.Error(http.StatusBadRequest, "Passwords do not match", nil)
+	}
+	if models.Password(form.NewPassword).IsWeak() {
+		return response.Error(http.StatusBadRequest, "New password is too short", nil)
+	}
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), user, form.NewPassword); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
+	encoded, err := util.EncodePassword(form.NewPassword, user.Salt)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to encode password", err)
+	}
+	if encoded == user.Password {
+		return passwordPolicyErrorResponse(models.ErrPasswordReused)
+	}
+
+	cmd := models.ChangeUserPasswordCommand{UserId: user.Id, NewPassword: encoded}
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to change user password", err)
+	}
+
+	if err := hs.RemoteCacheService.Delete(fmt.Sprintf(passwordChangeLoginKeyPrefix, token)); err != nil {
+		hs.log.Warn("Failed to delete password change login", "err", err)
+	}
+	cookies.DeleteCookie(c.Resp, passwordChangeLoginCookieName, hs.CookieOptionsFromCfg)
+
+	if err := hs.loginUserWithUser(user, c); err != nil {
+		var createTokenErr *models.CreateTokenErr
+		if errors.As(err, &createTokenErr) {
+			return response.Error(createTokenErr.StatusCode, createTokenErr.ExternalErr, createTokenErr.InternalErr)
+		}
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+
+	metrics.MApiLoginPost.Inc()
+	return response.JSON(http.StatusOK, hs.loginResult(c))
+}
+
+func passwordPolicyErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, models.ErrPasswordTooShort),
+		errors.Is(err, models.ErrPasswordMissingCharacters),
+		errors.Is(err, models.ErrPasswordBreached),
+		errors.Is(err, models.ErrPasswordReused):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+
+	return response.Error(http.StatusInternalServerError, "Failed to validate password", err)
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginChangePassword(t *testing.T) {
+	sc := setupScenarioContext(t, "/login")
+	passwordPolicyService := passwordpolicy.NewFakePasswordPolicyService()
+	hs := &HTTPServer{
+		log:                   log.New("test"),
+		Cfg:                   setting.NewCfg(),
+		License:               &licensing.OSSLicensingService{},
+		AuthTokenService:      auth.NewFakeUserAuthTokenService(),
+		HooksService:          &hooks.HooksService{},
+		RemoteCacheService:    remotecache.NewFakeStore(t),
+		TwoFactorService:      twofactor.NewFakeTwoFactorService(),
+		PasswordPolicyService: passwordPolicyService,
+	}
+	hs.Cfg.LoginCookieName = "grafana_session"
+
+	encoded, err := util.EncodePassword("password", "salt")
+	require.NoError(t, err)
+	user := &models.User{Id: 42, Login: "admin", Password: encoded, Salt: "salt", PasswordChangeRequired: true}
+	bus.AddHandler("grafana-auth", func(query *models.LoginUserQuery) error {
+		query.User = user
+		query.AuthModule = "grafana"
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+		query.Result = user
+		return nil
+	})
+	var changed *models.ChangeUserPasswordCommand
+	bus.AddHandler("test", func(cmd *models.ChangeUserPasswordCommand) error {
+		changed = cmd
+		user.PasswordChangeRequired = false
+		return nil
+	})
+
+	form := dtos.LoginChangePasswordForm{}
+	sc.m.Post("/login", routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.LoginPost(c, dtos.LoginCommand{User: "admin", Password: "password"})
+	}))
+	sc.m.Post("/login/password", routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.LoginChangePassword(c, form)
+	}))
+
+	hasSession := func() bool {
+		for _, cookie := range sc.resp.Result().Cookies() {
+			if cookie.Name == hs.Cfg.LoginCookieName {
+				return true
+			}
+		}
+		return false
+	}
+
+	sc.fakeReqNoAssertions("POST", "/login").exec()
+	require.Equal(t, http.StatusOK, sc.resp.Code)
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &body))
+	assert.Equal(t, true, body["passwordChangeRequired"])
+	require.False(t, hasSession(), "no session should be created before the password is changed")
+
+	var cookie http.Cookie
+	for _, c := range sc.resp.Result().Cookies() {
+		if c.Name == passwordChangeLoginCookieName {
+			cookie = http.Cookie{Name: c.Name, Value: c.Value}
+		}
+	}
+	require.NotEmpty(t, cookie.Value)
+
+	t.Run("passwords have to match", func(t *testing.T) {
+		form = dtos.LoginChangePasswordForm{NewPassword: "new-password", ConfirmNew: "other-password"}
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/password", cookie).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+	})
+
+	t.Run("the current password can't be kept", func(t *testing.T) {
+		form = dtos.LoginChangePasswordForm{NewPassword: "password", ConfirmNew: "password"}
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/password", cookie).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.Contains(t, sc.resp.Body.String(), models.ErrPasswordReused.Error())
+	})
+
+	t.Run("the new password has to satisfy the policy", func(t *testing.T) {
+		passwordPolicyService.Err = models.ErrPasswordBreached
+		t.Cleanup(func() { passwordPolicyService.Err = nil })
+
+		form = dtos.LoginChangePasswordForm{NewPassword: "new-password", ConfirmNew: "new-password"}
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/password", cookie).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.Nil(t, changed)
+	})
+
+	t.Run("changing the password logs the user in", func(t *testing.T) {
+		form = dtos.LoginChangePasswordForm{NewPassword: "new-password", ConfirmNew: "new-password"}
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/password", cookie).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.True(t, hasSession())
+
+		require.NotNil(t, changed)
+		expected, err := util.EncodePassword("new-password", "salt")
+		require.NoError(t, err)
+		assert.Equal(t, expected, changed.NewPassword)
+		assert.False(t, changed.PasswordChangeRequired)
+
+		// the password change login can't be used again
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/password", cookie).exec()
+		assert.Equal(t, http.StatusUnauthorized, sc.resp.Code)
+	})
+}
+
+func TestPasswordChangesEnforcePasswordPolicy(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.PasswordPolicy = setting.PasswordPolicySettings{Enabled: true, MinLength: 12}
+	hs := &HTTPServer{
+		Cfg:                   cfg,
+		PasswordPolicyService: &passwordpolicy.PasswordPolicyService{Cfg: cfg},
+	}
+
+	encoded, err := util.EncodePassword("password", "salt")
+	require.NoError(t, err)
+	user := &models.User{Id: 42, Login: "admin", Password: encoded, Salt: "salt"}
+
+	t.Run("resetting the password to a weak password returns 400", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/api/user/password/reset")
+		bus.AddHandler("test", func(query *models.ValidateResetPasswordCodeQuery) error {
+			query.Result = user
+			return nil
+		})
+
+		form := dtos.ResetUserPasswordForm{Code: "code", NewPassword: "weak", ConfirmPassword: "weak"}
+		sc.m.Post("/api/user/password/reset", routing.Wrap(func(c *models.ReqContext) response.Response {
+			return hs.ResetPassword(c, form)
+		}))
+
+		sc.fakeReqNoAssertions("POST", "/api/user/password/reset").exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.Contains(t, sc.resp.Body.String(), models.ErrPasswordTooShort.Error())
+	})
+
+	t.Run("changing the password to a weak password returns 400", func(t *testing.T) {
+		sc := setupScenarioContext(t, "/api/user/password")
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+			query.Result = user
+			return nil
+		})
+
+		cmd := models.ChangeUserPasswordCommand{OldPassword: "password", NewPassword: "weak-pass"}
+		sc.m.Put("/api/user/password", routing.Wrap(func(c *models.ReqContext) response.Response {
+			c.SignedInUser = &models.SignedInUser{UserId: user.Id}
+			return hs.ChangeUserPassword(c, cmd)
+		}))
+
+		sc.fakeReqNoAssertions("PUT", "/api/user/password").exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.Contains(t, sc.resp.Body.String(), models.ErrPasswordTooShort.Error())
+	})
+}
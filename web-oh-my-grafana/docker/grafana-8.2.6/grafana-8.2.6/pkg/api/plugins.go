@@ -154,6 +154,13 @@ func (hs *HTTPServer) UpdatePluginSetting(c *models.ReqContext, cmd models.Updat
 
 	cmd.OrgId = c.OrgId
 	cmd.PluginId = pluginID
+
+	var err error
+	cmd.EncryptedSecureJsonData, err = hs.EncryptionService.EncryptJsonData(cmd.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		return response.Error(500, "Failed to update plugin setting", err)
+	}
+
 	if err := bus.Dispatch(&cmd); err != nil {
 		return response.Error(500, "Failed to update plugin setting", err)
 	}
@@ -1,9 +1,11 @@
 package api
 
 import (
+	"github.com/grafana/grafana/pkg/api/apierrors"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/setting"
 	macaron "gopkg.in/macaron.v1"
 )
@@ -28,7 +30,9 @@ func UpdateOrgQuota(c *models.ReqContext, cmd models.UpdateOrgQuotaCmd) response
 	cmd.OrgId = c.ParamsInt64(":orgId")
 	cmd.Target = macaron.Params(c.Req)[":target"]
 
-	if _, ok := setting.Quota.Org.ToMap()[cmd.Target]; !ok {
+	_, isOrgTarget := setting.Quota.Org.ToMap()[cmd.Target]
+	_, isRateTarget := setting.Quota.OrgRate.ToMap()[cmd.Target]
+	if !isOrgTarget && !isRateTarget {
 		return response.Error(404, "Invalid quota target", nil)
 	}
 
@@ -38,6 +42,69 @@ func UpdateOrgQuota(c *models.ReqContext, cmd models.UpdateOrgQuotaCmd) response
 	return response.Success("Organization quota updated")
 }
 
+// GetOrgQuotaStatus reports the usage of all quotas of the current
+// organization, including folder and rate quotas.
+func (hs *HTTPServer) GetOrgQuotaStatus(c *models.ReqContext) response.Response {
+	if !hs.Cfg.Quota.Enabled {
+		return response.Error(404, "Quotas not enabled", nil)
+	}
+
+	status, err := hs.QuotaService.GetQuotaStatus(c.OrgId)
+	if err != nil {
+		return response.Error(500, "Failed to get quota status", err)
+	}
+
+	return response.JSON(200, status)
+}
+
+func (hs *HTTPServer) GetFolderQuotas(c *models.ReqContext) response.Response {
+	if !hs.Cfg.Quota.Enabled {
+		return response.Error(404, "Quotas not enabled", nil)
+	}
+
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	folder, err := s.GetFolderByUID(c.Req.Context(), macaron.Params(c.Req)[":uid"])
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	result := make([]*models.FolderQuotaDTO, 0)
+	for target, limit := range hs.Cfg.Quota.Folder.ToMap() {
+		query := models.GetFolderQuotaByTargetQuery{OrgId: c.OrgId, FolderId: folder.Id, Target: target, Default: limit}
+		if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+			return response.Error(500, "Failed to get folder quotas", err)
+		}
+		query.Result.FolderUid = folder.Uid
+		result = append(result, query.Result)
+	}
+
+	return response.JSON(200, result)
+}
+
+func (hs *HTTPServer) UpdateFolderQuota(c *models.ReqContext, cmd models.UpdateFolderQuotaCmd) response.Response {
+	if !hs.Cfg.Quota.Enabled {
+		return response.Error(404, "Quotas not enabled", nil)
+	}
+
+	cmd.OrgId = c.OrgId
+	cmd.Target = macaron.Params(c.Req)[":target"]
+	if _, ok := hs.Cfg.Quota.Folder.ToMap()[cmd.Target]; !ok {
+		return response.Error(404, "Invalid quota target", nil)
+	}
+
+	s := dashboards.NewFolderService(c.OrgId, c.SignedInUser, hs.SQLStore)
+	folder, err := s.GetFolderByUID(c.Req.Context(), macaron.Params(c.Req)[":uid"])
+	if err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+	cmd.FolderId = folder.Id
+
+	if err := bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		return response.Error(500, "Failed to update folder quota", err)
+	}
+	return response.Success("Folder quota updated")
+}
+
 func GetUserQuotas(c *models.ReqContext) response.Response {
 	if !setting.Quota.Enabled {
 		return response.Error(404, "Quotas not enabled", nil)
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// GET /api/serviceaccounts
+func (hs *HTTPServer) GetServiceAccounts(c *models.ReqContext) response.Response {
+	accounts, err := hs.SQLStore.GetServiceAccounts(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list service accounts", err)
+	}
+
+	return response.JSON(http.StatusOK, accounts)
+}
+
+// POST /api/serviceaccounts
+func (hs *HTTPServer) CreateServiceAccount(c *models.ReqContext, cmd models.CreateServiceAccountCommand) response.Response {
+	if !cmd.Role.IsValid() {
+		return response.Error(http.StatusBadRequest, "Invalid role specified", nil)
+	}
+	cmd.OrgId = c.OrgId
+
+	if err := hs.SQLStore.CreateServiceAccount(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to create service account")
+	}
+
+	return response.JSON(http.StatusOK, cmd.Result)
+}
+
+// GET /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) GetServiceAccount(c *models.ReqContext) response.Response {
+	account, err := hs.SQLStore.GetServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"))
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to get service account")
+	}
+
+	return response.JSON(http.StatusOK, account)
+}
+
+// PATCH /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) UpdateServiceAccount(c *models.ReqContext, cmd models.UpdateServiceAccountCommand) response.Response {
+	if cmd.Role != "" && !cmd.Role.IsValid() {
+		return response.Error(http.StatusBadRequest, "Invalid role specified", nil)
+	}
+	cmd.Id = c.ParamsInt64(":serviceAccountId")
+	cmd.OrgId = c.OrgId
+
+	if err := hs.SQLStore.UpdateServiceAccount(c.Req.Context(), &cmd); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to update service account")
+	}
+
+	return response.Success("Service account updated")
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId
+func (hs *HTTPServer) DeleteServiceAccount(c *models.ReqContext) response.Response {
+	if err := hs.SQLStore.DeleteServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId")); err != nil {
+		return serviceAccountErrorResponse(err, "Failed to delete service account")
+	}
+
+	return response.Success("Service account deleted")
+}
+
+// GET /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) GetServiceAccountTokens(c *models.ReqContext) response.Response {
+	tokens, err := hs.SQLStore.GetServiceAccountTokens(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"))
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to list service account tokens")
+	}
+
+	result := make([]*models.ServiceAccountTokenDTO, len(tokens))
+	for i, t := range tokens {
+		var expiration *time.Time
+		if t.Expires != nil {
+			v := time.Unix(*t.Expires, 0)
+			expiration = &v
+		}
+		result[i] = &models.ServiceAccountTokenDTO{
+			Id:         t.Id,
+			Name:       t.Name,
+			Created:    t.Created,
+			Expiration: expiration,
+			LastUsedAt: t.LastUsedAt,
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// POST /api/serviceaccounts/:serviceAccountId/tokens
+func (hs *HTTPServer) CreateServiceAccountToken(c *models.ReqContext, form dtos.AddServiceAccountTokenForm) response.Response {
+	account, err := hs.SQLStore.GetServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"))
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to get service account")
+	}
+
+	if hs.Cfg.ApiKeyMaxSecondsToLive != -1 {
+		if form.SecondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if form.SecondsToLive > hs.Cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	newKeyInfo, err := apikeygen.New(c.OrgId, form.Name)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd := models.AddApiKeyCommand{
+		Name:             form.Name,
+		Role:             account.Role,
+		OrgId:            c.OrgId,
+		Key:              newKeyInfo.HashedKey,
+		SecondsToLive:    form.SecondsToLive,
+		ServiceAccountId: &account.Id,
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrInvalidApiKeyExpiration) {
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		}
+		if errors.Is(err, models.ErrDuplicateApiKey) {
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to add service account token", err)
+	}
+
+	return response.JSON(http.StatusOK, &dtos.NewApiKeyResult{
+		ID:   cmd.Result.Id,
+		Name: cmd.Result.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// DELETE /api/serviceaccounts/:serviceAccountId/tokens/:tokenId
+func (hs *HTTPServer) DeleteServiceAccountToken(c *models.ReqContext) response.Response {
+	err := hs.SQLStore.DeleteServiceAccountToken(c.Req.Context(), c.OrgId,
+		c.ParamsInt64(":serviceAccountId"), c.ParamsInt64(":tokenId"))
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to delete service account token")
+	}
+
+	return response.Success("Service account token deleted")
+}
+
+// GET /api/serviceaccounts/:serviceAccountId/permissions
+func (hs *HTTPServer) GetServiceAccountPermissions(c *models.ReqContext) response.Response {
+	account, err := hs.SQLStore.GetServiceAccount(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"))
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to get service account")
+	}
+
+	query := models.GetServiceAccountPermissionsQuery{OrgId: c.OrgId, ServiceAccountId: account.Id}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get service account permissions", err)
+	}
+
+	result := dtos.UpdateServiceAccountPermissionsForm{Permissions: make([]accesscontrol.Permission, len(query.Result))}
+	for i, p := range query.Result {
+		result.Permissions[i] = accesscontrol.Permission{Action: p.Action, Scope: p.Scope}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// PUT /api/serviceaccounts/:serviceAccountId/permissions
+func (hs *HTTPServer) UpdateServiceAccountPermissions(c *models.ReqContext, form dtos.UpdateServiceAccountPermissionsForm) response.Response {
+	permissions := make([]*models.ServiceAccountPermission, 0, len(form.Permissions))
+	for _, p := range form.Permissions {
+		if p.Action == "" {
+			return response.Error(http.StatusBadRequest, "Permission action is required", nil)
+		}
+		if !accesscontrol.IsOrgAction(p.Action) {
+			return response.Error(http.StatusForbidden, "Only organization permissions can be granted to a service account", nil)
+		}
+
+		// a permission without scope can be used for any scope
+		evaluator := accesscontrol.EvalPermission(p.Action)
+		if p.Scope != "" {
+			evaluator = accesscontrol.EvalPermission(p.Action, p.Scope)
+		}
+		hasAccess, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !hasAccess {
+			return response.Error(http.StatusForbidden, "Cannot grant a permission you don't have", nil)
+		}
+
+		permissions = append(permissions, &models.ServiceAccountPermission{Action: p.Action, Scope: p.Scope})
+	}
+
+	err := hs.SQLStore.SetServiceAccountPermissions(c.Req.Context(), c.OrgId, c.ParamsInt64(":serviceAccountId"), permissions)
+	if err != nil {
+		return serviceAccountErrorResponse(err, "Failed to update service account permissions")
+	}
+
+	return response.Success("Service account permissions updated")
+}
+
+func serviceAccountErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, models.ErrServiceAccountNotFound), errors.Is(err, models.ErrServiceAccountTokenNotFound):
+		return response.Error(http.StatusNotFound, err.Error(), err)
+	case errors.Is(err, models.ErrServiceAccountAlreadyExists):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, models.ErrServiceAccountPermissionNotOrgScoped):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+
+	return response.Error(http.StatusInternalServerError, message, err)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateServiceAccountPermissions(t *testing.T) {
+	orgAdminPermissions := []*accesscontrol.Permission{
+		{Action: accesscontrol.ActionOrgUsersRead, Scope: accesscontrol.ScopeUsersAll},
+		{Action: accesscontrol.ActionOrgUsersAdd, Scope: accesscontrol.ScopeUsersAll},
+	}
+
+	updateServiceAccountPermissionsScenario(t, "When an org admin grants a permission on all users",
+		"/api/serviceaccounts/2/permissions", "/api/serviceaccounts/:serviceAccountId/permissions", orgAdminPermissions,
+		dtos.UpdateServiceAccountPermissionsForm{Permissions: []accesscontrol.Permission{
+			{Action: accesscontrol.ActionUsersWrite, Scope: accesscontrol.ScopeGlobalUsersAll},
+		}}, func(sc *scenarioContext) {
+			sc.fakeReq("PUT", sc.url).exec()
+			assert.Equal(t, http.StatusForbidden, sc.resp.Code)
+		})
+
+	updateServiceAccountPermissionsScenario(t, "When an org admin grants a permission they don't have",
+		"/api/serviceaccounts/2/permissions", "/api/serviceaccounts/:serviceAccountId/permissions", orgAdminPermissions,
+		dtos.UpdateServiceAccountPermissionsForm{Permissions: []accesscontrol.Permission{
+			{Action: accesscontrol.ActionOrgUsersRemove, Scope: accesscontrol.ScopeUsersAll},
+		}}, func(sc *scenarioContext) {
+			sc.fakeReq("PUT", sc.url).exec()
+			assert.Equal(t, http.StatusForbidden, sc.resp.Code)
+		})
+
+	updateServiceAccountPermissionsScenario(t, "When an org admin grants a permission without action",
+		"/api/serviceaccounts/2/permissions", "/api/serviceaccounts/:serviceAccountId/permissions", orgAdminPermissions,
+		dtos.UpdateServiceAccountPermissionsForm{Permissions: []accesscontrol.Permission{
+			{Scope: accesscontrol.ScopeUsersAll},
+		}}, func(sc *scenarioContext) {
+			sc.fakeReq("PUT", sc.url).exec()
+			assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		})
+}
+
+func updateServiceAccountPermissionsScenario(t *testing.T, desc string, url string, routePattern string,
+	permissions []*accesscontrol.Permission, form dtos.UpdateServiceAccountPermissionsForm, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+
+		hs := &HTTPServer{
+			Cfg:           setting.NewCfg(),
+			AccessControl: accesscontrolmock.New().WithPermissions(permissions),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserId = testUserID
+			sc.context.OrgId = testOrgID
+			sc.context.OrgRole = models.ROLE_ADMIN
+
+			return hs.UpdateServiceAccountPermissions(c, form)
+		})
+
+		sc.m.Put(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
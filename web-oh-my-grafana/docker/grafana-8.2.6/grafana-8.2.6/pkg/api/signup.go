@@ -73,6 +73,10 @@ func (hs *HTTPServer) SignUpStep2(c *models.ReqContext, form dtos.SignUpStep2For
 		OrgName:  form.OrgName,
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), nil, form.Password); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+
 	// verify email
 	if setting.VerifyEmailEnabled {
 		if ok, rsp := verifyUserSignUpEmail(form.Email, form.Code); !ok {
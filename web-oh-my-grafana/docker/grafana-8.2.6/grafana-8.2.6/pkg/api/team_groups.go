@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/teamguardian"
+	"gopkg.in/macaron.v1"
+)
+
+// GET /api/teams/:teamId/groups
+func (hs *HTTPServer) GetTeamGroups(c *models.ReqContext) response.Response {
+	teamId := c.ParamsInt64(":teamId")
+
+	if err := teamguardian.CanAdmin(hs.Bus, c.OrgId, teamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to view team groups", err)
+	}
+
+	query := models.GetTeamGroupsQuery{OrgId: c.OrgId, TeamId: teamId}
+	if err := bus.Dispatch(&query); err != nil {
+		return response.Error(500, "Failed to get Team Groups", err)
+	}
+
+	return response.JSON(200, query.Result)
+}
+
+// POST /api/teams/:teamId/groups
+func (hs *HTTPServer) AddTeamGroup(c *models.ReqContext, cmd models.AddTeamGroupCommand) response.Response {
+	cmd.OrgId = c.OrgId
+	cmd.TeamId = c.ParamsInt64(":teamId")
+
+	if err := teamguardian.CanAdmin(hs.Bus, cmd.OrgId, cmd.TeamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to add team group", err)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return response.Error(404, "Team not found", nil)
+		}
+
+		if errors.Is(err, models.ErrTeamGroupAlreadyAdded) {
+			return response.Error(400, "Group is already added to this team", nil)
+		}
+
+		return response.Error(500, "Failed to add Group to Team", err)
+	}
+	audit.SetAction(c.Req.Context(), "team.group.add", "team", strconv.FormatInt(cmd.TeamId, 10))
+
+	return response.Success("Group added to Team")
+}
+
+// DELETE /api/teams/:teamId/groups/*
+// The group ID is the rest of the path, as groups of OAuth providers and LDAP
+// DNs can contain slashes.
+func (hs *HTTPServer) RemoveTeamGroup(c *models.ReqContext) response.Response {
+	cmd := models.RemoveTeamGroupCommand{
+		OrgId:   c.OrgId,
+		TeamId:  c.ParamsInt64(":teamId"),
+		GroupId: macaron.Params(c.Req)["*"],
+	}
+
+	if err := teamguardian.CanAdmin(hs.Bus, cmd.OrgId, cmd.TeamId, c.SignedInUser); err != nil {
+		return response.Error(403, "Not allowed to remove team group", err)
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if errors.Is(err, models.ErrTeamNotFound) {
+			return response.Error(404, "Team not found", nil)
+		}
+
+		if errors.Is(err, models.ErrTeamGroupNotFound) {
+			return response.Error(404, "Team group not found", nil)
+		}
+
+		return response.Error(500, "Failed to remove Group from Team", err)
+	}
+	audit.SetAction(c.Req.Context(), "team.group.remove", "team", strconv.FormatInt(cmd.TeamId, 10))
+
+	return response.Success("Group removed from Team")
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	twoFactorLoginCookieName = "grafana_2fa_login"
+	twoFactorLoginKeyPrefix  = "2fa-login-%s"
+	twoFactorLoginTimeout    = 5 * time.Minute
+)
+
+func init() {
+	remotecache.Register(&TwoFactorLogin{})
+}
+
+// TwoFactorLogin is a login waiting for the second factor of the user,
+// kept in the remote cache so that it works across instances.
+type TwoFactorLogin struct {
+	UserID             int64
+	EnrollmentRequired bool
+}
+
+// startTwoFactorLogin is called once the password of a user requiring
+// two-factor authentication has been validated. No session is created
+// until LoginTwoFactor receives a valid code.
+func (hs *HTTPServer) startTwoFactorLogin(c *models.ReqContext, user *models.User, enrollmentRequired bool) *response.NormalResponse {
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+
+	err = hs.RemoteCacheService.Set(fmt.Sprintf(twoFactorLoginKeyPrefix, token), &TwoFactorLogin{
+		UserID:             user.Id,
+		EnrollmentRequired: enrollmentRequired,
+	}, twoFactorLoginTimeout)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+	cookies.WriteCookie(c.Resp, twoFactorLoginCookieName, token, int(twoFactorLoginTimeout.Seconds()), hs.CookieOptionsFromCfg)
+
+	return response.JSON(http.StatusOK, map[string]interface{}{
+		"message":            "Two-factor authentication required",
+		"twoFactorRequired":  true,
+		"enrollmentRequired": enrollmentRequired,
+	})
+}
+
+func (hs *HTTPServer) getTwoFactorLogin(c *models.ReqContext) (string, *TwoFactorLogin, *models.User, error) {
+	token := c.GetCookie(twoFactorLoginCookieName)
+	if token == "" {
+		return "", nil, nil, models.ErrTwoFactorLoginNotFound
+	}
+
+	value, err := hs.RemoteCacheService.Get(fmt.Sprintf(twoFactorLoginKeyPrefix, token))
+	if err != nil {
+		return "", nil, nil, models.ErrTwoFactorLoginNotFound
+	}
+	pending, ok := value.(*TwoFactorLogin)
+	if !ok {
+		return "", nil, nil, models.ErrTwoFactorLoginNotFound
+	}
+
+	query := models.GetUserByIdQuery{Id: pending.UserID}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return "", nil, nil, err
+	}
+	if query.Result.IsDisabled {
+		return "", nil, nil, models.ErrTwoFactorLoginNotFound
+	}
+
+	return token, pending, query.Result, nil
+}
+
+// LoginTwoFactor completes a login started by LoginPost with a code from
+// the authenticator app of the user or a recovery code. Users who have to
+// enroll first confirm their enrollment with their first code.
+func (hs *HTTPServer) LoginTwoFactor(c *models.ReqContext, form dtos.TwoFactorCodeForm) response.Response {
+	token, pending, user, err := hs.getTwoFactorLogin(c)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, "Two-factor login expired, please log in again", err)
+	}
+
+	if pending.EnrollmentRequired {
+		err = hs.TwoFactorService.Enable(c.Req.Context(), user, form.Code, c.RemoteAddr())
+	} else {
+		err = hs.TwoFactorService.Verify(c.Req.Context(), user, form.Code, c.RemoteAddr())
+	}
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+
+	if err := hs.RemoteCacheService.Delete(fmt.Sprintf(twoFactorLoginKeyPrefix, token)); err != nil {
+		hs.log.Warn("Failed to delete two-factor login", "err", err)
+	}
+	cookies.DeleteCookie(c.Resp, twoFactorLoginCookieName, hs.CookieOptionsFromCfg)
+
+	if resp := hs.startPasswordChangeLogin(c, user); resp != nil {
+		return resp
+	}
+
+	if err := hs.loginUserWithUser(user, c); err != nil {
+		var createTokenErr *models.CreateTokenErr
+		if errors.As(err, &createTokenErr) {
+			return response.Error(createTokenErr.StatusCode, createTokenErr.ExternalErr, createTokenErr.InternalErr)
+		}
+		return response.Error(http.StatusInternalServerError, "Error while signing in user", err)
+	}
+
+	metrics.MApiLoginPost.Inc()
+	return response.JSON(http.StatusOK, hs.loginResult(c))
+}
+
+// LoginTwoFactorEnroll enrolls a user who has to use two-factor
+// authentication but hasn't enrolled yet, during their login.
+func (hs *HTTPServer) LoginTwoFactorEnroll(c *models.ReqContext) response.Response {
+	_, pending, user, err := hs.getTwoFactorLogin(c)
+	if err != nil {
+		return response.Error(http.StatusUnauthorized, "Two-factor login expired, please log in again", err)
+	}
+	if !pending.EnrollmentRequired {
+		return response.Error(http.StatusBadRequest, "Two-factor authentication is already enrolled", nil)
+	}
+
+	enrollment, err := hs.TwoFactorService.Enroll(c.Req.Context(), user)
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// GET /api/user/2fa
+func (hs *HTTPServer) GetUserTwoFactorStatus(c *models.ReqContext) response.Response {
+	status, err := hs.TwoFactorService.GetStatus(c.Req.Context(), c.UserId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+
+	return response.JSON(http.StatusOK, status)
+}
+
+// POST /api/user/2fa/enroll
+func (hs *HTTPServer) EnrollUserTwoFactor(c *models.ReqContext) response.Response {
+	enrollment, err := hs.TwoFactorService.Enroll(c.Req.Context(), signedInUser(c))
+	if err != nil {
+		return twoFactorErrorResponse(err)
+	}
+
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// POST /api/user/2fa/enable
+func (hs *HTTPServer) EnableUserTwoFactor(c *models.ReqContext, form dtos.TwoFactorCodeForm) response.Response {
+	if err := hs.TwoFactorService.Enable(c.Req.Context(), signedInUser(c), form.Code, c.RemoteAddr()); err != nil {
+		return twoFactorErrorResponse(err)
+	}
+
+	return response.Success("Two-factor authentication enabled")
+}
+
+// POST /api/user/2fa/disable
+func (hs *HTTPServer) DisableUserTwoFactor(c *models.ReqContext, form dtos.TwoFactorCodeForm) response.Response {
+	status, err := hs.TwoFactorService.GetStatus(c.Req.Context(), c.UserId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+	if status.Required {
+		return twoFactorErrorResponse(models.ErrTwoFactorRequiredByPolicy)
+	}
+
+	if err := hs.TwoFactorService.Verify(c.Req.Context(), signedInUser(c), form.Code, c.RemoteAddr()); err != nil {
+		return twoFactorErrorResponse(err)
+	}
+	if err := hs.TwoFactorService.Disable(c.Req.Context(), c.UserId); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+	}
+
+	return response.Success("Two-factor authentication disabled")
+}
+
+// DELETE /api/admin/users/:id/2fa
+func (hs *HTTPServer) AdminResetUserTwoFactor(c *models.ReqContext) response.Response {
+	userID := c.ParamsInt64(":id")
+	if err := hs.TwoFactorService.Disable(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+	}
+
+	return response.Success("Two-factor authentication reset")
+}
+
+// GET /api/org/auth-policy
+func (hs *HTTPServer) GetOrgAuthPolicy(c *models.ReqContext) response.Response {
+	policy, err := hs.TwoFactorService.GetOrgPolicy(c.Req.Context(), c.OrgId)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get authentication policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// PUT /api/org/auth-policy
+func (hs *HTTPServer) UpdateOrgAuthPolicy(c *models.ReqContext, form dtos.UpdateOrgAuthPolicyForm) response.Response {
+	policy := &models.OrgAuthPolicy{
+		OrgId:                     c.OrgId,
+		RequireTwoFactorForAdmins: form.RequireTwoFactorForAdmins,
+	}
+	if err := hs.TwoFactorService.SaveOrgPolicy(c.Req.Context(), policy); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update authentication policy", err)
+	}
+
+	return response.Success("Authentication policy updated")
+}
+
+func signedInUser(c *models.ReqContext) *models.User {
+	return &models.User{Id: c.UserId, Login: c.Login}
+}
+
+func twoFactorErrorResponse(err error) response.Response {
+	switch {
+	case errors.Is(err, models.ErrTwoFactorInvalidCode):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, models.ErrTwoFactorTooManyAttempts):
+		return response.Error(http.StatusTooManyRequests, err.Error(), err)
+	case errors.Is(err, models.ErrTwoFactorNotEnrolled), errors.Is(err, models.ErrTwoFactorEnrollmentMissing):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, models.ErrTwoFactorAlreadyEnabled):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, models.ErrTwoFactorRequiredByPolicy):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+
+	return response.Error(http.StatusInternalServerError, "Two-factor authentication failed", err)
+}
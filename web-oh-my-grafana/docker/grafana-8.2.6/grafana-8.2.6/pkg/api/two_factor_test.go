@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/hooks"
+	"github.com/grafana/grafana/pkg/services/licensing"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginTwoFactor(t *testing.T) {
+	sc := setupScenarioContext(t, "/login")
+	twoFactorService := twofactor.NewFakeTwoFactorService()
+	twoFactorService.ValidCodes = []string{"123456"}
+	hs := &HTTPServer{
+		log:                   log.New("test"),
+		Cfg:                   setting.NewCfg(),
+		License:               &licensing.OSSLicensingService{},
+		AuthTokenService:      auth.NewFakeUserAuthTokenService(),
+		HooksService:          &hooks.HooksService{},
+		RemoteCacheService:    remotecache.NewFakeStore(t),
+		TwoFactorService:      twoFactorService,
+		PasswordPolicyService: passwordpolicy.NewFakePasswordPolicyService(),
+	}
+	hs.Cfg.LoginCookieName = "grafana_session"
+
+	user := &models.User{Id: 42, Login: "admin"}
+	bus.AddHandler("grafana-auth", func(query *models.LoginUserQuery) error {
+		query.User = user
+		query.AuthModule = "grafana"
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+		query.Result = user
+		return nil
+	})
+
+	code := "000000"
+	sc.m.Post("/login", routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.LoginPost(c, dtos.LoginCommand{User: "admin", Password: "password"})
+	}))
+	sc.m.Post("/login/2fa", routing.Wrap(func(c *models.ReqContext) response.Response {
+		return hs.LoginTwoFactor(c, dtos.TwoFactorCodeForm{Code: code})
+	}))
+	sc.m.Post("/login/2fa/enroll", routing.Wrap(hs.LoginTwoFactorEnroll))
+
+	login := func(t *testing.T) http.Cookie {
+		sc.fakeReqNoAssertions("POST", "/login").exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &body))
+		assert.Equal(t, true, body["twoFactorRequired"])
+		assert.Equal(t, !twoFactorService.Status.Enabled, body["enrollmentRequired"])
+
+		for _, cookie := range sc.resp.Result().Cookies() {
+			require.NotEqual(t, hs.Cfg.LoginCookieName, cookie.Name, "no session should be created before the second factor")
+			if cookie.Name == twoFactorLoginCookieName {
+				return http.Cookie{Name: cookie.Name, Value: cookie.Value}
+			}
+		}
+		require.Fail(t, "two-factor login cookie not set")
+		return http.Cookie{}
+	}
+	hasSession := func() bool {
+		for _, cookie := range sc.resp.Result().Cookies() {
+			if cookie.Name == hs.Cfg.LoginCookieName {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("users without two-factor authentication are logged in", func(t *testing.T) {
+		sc.fakeReqNoAssertions("POST", "/login").exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.NotContains(t, sc.resp.Body.String(), "twoFactorRequired")
+	})
+
+	t.Run("users required to use two-factor authentication enroll during login", func(t *testing.T) {
+		twoFactorService.Status = models.TwoFactorStatus{Required: true}
+		cookie := login(t)
+
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa/enroll", cookie).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Contains(t, sc.resp.Body.String(), "otpauth://totp/Grafana:admin")
+
+		code = "123456"
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa", cookie).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.True(t, twoFactorService.Status.Enabled)
+	})
+
+	t.Run("users with two-factor authentication need a valid code", func(t *testing.T) {
+		cookie := login(t)
+
+		code = "000000"
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa", cookie).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+		assert.False(t, hasSession())
+
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa/enroll", cookie).exec()
+		assert.Equal(t, http.StatusBadRequest, sc.resp.Code)
+
+		code = "123456"
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa", cookie).exec()
+		require.Equal(t, http.StatusOK, sc.resp.Code)
+		assert.Contains(t, sc.resp.Body.String(), "Logged in")
+		assert.True(t, hasSession())
+
+		// the two-factor login can't be used again
+		sc.fakeReqNoAssertionsWithCookie("POST", "/login/2fa", cookie).exec()
+		assert.Equal(t, http.StatusUnauthorized, sc.resp.Code)
+	})
+
+	t.Run("second step without a two-factor login fails", func(t *testing.T) {
+		sc.fakeReqNoAssertions("POST", "/login/2fa").exec()
+		assert.Equal(t, http.StatusUnauthorized, sc.resp.Code)
+	})
+}
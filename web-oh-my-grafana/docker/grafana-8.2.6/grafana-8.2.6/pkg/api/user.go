@@ -216,8 +216,7 @@ func (hs *HTTPServer) ChangeActiveOrgAndRedirectToHome(c *models.ReqContext) {
 	c.Redirect(hs.Cfg.AppSubURL + "/")
 }
 
-func ChangeUserPassword(c *models.ReqContext, cmd models.ChangeUserPasswordCommand) response.Response {
-	return response.Success("Failed to change user password")
+func (hs *HTTPServer) ChangeUserPassword(c *models.ReqContext, cmd models.ChangeUserPasswordCommand) response.Response {
 	if setting.LDAPEnabled || setting.AuthProxyEnabled {
 		return response.Error(400, "Not allowed to change password when LDAP or Auth Proxy is enabled", nil)
 	}
@@ -241,6 +240,11 @@ func ChangeUserPassword(c *models.ReqContext, cmd models.ChangeUserPasswordComma
 		return response.Error(400, "New password is too short", nil)
 	}
 
+	if err := hs.PasswordPolicyService.Validate(c.Req.Context(), userQuery.Result, cmd.NewPassword); err != nil {
+		return passwordPolicyErrorResponse(err)
+	}
+	return response.Success("Failed to change user password")
+
 	cmd.UserId = c.UserId
 	cmd.NewPassword, err = util.EncodePassword(cmd.NewPassword, userQuery.Result.Salt)
 	if err != nil {
@@ -304,6 +308,8 @@ func GetAuthProviderLabel(authModule string) string {
 		return "grafana.com"
 	case "auth.saml":
 		return "SAML"
+	case "auth.jwt":
+		return "JWT"
 	case "ldap", "":
 		return "LDAP"
 	default:
@@ -58,6 +58,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 		return response.Error(500, "Failed to get user auth tokens", err)
 	}
 
+	parser := uaparser.NewFromSaved()
 	result := []*dtos.UserToken{}
 	for _, token := range tokens {
 		isActive := false
@@ -65,46 +66,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *models.ReqContext, userID int
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
-
-		osVersion := ""
-		if client.Os.Major != "" {
-			osVersion = client.Os.Major
-
-			if client.Os.Minor != "" {
-				osVersion = osVersion + "." + client.Os.Minor
-			}
-		}
-
-		browserVersion := ""
-		if client.UserAgent.Major != "" {
-			browserVersion = client.UserAgent.Major
-
-			if client.UserAgent.Minor != "" {
-				browserVersion = browserVersion + "." + client.UserAgent.Minor
-			}
-		}
-
-		createdAt := time.Unix(token.CreatedAt, 0)
-		seenAt := time.Unix(token.SeenAt, 0)
-
-		if token.SeenAt == 0 {
-			seenAt = createdAt
-		}
-
-		result = append(result, &dtos.UserToken{
-			Id:                     token.Id,
-			IsActive:               isActive,
-			ClientIp:               token.ClientIp,
-			Device:                 client.Device.ToString(),
-			OperatingSystem:        client.Os.Family,
-			OperatingSystemVersion: osVersion,
-			Browser:                client.UserAgent.Family,
-			BrowserVersion:         browserVersion,
-			CreatedAt:              createdAt,
-			SeenAt:                 seenAt,
-		})
+		result = append(result, newUserTokenDTO(parser, token, isActive))
 	}
 
 	return response.JSON(200, result)
@@ -144,3 +106,46 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *models.ReqContext, userID i
 		"message": "User auth token revoked",
 	})
 }
+
+// newUserTokenDTO returns the device and the activity of a token.
+func newUserTokenDTO(parser *uaparser.Parser, token *models.UserToken, isActive bool) *dtos.UserToken {
+	client := parser.Parse(token.UserAgent)
+
+	osVersion := ""
+	if client.Os.Major != "" {
+		osVersion = client.Os.Major
+
+		if client.Os.Minor != "" {
+			osVersion = osVersion + "." + client.Os.Minor
+		}
+	}
+
+	browserVersion := ""
+	if client.UserAgent.Major != "" {
+		browserVersion = client.UserAgent.Major
+
+		if client.UserAgent.Minor != "" {
+			browserVersion = browserVersion + "." + client.UserAgent.Minor
+		}
+	}
+
+	createdAt := time.Unix(token.CreatedAt, 0)
+	seenAt := time.Unix(token.SeenAt, 0)
+
+	if token.SeenAt == 0 {
+		seenAt = createdAt
+	}
+
+	return &dtos.UserToken{
+		Id:                     token.Id,
+		IsActive:               isActive,
+		ClientIp:               token.ClientIp,
+		Device:                 client.Device.ToString(),
+		OperatingSystem:        client.Os.Family,
+		OperatingSystemVersion: osVersion,
+		Browser:                client.UserAgent.Family,
+		BrowserVersion:         browserVersion,
+		CreatedAt:              createdAt,
+		SeenAt:                 seenAt,
+	}
+}
@@ -17,6 +17,10 @@ type HandlerFunc interface{}
 // Msg defines a message interface.
 type Msg interface{}
 
+// DispatchHook is called with every message that was handled successfully
+// by Dispatch or DispatchCtx.
+type DispatchHook func(ctx context.Context, msg Msg)
+
 // ErrHandlerNotFound defines an error if a handler is not found
 var ErrHandlerNotFound = errors.New("handler not found")
 
@@ -40,6 +44,7 @@ type Bus interface {
 	AddHandler(handler HandlerFunc)
 	AddHandlerCtx(handler HandlerFunc)
 	AddEventListener(handler HandlerFunc)
+	AddDispatchHook(hook DispatchHook)
 
 	// SetTransactionManager allows the user to replace the internal
 	// noop TransactionManager that is responsible for managing
@@ -53,6 +58,7 @@ type InProcBus struct {
 	handlers        map[string]HandlerFunc
 	handlersWithCtx map[string]HandlerFunc
 	listeners       map[string][]HandlerFunc
+	dispatchHooks   []DispatchHook
 	txMng           TransactionManager
 }
 
@@ -119,6 +125,7 @@ func (b *InProcBus) DispatchCtx(ctx context.Context, msg Msg) error {
 	ret := reflect.ValueOf(handler).Call(params)
 	err := ret[0].Interface()
 	if err == nil {
+		b.runDispatchHooks(ctx, msg)
 		return nil
 	}
 	return err.(error)
@@ -150,11 +157,18 @@ func (b *InProcBus) Dispatch(msg Msg) error {
 	ret := reflect.ValueOf(handler).Call(params)
 	err := ret[0].Interface()
 	if err == nil {
+		b.runDispatchHooks(context.Background(), msg)
 		return nil
 	}
 	return err.(error)
 }
 
+func (b *InProcBus) runDispatchHooks(ctx context.Context, msg Msg) {
+	for _, hook := range b.dispatchHooks {
+		hook(ctx, msg)
+	}
+}
+
 // Publish function publish a message to the bus listener.
 func (b *InProcBus) Publish(msg Msg) error {
 	var msgName = reflect.TypeOf(msg).Elem().Name()
@@ -205,6 +219,12 @@ func (b *InProcBus) AddEventListener(handler HandlerFunc) {
 	b.listeners[eventName] = append(b.listeners[eventName], handler)
 }
 
+// AddDispatchHook registers a hook that is called after a message has been
+// handled successfully.
+func (b *InProcBus) AddDispatchHook(hook DispatchHook) {
+	b.dispatchHooks = append(b.dispatchHooks, hook)
+}
+
 // AddHandler attaches a handler function to the global bus.
 // Package level function.
 func AddHandler(implName string, handler HandlerFunc) {
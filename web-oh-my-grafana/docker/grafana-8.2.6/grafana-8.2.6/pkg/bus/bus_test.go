@@ -144,3 +144,29 @@ func TestEvent_NoRegisteredListener(t *testing.T) {
 	err := bus.Publish(&testQuery{})
 	require.NoError(t, err, "unable to publish event")
 }
+
+func TestDispatchHook(t *testing.T) {
+	bus := New()
+
+	fail := false
+	bus.AddHandlerCtx(func(ctx context.Context, query *testQuery) error {
+		if fail {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+
+	var hooked []Msg
+	bus.AddDispatchHook(func(ctx context.Context, msg Msg) {
+		hooked = append(hooked, msg)
+	})
+
+	query := &testQuery{ID: 1}
+	require.NoError(t, bus.DispatchCtx(context.Background(), query))
+	require.NoError(t, bus.Dispatch(query))
+	require.Equal(t, []Msg{query, query}, hooked)
+
+	fail = true
+	require.Error(t, bus.DispatchCtx(context.Background(), query))
+	require.Len(t, hooked, 2, "expected hook not to be called for failed messages")
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/fatih/color"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/orgarchive"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
@@ -124,6 +126,73 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "reset-2fa",
+		Usage:  "reset-2fa <user login or email>",
+		Action: runDbCommand(resetTwoFactorCommand),
+	},
+	{
+		Name:   "export-org",
+		Usage:  "export-org <archive path>",
+		Action: runDbCommand(orgarchive.ExportOrgCommand),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "ID of the organization to export",
+				Value: 1,
+			},
+			&cli.StringFlag{
+				Name:  "archive-key",
+				Usage: "Key used to encrypt data source secrets in the archive. Secrets are not exported without it",
+			},
+		},
+	},
+	{
+		Name:   "import-org",
+		Usage:  "import-org <archive path>",
+		Action: runDbCommand(orgarchive.ImportOrgCommand),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "ID of the organization to import into. Defaults to the organization with the name stored in the archive, which is created if missing",
+			},
+			&cli.StringFlag{
+				Name:  "archive-key",
+				Usage: "Key the data source secrets in the archive were encrypted with",
+			},
+		},
+	},
+	{
+		Name:  "secrets",
+		Usage: "Manage the secrets encrypted with the secret key",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "rotate",
+				Usage:  "Re-encrypts all secrets stored in the database with a new secret key.",
+				Action: runDbCommand(secretsmigrations.RotateSecretKey),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "new-key",
+						Usage: "The new secret key",
+					},
+					&cli.StringFlag{
+						Name:  "old-key",
+						Usage: "The secret key the secrets are currently encrypted with. Defaults to the configured secret_key",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Report the secrets that would be re-encrypted without changing them",
+						Value: false,
+					},
+				},
+			},
+			{
+				Name:   "re-encrypt-data-keys",
+				Usage:  "Re-encrypts the data keys of envelope encryption with the active key encryption key.",
+				Action: runDbCommand(secretsmigrations.ReEncryptDataKeys),
+			},
+		},
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your db",
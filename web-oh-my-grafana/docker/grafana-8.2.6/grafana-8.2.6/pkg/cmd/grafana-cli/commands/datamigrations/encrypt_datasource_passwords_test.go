@@ -6,9 +6,10 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,16 +28,17 @@ func TestPasswordMigrationCommand(t *testing.T) {
 	}
 
 	// set required default values
+	encryptionService := &ossencryption.Service{}
 	for _, ds := range datasources {
 		ds.Created = time.Now()
 		ds.Updated = time.Now()
+		secureJsonData := map[string]string{}
 		if ds.Name == "elasticsearch" {
-			ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
-				"key": "value",
-			})
-		} else {
-			ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{})
+			secureJsonData["key"] = "value"
 		}
+		encryptedJsonData, err := encryptionService.EncryptJsonData(secureJsonData, setting.SecretKey)
+		require.NoError(t, err)
+		ds.SecureJsonData = encryptedJsonData
 	}
 
 	_, err := session.Insert(&datasources)
@@ -59,7 +61,8 @@ func TestPasswordMigrationCommand(t *testing.T) {
 	assert.Equal(t, len(dss), 4)
 
 	for _, ds := range dss {
-		sj := ds.SecureJsonData.Decrypt()
+		sj, err := encryptionService.DecryptJsonData(ds.SecureJsonData, setting.SecretKey)
+		require.NoError(t, err)
 
 		if ds.Name == "influxdb" {
 			assert.Equal(t, ds.Password, "")
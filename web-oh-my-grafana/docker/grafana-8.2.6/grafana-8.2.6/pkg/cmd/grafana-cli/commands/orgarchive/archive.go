@@ -0,0 +1,267 @@
+// Package orgarchive implements exporting an organization into a portable
+// archive and restoring it into the database of another Grafana instance.
+package orgarchive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// archiveVersion is the version of the archive format. It's bumped on
+// changes that older versions of Grafana aren't able to import.
+const archiveVersion = 1
+
+// Archive is the content of an organization archive. Every field is stored
+// as a separate JSON file in a gzipped tar file.
+type Archive struct {
+	Manifest        Manifest
+	Folders         []*Dashboard
+	Dashboards      []*Dashboard
+	DataSources     []*DataSource
+	AlertRules      []*ngmodels.AlertRule
+	LibraryElements []*LibraryElement
+	Teams           []*Team
+	Preferences     []*Preferences
+}
+
+type Manifest struct {
+	Version        int       `json:"version"`
+	GrafanaVersion string    `json:"grafanaVersion"`
+	ExportedAt     time.Time `json:"exportedAt"`
+	OrgName        string    `json:"orgName"`
+	// SecretsIncluded is true if the secure JSON data of the data sources
+	// has been encrypted with an archive key and is part of the archive.
+	SecretsIncluded bool `json:"secretsIncluded"`
+	// KeyCheck is a known value encrypted with the archive key, which is
+	// used to verify that the key given on import is the right one.
+	KeyCheck []byte `json:"keyCheck,omitempty"`
+}
+
+// keyCheckValue is the value encrypted into Manifest.KeyCheck.
+const keyCheckValue = "grafana-org-archive"
+
+// Dashboard is a dashboard or folder. Users are referenced by their login
+// and teams by their name since ids differ between instances.
+type Dashboard struct {
+	UID         string              `json:"uid"`
+	Title       string              `json:"title"`
+	Slug        string              `json:"slug"`
+	FolderUID   string              `json:"folderUid,omitempty"`
+	IsFolder    bool                `json:"isFolder"`
+	HasACL      bool                `json:"hasAcl"`
+	PluginID    string              `json:"pluginId,omitempty"`
+	GnetID      int64               `json:"gnetId,omitempty"`
+	Version     int                 `json:"version"`
+	Created     time.Time           `json:"created"`
+	Updated     time.Time           `json:"updated"`
+	Data        *simplejson.Json    `json:"data"`
+	Versions    []*DashboardVersion `json:"versions,omitempty"`
+	Permissions []*Permission       `json:"permissions,omitempty"`
+}
+
+type DashboardVersion struct {
+	ParentVersion int              `json:"parentVersion"`
+	RestoredFrom  int              `json:"restoredFrom"`
+	Version       int              `json:"version"`
+	Created       time.Time        `json:"created"`
+	CreatedBy     string           `json:"createdBy,omitempty"`
+	Message       string           `json:"message"`
+	Data          *simplejson.Json `json:"data"`
+}
+
+type Permission struct {
+	UserLogin  string                `json:"userLogin,omitempty"`
+	TeamName   string                `json:"teamName,omitempty"`
+	Role       *models.RoleType      `json:"role,omitempty"`
+	Permission models.PermissionType `json:"permission"`
+}
+
+type DataSource struct {
+	UID             string           `json:"uid"`
+	Name            string           `json:"name"`
+	Type            string           `json:"type"`
+	Access          models.DsAccess  `json:"access"`
+	URL             string           `json:"url"`
+	User            string           `json:"user"`
+	Database        string           `json:"database"`
+	BasicAuth       bool             `json:"basicAuth"`
+	BasicAuthUser   string           `json:"basicAuthUser"`
+	WithCredentials bool             `json:"withCredentials"`
+	IsDefault       bool             `json:"isDefault"`
+	JSONData        *simplejson.Json `json:"jsonData"`
+	ReadOnly        bool             `json:"readOnly"`
+	// SecureJSONData is encrypted with the archive key, not with the
+	// secret key of the exporting instance.
+	SecureJSONData map[string][]byte `json:"secureJsonData,omitempty"`
+}
+
+type LibraryElement struct {
+	UID         string          `json:"uid"`
+	FolderUID   string          `json:"folderUid,omitempty"`
+	Name        string          `json:"name"`
+	Kind        int64           `json:"kind"`
+	Type        string          `json:"type"`
+	Description string          `json:"description"`
+	Model       json.RawMessage `json:"model"`
+	Version     int64           `json:"version"`
+	Created     time.Time       `json:"created"`
+	Updated     time.Time       `json:"updated"`
+	// Dashboards are the UIDs of the dashboards using the element.
+	Dashboards []string `json:"dashboards,omitempty"`
+}
+
+type Team struct {
+	Name    string        `json:"name"`
+	Email   string        `json:"email"`
+	Members []*TeamMember `json:"members,omitempty"`
+}
+
+type TeamMember struct {
+	Login      string                `json:"login"`
+	External   bool                  `json:"external"`
+	Permission models.PermissionType `json:"permission"`
+}
+
+// Preferences are the preferences of the organization, or of a team if
+// TeamName is set.
+type Preferences struct {
+	TeamName         string `json:"teamName,omitempty"`
+	HomeDashboardUID string `json:"homeDashboardUid,omitempty"`
+	Timezone         string `json:"timezone"`
+	Theme            string `json:"theme"`
+}
+
+// files maps the file names in the tar file to the parts of the archive.
+func (a *Archive) files() map[string]interface{} {
+	return map[string]interface{}{
+		"manifest.json":         &a.Manifest,
+		"folders.json":          &a.Folders,
+		"dashboards.json":       &a.Dashboards,
+		"datasources.json":      &a.DataSources,
+		"alert_rules.json":      &a.AlertRules,
+		"library_elements.json": &a.LibraryElements,
+		"teams.json":            &a.Teams,
+		"preferences.json":      &a.Preferences,
+	}
+}
+
+// Write writes the archive as a gzipped tar file.
+func (a *Archive) Write(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	files := a.files()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		content := files[name]
+		data, err := json.MarshalIndent(content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0600,
+			Size:    int64(len(data)),
+			ModTime: a.Manifest.ExportedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// WriteFile writes the archive to path.
+func (a *Archive) WriteFile(path string) error {
+	// nolint:gosec
+	f, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := a.Write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read reads an archive written by Write.
+func Read(r io.Reader) (*Archive, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("archive is not a gzipped file: %w", err)
+	}
+	defer func() { _ = gr.Close() }()
+
+	a := &Archive{}
+	files := a.files()
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, ok := files[header.Name]
+		if !ok {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, content); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", header.Name, err)
+		}
+	}
+
+	if a.Manifest.Version == 0 {
+		return nil, fmt.Errorf("archive has no manifest")
+	}
+	if a.Manifest.Version > archiveVersion {
+		return nil, fmt.Errorf("archive version %d is not supported, upgrade Grafana to import it", a.Manifest.Version)
+	}
+
+	return a, nil
+}
+
+// ReadFile reads the archive stored at path.
+func ReadFile(path string) (*Archive, error) {
+	// nolint:gosec
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	return Read(f)
+}
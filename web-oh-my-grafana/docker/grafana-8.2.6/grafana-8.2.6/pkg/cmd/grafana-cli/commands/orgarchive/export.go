@@ -0,0 +1,425 @@
+package orgarchive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// libraryElement and libraryElementConnection map the library element
+// tables without depending on the library elements service.
+type libraryElement struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	FolderID    int64  `xorm:"folder_id"`
+	UID         string `xorm:"uid"`
+	Name        string
+	Kind        int64
+	Type        string
+	Description string
+	Model       json.RawMessage
+	Version     int64
+
+	Created time.Time
+	Updated time.Time
+
+	CreatedBy int64
+	UpdatedBy int64
+}
+
+type libraryElementConnection struct {
+	ID           int64 `xorm:"pk autoincr 'id'"`
+	ElementID    int64 `xorm:"element_id"`
+	Kind         int64 `xorm:"kind"`
+	ConnectionID int64 `xorm:"connection_id"`
+	Created      time.Time
+	CreatedBy    int64
+}
+
+// dashboardConnection is the kind of library element connections to dashboards.
+const dashboardConnection = 1
+
+// ExportOrgCommand exports the organization given by --org-id to the archive
+// file given as first argument.
+func ExportOrgCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing path of the archive to write")
+	}
+
+	archiveKey := c.String("archive-key")
+	if archiveKey == "" {
+		logger.Warn("No archive key given, data source secrets won't be exported.\n")
+	}
+
+	encryptionService, err := ossencryption.ProvideService(sqlStore.Cfg, sqlStore)
+	if err != nil {
+		return errutil.Wrap("failed to initialize encryption", err)
+	}
+
+	archive, err := Export(context.Background(), sqlStore, encryptionService, int64(c.Int("org-id")), archiveKey)
+	if err != nil {
+		return err
+	}
+	if err := archive.WriteFile(path); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	logger.Infof("Exported %d dashboards, %d folders and %d data sources of organization %q to %s %s\n",
+		len(archive.Dashboards), len(archive.Folders), len(archive.DataSources), archive.Manifest.OrgName, path,
+		color.GreenString("✔"))
+	return nil
+}
+
+// Export reads an organization from the database. Secure JSON data is
+// decrypted with encryptionService and re-encrypted with archiveKey; if
+// archiveKey is empty it's left out.
+func Export(ctx context.Context, sqlStore *sqlstore.SQLStore, encryptionService encryption.Service, orgID int64,
+	archiveKey string) (*Archive, error) {
+	archive := &Archive{
+		Manifest: Manifest{
+			Version:         archiveVersion,
+			GrafanaVersion:  setting.BuildVersion,
+			ExportedAt:      time.Now(),
+			SecretsIncluded: archiveKey != "",
+		},
+	}
+
+	if archiveKey != "" {
+		keyCheck, err := util.Encrypt([]byte(keyCheckValue), archiveKey)
+		if err != nil {
+			return nil, err
+		}
+		archive.Manifest.KeyCheck = keyCheck
+	}
+
+	err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		e := &exporter{
+			sess:              sess,
+			encryptionService: encryptionService,
+			orgID:             orgID,
+			archiveKey:        archiveKey,
+			archive:           archive,
+		}
+		return e.export()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+type exporter struct {
+	sess              *sqlstore.DBSession
+	encryptionService encryption.Service
+	orgID             int64
+	archiveKey        string
+	archive           *Archive
+
+	userLogins    map[int64]string
+	teamNames     map[int64]string
+	dashboardUIDs map[int64]string
+}
+
+func (e *exporter) export() error {
+	var org models.Org
+	exists, err := e.sess.ID(e.orgID).Get(&org)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("organization %d: %w", e.orgID, models.ErrOrgNotFound)
+	}
+	e.archive.Manifest.OrgName = org.Name
+
+	var users []*models.User
+	if err := e.sess.Cols("id", "login").Find(&users); err != nil {
+		return err
+	}
+	e.userLogins = make(map[int64]string, len(users))
+	for _, u := range users {
+		e.userLogins[u.Id] = u.Login
+	}
+
+	for _, step := range []func() error{
+		e.exportTeams,
+		e.exportDashboards,
+		e.exportDataSources,
+		e.exportLibraryElements,
+		e.exportAlertRules,
+		e.exportPreferences,
+	} {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *exporter) exportTeams() error {
+	var teams []*models.Team
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&teams); err != nil {
+		return err
+	}
+
+	var members []*models.TeamMember
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&members); err != nil {
+		return err
+	}
+
+	e.teamNames = make(map[int64]string, len(teams))
+	byID := make(map[int64]*Team, len(teams))
+	e.archive.Teams = make([]*Team, 0, len(teams))
+	for _, t := range teams {
+		team := &Team{Name: t.Name, Email: t.Email}
+		e.teamNames[t.Id] = t.Name
+		byID[t.Id] = team
+		e.archive.Teams = append(e.archive.Teams, team)
+	}
+
+	for _, m := range members {
+		team, ok := byID[m.TeamId]
+		if !ok {
+			continue
+		}
+		login, ok := e.userLogins[m.UserId]
+		if !ok {
+			continue
+		}
+		team.Members = append(team.Members, &TeamMember{
+			Login:      login,
+			External:   m.External,
+			Permission: m.Permission,
+		})
+	}
+
+	return nil
+}
+
+func (e *exporter) exportDashboards() error {
+	var dashboards []*models.Dashboard
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&dashboards); err != nil {
+		return err
+	}
+
+	e.dashboardUIDs = make(map[int64]string, len(dashboards))
+	for _, d := range dashboards {
+		e.dashboardUIDs[d.Id] = d.Uid
+	}
+
+	var acl []*models.DashboardAcl
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&acl); err != nil {
+		return err
+	}
+	permissions := make(map[int64][]*Permission)
+	for _, item := range acl {
+		p := &Permission{Role: item.Role, Permission: item.Permission}
+		if item.UserID > 0 {
+			if p.UserLogin = e.userLogins[item.UserID]; p.UserLogin == "" {
+				continue
+			}
+		}
+		if item.TeamID > 0 {
+			if p.TeamName = e.teamNames[item.TeamID]; p.TeamName == "" {
+				continue
+			}
+		}
+		permissions[item.DashboardID] = append(permissions[item.DashboardID], p)
+	}
+
+	e.archive.Folders = make([]*Dashboard, 0)
+	e.archive.Dashboards = make([]*Dashboard, 0, len(dashboards))
+	for _, d := range dashboards {
+		dash := &Dashboard{
+			UID:         d.Uid,
+			Title:       d.Title,
+			Slug:        d.Slug,
+			FolderUID:   e.dashboardUIDs[d.FolderId],
+			IsFolder:    d.IsFolder,
+			HasACL:      d.HasAcl,
+			PluginID:    d.PluginId,
+			GnetID:      d.GnetId,
+			Version:     d.Version,
+			Created:     d.Created,
+			Updated:     d.Updated,
+			Data:        d.Data,
+			Permissions: permissions[d.Id],
+		}
+
+		if d.IsFolder {
+			e.archive.Folders = append(e.archive.Folders, dash)
+			continue
+		}
+
+		var versions []*models.DashboardVersion
+		if err := e.sess.Where("dashboard_id = ?", d.Id).Asc("version").Find(&versions); err != nil {
+			return err
+		}
+		for _, v := range versions {
+			dash.Versions = append(dash.Versions, &DashboardVersion{
+				ParentVersion: v.ParentVersion,
+				RestoredFrom:  v.RestoredFrom,
+				Version:       v.Version,
+				Created:       v.Created,
+				CreatedBy:     e.userLogins[v.CreatedBy],
+				Message:       v.Message,
+				Data:          v.Data,
+			})
+		}
+
+		e.archive.Dashboards = append(e.archive.Dashboards, dash)
+	}
+
+	return nil
+}
+
+func (e *exporter) exportDataSources() error {
+	var dataSources []*models.DataSource
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&dataSources); err != nil {
+		return err
+	}
+
+	e.archive.DataSources = make([]*DataSource, 0, len(dataSources))
+	for _, ds := range dataSources {
+		dataSource := &DataSource{
+			UID:             ds.Uid,
+			Name:            ds.Name,
+			Type:            ds.Type,
+			Access:          ds.Access,
+			URL:             ds.Url,
+			User:            ds.User,
+			Database:        ds.Database,
+			BasicAuth:       ds.BasicAuth,
+			BasicAuthUser:   ds.BasicAuthUser,
+			WithCredentials: ds.WithCredentials,
+			IsDefault:       ds.IsDefault,
+			JSONData:        ds.JsonData,
+			ReadOnly:        ds.ReadOnly,
+		}
+
+		if e.archiveKey != "" {
+			secrets, err := e.reencryptSecrets(ds)
+			if err != nil {
+				return fmt.Errorf("failed to export secrets of data source %q: %w", ds.Name, err)
+			}
+			dataSource.SecureJSONData = secrets
+		}
+
+		e.archive.DataSources = append(e.archive.DataSources, dataSource)
+	}
+
+	return nil
+}
+
+// reencryptSecrets decrypts the secure JSON data of a data source with the
+// secret key of this instance and encrypts it with the archive key. Legacy
+// unencrypted passwords are exported as secure JSON data, the same way the
+// encrypt-datasource-passwords data migration does.
+func (e *exporter) reencryptSecrets(ds *models.DataSource) (map[string][]byte, error) {
+	plain := make(map[string][]byte, len(ds.SecureJsonData)+2)
+	if ds.Password != "" {
+		plain["password"] = []byte(ds.Password)
+	}
+	if ds.BasicAuthPassword != "" {
+		plain["basicAuthPassword"] = []byte(ds.BasicAuthPassword)
+	}
+	for key, value := range ds.SecureJsonData {
+		decrypted, err := e.encryptionService.Decrypt(value, setting.SecretKey)
+		if err != nil {
+			return nil, err
+		}
+		plain[key] = decrypted
+	}
+
+	secrets := make(map[string][]byte, len(plain))
+	for key, value := range plain {
+		encrypted, err := util.Encrypt(value, e.archiveKey)
+		if err != nil {
+			return nil, err
+		}
+		secrets[key] = encrypted
+	}
+
+	return secrets, nil
+}
+
+func (e *exporter) exportLibraryElements() error {
+	var elements []*libraryElement
+	if err := e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&elements); err != nil {
+		return err
+	}
+
+	e.archive.LibraryElements = make([]*LibraryElement, 0, len(elements))
+	for _, le := range elements {
+		var connections []*libraryElementConnection
+		if err := e.sess.Where("element_id = ? AND kind = ?", le.ID, dashboardConnection).Asc("id").Find(&connections); err != nil {
+			return err
+		}
+
+		element := &LibraryElement{
+			UID:         le.UID,
+			FolderUID:   e.dashboardUIDs[le.FolderID],
+			Name:        le.Name,
+			Kind:        le.Kind,
+			Type:        le.Type,
+			Description: le.Description,
+			Model:       le.Model,
+			Version:     le.Version,
+			Created:     le.Created,
+			Updated:     le.Updated,
+		}
+		for _, c := range connections {
+			if uid, ok := e.dashboardUIDs[c.ConnectionID]; ok {
+				element.Dashboards = append(element.Dashboards, uid)
+			}
+		}
+
+		e.archive.LibraryElements = append(e.archive.LibraryElements, element)
+	}
+
+	return nil
+}
+
+func (e *exporter) exportAlertRules() error {
+	e.archive.AlertRules = make([]*ngmodels.AlertRule, 0)
+	return e.sess.Where("org_id = ?", e.orgID).Asc("id").Find(&e.archive.AlertRules)
+}
+
+func (e *exporter) exportPreferences() error {
+	var prefs []*models.Preferences
+	if err := e.sess.Where("org_id = ? AND user_id = 0", e.orgID).Asc("id").Find(&prefs); err != nil {
+		return err
+	}
+
+	e.archive.Preferences = make([]*Preferences, 0, len(prefs))
+	for _, p := range prefs {
+		pref := &Preferences{
+			HomeDashboardUID: e.dashboardUIDs[p.HomeDashboardId],
+			Timezone:         p.Timezone,
+			Theme:            p.Theme,
+		}
+		if p.TeamId > 0 {
+			if pref.TeamName = e.teamNames[p.TeamId]; pref.TeamName == "" {
+				continue
+			}
+		}
+		e.archive.Preferences = append(e.archive.Preferences, pref)
+	}
+
+	return nil
+}
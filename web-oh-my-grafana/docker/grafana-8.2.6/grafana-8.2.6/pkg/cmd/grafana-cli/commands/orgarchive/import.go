@@ -0,0 +1,587 @@
+package orgarchive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// serverAdminID is the id of the user made admin of organizations created
+// by an import.
+const serverAdminID = 1
+
+// ImportOrgCommand restores the archive file given as first argument into
+// the organization given by --org-id. Without --org-id the organization
+// is looked up by the name stored in the archive, and created if missing.
+func ImportOrgCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	path := c.Args().First()
+	if path == "" {
+		return fmt.Errorf("missing path of the archive to import")
+	}
+
+	archive, err := ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	orgID := int64(c.Int("org-id"))
+	if orgID == 0 {
+		org, err := sqlStore.GetOrgByName(archive.Manifest.OrgName)
+		switch {
+		case errors.Is(err, models.ErrOrgNotFound):
+			created, err := sqlStore.CreateOrgWithMember(archive.Manifest.OrgName, serverAdminID)
+			if err != nil {
+				return fmt.Errorf("failed to create organization %q: %w", archive.Manifest.OrgName, err)
+			}
+			orgID = created.Id
+		case err != nil:
+			return err
+		default:
+			orgID = org.Id
+		}
+	}
+
+	encryptionService, err := ossencryption.ProvideService(sqlStore.Cfg, sqlStore)
+	if err != nil {
+		return errutil.Wrap("failed to initialize encryption", err)
+	}
+
+	if err := Import(context.Background(), sqlStore, encryptionService, archive, orgID, c.String("archive-key")); err != nil {
+		return err
+	}
+
+	logger.Infof("Imported %d dashboards, %d folders and %d data sources into organization %d %s\n",
+		len(archive.Dashboards), len(archive.Folders), len(archive.DataSources), orgID, color.GreenString("✔"))
+	return nil
+}
+
+// Import restores an archive into an organization in a single transaction.
+// Resources are matched by UID, or by name for teams, so importing the same
+// archive again updates the resources instead of duplicating them.
+//
+// Users aren't part of the archive. Team memberships, permissions and
+// version authors are restored for users with the same login which are
+// members of the organization, and skipped for others.
+//
+// Data source secrets are decrypted with archiveKey and encrypted with
+// encryptionService before the transaction starts, since the encryption
+// service may store data keys in the database itself.
+func Import(ctx context.Context, sqlStore *sqlstore.SQLStore, encryptionService encryption.Service, archive *Archive,
+	orgID int64, archiveKey string) error {
+	if archive.Manifest.SecretsIncluded && archiveKey == "" {
+		return fmt.Errorf("the archive contains data source secrets, the archive key used for the export is required")
+	}
+	if archive.Manifest.SecretsIncluded {
+		keyCheck, err := util.Decrypt(archive.Manifest.KeyCheck, archiveKey)
+		if err != nil || string(keyCheck) != keyCheckValue {
+			return fmt.Errorf("the archive key doesn't match the key used for the export")
+		}
+	}
+
+	var dataSourceSecrets []map[string][]byte
+	if archive.Manifest.SecretsIncluded {
+		dataSourceSecrets = make([]map[string][]byte, len(archive.DataSources))
+		for idx, d := range archive.DataSources {
+			secrets, err := reencryptSecrets(encryptionService, d.SecureJSONData, archiveKey)
+			if err != nil {
+				return fmt.Errorf("failed to import secrets of data source %q: %w", d.Name, err)
+			}
+			dataSourceSecrets[idx] = secrets
+		}
+	}
+
+	return sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		i := &importer{
+			sess:         sess,
+			dialect:      sqlStore.Dialect,
+			orgID:        orgID,
+			archive:      archive,
+			secrets:      dataSourceSecrets,
+			teamIDs:      make(map[string]int64),
+			dashboardIDs: make(map[string]int64),
+		}
+		return i.importArchive()
+	})
+}
+
+type importer struct {
+	sess    *sqlstore.DBSession
+	dialect migrator.Dialect
+	orgID   int64
+	archive *Archive
+	// secrets holds the re-encrypted secure JSON data of the archived
+	// data sources, by index; it's nil when the archive has no secrets.
+	secrets []map[string][]byte
+
+	userIDs      map[string]int64
+	teamIDs      map[string]int64
+	dashboardIDs map[string]int64
+}
+
+func (i *importer) importArchive() error {
+	var org models.Org
+	exists, err := i.sess.ID(i.orgID).Get(&org)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("organization %d: %w", i.orgID, models.ErrOrgNotFound)
+	}
+
+	var users []*models.User
+	rawSQL := "SELECT u.id, u.login FROM " + i.dialect.Quote("user") + " AS u" +
+		" INNER JOIN org_user ON org_user.user_id = u.id WHERE org_user.org_id = ?"
+	if err := i.sess.SQL(rawSQL, i.orgID).Find(&users); err != nil {
+		return err
+	}
+	i.userIDs = make(map[string]int64, len(users))
+	for _, u := range users {
+		i.userIDs[u.Login] = u.Id
+	}
+
+	for _, step := range []func() error{
+		i.importTeams,
+		i.importDashboards,
+		i.importDataSources,
+		i.importLibraryElements,
+		i.importAlertRules,
+		i.importPreferences,
+	} {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importTeams() error {
+	for _, t := range i.archive.Teams {
+		team := models.Team{}
+		exists, err := i.sess.Where("org_id = ? AND name = ?", i.orgID, t.Name).Get(&team)
+		if err != nil {
+			return err
+		}
+
+		team.OrgId = i.orgID
+		team.Name = t.Name
+		team.Email = t.Email
+		team.Updated = time.Now()
+		if exists {
+			_, err = i.sess.ID(team.Id).Cols("email", "updated").Update(&team)
+		} else {
+			team.Created = team.Updated
+			_, err = i.sess.Insert(&team)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import team %q: %w", t.Name, err)
+		}
+		i.teamIDs[t.Name] = team.Id
+
+		for _, m := range t.Members {
+			userID, ok := i.userIDs[m.Login]
+			if !ok {
+				logger.Warnf("Skipping member %q of team %q, user is not a member of the organization\n", m.Login, t.Name)
+				continue
+			}
+
+			exists, err := i.sess.Where("team_id = ? AND user_id = ?", team.Id, userID).Exist(&models.TeamMember{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			member := models.TeamMember{
+				OrgId:      i.orgID,
+				TeamId:     team.Id,
+				UserId:     userID,
+				External:   m.External,
+				Permission: m.Permission,
+				Created:    time.Now(),
+				Updated:    time.Now(),
+			}
+			if _, err := i.sess.Insert(&member); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importDashboards() error {
+	// folders first so that dashboards can reference them
+	for _, list := range [][]*Dashboard{i.archive.Folders, i.archive.Dashboards} {
+		for _, d := range list {
+			if err := i.importDashboard(d); err != nil {
+				return fmt.Errorf("failed to import dashboard %q: %w", d.Title, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importDashboard(d *Dashboard) error {
+	var existing models.Dashboard
+	exists, err := i.sess.Where("org_id = ? AND uid = ?", i.orgID, d.UID).Get(&existing)
+	if err != nil {
+		return err
+	}
+
+	dash := &models.Dashboard{
+		Id:       existing.Id,
+		Uid:      d.UID,
+		Slug:     d.Slug,
+		OrgId:    i.orgID,
+		GnetId:   d.GnetID,
+		Version:  d.Version,
+		PluginId: d.PluginID,
+		Created:  d.Created,
+		Updated:  d.Updated,
+		FolderId: i.dashboardIDs[d.FolderUID],
+		IsFolder: d.IsFolder,
+		HasAcl:   d.HasACL,
+		Title:    d.Title,
+		Data:     d.Data,
+	}
+	if dash.Data == nil {
+		dash.Data = simplejson.New()
+	}
+
+	if exists {
+		dash.SetId(existing.Id)
+		if _, err := i.sess.ID(existing.Id).AllCols().Update(dash); err != nil {
+			return err
+		}
+	} else {
+		if _, err := i.sess.Insert(dash); err != nil {
+			return err
+		}
+		// the id in the JSON model is the one of the exporting instance
+		dash.SetId(dash.Id)
+		if _, err := i.sess.ID(dash.Id).Cols("data").Update(dash); err != nil {
+			return err
+		}
+	}
+	i.dashboardIDs[d.UID] = dash.Id
+
+	if _, err := i.sess.Exec("DELETE FROM dashboard_tag WHERE dashboard_id = ?", dash.Id); err != nil {
+		return err
+	}
+	for _, tag := range dash.GetTags() {
+		if _, err := i.sess.Insert(&sqlstore.DashboardTag{DashboardId: dash.Id, Term: tag}); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range d.Versions {
+		exists, err := i.sess.Where("dashboard_id = ? AND version = ?", dash.Id, v.Version).Exist(&models.DashboardVersion{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		data := v.Data
+		if data == nil {
+			data = simplejson.New()
+		}
+		data.Set("id", dash.Id)
+		version := models.DashboardVersion{
+			DashboardId:   dash.Id,
+			ParentVersion: v.ParentVersion,
+			RestoredFrom:  v.RestoredFrom,
+			Version:       v.Version,
+			Created:       v.Created,
+			CreatedBy:     i.userIDs[v.CreatedBy],
+			Message:       v.Message,
+			Data:          data,
+		}
+		if _, err := i.sess.Insert(&version); err != nil {
+			return err
+		}
+	}
+
+	// permissions are replaced so that removed ones don't linger
+	if _, err := i.sess.Exec("DELETE FROM dashboard_acl WHERE dashboard_id = ?", dash.Id); err != nil {
+		return err
+	}
+	for _, p := range d.Permissions {
+		item := models.DashboardAcl{
+			OrgID:       i.orgID,
+			DashboardID: dash.Id,
+			Role:        p.Role,
+			Permission:  p.Permission,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		}
+		if p.UserLogin != "" {
+			userID, ok := i.userIDs[p.UserLogin]
+			if !ok {
+				logger.Warnf("Skipping permission of %q on %q, user is not a member of the organization\n", p.UserLogin, d.Title)
+				continue
+			}
+			item.UserID = userID
+		}
+		if p.TeamName != "" {
+			item.TeamID = i.teamIDs[p.TeamName]
+		}
+		if _, err := i.sess.Insert(&item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importDataSources() error {
+	for idx, d := range i.archive.DataSources {
+		var existing models.DataSource
+		exists, err := i.sess.Where("org_id = ? AND uid = ?", i.orgID, d.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			exists, err = i.sess.Where("org_id = ? AND name = ?", i.orgID, d.Name).Get(&existing)
+			if err != nil {
+				return err
+			}
+		}
+
+		ds := &models.DataSource{
+			Id:              existing.Id,
+			OrgId:           i.orgID,
+			Version:         existing.Version + 1,
+			Name:            d.Name,
+			Type:            d.Type,
+			Access:          d.Access,
+			Url:             d.URL,
+			User:            d.User,
+			Database:        d.Database,
+			BasicAuth:       d.BasicAuth,
+			BasicAuthUser:   d.BasicAuthUser,
+			WithCredentials: d.WithCredentials,
+			IsDefault:       d.IsDefault,
+			JsonData:        d.JSONData,
+			SecureJsonData:  existing.SecureJsonData,
+			ReadOnly:        d.ReadOnly,
+			Uid:             d.UID,
+			Created:         existing.Created,
+			Updated:         time.Now(),
+		}
+		if ds.JsonData == nil {
+			ds.JsonData = simplejson.New()
+		}
+		if ds.SecureJsonData == nil {
+			ds.SecureJsonData = make(map[string][]byte)
+		}
+
+		if i.secrets != nil {
+			ds.SecureJsonData = i.secrets[idx]
+		}
+
+		if exists {
+			_, err = i.sess.ID(existing.Id).AllCols().Update(ds)
+		} else {
+			ds.Created = ds.Updated
+			_, err = i.sess.Insert(ds)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import data source %q: %w", d.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// reencryptSecrets decrypts secrets with the archive key and encrypts them
+// with the encryption service of this instance.
+func reencryptSecrets(encryptionService encryption.Service, secrets map[string][]byte,
+	archiveKey string) (map[string][]byte, error) {
+	encrypted := make(map[string][]byte, len(secrets))
+	for key, value := range secrets {
+		decrypted, err := util.Decrypt(value, archiveKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt %q: %w", key, err)
+		}
+		if encrypted[key], err = encryptionService.Encrypt(decrypted, setting.SecretKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return encrypted, nil
+}
+
+func (i *importer) importLibraryElements() error {
+	for _, l := range i.archive.LibraryElements {
+		var existing libraryElement
+		exists, err := i.sess.Where("org_id = ? AND uid = ?", i.orgID, l.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		element := &libraryElement{
+			ID:          existing.ID,
+			OrgID:       i.orgID,
+			FolderID:    i.dashboardIDs[l.FolderUID],
+			UID:         l.UID,
+			Name:        l.Name,
+			Kind:        l.Kind,
+			Type:        l.Type,
+			Description: l.Description,
+			Model:       l.Model,
+			Version:     l.Version,
+			Created:     l.Created,
+			Updated:     l.Updated,
+			CreatedBy:   existing.CreatedBy,
+			UpdatedBy:   existing.UpdatedBy,
+		}
+		if exists {
+			_, err = i.sess.ID(existing.ID).AllCols().Update(element)
+		} else {
+			_, err = i.sess.Insert(element)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import library element %q: %w", l.Name, err)
+		}
+
+		for _, uid := range l.Dashboards {
+			dashboardID, ok := i.dashboardIDs[uid]
+			if !ok {
+				continue
+			}
+
+			exists, err := i.sess.Where("element_id = ? AND kind = ? AND connection_id = ?", element.ID, dashboardConnection, dashboardID).
+				Exist(&libraryElementConnection{})
+			if err != nil {
+				return err
+			}
+			if exists {
+				continue
+			}
+
+			connection := libraryElementConnection{
+				ElementID:    element.ID,
+				Kind:         dashboardConnection,
+				ConnectionID: dashboardID,
+				Created:      time.Now(),
+			}
+			if _, err := i.sess.Insert(&connection); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importAlertRules() error {
+	for _, r := range i.archive.AlertRules {
+		var existing ngmodels.AlertRule
+		exists, err := i.sess.Where("org_id = ? AND uid = ?", i.orgID, r.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		rule := *r
+		rule.ID = existing.ID
+		rule.OrgID = i.orgID
+		if exists {
+			_, err = i.sess.ID(existing.ID).AllCols().Update(&rule)
+		} else {
+			_, err = i.sess.Insert(&rule)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import alert rule %q: %w", r.Title, err)
+		}
+
+		exists, err = i.sess.Where("rule_org_id = ? AND rule_uid = ? AND version = ?", i.orgID, rule.UID, rule.Version).
+			Exist(&ngmodels.AlertRuleVersion{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		version := ngmodels.AlertRuleVersion{
+			RuleOrgID:        rule.OrgID,
+			RuleUID:          rule.UID,
+			RuleNamespaceUID: rule.NamespaceUID,
+			RuleGroup:        rule.RuleGroup,
+			Version:          rule.Version,
+			Created:          rule.Updated,
+			Title:            rule.Title,
+			Condition:        rule.Condition,
+			Data:             rule.Data,
+			IntervalSeconds:  rule.IntervalSeconds,
+			NoDataState:      rule.NoDataState,
+			ExecErrState:     rule.ExecErrState,
+			For:              rule.For,
+			Annotations:      rule.Annotations,
+			Labels:           rule.Labels,
+			Variables:        rule.Variables,
+		}
+		if _, err := i.sess.Insert(&version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *importer) importPreferences() error {
+	for _, p := range i.archive.Preferences {
+		var teamID int64
+		if p.TeamName != "" {
+			var ok bool
+			if teamID, ok = i.teamIDs[p.TeamName]; !ok {
+				continue
+			}
+		}
+
+		var prefs models.Preferences
+		exists, err := i.sess.Where("org_id = ? AND team_id = ? AND user_id = 0", i.orgID, teamID).Get(&prefs)
+		if err != nil {
+			return err
+		}
+
+		prefs.OrgId = i.orgID
+		prefs.TeamId = teamID
+		prefs.HomeDashboardId = i.dashboardIDs[p.HomeDashboardUID]
+		prefs.Timezone = p.Timezone
+		prefs.Theme = p.Theme
+		prefs.Updated = time.Now()
+		if exists {
+			prefs.Version++
+			_, err = i.sess.ID(prefs.Id).AllCols().Update(&prefs)
+		} else {
+			prefs.Created = prefs.Updated
+			_, err = i.sess.Insert(&prefs)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package orgarchive
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportOrg(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+	encryptionService, err := ossencryption.ProvideService(sqlStore.Cfg, sqlStore)
+	require.NoError(t, err)
+
+	user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "editor"})
+	require.NoError(t, err)
+	sourceOrgID := user.OrgId
+
+	team, err := sqlStore.CreateTeam("devs", "devs@example.com", sourceOrgID)
+	require.NoError(t, err)
+	err = sqlStore.AddTeamMember(user.Id, sourceOrgID, team.Id, false, models.PERMISSION_ADMIN)
+	require.NoError(t, err)
+
+	folder, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:     sourceOrgID,
+		IsFolder:  true,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Folder", "uid": "folder"}),
+	})
+	require.NoError(t, err)
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId:    sourceOrgID,
+		FolderId: folder.Id,
+		UserId:   user.Id,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title": "Dashboard",
+			"uid":   "dash",
+			"tags":  []interface{}{"prod"},
+		}),
+	})
+	require.NoError(t, err)
+	err = sqlStore.UpdateDashboardACL(dash.Id, []*models.DashboardAcl{
+		{OrgID: sourceOrgID, DashboardID: dash.Id, UserID: user.Id, Permission: models.PERMISSION_EDIT, Created: time.Now(), Updated: time.Now()},
+		{OrgID: sourceOrgID, DashboardID: dash.Id, TeamID: team.Id, Permission: models.PERMISSION_ADMIN, Created: time.Now(), Updated: time.Now()},
+	})
+	require.NoError(t, err)
+
+	secureJsonData, err := encryptionService.EncryptJsonData(map[string]string{"password": "secret"}, setting.SecretKey)
+	require.NoError(t, err)
+	err = sqlstore.AddDataSource(&models.AddDataSourceCommand{
+		OrgId:                   sourceOrgID,
+		Name:                    "prom",
+		Type:                    "prometheus",
+		Access:                  models.DS_ACCESS_PROXY,
+		Uid:                     "prom",
+		EncryptedSecureJsonData: secureJsonData,
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "org.tar.gz")
+	archive, err := Export(ctx, sqlStore, encryptionService, sourceOrgID, "archive-key")
+	require.NoError(t, err)
+	require.NoError(t, archive.WriteFile(path))
+
+	t.Run("Archive contains the organization", func(t *testing.T) {
+		archive, err := ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, archiveVersion, archive.Manifest.Version)
+		require.True(t, archive.Manifest.SecretsIncluded)
+		require.Len(t, archive.Folders, 1)
+		require.Len(t, archive.Dashboards, 1)
+		require.Equal(t, "folder", archive.Dashboards[0].FolderUID)
+		require.Len(t, archive.Dashboards[0].Versions, 1)
+		require.Equal(t, "editor", archive.Dashboards[0].Versions[0].CreatedBy)
+		require.Len(t, archive.Dashboards[0].Permissions, 2)
+		require.Len(t, archive.Teams, 1)
+		require.Len(t, archive.Teams[0].Members, 1)
+		require.Len(t, archive.DataSources, 1)
+		require.NotEqual(t, "secret", string(archive.DataSources[0].SecureJSONData["password"]))
+	})
+
+	t.Run("Import requires the archive key", func(t *testing.T) {
+		err := Import(ctx, sqlStore, encryptionService, archive, sourceOrgID, "")
+		require.Error(t, err)
+		err = Import(ctx, sqlStore, encryptionService, archive, sourceOrgID, "wrong-key")
+		require.Error(t, err)
+	})
+
+	t.Run("Import restores the organization and can be repeated", func(t *testing.T) {
+		target, err := sqlStore.CreateOrgWithMember("Target", user.Id)
+		require.NoError(t, err)
+
+		for n := 0; n < 2; n++ {
+			err := Import(ctx, sqlStore, encryptionService, archive, target.Id, "archive-key")
+			require.NoError(t, err)
+		}
+
+		var dashboards []*models.Dashboard
+		err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			return sess.Where("org_id = ?", target.Id).Asc("id").Find(&dashboards)
+		})
+		require.NoError(t, err)
+		require.Len(t, dashboards, 2)
+		require.True(t, dashboards[0].IsFolder)
+		require.Equal(t, dashboards[0].Id, dashboards[1].FolderId)
+		require.Equal(t, dashboards[1].Id, dashboards[1].Data.Get("id").MustInt64())
+
+		imported := dashboards[1]
+		count := func(table, where string, args ...interface{}) int64 {
+			var n int64
+			err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+				var err error
+				n, err = sess.Table(table).Where(where, args...).Count()
+				return err
+			})
+			require.NoError(t, err)
+			return n
+		}
+		require.Equal(t, int64(1), count("dashboard_version", "dashboard_id = ?", imported.Id))
+		require.Equal(t, int64(1), count("dashboard_tag", "dashboard_id = ?", imported.Id))
+		require.Equal(t, int64(2), count("dashboard_acl", "dashboard_id = ?", imported.Id))
+		require.Equal(t, int64(1), count("team", "org_id = ?", target.Id))
+		require.Equal(t, int64(1), count("team_member", "org_id = ?", target.Id))
+
+		ds, err := sqlStore.GetDataSource("prom", 0, "", target.Id)
+		require.NoError(t, err)
+		decrypted, err := encryptionService.DecryptJsonData(ds.SecureJsonData, setting.SecretKey)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"password": "secret"}, decrypted)
+	})
+}
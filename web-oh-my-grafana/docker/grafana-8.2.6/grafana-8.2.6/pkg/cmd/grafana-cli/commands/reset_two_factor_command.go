@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// resetTwoFactorCommand removes the two-factor authentication of a user
+// who lost access to their authenticator app and recovery codes.
+func resetTwoFactorCommand(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	loginOrEmail := c.Args().First()
+	if loginOrEmail == "" {
+		return fmt.Errorf("missing user login or email")
+	}
+
+	userQuery := models.GetUserByLoginQuery{LoginOrEmail: loginOrEmail}
+	if err := bus.DispatchCtx(context.Background(), &userQuery); err != nil {
+		return fmt.Errorf("could not read user from database. Error: %v", err)
+	}
+
+	encryptionService, err := ossencryption.ProvideService(sqlStore.Cfg, sqlStore)
+	if err != nil {
+		return errutil.Wrap("failed to initialize encryption", err)
+	}
+	service := twofactor.ProvideService(sqlStore.Cfg, sqlStore, encryptionService)
+	if err := service.Disable(context.Background(), userQuery.Result.Id); err != nil {
+		return errutil.Wrapf(err, "failed to reset two-factor authentication")
+	}
+
+	logger.Infof("\n")
+	logger.Infof("Two-factor authentication of %s reset successfully %s", userQuery.Result.Login, color.GreenString("✔"))
+
+	return nil
+}
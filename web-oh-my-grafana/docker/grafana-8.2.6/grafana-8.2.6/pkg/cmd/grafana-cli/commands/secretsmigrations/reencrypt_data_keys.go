@@ -0,0 +1,29 @@
+package secretsmigrations
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ReEncryptDataKeys encrypts the data keys used for envelope encryption
+// with the active key encryption key of the configured provider.
+func ReEncryptDataKeys(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	encryptionService, err := ossencryption.ProvideService(sqlStore.Cfg, sqlStore)
+	if err != nil {
+		return errutil.Wrap("failed to initialize encryption", err)
+	}
+
+	count, err := encryptionService.ReEncryptDataKeys(context.Background())
+	if err != nil {
+		return errutil.Wrap("failed to re-encrypt data keys", err)
+	}
+
+	logger.Infof("%s Re-encrypted %d data keys\n", color.GreenString("✔"), count)
+	return nil
+}
@@ -0,0 +1,340 @@
+package secretsmigrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// secretColumn is a database column holding values encrypted with the
+// secret key, and how the encrypted values are stored in it.
+type secretColumn struct {
+	table  string
+	column string
+	// binary is true for blob columns, text columns are updated with strings.
+	binary bool
+	rotate func(r *rotator, value []byte) ([]byte, int, error)
+}
+
+var secretColumns = []secretColumn{
+	{table: "data_source", column: "secure_json_data", rotate: (*rotator).rotateSecureJSONData},
+	{table: "plugin_setting", column: "secure_json_data", rotate: (*rotator).rotateSecureJSONData},
+	{table: "alert_notification", column: "secure_settings", rotate: (*rotator).rotateSecureJSONData},
+	{table: "alert_configuration", column: "alertmanager_configuration", rotate: (*rotator).rotateAlertmanagerConfig},
+	{table: "dashboard_snapshot", column: "dashboard_encrypted", binary: true, rotate: (*rotator).rotateSnapshot},
+	{table: "user_auth", column: "o_auth_access_token", rotate: (*rotator).rotateEncoded},
+	{table: "user_auth", column: "o_auth_refresh_token", rotate: (*rotator).rotateEncoded},
+	{table: "user_auth", column: "o_auth_token_type", rotate: (*rotator).rotateEncoded},
+	{table: "user_two_factor", column: "secret", rotate: (*rotator).rotateTOTPSecret},
+}
+
+// RotationReport counts the rows and secrets of a column that have been,
+// or in a dry run would be, re-encrypted.
+type RotationReport struct {
+	Table   string
+	Column  string
+	Rows    int
+	Secrets int
+	// Failed are the ids of the rows which couldn't be decrypted with the
+	// old key.
+	Failed []string
+}
+
+// RotateSecretKey re-encrypts every secret stored in the database with the
+// key given by --new-key. Secrets are decrypted with --old-key, which
+// defaults to the configured secret_key.
+func RotateSecretKey(c utils.CommandLine, sqlStore *sqlstore.SQLStore) error {
+	newKey := c.String("new-key")
+	if newKey == "" {
+		return fmt.Errorf("missing new secret key, use --new-key")
+	}
+	oldKey := c.String("old-key")
+	if oldKey == "" {
+		oldKey = setting.SecretKey
+	}
+	if oldKey == newKey {
+		return fmt.Errorf("the new secret key is the same as the old one")
+	}
+
+	dryRun := c.Bool("dry-run")
+	reports, err := Rotate(context.Background(), sqlStore, oldKey, newKey, dryRun)
+	for _, r := range reports {
+		status := color.GreenString("✔")
+		if len(r.Failed) > 0 {
+			status = color.RedString("✗")
+		}
+		logger.Infof("%s %s.%s: %d secrets in %d rows\n", status, r.Table, r.Column, r.Secrets, r.Rows)
+		if len(r.Failed) > 0 {
+			logger.Infof("  could not decrypt rows with id %v\n", r.Failed)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("\n")
+	if dryRun {
+		logger.Infof("Dry run, no secrets have been changed.\n")
+		return nil
+	}
+	logger.Warn("All secrets have been re-encrypted, set secret_key in the configuration of every Grafana instance " +
+		"using this database to the new key before restarting them.\n")
+	return nil
+}
+
+// Rotate re-encrypts the secrets in a single transaction. The old key is
+// first verified against the secret key check of the database, and nothing
+// is changed if it doesn't match or if any secret can't be decrypted with
+// it. In a dry run the secrets are only decrypted to build the reports.
+func Rotate(ctx context.Context, sqlStore *sqlstore.SQLStore, oldKey, newKey string, dryRun bool) ([]*RotationReport, error) {
+	r := &rotator{oldKey: oldKey, newKey: newKey}
+	reports := make([]*RotationReport, 0, len(secretColumns))
+
+	err := sqlStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		hasCheck, err := sqlstore.VerifySecretKey(sess, oldKey)
+		if err != nil {
+			return errutil.Wrap("failed to verify the old secret key", err)
+		}
+		if !hasCheck {
+			logger.Warn("The database has no secret key check, the old secret key is only verified by decrypting the secrets.\n")
+		}
+
+		failed := 0
+		for _, col := range secretColumns {
+			report, err := r.rotateColumn(sess, col, dryRun)
+			if err != nil {
+				return errutil.Wrapf(err, "failed to re-encrypt %s.%s", col.table, col.column)
+			}
+			reports = append(reports, report)
+			failed += len(report.Failed)
+		}
+
+		if failed > 0 && !dryRun {
+			return fmt.Errorf("%d rows could not be decrypted with the old secret key, no secrets have been changed", failed)
+		}
+		if dryRun {
+			return nil
+		}
+		return sqlstore.StoreSecretKeyCheck(sess, newKey)
+	})
+
+	return reports, err
+}
+
+type rotator struct {
+	oldKey string
+	newKey string
+}
+
+func (r *rotator) rotateColumn(sess *sqlstore.DBSession, col secretColumn, dryRun bool) (*RotationReport, error) {
+	report := &RotationReport{Table: col.table, Column: col.column}
+
+	var rows []map[string][]byte
+	sess.Table(col.table)
+	sess.Cols("id", col.column)
+	sess.Where(col.column + " IS NOT NULL")
+	if err := sess.Find(&rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		value := row[col.column]
+		if len(value) == 0 {
+			continue
+		}
+
+		rotated, secrets, err := col.rotate(r, value)
+		if err != nil {
+			report.Failed = append(report.Failed, string(row["id"]))
+			continue
+		}
+		if secrets == 0 {
+			continue
+		}
+		report.Rows++
+		report.Secrets += secrets
+
+		if dryRun {
+			continue
+		}
+
+		var newValue interface{} = string(rotated)
+		if col.binary {
+			newValue = rotated
+		}
+		if _, err := sess.Exec("UPDATE "+col.table+" SET "+col.column+" = ? WHERE id = ?", newValue, string(row["id"])); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// reencrypt decrypts a secret with the old key and encrypts it with the new
+// one. Decryption of a ciphertext with a wrong key doesn't fail, so valid
+// is used to check that the decrypted value looks like a secret. This is
+// what catches a wrong key in databases without a secret key check.
+func (r *rotator) reencrypt(ciphertext []byte, valid func([]byte) bool) ([]byte, error) {
+	decrypted, err := util.Decrypt(ciphertext, r.oldKey)
+	if err != nil {
+		return nil, err
+	}
+	if !valid(decrypted) {
+		return nil, fmt.Errorf("secret could not be decrypted with the old key")
+	}
+
+	return util.Encrypt(decrypted, r.newKey)
+}
+
+// isText returns whether value is UTF-8 text without control characters
+// other than line breaks and tabs. Secrets are passwords, tokens and PEM
+// encoded certificates, while most of the values decrypted with a wrong key
+// contain non printable characters.
+func isText(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+	for _, c := range string(value) {
+		if !unicode.IsPrint(c) && c != '\n' && c != '\r' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// rotateSecureJSONData re-encrypts securejsondata.SecureJsonData, which is
+// stored as a JSON object with base64 encoded ciphertexts.
+func (r *rotator) rotateSecureJSONData(value []byte) ([]byte, int, error) {
+	var secrets map[string][]byte
+	if err := json.Unmarshal(value, &secrets); err != nil {
+		return nil, 0, err
+	}
+
+	count := 0
+	for key, secret := range secrets {
+		if encryption.IsEnvelopeEncrypted(secret) {
+			continue
+		}
+		rotated, err := r.reencrypt(secret, isText)
+		if err != nil {
+			return nil, 0, err
+		}
+		secrets[key] = rotated
+		count++
+	}
+
+	rotated, err := json.Marshal(secrets)
+	return rotated, count, err
+}
+
+// rotateEncoded re-encrypts a base64 encoded ciphertext.
+func (r *rotator) rotateEncoded(value []byte) ([]byte, int, error) {
+	secret, err := base64.StdEncoding.DecodeString(string(value))
+	if err != nil {
+		return nil, 0, err
+	}
+	// envelope encrypted secrets don't depend on the secret key
+	if encryption.IsEnvelopeEncrypted(secret) {
+		return value, 0, nil
+	}
+
+	rotated, err := r.reencrypt(secret, isText)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(rotated)), 1, nil
+}
+
+// rotateTOTPSecret re-encrypts the base64 encoded TOTP secret of a user,
+// which is encrypted in its base32 form.
+func (r *rotator) rotateTOTPSecret(value []byte) ([]byte, int, error) {
+	secret, err := base64.StdEncoding.DecodeString(string(value))
+	if err != nil {
+		return nil, 0, err
+	}
+	if encryption.IsEnvelopeEncrypted(secret) {
+		return value, 0, nil
+	}
+
+	rotated, err := r.reencrypt(secret, func(decrypted []byte) bool {
+		_, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(string(decrypted))
+		return len(decrypted) > 0 && err == nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(rotated)), 1, nil
+}
+
+// rotateSnapshot re-encrypts the dashboard of a snapshot.
+func (r *rotator) rotateSnapshot(value []byte) ([]byte, int, error) {
+	if encryption.IsEnvelopeEncrypted(value) {
+		return value, 0, nil
+	}
+
+	rotated, err := r.reencrypt(value, json.Valid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rotated, 1, nil
+}
+
+// rotateAlertmanagerConfig re-encrypts the secure settings of the Grafana
+// managed receivers of an Alertmanager configuration. The configuration
+// is handled as plain JSON so that no setting is lost when it's written back.
+func (r *rotator) rotateAlertmanagerConfig(value []byte) ([]byte, int, error) {
+	var config map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, 0, err
+	}
+
+	count := 0
+	amConfig, _ := config["alertmanager_config"].(map[string]interface{})
+	receivers, _ := amConfig["receivers"].([]interface{})
+	for _, receiver := range receivers {
+		receiver, _ := receiver.(map[string]interface{})
+		grafanaReceivers, _ := receiver["grafana_managed_receiver_configs"].([]interface{})
+		for _, gr := range grafanaReceivers {
+			gr, _ := gr.(map[string]interface{})
+			secureSettings, _ := gr["secureSettings"].(map[string]interface{})
+			for key, secret := range secureSettings {
+				encoded, ok := secret.(string)
+				if !ok {
+					continue
+				}
+				rotated, n, err := r.rotateEncoded([]byte(encoded))
+				if err != nil {
+					return nil, 0, err
+				}
+				secureSettings[key] = string(rotated)
+				count += n
+			}
+		}
+	}
+
+	if count == 0 {
+		return value, 0, nil
+	}
+
+	rotated, err := json.Marshal(config)
+	return rotated, count, err
+}
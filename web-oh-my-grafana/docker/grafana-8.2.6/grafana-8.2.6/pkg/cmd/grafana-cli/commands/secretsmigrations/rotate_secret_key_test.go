@@ -0,0 +1,169 @@
+package secretsmigrations
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateSecretKey(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	session := sqlStore.NewSession(context.Background())
+	defer session.Close()
+
+	encrypt := func(value, key string) []byte {
+		encrypted, err := util.Encrypt([]byte(value), key)
+		require.NoError(t, err)
+		return encrypted
+	}
+	decrypt := func(value []byte, key string) string {
+		decrypted, err := util.Decrypt(value, key)
+		require.NoError(t, err)
+		return string(decrypted)
+	}
+
+	ds := &models.DataSource{
+		Name: "prom", Type: "prometheus", Uid: "prom", Created: time.Now(), Updated: time.Now(),
+		SecureJsonData: map[string][]byte{"password": encrypt("ds-secret", setting.SecretKey)},
+	}
+	notification := &models.AlertNotification{
+		Name: "slack", Type: "slack", Uid: "slack", Created: time.Now(), Updated: time.Now(),
+		SecureSettings: map[string][]byte{"url": encrypt("notification-secret", setting.SecretKey)},
+	}
+	snapshot := &models.DashboardSnapshot{
+		Name: "snapshot", Key: "key", DeleteKey: "delete-key", Created: time.Now(), Updated: time.Now(), Expires: time.Now(),
+		DashboardEncrypted: encrypt(`{"title":"snapshot"}`, setting.SecretKey),
+	}
+	userAuth := &models.UserAuth{
+		UserId: 1, AuthModule: "oauth_github", AuthId: "1", Created: time.Now(), OAuthExpiry: time.Now(),
+		OAuthAccessToken: base64.StdEncoding.EncodeToString(encrypt("access-token", setting.SecretKey)),
+	}
+	amConfig := &ngmodels.AlertConfiguration{
+		AlertmanagerConfiguration: fmt.Sprintf(`{"alertmanager_config":{"receivers":[{"name":"email",
+			"grafana_managed_receiver_configs":[{"uid":"abc","type":"email","settings":{"limit":3},
+			"secureSettings":{"password":"%s"}}]}]}}`,
+			base64.StdEncoding.EncodeToString(encrypt("contact-point-secret", setting.SecretKey))),
+		ConfigurationVersion: "v1",
+		OrgID:                1,
+	}
+	for _, bean := range []interface{}{ds, notification, snapshot, userAuth, amConfig} {
+		_, err := session.Insert(bean)
+		require.NoError(t, err)
+	}
+
+	reload := func() {
+		ids := []int64{ds.Id, notification.Id, snapshot.Id, userAuth.Id, amConfig.ID}
+		for i, bean := range []interface{}{ds, notification, snapshot, userAuth, amConfig} {
+			_, err := session.NoAutoCondition().ID(ids[i]).Get(bean)
+			require.NoError(t, err)
+		}
+	}
+	contactPointSecret := func(key string) string {
+		var config struct {
+			AlertmanagerConfig struct {
+				Receivers []struct {
+					GrafanaManagedReceivers []struct {
+						SecureSettings map[string][]byte `json:"secureSettings"`
+					} `json:"grafana_managed_receiver_configs"`
+				} `json:"receivers"`
+			} `json:"alertmanager_config"`
+		}
+		err := json.Unmarshal([]byte(amConfig.AlertmanagerConfiguration), &config)
+		require.NoError(t, err)
+		return decrypt(config.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].SecureSettings["password"], key)
+	}
+
+	t.Run("Dry run reports secrets without changing them", func(t *testing.T) {
+		c, err := commandstest.NewCliContext(map[string]string{"new-key": "new-key", "dry-run": "true"})
+		require.NoError(t, err)
+		err = RotateSecretKey(c, sqlStore)
+		require.NoError(t, err)
+
+		reports, err := Rotate(context.Background(), sqlStore, setting.SecretKey, "new-key", true)
+		require.NoError(t, err)
+		secrets := map[string]int{}
+		for _, r := range reports {
+			require.Empty(t, r.Failed)
+			secrets[r.Table+"."+r.Column] = r.Secrets
+		}
+		assert.Equal(t, map[string]int{
+			"data_source.secure_json_data":                   1,
+			"plugin_setting.secure_json_data":                0,
+			"alert_notification.secure_settings":             1,
+			"alert_configuration.alertmanager_configuration": 1,
+			"dashboard_snapshot.dashboard_encrypted":         1,
+			"user_auth.o_auth_access_token":                  1,
+			"user_auth.o_auth_refresh_token":                 0,
+			"user_auth.o_auth_token_type":                    0,
+			"user_two_factor.secret":                         0,
+		}, secrets)
+
+		reload()
+		assert.Equal(t, "ds-secret", decrypt(ds.SecureJsonData["password"], setting.SecretKey))
+		assert.Equal(t, "contact-point-secret", contactPointSecret(setting.SecretKey))
+	})
+
+	t.Run("Rotation fails without changes if the old key is wrong", func(t *testing.T) {
+		reports, err := Rotate(context.Background(), sqlStore, "wrong-key", "new-key", false)
+		require.Error(t, err)
+		require.NotEmpty(t, reports[0].Failed)
+
+		reload()
+		assert.Equal(t, "ds-secret", decrypt(ds.SecureJsonData["password"], setting.SecretKey))
+	})
+
+	t.Run("Rotation fails without changes if the old key doesn't match the secret key check", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sqlstore.StoreSecretKeyCheck(sess, setting.SecretKey)
+		})
+		require.NoError(t, err)
+
+		reports, err := Rotate(context.Background(), sqlStore, "wrong-key", "new-key", false)
+		require.ErrorIs(t, err, sqlstore.ErrSecretKeyMismatch)
+		require.Empty(t, reports)
+
+		reload()
+		assert.Equal(t, "ds-secret", decrypt(ds.SecureJsonData["password"], setting.SecretKey))
+	})
+
+	t.Run("Rotation re-encrypts every secret with the new key", func(t *testing.T) {
+		_, err := Rotate(context.Background(), sqlStore, setting.SecretKey, "new-key", false)
+		require.NoError(t, err)
+
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			found, err := sqlstore.VerifySecretKey(sess, "new-key")
+			require.True(t, found)
+			return err
+		})
+		require.NoError(t, err)
+
+		reload()
+		assert.Equal(t, "ds-secret", decrypt(ds.SecureJsonData["password"], "new-key"))
+		assert.Equal(t, "notification-secret", decrypt(notification.SecureSettings["url"], "new-key"))
+		assert.Equal(t, `{"title":"snapshot"}`, decrypt(snapshot.DashboardEncrypted, "new-key"))
+		accessToken, err := base64.StdEncoding.DecodeString(userAuth.OAuthAccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "access-token", decrypt(accessToken, "new-key"))
+		assert.Equal(t, "contact-point-secret", contactPointSecret("new-key"))
+		assert.Contains(t, amConfig.AlertmanagerConfiguration, `"limit":3`)
+	})
+}
+
+func TestIsText(t *testing.T) {
+	assert.True(t, isText([]byte("p@ssw0rd")))
+	assert.True(t, isText([]byte("-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n")))
+	assert.False(t, isText([]byte("p@ss\x00word")))
+	assert.False(t, isText([]byte{0xff, 0xfe}))
+}
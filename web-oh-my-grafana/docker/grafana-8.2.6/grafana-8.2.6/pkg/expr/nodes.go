@@ -216,12 +216,18 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		},
 	}
 
+	interval := time.Duration(int64(time.Millisecond) * dn.intervalMS)
+	query, err := newVariableInterpolator(dn.request.Variables).interpolateJSON(dn.query)
+	if err != nil {
+		return mathexp.Results{}, fmt.Errorf("failed to interpolate the variables of query %v: %w", dn.refID, err)
+	}
+
 	q := []backend.DataQuery{
 		{
 			RefID:         dn.refID,
 			MaxDataPoints: dn.maxDP,
-			Interval:      time.Duration(int64(time.Millisecond) * dn.intervalMS),
-			JSON:          dn.query,
+			Interval:      interval,
+			JSON:          query,
 			TimeRange: backend.TimeRange{
 				From: dn.timeRange.From,
 				To:   dn.timeRange.To,
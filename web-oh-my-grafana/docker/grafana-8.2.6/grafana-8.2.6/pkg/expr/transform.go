@@ -65,6 +65,10 @@ type Request struct {
 	Debug   bool
 	OrgId   int64
 	Queries []Query
+	// Variables are the values of the template variables of the data source
+	// queries, replaced along with the built-in variables of their time range
+	// and interval.
+	Variables map[string]string
 }
 
 // Query is like plugins.DataSubQuery, but with a a time range, and only the UID
@@ -0,0 +1,122 @@
+package expr
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// variableRegExp matches the $name, [[name]], [[name:format]], ${name} and
+// ${name:format} syntaxes of the template variables of the dashboards.
+var variableRegExp = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^}]+))?\}`)
+
+var luceneEscaper = strings.NewReplacer(
+	`\`, `\\`, `+`, `\+`, `-`, `\-`, `=`, `\=`, `&`, `\&`, `|`, `\|`, `>`, `\>`, `<`, `\<`,
+	`!`, `\!`, `(`, `\(`, `)`, `\)`, `{`, `\{`, `}`, `\}`, `[`, `\[`, `]`, `\]`, `^`, `\^`,
+	`"`, `\"`, `~`, `\~`, `*`, `\*`, `?`, `\?`, `:`, `\:`, `/`, `\/`,
+)
+
+// variableInterpolator replaces the user-defined variables of a request in
+// the string values of queries. The built-in variables, such as __interval
+// and __from, are left to the data sources, which know how to format them.
+type variableInterpolator struct {
+	vars map[string]string
+}
+
+func newVariableInterpolator(vars map[string]string) *variableInterpolator {
+	return &variableInterpolator{vars: vars}
+}
+
+// interpolateJSON replaces the variables of the string values of a JSON
+// query model.
+func (vi *variableInterpolator) interpolateJSON(model json.RawMessage) (json.RawMessage, error) {
+	if len(vi.vars) == 0 || (!bytes.Contains(model, []byte("$")) && !bytes.Contains(model, []byte("[["))) {
+		return model, nil
+	}
+
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader(model))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(vi.interpolateValue(v))
+}
+
+func (vi *variableInterpolator) interpolateValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case string:
+		return vi.interpolate(t)
+	case map[string]interface{}:
+		for key, value := range t {
+			t[key] = vi.interpolateValue(value)
+		}
+	case []interface{}:
+		for i, value := range t {
+			t[i] = vi.interpolateValue(value)
+		}
+	}
+	return v
+}
+
+// interpolate replaces the variables of s, leaving the built-in and unknown
+// variables, and the variables with an unknown format, as they are.
+func (vi *variableInterpolator) interpolate(s string) string {
+	return variableRegExp.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegExp.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[5]
+		}
+
+		if strings.HasPrefix(name, "__") {
+			return match
+		}
+		value, ok := vi.vars[name]
+		if !ok {
+			return match
+		}
+		formatted, ok := formatVariable(name, value, format)
+		if !ok {
+			return match
+		}
+		return formatted
+	})
+}
+
+// formatVariable formats the single value of a variable with the formats of
+// the dashboard variables. It returns false for an unknown format.
+func formatVariable(name string, value string, format string) (string, bool) {
+	switch format {
+	case "", "raw", "text", "csv", "pipe", "glob", "distributed":
+		return value, true
+	case "regex":
+		return regexp.QuoteMeta(value), true
+	case "singlequote":
+		return "'" + strings.ReplaceAll(value, "'", `\'`) + "'", true
+	case "doublequote":
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`, true
+	case "sqlstring":
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", true
+	case "json":
+		b, err := json.Marshal(value)
+		return string(b), err == nil
+	case "lucene":
+		return luceneEscaper.Replace(value), true
+	case "percentencode":
+		return percentEncode(value), true
+	case "queryparam":
+		return "var-" + name + "=" + percentEncode(value), true
+	default:
+		return "", false
+	}
+}
+
+func percentEncode(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
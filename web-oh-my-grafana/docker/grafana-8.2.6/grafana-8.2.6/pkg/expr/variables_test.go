@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariableInterpolator(t *testing.T) {
+	vi := newVariableInterpolator(map[string]string{
+		"host":     "web-1.example.com",
+		"quote":    "it's",
+		"__from":   "0",
+		"interval": "1m",
+	})
+
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{input: "host = '$host' AND [[host]] AND ${host}", expected: "host = 'web-1.example.com' AND web-1.example.com AND web-1.example.com"},
+		{input: "${host:regex} ${host:percentencode} ${host:queryparam}", expected: `web-1\.example\.com web-1.example.com var-host=web-1.example.com`},
+		{input: "${quote:sqlstring} ${quote:singlequote} ${quote:doublequote} ${quote:json}", expected: `'it''s' 'it\'s' "it's" "it's"`},
+		{input: "${host:lucene}", expected: `web\-1.example.com`},
+		{input: "rate(up[$__interval]) [$interval]", expected: "rate(up[$__interval]) [1m]"},
+		{input: "$__from ${__from:date:YYYY-MM} $__timeFilter(time)", expected: "$__from ${__from:date:YYYY-MM} $__timeFilter(time)"},
+		{input: "${host:unknown} [[host:unknown]]", expected: "${host:unknown} [[host:unknown]]"},
+		{input: "$hostname $unknown ${unknown:csv} $A", expected: "$hostname $unknown ${unknown:csv} $A"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, vi.interpolate(tt.input))
+		})
+	}
+
+	t.Run("Should interpolate the string values of a query model", func(t *testing.T) {
+		model, err := vi.interpolateJSON(json.RawMessage(`{
+			"refId": "A",
+			"datasourceId": 9007199254740993,
+			"expr": "up{instance=\"$host\"}[$__interval]",
+			"tags": [{"key": "host", "value": "[[host]]"}]
+		}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"refId": "A",
+			"datasourceId": 9007199254740993,
+			"expr": "up{instance=\"web-1.example.com\"}[$__interval]",
+			"tags": [{"key": "host", "value": "web-1.example.com"}]
+		}`, string(model))
+		assert.Contains(t, string(model), "9007199254740993")
+	})
+
+	t.Run("Should leave a query model without variables as it is", func(t *testing.T) {
+		model := json.RawMessage(`{"refId": "A", "expr": "rate(up[$__interval])"}`)
+		result, err := newVariableInterpolator(nil).interpolateJSON(model)
+		require.NoError(t, err)
+		assert.Equal(t, string(model), string(result))
+	})
+}
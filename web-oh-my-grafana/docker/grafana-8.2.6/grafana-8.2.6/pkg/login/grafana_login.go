@@ -29,6 +29,11 @@ var loginUsingGrafanaDB = func(query *models.LoginUserQuery) error {
 
 	user := userQuery.Result
 
+	// service accounts only authenticate with their tokens
+	if user.IsServiceAccount {
+		return models.ErrUserNotFound
+	}
+
 	if user.IsDisabled {
 		return ErrUserDisabled
 	}
@@ -78,6 +78,14 @@ func (auth *mockAuth) Users(logins []string) (
 	return nil, nil
 }
 
+func (auth *mockAuth) UsersWithServerErrors(logins []string) (
+	[]*models.ExternalUserInfo,
+	[]*multildap.ServerStatus,
+	error,
+) {
+	return nil, nil, nil
+}
+
 func (auth *mockAuth) User(login string) (
 	*models.ExternalUserInfo,
 	ldap.ServerConfig,
@@ -106,6 +106,56 @@ func TestMiddlewareBasicAuth(t *testing.T) {
 		assert.Equal(t, id, sc.context.UserId)
 	}, configure)
 
+	middlewareScenario(t, "Should return error if user has to use two-factor authentication", func(t *testing.T, sc *scenarioContext) {
+		const password = "MyPass"
+
+		login.Init()
+
+		user, err := sc.sqlStore.CreateUser(context.Background(), models.CreateUserCommand{
+			Login:    "myUser",
+			Password: password,
+		})
+		require.NoError(t, err)
+		err = sc.contextHandler.TwoFactorService.SaveOrgPolicy(context.Background(),
+			&models.OrgAuthPolicy{OrgId: user.OrgId, RequireTwoFactorForAdmins: true})
+		require.NoError(t, err)
+
+		bus.AddHandler("user-query", func(query *models.GetUserByLoginQuery) error {
+			query.Result = user
+			return nil
+		})
+
+		authHeader := util.GetBasicAuthHeader("myUser", password)
+		sc.fakeReq("GET", "/").withAuthorizationHeader(authHeader).exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Nil(t, sc.context)
+	}, configure)
+
+	middlewareScenario(t, "Should return error if user has to change their password", func(t *testing.T, sc *scenarioContext) {
+		const password = "MyPass"
+
+		login.Init()
+
+		user, err := sc.sqlStore.CreateUser(context.Background(), models.CreateUserCommand{
+			Login:                  "myUser",
+			Password:               password,
+			PasswordChangeRequired: true,
+		})
+		require.NoError(t, err)
+
+		bus.AddHandler("user-query", func(query *models.GetUserByLoginQuery) error {
+			query.Result = user
+			return nil
+		})
+
+		authHeader := util.GetBasicAuthHeader("myUser", password)
+		sc.fakeReq("GET", "/").withAuthorizationHeader(authHeader).exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Nil(t, sc.context)
+	}, configure)
+
 	middlewareScenario(t, "Should return error if user is not found", func(t *testing.T, sc *scenarioContext) {
 		sc.fakeReq("GET", "/")
 		sc.req.SetBasicAuth("user", "password")
@@ -3,13 +3,17 @@ package middleware
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/teamsync"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMiddlewareJWTAuth(t *testing.T) {
@@ -29,6 +33,10 @@ func TestMiddlewareJWTAuth(t *testing.T) {
 		cfg.JWTAuthEmailClaim = "foo-email"
 	}
 
+	configureGroupsClaim := func(cfg *setting.Cfg) {
+		cfg.JWTAuthGroupsClaim = "foo-groups"
+	}
+
 	token := "some-token"
 
 	middlewareScenario(t, "Valid token with valid login claim", func(t *testing.T, sc *scenarioContext) {
@@ -85,6 +93,184 @@ func TestMiddlewareJWTAuth(t *testing.T) {
 		assert.Equal(t, myEmail, sc.context.Email)
 	}, configure, configureEmailClaim)
 
+	middlewareScenario(t, "Valid token with groups claim", func(t *testing.T, sc *scenarioContext) {
+		myUsername := "vladimir"
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			return models.JWTClaims{
+				"foo-username": myUsername,
+				"foo-groups":   []interface{}{"sre", "developers"},
+			}, nil
+		}
+		bus.AddHandlerCtx("get-sign-user", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{
+				UserId: id,
+				OrgId:  orgID,
+				Login:  query.Login,
+			}
+			return nil
+		})
+		teamSync := sc.contextHandler.TeamSyncService.(*teamsync.FakeTeamSyncService)
+
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		require.Len(t, teamSync.Synced, 1)
+		assert.Equal(t, models.AuthModuleJWT, teamSync.Synced[0].AuthModule)
+		assert.Equal(t, myUsername, teamSync.Synced[0].Login)
+		assert.Equal(t, []string{"sre", "developers"}, teamSync.Synced[0].Groups)
+
+		// The teams are not synced again while the groups don't change.
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Len(t, teamSync.Synced, 1)
+	}, configure, configureUsernameClaim, configureGroupsClaim)
+
+	middlewareScenario(t, "Valid token with groups and role claims", func(t *testing.T, sc *scenarioContext) {
+		myUsername := "vladimir"
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			return models.JWTClaims{
+				"sub":          "1234",
+				"foo-username": myUsername,
+				"foo-groups":   []interface{}{"sre", "developers"},
+				"role":         "Editor",
+			}, nil
+		}
+		var upserted []*models.ExternalUserInfo
+		bus.AddHandlerCtx("upsert-user", func(ctx context.Context, cmd *models.UpsertUserCommand) error {
+			upserted = append(upserted, cmd.ExternalUser)
+			assert.False(t, cmd.SignupAllowed)
+			cmd.Result = &models.User{Id: id, Login: cmd.ExternalUser.Login}
+			return nil
+		})
+		disabled := false
+		bus.AddHandlerCtx("get-user", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: query.Id, Login: myUsername, IsDisabled: disabled}
+			return nil
+		})
+		bus.AddHandlerCtx("get-sign-user", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			assert.Equal(t, id, query.UserId)
+			query.Result = &models.SignedInUser{
+				UserId: query.UserId,
+				OrgId:  orgID,
+				Login:  myUsername,
+			}
+			return nil
+		})
+		teamSync := sc.contextHandler.TeamSyncService.(*teamsync.FakeTeamSyncService)
+
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, id, sc.context.UserId)
+		require.Len(t, upserted, 1)
+		assert.Equal(t, models.AuthModuleJWT, upserted[0].AuthModule)
+		assert.Equal(t, "1234", upserted[0].AuthId)
+		assert.Equal(t, myUsername, upserted[0].Login)
+		assert.Equal(t, []string{"sre", "developers"}, upserted[0].Groups)
+		assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_EDITOR}, upserted[0].OrgRoles)
+		// The teams are synced with the user.
+		assert.Empty(t, teamSync.Synced)
+
+		// The user is not synced again while the claims don't change.
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Len(t, upserted, 1)
+
+		// A user disabled since they were synced is rejected.
+		disabled = true
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, contexthandler.InvalidJWT, sc.respJson["message"])
+		assert.Len(t, upserted, 1)
+	}, configure, configureUsernameClaim, configureGroupsClaim, func(cfg *setting.Cfg) {
+		cfg.JWTAuthRoleAttributePath = "role"
+	})
+
+	middlewareScenario(t, "Valid token with role and org claims", func(t *testing.T, sc *scenarioContext) {
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			return models.JWTClaims{
+				"foo-email": "vladimir@example.com",
+				"foo-name":  "Vladimir",
+				"info": map[string]interface{}{
+					"roles": []interface{}{"admin"},
+					"orgs":  []interface{}{"engineering", "unknown"},
+				},
+			}, nil
+		}
+		var upserted *models.UpsertUserCommand
+		bus.AddHandlerCtx("upsert-user", func(ctx context.Context, cmd *models.UpsertUserCommand) error {
+			upserted = cmd
+			cmd.Result = &models.User{Id: id}
+			return nil
+		})
+		bus.AddHandlerCtx("get-sign-user", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: query.UserId, OrgId: orgID}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 200, sc.resp.Code)
+		require.NotNil(t, upserted)
+		assert.True(t, upserted.SignupAllowed)
+		assert.Equal(t, "vladimir@example.com", upserted.ExternalUser.Login)
+		assert.Equal(t, "Vladimir", upserted.ExternalUser.Name)
+		assert.Equal(t, map[int64]models.RoleType{2: models.ROLE_ADMIN, 3: models.ROLE_ADMIN}, upserted.ExternalUser.OrgRoles)
+	}, configure, configureEmailClaim, func(cfg *setting.Cfg) {
+		cfg.JWTAuthNameClaim = "foo-name"
+		cfg.JWTAuthAutoSignUp = true
+		cfg.JWTAuthRoleAttributePath = "contains(info.roles[*], 'admin') && 'Admin' || 'Viewer'"
+		cfg.JWTAuthOrgAttributePath = "info.orgs"
+		cfg.JWTAuthOrgMapping = map[string][]int64{"engineering": {2, 3}, "sales": {4}}
+	})
+
+	middlewareScenario(t, "Valid token of a user not allowed to sign up", func(t *testing.T, sc *scenarioContext) {
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			return models.JWTClaims{"foo-username": "vladimir", "role": "Editor"}, nil
+		}
+		bus.AddHandlerCtx("upsert-user", func(ctx context.Context, cmd *models.UpsertUserCommand) error {
+			assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_EDITOR}, cmd.ExternalUser.OrgRoles)
+			return login.ErrInvalidCredentials
+		})
+
+		sc.fakeReq("GET", "/").withJWTAuthHeader(token).exec()
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, contexthandler.InvalidJWT, sc.respJson["message"])
+	}, configure, configureUsernameClaim, func(cfg *setting.Cfg) {
+		cfg.JWTAuthRoleAttributePath = "role"
+	})
+
+	middlewareScenario(t, "Valid token in the URL", func(t *testing.T, sc *scenarioContext) {
+		var verifiedToken string
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			verifiedToken = token
+			return models.JWTClaims{"foo-username": "vladimir"}, nil
+		}
+		bus.AddHandlerCtx("get-sign-user", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{UserId: id, OrgId: orgID, Login: query.Login}
+			return nil
+		})
+		sc.userAuthTokenService.CreateTokenProvider = func(ctx context.Context, user *models.User, clientIP net.IP, userAgent string) (*models.UserToken, error) {
+			assert.Equal(t, id, user.Id)
+			return &models.UserToken{UserId: user.Id, UnhashedToken: "session-token"}, nil
+		}
+
+		sc.fakeReq("GET", "/?auth_token="+token).exec()
+		assert.Equal(t, token, verifiedToken)
+		assert.Equal(t, 200, sc.resp.Code)
+		assert.Equal(t, id, sc.context.UserId)
+		assert.Contains(t, sc.resp.Header().Get("Set-Cookie"), "grafana_session=session-token")
+	}, configure, configureUsernameClaim, func(cfg *setting.Cfg) {
+		cfg.JWTAuthURLLogin = true
+	})
+
+	middlewareScenario(t, "Token in the URL without URL login", func(t *testing.T, sc *scenarioContext) {
+		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
+			t.Fatal("the token should not be verified")
+			return nil, nil
+		}
+
+		sc.fakeReq("GET", "/?auth_token="+token).exec()
+		assert.False(t, sc.context.IsSignedIn)
+	}, configure, configureUsernameClaim)
+
 	middlewareScenario(t, "Valid token without a login claim", func(t *testing.T, sc *scenarioContext) {
 		var verifiedToken string
 		sc.jwtAuthService.VerifyProvider = func(ctx context.Context, token string) (models.JWTClaims, error) {
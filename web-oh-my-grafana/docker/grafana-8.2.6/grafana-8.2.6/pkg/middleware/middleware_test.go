@@ -26,8 +26,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/contexthandler/authproxy"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/teamsync"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -162,6 +166,66 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
 	})
 
+	middlewareScenario(t, "Valid service account token", func(t *testing.T, sc *scenarioContext) {
+		const orgID int64 = 12
+		const serviceAccountID int64 = 7
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		var lastUsed *models.UpdateApiKeyLastUsedCommand
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			id := serviceAccountID
+			query.Result = &models.ApiKey{Id: 3, OrgId: orgID, Role: models.ROLE_EDITOR, Key: keyhash, ServiceAccountId: &id}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.UpdateApiKeyLastUsedCommand) error {
+			lastUsed = cmd
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: serviceAccountID, IsServiceAccount: true}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetSignedInUserQuery) error {
+			query.Result = &models.SignedInUser{OrgId: query.OrgId, UserId: query.UserId, Login: "sa-ci",
+				OrgRole: models.ROLE_VIEWER, IsServiceAccount: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+
+		assert.True(t, sc.context.IsSignedIn)
+		assert.True(t, sc.context.IsServiceAccount)
+		assert.Equal(t, serviceAccountID, sc.context.UserId)
+		assert.Equal(t, int64(3), sc.context.ApiKeyId)
+		assert.Equal(t, "sa-ci", sc.context.Login)
+		assert.Equal(t, models.ROLE_VIEWER, sc.context.OrgRole)
+		require.NotNil(t, lastUsed)
+		assert.Equal(t, int64(3), lastUsed.Id)
+	})
+
+	middlewareScenario(t, "Valid token of a disabled service account", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			id := int64(7)
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash, ServiceAccountId: &id}
+			return nil
+		})
+		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetUserByIdQuery) error {
+			query.Result = &models.User{Id: 7, IsServiceAccount: true, IsDisabled: true}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 401, sc.resp.Code)
+		assert.Equal(t, "Service account is disabled", sc.respJson["message"])
+	})
+
 	middlewareScenario(t, "Valid API key, but does not match DB hash", func(t *testing.T, sc *scenarioContext) {
 		const keyhash = "Something_not_matching"
 
@@ -678,7 +742,9 @@ func getContextHandler(t *testing.T, cfg *setting.Cfg) *contexthandler.ContextHa
 	userAuthTokenSvc := auth.NewFakeUserAuthTokenService()
 	renderSvc := &fakeRenderService{}
 	authJWTSvc := models.NewFakeJWTService()
-	return contexthandler.ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, sqlStore)
+	return contexthandler.ProvideService(cfg, userAuthTokenSvc, authJWTSvc, remoteCacheSvc, renderSvc, sqlStore,
+		twofactor.ProvideService(cfg, sqlStore, &ossencryption.Service{}), teamsync.NewFakeTeamSyncService(),
+		passwordpolicy.NewFakePasswordPolicyService())
 }
 
 type fakeRenderService struct {
@@ -2,6 +2,10 @@ package middleware
 
 import (
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
 
 	"gopkg.in/macaron.v1"
 
@@ -23,6 +27,12 @@ func Quota(quotaService *quota.QuotaService) func(string) macaron.Handler {
 				return
 			}
 			if limitReached {
+				if quota.IsRateTarget(target) {
+					retryAfter := math.Ceil(time.Until(quotaService.RateQuotaResetsAt()).Seconds())
+					c.Resp.Header().Set("Retry-After", strconv.Itoa(int(retryAfter)))
+					c.JsonApiErr(http.StatusTooManyRequests, fmt.Sprintf("%s Quota reached", target), nil)
+					return
+				}
 				c.JsonApiErr(403, fmt.Sprintf("%s Quota reached", target), nil)
 				return
 			}
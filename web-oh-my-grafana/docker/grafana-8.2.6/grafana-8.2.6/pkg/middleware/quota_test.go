@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -127,6 +128,11 @@ func TestMiddlewareQuota(t *testing.T) {
 				}
 				return nil
 			})
+
+			bus.AddHandler("orgQuotaLimit", func(query *models.GetOrgQuotaLimitQuery) error {
+				query.Result = query.Default
+				return nil
+			})
 		}
 
 		middlewareScenario(t, "global datasource quota reached", func(t *testing.T, sc *scenarioContext) {
@@ -251,6 +257,36 @@ func TestMiddlewareQuota(t *testing.T) {
 			cfg.Quota.Org.AlertRule = quotaUsed
 		})
 
+		middlewareScenario(t, "org data source query rate quota reached", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "data_source_query")
+			sc.m.Get("/ds/query", quotaHandler, sc.defaultHandler)
+			for i := 0; i < quotaUsed; i++ {
+				sc.fakeReq("GET", "/ds/query").exec()
+				assert.Equal(t, 200, sc.resp.Code)
+			}
+
+			sc.fakeReq("GET", "/ds/query").exec()
+			assert.Equal(t, 429, sc.resp.Code)
+			assert.NotEmpty(t, sc.resp.Header().Get("Retry-After"))
+		}, func(cfg *setting.Cfg) {
+			configure(cfg)
+
+			cfg.Quota.OrgRate.DataSourceQuery = quotaUsed
+		})
+
+		middlewareScenario(t, "org api request rate quota unlimited", func(t *testing.T, sc *scenarioContext) {
+			setUp(sc)
+
+			quotaHandler := getQuotaHandler(sc, "api_request")
+			sc.m.Get("/api", quotaHandler, sc.defaultHandler)
+			for i := 0; i < quotaUsed*2; i++ {
+				sc.fakeReq("GET", "/api").exec()
+				assert.Equal(t, 200, sc.resp.Code)
+			}
+		}, configure)
+
 		middlewareScenario(t, "org alert quota not reached but ngalert disabled", func(t *testing.T, sc *scenarioContext) {
 			setUp(sc)
 
@@ -299,5 +335,13 @@ func configure(cfg *setting.Cfg) {
 			Session:    5,
 			AlertRule:  5,
 		},
+		Folder: &setting.FolderQuota{
+			Dashboard: 5,
+		},
+		OrgRate: &setting.OrgRateQuota{
+			DataSourceQuery: -1,
+			ApiRequest:      -1,
+		},
+		RateWindow: time.Hour,
 	}
 }
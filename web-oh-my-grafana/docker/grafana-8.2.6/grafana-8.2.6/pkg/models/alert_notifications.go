@@ -53,8 +53,9 @@ type CreateAlertNotificationCommand struct {
 	Settings              *simplejson.Json  `json:"settings"`
 	SecureSettings        map[string]string `json:"secureSettings"`
 
-	OrgId  int64 `json:"-"`
-	Result *AlertNotification
+	OrgId                   int64             `json:"-"`
+	EncryptedSecureSettings map[string][]byte `json:"-"`
+	Result                  *AlertNotification
 }
 
 type UpdateAlertNotificationCommand struct {
@@ -69,8 +70,9 @@ type UpdateAlertNotificationCommand struct {
 	Settings              *simplejson.Json  `json:"settings"  binding:"Required"`
 	SecureSettings        map[string]string `json:"secureSettings"`
 
-	OrgId  int64 `json:"-"`
-	Result *AlertNotification
+	OrgId                   int64             `json:"-"`
+	EncryptedSecureSettings map[string][]byte `json:"-"`
+	Result                  *AlertNotification
 }
 
 type UpdateAlertNotificationWithUidCommand struct {
@@ -85,8 +87,9 @@ type UpdateAlertNotificationWithUidCommand struct {
 	Settings              *simplejson.Json  `json:"settings"  binding:"Required"`
 	SecureSettings        map[string]string `json:"secureSettings"`
 
-	OrgId  int64
-	Result *AlertNotification
+	OrgId                   int64
+	EncryptedSecureSettings map[string][]byte `json:"-"`
+	Result                  *AlertNotification
 }
 
 type DeleteAlertNotificationCommand struct {
@@ -166,11 +169,3 @@ type GetOrCreateNotificationStateQuery struct {
 
 	Result *AlertNotificationState
 }
-
-// decryptedValue returns decrypted value from secureSettings
-func (an *AlertNotification) DecryptedValue(field string, fallback string) string {
-	if value, ok := an.SecureSettings.DecryptedValue(field); ok {
-		return value
-	}
-	return fallback
-}
@@ -21,6 +21,10 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// ServiceAccountId is set for the tokens of a service account, which
+	// authenticate as the service account instead of with Role.
+	ServiceAccountId *int64
+	LastUsedAt       *time.Time
 }
 
 // ---------------------
@@ -31,10 +35,17 @@ type AddApiKeyCommand struct {
 	OrgId         int64    `json:"-"`
 	Key           string   `json:"-"`
 	SecondsToLive int64    `json:"secondsToLive"`
+	// ServiceAccountId is set when adding a token to a service account.
+	ServiceAccountId *int64 `json:"-"`
 
 	Result *ApiKey `json:"-"`
 }
 
+type UpdateApiKeyLastUsedCommand struct {
+	Id         int64
+	LastUsedAt time.Time
+}
+
 type DeleteApiKeyCommand struct {
 	Id    int64 `json:"id"`
 	OrgId int64 `json:"-"`
@@ -64,8 +75,10 @@ type GetApiKeyByIdQuery struct {
 // DTO & Projections
 
 type ApiKeyDTO struct {
-	Id         int64      `json:"id"`
-	Name       string     `json:"name"`
-	Role       RoleType   `json:"role"`
-	Expiration *time.Time `json:"expiration,omitempty"`
+	Id               int64      `json:"id"`
+	Name             string     `json:"name"`
+	Role             RoleType   `json:"role"`
+	Expiration       *time.Time `json:"expiration,omitempty"`
+	ServiceAccountId *int64     `json:"serviceAccountId,omitempty"`
+	LastUsedAt       *time.Time `json:"lastUsedAt,omitempty"`
 }
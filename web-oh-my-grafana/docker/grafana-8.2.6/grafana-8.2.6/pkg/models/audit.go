@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Types of the actor of an audit event.
+const (
+	AuditActorUser           = "user"
+	AuditActorAPIKey         = "api_key"
+	AuditActorServiceAccount = "service_account"
+	AuditActorAnonymous      = "anonymous"
+	AuditActorProvisioning   = "provisioning"
+)
+
+// AuditEvent records a change made by a user, an API key or service
+// account, or provisioning.
+type AuditEvent struct {
+	Id           int64
+	OrgId        int64
+	Created      time.Time
+	ActorType    string
+	ActorId      int64
+	ActorLogin   string
+	Action       string
+	ResourceType string
+	ResourceUid  string
+	// Diff is the JSON encoded list of the changes made to the resource.
+	Diff      string
+	IpAddress string
+}
+
+func (e *AuditEvent) ToDTO() *AuditEventDTO {
+	dto := &AuditEventDTO{
+		Id:           e.Id,
+		OrgId:        e.OrgId,
+		Time:         e.Created,
+		ActorType:    e.ActorType,
+		ActorId:      e.ActorId,
+		ActorLogin:   e.ActorLogin,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceUid:  e.ResourceUid,
+		IpAddress:    e.IpAddress,
+	}
+	if e.Diff != "" {
+		dto.Diff = json.RawMessage(e.Diff)
+	}
+
+	return dto
+}
+
+// ---------------------
+// COMMANDS
+
+// RecordAuditEventCommand records an event made outside of an API request,
+// such as by provisioning.
+type RecordAuditEventCommand struct {
+	Event *AuditEvent
+}
+
+// ----------------------
+// QUERIES
+
+type SearchAuditEventsQuery struct {
+	OrgId        int64
+	From         time.Time
+	To           time.Time
+	ActorType    string
+	ActorLogin   string
+	Action       string
+	ResourceType string
+	ResourceUid  string
+	Page         int
+	Limit        int
+
+	Result SearchAuditEventsResult
+}
+
+// ------------------------
+// DTO & Projections
+
+type AuditEventDTO struct {
+	Id           int64           `json:"id"`
+	OrgId        int64           `json:"orgId"`
+	Time         time.Time       `json:"time"`
+	ActorType    string          `json:"actorType"`
+	ActorId      int64           `json:"actorId"`
+	ActorLogin   string          `json:"actorLogin"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resourceType,omitempty"`
+	ResourceUid  string          `json:"resourceUid,omitempty"`
+	Diff         json.RawMessage `json:"diff,omitempty"`
+	IpAddress    string          `json:"ipAddress,omitempty"`
+}
+
+type SearchAuditEventsResult struct {
+	TotalCount int64            `json:"totalCount"`
+	Events     []*AuditEventDTO `json:"events"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"perPage"`
+}
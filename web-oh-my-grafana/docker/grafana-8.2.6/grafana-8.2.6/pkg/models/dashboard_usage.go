@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// DashboardUsageByUser keeps track of how many times a user has
+// viewed a dashboard and when it was last viewed.
+type DashboardUsageByUser struct {
+	Id           int64
+	OrgId        int64
+	DashboardId  int64
+	UserId       int64
+	Views        int64
+	LastViewedAt int64
+}
+
+// DashboardUsageByDay is the daily aggregate of views for a dashboard.
+type DashboardUsageByDay struct {
+	Id          int64
+	OrgId       int64
+	DashboardId int64
+	Day         string
+	Views       int64
+}
+
+type StaleDashboard struct {
+	Id           int64     `json:"id"`
+	Uid          string    `json:"uid"`
+	Title        string    `json:"title"`
+	Slug         string    `json:"-"`
+	Url          string    `json:"url" xorm:"-"`
+	FolderId     int64     `json:"folderId"`
+	Created      time.Time `json:"created"`
+	Views        int64     `json:"views"`
+	LastViewedAt int64     `json:"lastViewedAt"`
+}
+
+// ----------------------
+// QUERIES
+
+type GetStaleDashboardsQuery struct {
+	OrgId     int64
+	OlderThan time.Time
+	Limit     int
+
+	Result []*StaleDashboard
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrDataKeyNotFound = errors.New("data key not found")
+)
+
+// DataKey is a key used to encrypt a single secret. It's stored encrypted
+// with a key encryption key which is managed by a key provider outside of
+// the database.
+type DataKey struct {
+	Id       int64
+	Name     string
+	Provider string
+	// KeyEncryptionKeyId identifies the key of the provider which
+	// EncryptedData has been encrypted with.
+	KeyEncryptionKeyId string
+	EncryptedData      []byte
+	Created            time.Time
+	Updated            time.Time
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/encryption"
 )
 
 const (
@@ -62,19 +63,19 @@ type DataSource struct {
 
 // DecryptedBasicAuthPassword returns data source basic auth password in plain text. It uses either deprecated
 // basic_auth_password field or encrypted secure_json_data[basicAuthPassword] variable.
-func (ds *DataSource) DecryptedBasicAuthPassword() string {
-	return ds.decryptedValue("basicAuthPassword", ds.BasicAuthPassword)
+func (ds *DataSource) DecryptedBasicAuthPassword(encryptionService encryption.Service) string {
+	return ds.decryptedValue(encryptionService, "basicAuthPassword", ds.BasicAuthPassword)
 }
 
 // DecryptedPassword returns data source password in plain text. It uses either deprecated password field
 // or encrypted secure_json_data[password] variable.
-func (ds *DataSource) DecryptedPassword() string {
-	return ds.decryptedValue("password", ds.Password)
+func (ds *DataSource) DecryptedPassword(encryptionService encryption.Service) string {
+	return ds.decryptedValue(encryptionService, "password", ds.Password)
 }
 
 // decryptedValue returns decrypted value from secureJsonData
-func (ds *DataSource) decryptedValue(field string, fallback string) string {
-	if value, ok := ds.DecryptedValue(field); ok {
+func (ds *DataSource) decryptedValue(encryptionService encryption.Service, field string, fallback string) string {
+	if value, ok := ds.DecryptedValue(encryptionService, field); ok {
 		return value
 	}
 	return fallback
@@ -101,8 +102,9 @@ type AddDataSourceCommand struct {
 	SecureJsonData    map[string]string `json:"secureJsonData"`
 	Uid               string            `json:"uid"`
 
-	OrgId    int64 `json:"-"`
-	ReadOnly bool  `json:"-"`
+	OrgId                   int64             `json:"-"`
+	ReadOnly                bool              `json:"-"`
+	EncryptedSecureJsonData map[string][]byte `json:"-"`
 
 	Result *DataSource
 }
@@ -126,9 +128,10 @@ type UpdateDataSourceCommand struct {
 	Version           int               `json:"version"`
 	Uid               string            `json:"uid"`
 
-	OrgId    int64 `json:"-"`
-	Id       int64 `json:"-"`
-	ReadOnly bool  `json:"-"`
+	OrgId                   int64             `json:"-"`
+	Id                      int64             `json:"-"`
+	ReadOnly                bool              `json:"-"`
+	EncryptedSecureJsonData map[string][]byte `json:"-"`
 
 	Result *DataSource
 }
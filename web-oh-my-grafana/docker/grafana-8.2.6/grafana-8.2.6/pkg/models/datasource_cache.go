@@ -11,6 +11,8 @@ import (
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor/azcredentials"
 )
@@ -48,8 +50,8 @@ var ptc = proxyTransportCache{
 	cache: make(map[int64]cachedRoundTripper),
 }
 
-func (ds *DataSource) GetHTTPClient(provider httpclient.Provider) (*http.Client, error) {
-	transport, err := ds.GetHTTPTransport(provider)
+func (ds *DataSource) GetHTTPClient(provider httpclient.Provider, encryptionService encryption.Service) (*http.Client, error) {
+	transport, err := ds.GetHTTPTransport(provider, encryptionService)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +62,7 @@ func (ds *DataSource) GetHTTPClient(provider httpclient.Provider) (*http.Client,
 	}, nil
 }
 
-func (ds *DataSource) GetHTTPTransport(provider httpclient.Provider, customMiddlewares ...sdkhttpclient.Middleware) (http.RoundTripper, error) {
+func (ds *DataSource) GetHTTPTransport(provider httpclient.Provider, encryptionService encryption.Service, customMiddlewares ...sdkhttpclient.Middleware) (http.RoundTripper, error) {
 	ptc.Lock()
 	defer ptc.Unlock()
 
@@ -68,7 +70,7 @@ func (ds *DataSource) GetHTTPTransport(provider httpclient.Provider, customMiddl
 		return t.roundTripper, nil
 	}
 
-	opts, err := ds.HTTPClientOptions()
+	opts, err := ds.HTTPClientOptions(encryptionService)
 	if err != nil {
 		return nil, err
 	}
@@ -88,8 +90,9 @@ func (ds *DataSource) GetHTTPTransport(provider httpclient.Provider, customMiddl
 	return rt, nil
 }
 
-func (ds *DataSource) HTTPClientOptions() (*sdkhttpclient.Options, error) {
-	tlsOptions := ds.TLSOptions()
+func (ds *DataSource) HTTPClientOptions(encryptionService encryption.Service) (*sdkhttpclient.Options, error) {
+	decryptedValues := ds.DecryptedValues(encryptionService)
+	tlsOptions := ds.TLSOptions(encryptionService)
 	timeouts := &sdkhttpclient.TimeoutOptions{
 		Timeout:               ds.getTimeout(),
 		DialTimeout:           sdkhttpclient.DefaultTimeoutOptions.DialTimeout,
@@ -103,7 +106,7 @@ func (ds *DataSource) HTTPClientOptions() (*sdkhttpclient.Options, error) {
 	}
 	opts := &sdkhttpclient.Options{
 		Timeouts: timeouts,
-		Headers:  getCustomHeaders(ds.JsonData, ds.DecryptedValues()),
+		Headers:  getCustomHeaders(ds.JsonData, decryptedValues),
 		Labels: map[string]string{
 			"datasource_name": ds.Name,
 			"datasource_uid":  ds.Uid,
@@ -118,17 +121,17 @@ func (ds *DataSource) HTTPClientOptions() (*sdkhttpclient.Options, error) {
 	if ds.BasicAuth {
 		opts.BasicAuth = &sdkhttpclient.BasicAuthOptions{
 			User:     ds.BasicAuthUser,
-			Password: ds.DecryptedBasicAuthPassword(),
+			Password: ds.DecryptedBasicAuthPassword(encryptionService),
 		}
 	} else if ds.User != "" {
 		opts.BasicAuth = &sdkhttpclient.BasicAuthOptions{
 			User:     ds.User,
-			Password: ds.DecryptedPassword(),
+			Password: ds.DecryptedPassword(encryptionService),
 		}
 	}
 
 	if ds.JsonData != nil && ds.JsonData.Get("azureAuth").MustBool() {
-		credentials, err := azcredentials.FromDatasourceData(ds.JsonData.MustMap(), ds.DecryptedValues())
+		credentials, err := azcredentials.FromDatasourceData(ds.JsonData.MustMap(), decryptedValues)
 		if err != nil {
 			err = fmt.Errorf("invalid Azure credentials: %s", err)
 			return nil, err
@@ -150,11 +153,11 @@ func (ds *DataSource) HTTPClientOptions() (*sdkhttpclient.Options, error) {
 			Profile:       ds.JsonData.Get("sigV4Profile").MustString(),
 		}
 
-		if val, exists := ds.DecryptedValue("sigV4AccessKey"); exists {
+		if val, exists := decryptedValues["sigV4AccessKey"]; exists {
 			opts.SigV4.AccessKey = val
 		}
 
-		if val, exists := ds.DecryptedValue("sigV4SecretKey"); exists {
+		if val, exists := decryptedValues["sigV4SecretKey"]; exists {
 			opts.SigV4.SecretKey = val
 		}
 	}
@@ -162,7 +165,7 @@ func (ds *DataSource) HTTPClientOptions() (*sdkhttpclient.Options, error) {
 	return opts, nil
 }
 
-func (ds *DataSource) TLSOptions() sdkhttpclient.TLSOptions {
+func (ds *DataSource) TLSOptions(encryptionService encryption.Service) sdkhttpclient.TLSOptions {
 	var tlsSkipVerify, tlsClientAuth, tlsAuthWithCACert bool
 	var serverName string
 
@@ -179,17 +182,18 @@ func (ds *DataSource) TLSOptions() sdkhttpclient.TLSOptions {
 	}
 
 	if tlsClientAuth || tlsAuthWithCACert {
+		decryptedValues := ds.DecryptedValues(encryptionService)
 		if tlsAuthWithCACert {
-			if val, exists := ds.DecryptedValue("tlsCACert"); exists && len(val) > 0 {
+			if val, exists := decryptedValues["tlsCACert"]; exists && len(val) > 0 {
 				opts.CACertificate = val
 			}
 		}
 
 		if tlsClientAuth {
-			if val, exists := ds.DecryptedValue("tlsClientCert"); exists && len(val) > 0 {
+			if val, exists := decryptedValues["tlsClientCert"]; exists && len(val) > 0 {
 				opts.ClientCertificate = val
 			}
-			if val, exists := ds.DecryptedValue("tlsClientKey"); exists && len(val) > 0 {
+			if val, exists := decryptedValues["tlsClientKey"]; exists && len(val) > 0 {
 				opts.ClientKey = val
 			}
 		}
@@ -198,8 +202,8 @@ func (ds *DataSource) TLSOptions() sdkhttpclient.TLSOptions {
 	return opts
 }
 
-func (ds *DataSource) GetTLSConfig(httpClientProvider httpclient.Provider) (*tls.Config, error) {
-	opts, err := ds.HTTPClientOptions()
+func (ds *DataSource) GetTLSConfig(httpClientProvider httpclient.Provider, encryptionService encryption.Service) (*tls.Config, error) {
+	opts, err := ds.HTTPClientOptions(encryptionService)
 	if err != nil {
 		return nil, err
 	}
@@ -234,6 +238,8 @@ func getCustomHeaders(jsonData *simplejson.Json, decryptedValues map[string]stri
 	return headers
 }
 
+var decryptionLogger = log.New("securejsondata")
+
 type cachedDecryptedJSON struct {
 	updated time.Time
 	json    map[string]string
@@ -249,7 +255,7 @@ var dsDecryptionCache = secureJSONDecryptionCache{
 }
 
 // DecryptedValues returns cached decrypted values from secureJsonData.
-func (ds *DataSource) DecryptedValues() map[string]string {
+func (ds *DataSource) DecryptedValues(encryptionService encryption.Service) map[string]string {
 	dsDecryptionCache.Lock()
 	defer dsDecryptionCache.Unlock()
 
@@ -257,7 +263,11 @@ func (ds *DataSource) DecryptedValues() map[string]string {
 		return item.json
 	}
 
-	json := ds.SecureJsonData.Decrypt()
+	json, err := encryptionService.DecryptJsonData(ds.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		decryptionLogger.Error("Failed to decrypt secure json data", "datasource", ds.Name, "error", err)
+		return map[string]string{}
+	}
 	dsDecryptionCache.cache[ds.Id] = cachedDecryptedJSON{
 		updated: ds.Updated,
 		json:    json,
@@ -267,8 +277,8 @@ func (ds *DataSource) DecryptedValues() map[string]string {
 }
 
 // DecryptedValue returns cached decrypted value from cached secureJsonData.
-func (ds *DataSource) DecryptedValue(key string) (string, bool) {
-	value, exists := ds.DecryptedValues()[key]
+func (ds *DataSource) DecryptedValue(encryptionService encryption.Service, key string) (string, bool) {
+	value, exists := ds.DecryptedValues(encryptionService)[key]
 	return value, exists
 }
 
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/setting"
@@ -36,12 +35,12 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			Type: "Kubernetes",
 		}
 
-		rt1, err := ds.GetHTTPTransport(provider)
+		rt1, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt1)
 		tr1 := configuredTransport
 
-		rt2, err := ds.GetHTTPTransport(provider)
+		rt2, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt2)
 		tr2 := configuredTransport
@@ -76,7 +75,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			Updated:        time.Now().Add(-2 * time.Minute),
 		}
 
-		rt1, err := ds.GetHTTPTransport(provider)
+		rt1, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NotNil(t, rt1)
 		require.NoError(t, err)
 
@@ -90,7 +89,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 		ds.SecureJsonData = map[string][]byte{}
 		ds.Updated = time.Now()
 
-		rt2, err := ds.GetHTTPTransport(provider)
+		rt2, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt2)
 		tr2 := configuredTransport
@@ -128,7 +127,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			},
 		}
 
-		rt, err := ds.GetHTTPTransport(provider)
+		rt, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt)
 		tr := configuredTransport
@@ -165,7 +164,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			},
 		}
 
-		rt, err := ds.GetHTTPTransport(provider)
+		rt, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt)
 		tr := configuredTransport
@@ -194,12 +193,12 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			JsonData: json,
 		}
 
-		rt1, err := ds.GetHTTPTransport(provider)
+		rt1, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt1)
 		tr1 := configuredTransport
 
-		rt2, err := ds.GetHTTPTransport(provider)
+		rt2, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt2)
 		tr2 := configuredTransport
@@ -249,7 +248,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 
 		// 2. Get HTTP transport from datasource which uses the test server as backend
 		ds.Url = backend.URL
-		rt, err := ds.GetHTTPTransport(provider)
+		rt, err := ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, rt)
 
@@ -281,7 +280,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			JsonData: json,
 		}
 
-		client, err := ds.GetHTTPClient(provider)
+		client, err := ds.GetHTTPClient(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, client)
 		require.Equal(t, 19*time.Second, client.Timeout)
@@ -310,7 +309,7 @@ func TestDataSource_GetHttpTransport(t *testing.T) {
 			JsonData: json,
 		}
 
-		_, err = ds.GetHTTPTransport(provider)
+		_, err = ds.GetHTTPTransport(provider, encryptionService)
 		require.NoError(t, err)
 		require.NotNil(t, configuredOpts)
 		require.NotNil(t, configuredOpts.SigV4)
@@ -353,21 +352,21 @@ func TestDataSource_DecryptedValue(t *testing.T) {
 			Type:     DS_INFLUXDB_08,
 			JsonData: simplejson.New(),
 			User:     "user",
-			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			SecureJsonData: encryptJsonData(t, map[string]string{
 				"password": "password",
 			}),
 		}
 
 		// Populate cache
-		password, ok := ds.DecryptedValue("password")
+		password, ok := ds.DecryptedValue(encryptionService, "password")
 		require.True(t, ok)
 		require.Equal(t, "password", password)
 
-		ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+		ds.SecureJsonData = encryptJsonData(t, map[string]string{
 			"password": "",
 		})
 
-		password, ok = ds.DecryptedValue("password")
+		password, ok = ds.DecryptedValue(encryptionService, "password")
 		require.True(t, ok)
 		require.Equal(t, "password", password)
 	})
@@ -380,22 +379,22 @@ func TestDataSource_DecryptedValue(t *testing.T) {
 			Type:     DS_INFLUXDB_08,
 			JsonData: simplejson.New(),
 			User:     "user",
-			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			SecureJsonData: encryptJsonData(t, map[string]string{
 				"password": "password",
 			}),
 		}
 
 		// Populate cache
-		password, ok := ds.DecryptedValue("password")
+		password, ok := ds.DecryptedValue(encryptionService, "password")
 		require.True(t, ok)
 		require.Equal(t, "password", password)
 
-		ds.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+		ds.SecureJsonData = encryptJsonData(t, map[string]string{
 			"password": "",
 		})
 		ds.Updated = time.Now()
 
-		password, ok = ds.DecryptedValue("password")
+		password, ok = ds.DecryptedValue(encryptionService, "password")
 		require.True(t, ok)
 		require.Empty(t, password)
 	})
@@ -415,7 +414,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 		t.Run("should be disabled if not enabled in JsonData", func(t *testing.T) {
 			t.Cleanup(func() { ds.JsonData = emptyJsonData; ds.SecureJsonData = emptySecureJsonData })
 
-			opts, err := ds.HTTPClientOptions()
+			opts, err := ds.HTTPClientOptions(encryptionService)
 			require.NoError(t, err)
 
 			assert.NotEqual(t, true, opts.CustomOptions["_azureAuth"])
@@ -429,7 +428,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 				"azureAuth": true,
 			})
 
-			opts, err := ds.HTTPClientOptions()
+			opts, err := ds.HTTPClientOptions(encryptionService)
 			require.NoError(t, err)
 
 			assert.Equal(t, true, opts.CustomOptions["_azureAuth"])
@@ -446,7 +445,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 				},
 			})
 
-			opts, err := ds.HTTPClientOptions()
+			opts, err := ds.HTTPClientOptions(encryptionService)
 			require.NoError(t, err)
 
 			assert.Equal(t, true, opts.CustomOptions["_azureAuth"])
@@ -467,7 +466,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 				},
 			})
 
-			opts, err := ds.HTTPClientOptions()
+			opts, err := ds.HTTPClientOptions(encryptionService)
 			require.NoError(t, err)
 
 			assert.NotEqual(t, true, opts.CustomOptions["_azureAuth"])
@@ -482,7 +481,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 				"azureCredentials": "invalid",
 			})
 
-			_, err := ds.HTTPClientOptions()
+			_, err := ds.HTTPClientOptions(encryptionService)
 			assert.Error(t, err)
 		})
 
@@ -493,7 +492,7 @@ func TestDataSource_HTTPClientOptions(t *testing.T) {
 				"azureEndpointResourceId": "https://api.example.com/abd5c4ce-ca73-41e9-9cb2-bed39aa2adb5",
 			})
 
-			opts, err := ds.HTTPClientOptions()
+			opts, err := ds.HTTPClientOptions(encryptionService)
 			require.NoError(t, err)
 
 			require.Contains(t, opts.CustomOptions, "azureEndpointResourceId")
@@ -579,3 +578,60 @@ Wtnpl+TdAoGAGqKqo2KU3JoY3IuTDUk1dsNAm8jd9EWDh+s1x4aG4N79mwcss5GD
 FF8MbFPneK7xQd8L6HisKUDAUi2NOyynM81LAftPkvN6ZuUVeFDfCL4vCA0HUXLD
 +VrOhtUZkNNJlLMiVRJuQKUOGlg8PpObqYbstQAf/0/yFJMRHG82Tcg=
 -----END RSA PRIVATE KEY-----`
+
+// fakeEncryptionService encrypts with the secret only. The encryption
+// service implementation can't be used here since it depends on models.
+type fakeEncryptionService struct{}
+
+var encryptionService = fakeEncryptionService{}
+
+func (fakeEncryptionService) Encrypt(payload []byte, secret string) ([]byte, error) {
+	return util.Encrypt(payload, secret)
+}
+
+func (fakeEncryptionService) Decrypt(payload []byte, secret string) ([]byte, error) {
+	return util.Decrypt(payload, secret)
+}
+
+func (s fakeEncryptionService) EncryptJsonData(kv map[string]string, secret string) (map[string][]byte, error) {
+	encrypted := make(map[string][]byte)
+	for key, value := range kv {
+		encryptedData, err := s.Encrypt([]byte(value), secret)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[key] = encryptedData
+	}
+	return encrypted, nil
+}
+
+func (s fakeEncryptionService) DecryptJsonData(sjd map[string][]byte, secret string) (map[string]string, error) {
+	decrypted := make(map[string]string)
+	for key, data := range sjd {
+		decryptedData, err := s.Decrypt(data, secret)
+		if err != nil {
+			return nil, err
+		}
+		decrypted[key] = string(decryptedData)
+	}
+	return decrypted, nil
+}
+
+func (s fakeEncryptionService) GetDecryptedValue(sjd map[string][]byte, key, fallback, secret string) string {
+	if value, ok := sjd[key]; ok {
+		decryptedData, err := s.Decrypt(value, secret)
+		if err != nil {
+			return fallback
+		}
+		return string(decryptedData)
+	}
+	return fallback
+}
+
+func encryptJsonData(t *testing.T, kv map[string]string) map[string][]byte {
+	t.Helper()
+
+	encrypted, err := encryptionService.EncryptJsonData(kv, setting.SecretKey)
+	require.NoError(t, err)
+	return encrypted
+}
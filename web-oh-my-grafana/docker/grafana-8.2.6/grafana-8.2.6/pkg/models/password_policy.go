@@ -0,0 +1,24 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPasswordTooShort          = errors.New("password is too short")
+	ErrPasswordMissingCharacters = errors.New("password is missing required characters")
+	ErrPasswordBreached          = errors.New("password is too common or has appeared in a data breach")
+	ErrPasswordReused            = errors.New("password has been used before")
+	ErrPasswordChangeNotFound    = errors.New("password change login not found or expired")
+)
+
+// UserPasswordHistory is a previous password of a user, stored hashed like
+// the current one.
+type UserPasswordHistory struct {
+	Id       int64
+	UserId   int64
+	Password string
+	Salt     string
+	Created  time.Time
+}
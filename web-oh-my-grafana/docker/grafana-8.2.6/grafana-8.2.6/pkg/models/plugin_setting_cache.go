@@ -1,11 +1,16 @@
 package models
 
+import (
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
 var pluginSettingDecryptionCache = secureJSONDecryptionCache{
 	cache: make(map[int64]cachedDecryptedJSON),
 }
 
 // DecryptedValues returns cached decrypted values from secureJsonData.
-func (ps *PluginSetting) DecryptedValues() map[string]string {
+func (ps *PluginSetting) DecryptedValues(encryptionService encryption.Service) map[string]string {
 	pluginSettingDecryptionCache.Lock()
 	defer pluginSettingDecryptionCache.Unlock()
 
@@ -13,7 +18,11 @@ func (ps *PluginSetting) DecryptedValues() map[string]string {
 		return item.json
 	}
 
-	json := ps.SecureJsonData.Decrypt()
+	json, err := encryptionService.DecryptJsonData(ps.SecureJsonData, setting.SecretKey)
+	if err != nil {
+		decryptionLogger.Error("Failed to decrypt secure json data", "plugin", ps.PluginId, "error", err)
+		return map[string]string{}
+	}
 	pluginSettingDecryptionCache.cache[ps.Id] = cachedDecryptedJSON{
 		updated: ps.Updated,
 		json:    json,
@@ -5,8 +5,6 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
-
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 )
 
 // clearPluginSettingDecryptionCache clears the datasource decryption cache.
@@ -24,17 +22,17 @@ func TestPluginSettingDecryptionCache(t *testing.T) {
 		ps := PluginSetting{
 			Id:       1,
 			JsonData: map[string]interface{}{},
-			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			SecureJsonData: encryptJsonData(t, map[string]string{
 				"password": "password",
 			}),
 		}
 
 		// Populate cache
-		password, ok := ps.DecryptedValues()["password"]
+		password, ok := ps.DecryptedValues(encryptionService)["password"]
 		require.Equal(t, "password", password)
 		require.True(t, ok)
 
-		ps.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+		ps.SecureJsonData = encryptJsonData(t, map[string]string{
 			"password": "",
 		})
 
@@ -48,22 +46,22 @@ func TestPluginSettingDecryptionCache(t *testing.T) {
 		ps := PluginSetting{
 			Id:       1,
 			JsonData: map[string]interface{}{},
-			SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{
+			SecureJsonData: encryptJsonData(t, map[string]string{
 				"password": "password",
 			}),
 		}
 
 		// Populate cache
-		password, ok := ps.DecryptedValues()["password"]
+		password, ok := ps.DecryptedValues(encryptionService)["password"]
 		require.Equal(t, "password", password)
 		require.True(t, ok)
 
-		ps.SecureJsonData = securejsondata.GetEncryptedJsonData(map[string]string{
+		ps.SecureJsonData = encryptJsonData(t, map[string]string{
 			"password": "",
 		})
 		ps.Updated = time.Now()
 
-		password, ok = ps.DecryptedValues()["password"]
+		password, ok = ps.DecryptedValues(encryptionService)["password"]
 		require.Empty(t, password)
 		require.True(t, ok)
 	})
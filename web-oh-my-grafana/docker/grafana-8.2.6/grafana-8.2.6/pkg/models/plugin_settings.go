@@ -36,8 +36,9 @@ type UpdatePluginSettingCmd struct {
 	SecureJsonData map[string]string      `json:"secureJsonData"`
 	PluginVersion  string                 `json:"version"`
 
-	PluginId string `json:"-"`
-	OrgId    int64  `json:"-"`
+	PluginId                string            `json:"-"`
+	OrgId                   int64             `json:"-"`
+	EncryptedSecureJsonData map[string][]byte `json:"-"`
 }
 
 // specific command, will only update version
@@ -47,10 +48,6 @@ type UpdatePluginSettingVersionCmd struct {
 	OrgId         int64  `json:"-"`
 }
 
-func (cmd *UpdatePluginSettingCmd) GetEncryptedJsonData() securejsondata.SecureJsonData {
-	return securejsondata.GetEncryptedJsonData(cmd.SecureJsonData)
-}
-
 // ---------------------
 // QUERIES
 
@@ -8,13 +8,14 @@ import (
 var ErrInvalidQuotaTarget = errors.New("invalid quota target")
 
 type Quota struct {
-	Id      int64
-	OrgId   int64
-	UserId  int64
-	Target  string
-	Limit   int64
-	Created time.Time
-	Updated time.Time
+	Id       int64
+	OrgId    int64
+	UserId   int64
+	FolderId int64
+	Target   string
+	Limit    int64
+	Created  time.Time
+	Updated  time.Time
 }
 
 type QuotaScope struct {
@@ -37,6 +38,28 @@ type UserQuotaDTO struct {
 	Used   int64  `json:"used"`
 }
 
+type FolderQuotaDTO struct {
+	OrgId     int64  `json:"org_id"`
+	FolderId  int64  `json:"folder_id"`
+	FolderUid string `json:"folder_uid"`
+	Target    string `json:"target"`
+	Limit     int64  `json:"limit"`
+	Used      int64  `json:"used"`
+}
+
+// QuotaStatusDTO reports the usage of a quota for an organization. Window
+// and ResetsAt are only set for quotas counted within a time window.
+type QuotaStatusDTO struct {
+	Scope     string     `json:"scope"`
+	Target    string     `json:"target"`
+	FolderId  int64      `json:"folderId,omitempty"`
+	FolderUid string     `json:"folderUid,omitempty"`
+	Limit     int64      `json:"limit"`
+	Used      int64      `json:"used"`
+	Window    string     `json:"window,omitempty"`
+	ResetsAt  *time.Time `json:"resetsAt,omitempty"`
+}
+
 type GlobalQuotaDTO struct {
 	Target string `json:"target"`
 	Limit  int64  `json:"limit"`
@@ -57,6 +80,30 @@ type GetOrgQuotasQuery struct {
 	Result                 []*OrgQuotaDTO
 }
 
+// GetOrgQuotaLimitQuery returns the configured limit of an org quota
+// without counting its usage.
+type GetOrgQuotaLimitQuery struct {
+	Target  string
+	OrgId   int64
+	Default int64
+	Result  int64
+}
+
+type GetFolderQuotaByTargetQuery struct {
+	Target   string
+	OrgId    int64
+	FolderId int64
+	Default  int64
+	Result   *FolderQuotaDTO
+}
+
+type GetFolderQuotasQuery struct {
+	OrgId   int64
+	Target  string
+	Default int64
+	Result  []*FolderQuotaDTO
+}
+
 type GetUserQuotaByTargetQuery struct {
 	Target                 string
 	UserId                 int64
@@ -84,6 +131,13 @@ type UpdateOrgQuotaCmd struct {
 	OrgId  int64  `json:"-"`
 }
 
+type UpdateFolderQuotaCmd struct {
+	Target   string `json:"target"`
+	Limit    int64  `json:"limit"`
+	OrgId    int64  `json:"-"`
+	FolderId int64  `json:"-"`
+}
+
 type UpdateUserQuotaCmd struct {
 	Target string `json:"target"`
 	Limit  int64  `json:"limit"`
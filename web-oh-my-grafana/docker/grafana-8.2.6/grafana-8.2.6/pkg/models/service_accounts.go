@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrServiceAccountNotFound               = errors.New("service account not found")
+	ErrServiceAccountAlreadyExists          = errors.New("service account with that name already exists")
+	ErrServiceAccountTokenNotFound          = errors.New("service account token not found")
+	ErrServiceAccountPermissionNotOrgScoped = errors.New("service account permissions must be scoped to the organization")
+)
+
+// ServiceAccountPermission is an access control permission granted to a
+// service account. Service accounts with permissions are restricted to
+// them instead of the permissions of their organization role.
+type ServiceAccountPermission struct {
+	Id               int64
+	OrgId            int64
+	ServiceAccountId int64
+	Action           string
+	Scope            string
+	Created          time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateServiceAccountCommand struct {
+	Name  string   `json:"name" binding:"Required"`
+	Role  RoleType `json:"role" binding:"Required"`
+	OrgId int64    `json:"-"`
+
+	Result *ServiceAccountDTO `json:"-"`
+}
+
+type UpdateServiceAccountCommand struct {
+	Name       string   `json:"name"`
+	Role       RoleType `json:"role"`
+	IsDisabled *bool    `json:"isDisabled"`
+	Id         int64    `json:"-"`
+	OrgId      int64    `json:"-"`
+}
+
+// ----------------------
+// QUERIES
+
+type GetServiceAccountPermissionsQuery struct {
+	OrgId            int64
+	ServiceAccountId int64
+	Result           []*ServiceAccountPermission
+}
+
+// ------------------------
+// DTO & Projections
+
+type ServiceAccountDTO struct {
+	Id         int64    `json:"id"`
+	OrgId      int64    `json:"orgId"`
+	Name       string   `json:"name"`
+	Login      string   `json:"login"`
+	Role       RoleType `json:"role"`
+	IsDisabled bool     `json:"isDisabled"`
+	Tokens     int64    `json:"tokens"`
+}
+
+type ServiceAccountTokenDTO struct {
+	Id         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Created    time.Time  `json:"created"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrTeamGroupAlreadyAdded = errors.New("group is already added to this team")
+	ErrTeamGroupNotFound     = errors.New("team group not found")
+)
+
+// TeamGroup maps a group of an external auth provider, such as an LDAP group
+// DN or an OAuth group, to a team. Team sync keeps the members of the group
+// in the team.
+type TeamGroup struct {
+	Id      int64
+	OrgId   int64
+	TeamId  int64
+	GroupId string
+
+	Created time.Time
+	Updated time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type AddTeamGroupCommand struct {
+	GroupId string `json:"groupId" binding:"Required"`
+	OrgId   int64  `json:"-"`
+	TeamId  int64  `json:"-"`
+}
+
+type RemoveTeamGroupCommand struct {
+	OrgId   int64
+	TeamId  int64
+	GroupId string
+}
+
+// ----------------------
+// QUERIES
+
+type GetTeamGroupsQuery struct {
+	OrgId  int64
+	TeamId int64
+	Result []*TeamGroupDTO
+}
+
+// GetUserTeamGroupsQuery returns the groups of the teams of the organizations
+// the user is a member of.
+type GetUserTeamGroupsQuery struct {
+	UserId int64
+	Result []*TeamGroupDTO
+}
+
+// ----------------------
+// Projections and DTOs
+
+type TeamGroupDTO struct {
+	OrgId   int64  `json:"orgId"`
+	TeamId  int64  `json:"teamId"`
+	GroupId string `json:"groupId"`
+}
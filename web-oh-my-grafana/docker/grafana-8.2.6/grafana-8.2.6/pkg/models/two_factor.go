@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTwoFactorNotEnrolled       = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorInvalidCode       = errors.New("invalid two-factor authentication code")
+	ErrTwoFactorRequiredByPolicy  = errors.New("two-factor authentication is required by the organization")
+	ErrTwoFactorTooManyAttempts   = errors.New("too many consecutive incorrect two-factor authentication codes")
+	ErrTwoFactorLoginNotFound     = errors.New("two-factor login not found or expired")
+	ErrTwoFactorEnrollmentMissing = errors.New("two-factor authentication has to be enrolled before it can be enabled")
+)
+
+// UserTwoFactor holds the TOTP secret and the recovery codes of a user.
+// The secret is encrypted with the encryption service and the recovery
+// codes are stored hashed. Enabled is false until the enrollment has been
+// confirmed with a valid code.
+type UserTwoFactor struct {
+	Id     int64
+	UserId int64
+	Secret string
+	// RecoveryCodes is a JSON array of the hashes of the unused recovery codes.
+	RecoveryCodes string
+	Enabled       bool
+	// LastUsedStep is the time step of the last accepted code, codes can't
+	// be used twice.
+	LastUsedStep int64
+	Created      time.Time
+	Updated      time.Time
+}
+
+// OrgAuthPolicy holds the authentication requirements of an organization.
+type OrgAuthPolicy struct {
+	Id                        int64     `json:"-"`
+	OrgId                     int64     `json:"orgId"`
+	RequireTwoFactorForAdmins bool      `json:"requireTwoFactorForAdmins"`
+	Created                   time.Time `json:"-"`
+	Updated                   time.Time `json:"-"`
+}
+
+// TwoFactorEnrollment is returned once when a user enrolls in two-factor
+// authentication, neither the secret nor the recovery codes can be read
+// afterwards.
+type TwoFactorEnrollment struct {
+	Secret          string   `json:"secret"`
+	ProvisioningURI string   `json:"provisioningUri"`
+	RecoveryCodes   []string `json:"recoveryCodes"`
+}
+
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required is true if an organization policy requires the user to use
+	// two-factor authentication.
+	Required          bool `json:"required"`
+	RecoveryCodesLeft int  `json:"recoveryCodesLeft"`
+}
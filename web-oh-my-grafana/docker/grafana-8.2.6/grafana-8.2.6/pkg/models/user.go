@@ -33,9 +33,13 @@ type User struct {
 	Theme         string
 	HelpFlags1    HelpFlags1
 	IsDisabled    bool
+	// PasswordChangeRequired forces the user to change their password the
+	// next time they log in with it.
+	PasswordChangeRequired bool
 
-	IsAdmin bool
-	OrgId   int64
+	IsAdmin          bool
+	IsServiceAccount bool
+	OrgId            int64
 
 	Created    time.Time
 	Updated    time.Time
@@ -69,6 +73,8 @@ type CreateUserCommand struct {
 	SkipOrgSetup   bool
 	DefaultOrgRole string
 
+	PasswordChangeRequired bool
+
 	Result User
 }
 
@@ -86,6 +92,9 @@ type ChangeUserPasswordCommand struct {
 	NewPassword string `json:"newPassword"`
 
 	UserId int64 `json:"-"`
+	// PasswordChangeRequired forces the user to change the new password the
+	// next time they log in, when it has been set by an administrator.
+	PasswordChangeRequired bool `json:"-"`
 }
 
 type DisableUserCommand struct {
@@ -182,9 +191,12 @@ type SignedInUser struct {
 	OrgCount       int
 	IsGrafanaAdmin bool
 	IsAnonymous    bool
-	HelpFlags1     HelpFlags1
-	LastSeenAt     time.Time
-	Teams          []int64
+	// IsServiceAccount is true when the request is authenticated with a
+	// token of a service account.
+	IsServiceAccount bool
+	HelpFlags1       HelpFlags1
+	LastSeenAt       time.Time
+	Teams            []int64
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
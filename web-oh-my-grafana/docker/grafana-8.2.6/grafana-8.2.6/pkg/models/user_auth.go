@@ -9,6 +9,8 @@ import (
 
 const (
 	AuthModuleLDAP = "ldap"
+	AuthModuleSAML = "auth.saml"
+	AuthModuleJWT  = "auth.jwt"
 )
 
 type UserAuth struct {
@@ -63,6 +63,56 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+// UserTokenFilter selects the active tokens of all users. Empty fields match
+// any token.
+type UserTokenFilter struct {
+	UserId int64 `json:"userId"`
+	// ClientIp matches the IP of the last request exactly.
+	ClientIp string `json:"ip"`
+	// UserAgent matches a part of the user agent.
+	UserAgent string `json:"userAgent"`
+	// SeenFrom and SeenTo bound the last time the token was used, as Unix
+	// epochs in milliseconds.
+	SeenFrom int64 `json:"seenFrom"`
+	SeenTo   int64 `json:"seenTo"`
+	// ExcludeTokenId is not matched, to keep the session of the admin.
+	ExcludeTokenId int64 `json:"-"`
+}
+
+// IsEmpty returns whether the filter matches all the tokens.
+func (f *UserTokenFilter) IsEmpty() bool {
+	return f.UserId == 0 && f.ClientIp == "" && f.UserAgent == "" && f.SeenFrom == 0 && f.SeenTo == 0
+}
+
+type SearchUserTokensQuery struct {
+	Filter  UserTokenFilter
+	Page    int
+	PerPage int
+}
+
+type SearchUserTokensResult struct {
+	TotalCount int64
+	Tokens     []*UserTokenSearchHit
+}
+
+// UserTokenSearchHit is a token with the user it belongs to.
+type UserTokenSearchHit struct {
+	UserToken
+	Login string
+	Email string
+}
+
+// SessionPolicy limits the sessions of the members of an org. Zero values
+// don't limit them. Users in several orgs get the strictest limits.
+type SessionPolicy struct {
+	OrgId int64 `json:"orgId"`
+	// IdleTimeoutSeconds expires the sessions not used for longer.
+	IdleTimeoutSeconds int64 `json:"idleTimeoutSeconds"`
+	// MaxConcurrentSessions revokes the oldest sessions of the users having
+	// more.
+	MaxConcurrentSessions int64 `json:"maxConcurrentSessions"`
+}
+
 // UserTokenService are used for generating and validating user tokens
 type UserTokenService interface {
 	CreateToken(ctx context.Context, user *User, clientIP net.IP, userAgent string) (*UserToken, error)
@@ -74,6 +124,10 @@ type UserTokenService interface {
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
 	GetUserRevokedTokens(ctx context.Context, userId int64) ([]*UserToken, error)
+	SearchTokens(ctx context.Context, query *SearchUserTokensQuery) (*SearchUserTokensResult, error)
+	RevokeTokens(ctx context.Context, filter *UserTokenFilter) (int64, error)
+	GetSessionPolicy(ctx context.Context, orgId int64) (*SessionPolicy, error)
+	SetSessionPolicy(ctx context.Context, policy *SessionPolicy) error
 }
 
 type UserTokenBackgroundService interface {
@@ -37,7 +37,7 @@ func (p *DataSourcePlugin) Load(decoder *json.Decoder, base *PluginBase, backend
 		return nil, errutil.Wrapf(err, "Failed to decode datasource plugin")
 	}
 
-	if p.Backend {
+	if p.Backend && !base.IsCorePlugin {
 		cmd := ComposePluginStartCommand(p.Executable)
 		fullpath := filepath.Join(base.PluginDir, cmd)
 		factory := grpcplugin.NewBackendPlugin(p.Id, fullpath)
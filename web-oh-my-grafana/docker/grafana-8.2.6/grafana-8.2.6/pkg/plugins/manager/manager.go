@@ -463,6 +463,9 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 
 func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugins.PluginBase,
 	scanner *PluginScanner, loader plugins.PluginLoader) error {
+	// Core plugins with a backend are served by Grafana itself.
+	pluginBase.IsCorePlugin = strings.HasPrefix(pluginBase.PluginDir, pm.Cfg.StaticRootPath)
+
 	plug, err := loader.Load(jsonParser, pluginBase, scanner.backendPluginManager)
 	if err != nil {
 		return err
@@ -489,7 +492,7 @@ func (pm *PluginManager) loadPlugin(jsonParser *json.Decoder, pluginBase *plugin
 		panic(fmt.Sprintf("Unrecognized plugin type %T", plug))
 	}
 
-	if !strings.HasPrefix(pluginBase.PluginDir, pm.Cfg.StaticRootPath) {
+	if !pluginBase.IsCorePlugin {
 		pm.log.Info("Registering plugin", "id", pb.Id)
 	}
 
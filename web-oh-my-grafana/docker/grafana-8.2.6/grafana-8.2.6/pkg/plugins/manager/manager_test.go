@@ -42,6 +42,7 @@ func TestPluginManager_Init(t *testing.T) {
 		assert.Empty(t, pm.scanningErrors)
 		verifyCorePluginCatalogue(t, pm)
 		verifyBundledPlugins(t, pm)
+		assert.Empty(t, pm.BackendPluginManager.(*fakeBackendPluginManager).registeredPlugins)
 	})
 
 	t.Run("Base case with single external plugin", func(t *testing.T) {
@@ -579,6 +580,7 @@ func verifyCorePluginCatalogue(t *testing.T, pm *PluginManager) {
 	datasources := []string{
 		"alertmanager",
 		"stackdriver",
+		"clickhouse",
 		"cloudwatch",
 		"dashboard",
 		"elasticsearch",
@@ -594,6 +596,7 @@ func verifyCorePluginCatalogue(t *testing.T, pm *PluginManager) {
 		"opentsdb",
 		"postgres",
 		"prometheus",
+		"sqlite",
 		"tempo",
 		"testdata",
 		"zipkin",
@@ -13,24 +13,27 @@ import (
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 func ProvideService(bus bus.Bus, cacheService *localcache.CacheService, pluginManager plugins.Manager,
-	dataSourceCache datasources.CacheService) *Provider {
+	dataSourceCache datasources.CacheService, encryptionService encryption.Service) *Provider {
 	return &Provider{
-		Bus:             bus,
-		CacheService:    cacheService,
-		PluginManager:   pluginManager,
-		DataSourceCache: dataSourceCache,
+		Bus:               bus,
+		CacheService:      cacheService,
+		PluginManager:     pluginManager,
+		DataSourceCache:   dataSourceCache,
+		EncryptionService: encryptionService,
 	}
 }
 
 type Provider struct {
-	Bus             bus.Bus
-	CacheService    *localcache.CacheService
-	PluginManager   plugins.Manager
-	DataSourceCache datasources.CacheService
+	Bus               bus.Bus
+	CacheService      *localcache.CacheService
+	PluginManager     plugins.Manager
+	DataSourceCache   datasources.CacheService
+	EncryptionService encryption.Service
 }
 
 // Get allows getting plugin context by its ID. If datasourceUID is not empty string
@@ -59,7 +62,7 @@ func (p *Provider) Get(pluginID string, datasourceUID string, user *models.Signe
 		if err != nil {
 			return pc, false, errutil.Wrap("Failed to unmarshal plugin json data", err)
 		}
-		decryptedSecureJSONData = ps.DecryptedValues()
+		decryptedSecureJSONData = ps.DecryptedValues(p.EncryptionService)
 		updated = ps.Updated
 	}
 
@@ -79,7 +82,7 @@ func (p *Provider) Get(pluginID string, datasourceUID string, user *models.Signe
 		if err != nil {
 			return pc, false, errutil.Wrap("Failed to get datasource", err)
 		}
-		datasourceSettings, err := adapters.ModelToInstanceSettings(ds)
+		datasourceSettings, err := adapters.ModelToInstanceSettings(ds, p.EncryptionService)
 		if err != nil {
 			return pc, false, errutil.Wrap("Failed to convert datasource", err)
 		}
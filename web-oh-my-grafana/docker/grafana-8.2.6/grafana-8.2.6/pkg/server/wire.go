@@ -27,10 +27,12 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/plugincontext"
 	"github.com/grafana/grafana/pkg/plugins/plugindashboards"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/auth/jwt"
 	"github.com/grafana/grafana/pkg/services/cleanup"
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/dashboardusage"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/libraryelements"
@@ -44,15 +46,23 @@ import (
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
+	"github.com/grafana/grafana/pkg/services/querycache"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/ratelimit"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/saml"
 	"github.com/grafana/grafana/pkg/services/schemaloader"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/teamsync"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/tsdb/azuremonitor"
+	"github.com/grafana/grafana/pkg/tsdb/clickhouse"
 	"github.com/grafana/grafana/pkg/tsdb/cloudmonitoring"
 	"github.com/grafana/grafana/pkg/tsdb/cloudwatch"
 	"github.com/grafana/grafana/pkg/tsdb/elasticsearch"
@@ -65,6 +75,7 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/opentsdb"
 	"github.com/grafana/grafana/pkg/tsdb/postgres"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus"
+	"github.com/grafana/grafana/pkg/tsdb/sqlite"
 	"github.com/grafana/grafana/pkg/tsdb/tempo"
 	"github.com/grafana/grafana/pkg/tsdb/testdatasource"
 )
@@ -99,6 +110,8 @@ var wireBasicSet = wire.NewSet(
 	postgres.ProvideService,
 	mysql.ProvideService,
 	mssql.ProvideService,
+	sqlite.ProvideService,
+	clickhouse.ProvideService,
 	httpclientprovider.New,
 	wire.Bind(new(httpclient.Provider), new(*sdkhttpclient.Provider)),
 	serverlock.ProvideService,
@@ -143,6 +156,24 @@ var wireBasicSet = wire.NewSet(
 	elasticsearch.ProvideService,
 	grafanads.ProvideService,
 	dashboardsnapshots.ProvideService,
+	dashboardusage.ProvideService,
+	wire.Bind(new(dashboardusage.Service), new(*dashboardusage.DashboardUsageService)),
+	twofactor.ProvideService,
+	wire.Bind(new(twofactor.Service), new(*twofactor.TwoFactorService)),
+	audit.ProvideService,
+	wire.Bind(new(audit.Service), new(*audit.AuditService)),
+	ratelimit.ProvideService,
+	wire.Bind(new(ratelimit.Service), new(*ratelimit.RateLimitService)),
+	passwordpolicy.ProvideService,
+	wire.Bind(new(passwordpolicy.Service), new(*passwordpolicy.PasswordPolicyService)),
+	saml.ProvideService,
+	wire.Bind(new(saml.Service), new(*saml.SAMLService)),
+	teamsync.ProvideService,
+	wire.Bind(new(teamsync.Service), new(*teamsync.TeamSyncService)),
+	querycache.ProvideService,
+	wire.Bind(new(querycache.Service), new(*querycache.QueryCacheService)),
+	querylimits.ProvideService,
+	wire.Bind(new(querylimits.Service), new(*querylimits.QueryLimitsService)),
 )
 
 var wireSet = wire.NewSet(
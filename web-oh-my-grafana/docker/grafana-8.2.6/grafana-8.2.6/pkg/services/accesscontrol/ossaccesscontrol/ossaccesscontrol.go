@@ -3,6 +3,7 @@ package ossaccesscontrol
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
@@ -73,6 +74,21 @@ func (ac *OSSAccessControlService) GetUserPermissions(ctx context.Context, user
 	timer := prometheus.NewTimer(metrics.MAccessPermissionsSummary)
 	defer timer.ObserveDuration()
 
+	// service accounts with their own permissions don't get the ones of their role
+	if user.IsServiceAccount {
+		query := models.GetServiceAccountPermissionsQuery{OrgId: user.OrgId, ServiceAccountId: user.UserId}
+		if err := bus.DispatchCtx(ctx, &query); err != nil {
+			return nil, err
+		}
+		if len(query.Result) > 0 {
+			permissions := make([]*accesscontrol.Permission, 0, len(query.Result))
+			for _, p := range query.Result {
+				permissions = append(permissions, &accesscontrol.Permission{Action: p.Action, Scope: p.Scope})
+			}
+			return permissions, nil
+		}
+	}
+
 	builtinRoles := ac.GetUserBuiltInRoles(user)
 	permissions := make([]*accesscontrol.Permission, 0)
 	for _, builtin := range builtinRoles {
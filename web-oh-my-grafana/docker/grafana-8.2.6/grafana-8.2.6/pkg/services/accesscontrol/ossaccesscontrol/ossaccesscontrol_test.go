@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/models"
@@ -137,6 +138,39 @@ func TestEvaluatingPermissions(t *testing.T) {
 	}
 }
 
+func TestServiceAccountPermissions(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	granted := map[int64][]*models.ServiceAccountPermission{
+		2: {{Action: accesscontrol.ActionUsersCreate, Scope: accesscontrol.ScopeGlobalUsersAll}},
+	}
+	bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetServiceAccountPermissionsQuery) error {
+		query.Result = granted[query.ServiceAccountId]
+		return nil
+	})
+
+	ac := setupTestEnv(t)
+	evaluator := accesscontrol.EvalPermission(accesscontrol.ActionUsersCreate, accesscontrol.ScopeGlobalUsersAll)
+
+	t.Run("should use the permissions of the service account", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_VIEWER, IsServiceAccount: true}
+		permissions, err := ac.GetUserPermissions(context.Background(), user)
+		require.NoError(t, err)
+		require.Len(t, permissions, 1)
+
+		result, err := ac.Evaluate(context.Background(), user, evaluator)
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	t.Run("should use the permissions of the role without service account permissions", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 3, OrgId: 1, OrgRole: models.ROLE_VIEWER, IsServiceAccount: true}
+		result, err := ac.Evaluate(context.Background(), user, evaluator)
+		require.NoError(t, err)
+		assert.False(t, result)
+	})
+}
+
 func TestUsageMetrics(t *testing.T) {
 	tests := []struct {
 		name          string
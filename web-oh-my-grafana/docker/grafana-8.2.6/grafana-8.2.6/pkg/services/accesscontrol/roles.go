@@ -263,6 +263,22 @@ func ValidateBuiltInRoles(builtInRoles []string) error {
 	return nil
 }
 
+// IsOrgAction returns true when the action is granted to an organization
+// role by the fixed roles. Other actions, such as the ones on users of all
+// organizations, are only granted to Grafana Admins.
+func IsOrgAction(action string) bool {
+	for _, builtin := range []models.RoleType{models.ROLE_VIEWER, models.ROLE_EDITOR, models.ROLE_ADMIN} {
+		for _, name := range FixedRoleGrants[string(builtin)] {
+			for _, p := range FixedRoles[name].Permissions {
+				if p.Action == action {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
 type RegistrationList struct {
 	mx            sync.RWMutex
 	registrations []RoleRegistration
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/querycache"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -192,7 +193,7 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange p
 		})
 	}
 
-	resp, err := requestHandler.HandleRequest(context.Ctx, getDsInfo.Result, req)
+	resp, err := requestHandler.HandleRequest(querycache.WithoutCache(context.Ctx), getDsInfo.Result, req)
 	if err != nil {
 		return nil, toCustomError(err)
 	}
@@ -269,8 +270,7 @@ func (c *QueryCondition) getRequestForAlertRule(datasource *models.DataSource, t
 			},
 		},
 		Headers: map[string]string{
-			"FromAlert":    "true",
-			"X-Cache-Skip": "true",
+			"FromAlert": "true",
 		},
 		Debug: debug,
 	}
@@ -144,6 +144,7 @@ func applyScenario(timeRange string, dataSourceJsonData *simplejson.Json, queryM
 
 		ctx := &queryIntervalTestContext{}
 		ctx.result = &alerting.EvalContext{
+			Ctx:              context.Background(),
 			Rule:             &alerting.Rule{},
 			RequestValidator: &validations.OSSPluginRequestValidator{},
 		}
@@ -248,6 +248,7 @@ func queryConditionScenario(desc string, fn queryConditionScenarioFunc) {
 
 		ctx := &queryConditionTestContext{}
 		ctx.result = &alerting.EvalContext{
+			Ctx:              context.Background(),
 			Rule:             &alerting.Rule{},
 			RequestValidator: &validations.OSSPluginRequestValidator{},
 		}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/opentracing/opentracing-go"
@@ -23,11 +24,12 @@ import (
 // schedules alert evaluations and makes sure notifications
 // are sent.
 type AlertEngine struct {
-	RenderService    rendering.Service
-	Bus              bus.Bus
-	RequestValidator models.PluginRequestValidator
-	DataService      plugins.DataRequestHandler
-	Cfg              *setting.Cfg
+	RenderService     rendering.Service
+	Bus               bus.Bus
+	RequestValidator  models.PluginRequestValidator
+	DataService       plugins.DataRequestHandler
+	Cfg               *setting.Cfg
+	EncryptionService encryption.Service
 
 	execQueue     chan *Job
 	ticker        *Ticker
@@ -45,13 +47,14 @@ func (e *AlertEngine) IsDisabled() bool {
 
 // ProvideAlertEngine returns a new AlertEngine.
 func ProvideAlertEngine(renderer rendering.Service, bus bus.Bus, requestValidator models.PluginRequestValidator,
-	dataService plugins.DataRequestHandler, cfg *setting.Cfg) *AlertEngine {
+	dataService plugins.DataRequestHandler, encryptionService encryption.Service, cfg *setting.Cfg) *AlertEngine {
 	e := &AlertEngine{
-		Cfg:              cfg,
-		RenderService:    renderer,
-		Bus:              bus,
-		RequestValidator: requestValidator,
-		DataService:      dataService,
+		Cfg:               cfg,
+		RenderService:     renderer,
+		Bus:               bus,
+		RequestValidator:  requestValidator,
+		DataService:       dataService,
+		EncryptionService: encryptionService,
 	}
 	e.ticker = NewTicker(time.Now(), time.Second*0, clock.New(), 1)
 	e.execQueue = make(chan *Job, 1000)
@@ -59,7 +62,9 @@ func ProvideAlertEngine(renderer rendering.Service, bus bus.Bus, requestValidato
 	e.evalHandler = NewEvalHandler(e.DataService)
 	e.ruleReader = newRuleReader()
 	e.log = log.New("alerting.engine")
-	e.resultHandler = newResultHandler(e.RenderService)
+	e.resultHandler = newResultHandler(e.RenderService, e.EncryptionService)
+
+	e.Bus.AddHandlerCtx(e.handleNotificationTestCommand)
 
 	return e
 }
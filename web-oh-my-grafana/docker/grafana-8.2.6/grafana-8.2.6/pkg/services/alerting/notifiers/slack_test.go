@@ -7,9 +7,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,7 +27,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err = NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		_, err = NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		assert.EqualError(t, err, "alert validation error: recipient must be specified when using the Slack chat API")
 	})
 
@@ -44,7 +46,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		not, err := NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		not, err := NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		require.NoError(t, err)
 		slackNotifier := not.(*SlackNotifier)
 		assert.Equal(t, "ops", slackNotifier.Name)
@@ -82,7 +85,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		not, err := NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		not, err := NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		require.NoError(t, err)
 		slackNotifier := not.(*SlackNotifier)
 		assert.Equal(t, "ops", slackNotifier.Name)
@@ -114,9 +118,11 @@ func TestSlackNotifier(t *testing.T) {
 
 		settingsJSON, err := simplejson.NewJson([]byte(json))
 		require.NoError(t, err)
-		securedSettingsJSON := securejsondata.GetEncryptedJsonData(map[string]string{
+		encryptionService := &ossencryption.Service{}
+		securedSettingsJSON, err := encryptionService.EncryptJsonData(map[string]string{
 			"token": "xenc-XXXXXXXX-XXXXXXXX-XXXXXXXXXX",
-		})
+		}, setting.SecretKey)
+		require.NoError(t, err)
 		model := &models.AlertNotification{
 			Name:           "ops",
 			Type:           "slack",
@@ -124,7 +130,7 @@ func TestSlackNotifier(t *testing.T) {
 			SecureSettings: securedSettingsJSON,
 		}
 
-		not, err := NewSlackNotifier(model)
+		not, err := NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		require.NoError(t, err)
 		slackNotifier := not.(*SlackNotifier)
 		assert.Equal(t, "ops", slackNotifier.Name)
@@ -155,7 +161,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err = NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		_, err = NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		assert.EqualError(t, err, "alert validation error: recipient on invalid format: \"#open tsdb\"")
 	})
 
@@ -174,7 +181,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err = NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		_, err = NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		assert.EqualError(t, err, "alert validation error: recipient on invalid format: \"@user name\"")
 	})
 
@@ -193,7 +201,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		_, err = NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		_, err = NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		assert.EqualError(t, err, "alert validation error: recipient on invalid format: \"@User\"")
 	})
 
@@ -212,7 +221,8 @@ func TestSlackNotifier(t *testing.T) {
 			Settings: settingsJSON,
 		}
 
-		not, err := NewSlackNotifier(model)
+		encryptionService := &ossencryption.Service{}
+		not, err := NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 		require.NoError(t, err)
 		slackNotifier := not.(*SlackNotifier)
 		assert.Equal(t, "1ABCDE", slackNotifier.recipient)
@@ -304,7 +314,8 @@ func TestSendSlackRequest(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			not, err := NewSlackNotifier(model)
+			encryptionService := &ossencryption.Service{}
+			not, err := NewSlackNotifier(model, encryptionService.GetDecryptedValue)
 			require.NoError(t, err)
 			slackNotifier := not.(*SlackNotifier)
 
@@ -0,0 +1,288 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+var (
+	getTime = time.Now
+	logger  = log.New("audit")
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, bus bus.Bus) (*AuditService, error) {
+	s := &AuditService{
+		Cfg:      cfg,
+		SQLStore: sqlStore,
+		Bus:      bus,
+	}
+
+	if cfg.Audit.Enabled {
+		for _, name := range cfg.Audit.Outputs {
+			factory, ok := outputFactories[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown audit output %q", name)
+			}
+			output, err := factory(cfg, sqlStore)
+			if err != nil {
+				return nil, err
+			}
+			s.outputs = append(s.outputs, output)
+		}
+	}
+
+	s.Bus.AddHandlerCtx(s.RecordAuditEvent)
+	s.Bus.AddDispatchHook(s.onDispatch)
+
+	return s, nil
+}
+
+type Service interface {
+	// Middleware audits the state-changing requests to the HTTP API.
+	Middleware(c *models.ReqContext)
+	Record(ctx context.Context, event *models.AuditEvent) error
+	Search(ctx context.Context, query *models.SearchAuditEventsQuery) error
+}
+
+// AuditService records who changed what in Grafana, through the HTTP API,
+// provisioning or the bus commands dispatched on behalf of an actor.
+type AuditService struct {
+	Cfg      *setting.Cfg
+	SQLStore *sqlstore.SQLStore
+	Bus      bus.Bus
+
+	outputs []Output
+}
+
+func (s *AuditService) enabled() bool {
+	return len(s.outputs) > 0
+}
+
+// Record writes the event to every configured output.
+func (s *AuditService) Record(ctx context.Context, event *models.AuditEvent) error {
+	if !s.enabled() {
+		return nil
+	}
+	if event.Created.IsZero() {
+		event.Created = getTime()
+	}
+
+	var result error
+	for _, output := range s.outputs {
+		if err := output.Write(ctx, event); err != nil {
+			logger.Error("Failed to write audit event", "action", event.Action, "error", err)
+			result = err
+		}
+	}
+
+	return result
+}
+
+func (s *AuditService) RecordAuditEvent(ctx context.Context, cmd *models.RecordAuditEventCommand) error {
+	return s.Record(ctx, cmd.Event)
+}
+
+// Search returns the events stored by the sql output, the most recent first.
+func (s *AuditService) Search(ctx context.Context, query *models.SearchAuditEventsQuery) error {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	where := "1 = 1"
+	params := make([]interface{}, 0)
+	addFilter := func(cond string, value interface{}) {
+		where += " AND " + cond
+		params = append(params, value)
+	}
+	if query.OrgId != 0 {
+		addFilter("org_id = ?", query.OrgId)
+	}
+	if !query.From.IsZero() {
+		addFilter("created >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		addFilter("created <= ?", query.To)
+	}
+	if query.ActorType != "" {
+		addFilter("actor_type = ?", query.ActorType)
+	}
+	if query.ActorLogin != "" {
+		addFilter("actor_login = ?", query.ActorLogin)
+	}
+	if query.Action != "" {
+		addFilter("action = ?", query.Action)
+	}
+	if query.ResourceType != "" {
+		addFilter("resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceUid != "" {
+		addFilter("resource_uid = ?", query.ResourceUid)
+	}
+
+	return s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		count, err := sess.Where(where, params...).Count(&models.AuditEvent{})
+		if err != nil {
+			return err
+		}
+
+		events := make([]*models.AuditEvent, 0)
+		offset := (query.Page - 1) * query.Limit
+		err = sess.Where(where, params...).Desc("created").Desc("id").Limit(query.Limit, offset).Find(&events)
+		if err != nil {
+			return err
+		}
+
+		query.Result = models.SearchAuditEventsResult{
+			TotalCount: count,
+			Events:     make([]*models.AuditEventDTO, len(events)),
+			Page:       query.Page,
+			PerPage:    query.Limit,
+		}
+		for i, e := range events {
+			query.Result.Events[i] = e.ToDTO()
+		}
+
+		return nil
+	})
+}
+
+// onDispatch derives the action and resource of audited commands. Commands
+// dispatched while handling a request complete the event of the request,
+// others are recorded when dispatched on behalf of an actor.
+func (s *AuditService) onDispatch(ctx context.Context, msg bus.Msg) {
+	if !s.enabled() || ctx == nil {
+		return
+	}
+
+	action, resourceType, resourceUID, orgID, ok := auditedCommand(msg)
+	if !ok {
+		return
+	}
+
+	if e := requestEventFromContext(ctx); e != nil {
+		e.setDefaults(action, resourceType, resourceUID)
+		return
+	}
+
+	actor, ok := actorFromContext(ctx)
+	if !ok {
+		return
+	}
+
+	err := s.Record(ctx, &models.AuditEvent{
+		OrgId:        orgID,
+		ActorType:    actor.Type,
+		ActorId:      actor.Id,
+		ActorLogin:   actor.Login,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceUid:  resourceUID,
+	})
+	if err != nil {
+		logger.Error("Failed to record audit event", "action", action, "error", err)
+	}
+}
+
+// auditedCommand returns the action, resource and organization of the
+// commands changing the state of Grafana.
+func auditedCommand(msg bus.Msg) (action, resourceType, resourceUID string, orgID int64, ok bool) {
+	id := func(id int64) string {
+		return strconv.FormatInt(id, 10)
+	}
+
+	switch cmd := msg.(type) {
+	case *models.AddDataSourceCommand:
+		if cmd.Result != nil {
+			return "datasource.create", "datasource", cmd.Result.Uid, cmd.OrgId, true
+		}
+	case *models.UpdateDataSourceCommand:
+		if cmd.Result != nil {
+			return "datasource.update", "datasource", cmd.Result.Uid, cmd.OrgId, true
+		}
+	case *models.DeleteDataSourceCommand:
+		uid := cmd.UID
+		if uid == "" && cmd.ID != 0 {
+			uid = id(cmd.ID)
+		}
+		if uid == "" {
+			uid = cmd.Name
+		}
+		return "datasource.delete", "datasource", uid, cmd.OrgID, true
+	case *models.CreateAlertNotificationCommand:
+		if cmd.Result != nil {
+			return "alert_notification.create", "alert_notification", cmd.Result.Uid, cmd.OrgId, true
+		}
+	case *models.UpdateAlertNotificationCommand:
+		if cmd.Result != nil {
+			return "alert_notification.update", "alert_notification", cmd.Result.Uid, cmd.OrgId, true
+		}
+	case *models.UpdateAlertNotificationWithUidCommand:
+		if cmd.Result != nil {
+			return "alert_notification.update", "alert_notification", cmd.Result.Uid, cmd.OrgId, true
+		}
+	case *models.DeleteAlertNotificationCommand:
+		return "alert_notification.delete", "alert_notification", id(cmd.Id), cmd.OrgId, true
+	case *models.DeleteAlertNotificationWithUidCommand:
+		return "alert_notification.delete", "alert_notification", cmd.Uid, cmd.OrgId, true
+	case *models.PauseAlertCommand:
+		action := "alert.resume"
+		if cmd.Paused {
+			action = "alert.pause"
+		}
+		if len(cmd.AlertIds) == 1 {
+			return action, "alert", id(cmd.AlertIds[0]), cmd.OrgId, true
+		}
+		return action, "alert", "", cmd.OrgId, true
+	case *models.CreateOrgCommand:
+		return "org.create", "org", id(cmd.Result.Id), cmd.Result.Id, true
+	case *models.UpdateOrgCommand:
+		return "org.update", "org", id(cmd.OrgId), cmd.OrgId, true
+	case *models.DeleteOrgCommand:
+		return "org.delete", "org", id(cmd.Id), cmd.Id, true
+	case *models.AddOrgUserCommand:
+		return "org_user.add", "user", id(cmd.UserId), cmd.OrgId, true
+	case *models.UpdateOrgUserCommand:
+		return "org_user.update", "user", id(cmd.UserId), cmd.OrgId, true
+	case *models.RemoveOrgUserCommand:
+		return "org_user.remove", "user", id(cmd.UserId), cmd.OrgId, true
+	case *models.AddApiKeyCommand:
+		if cmd.Result != nil {
+			return "api_key.create", "api_key", id(cmd.Result.Id), cmd.OrgId, true
+		}
+	case *models.DeleteApiKeyCommand:
+		return "api_key.delete", "api_key", id(cmd.Id), cmd.OrgId, true
+	case *models.UpdateTeamCommand:
+		return "team.update", "team", id(cmd.Id), cmd.OrgId, true
+	case *models.DeleteTeamCommand:
+		return "team.delete", "team", id(cmd.Id), cmd.OrgId, true
+	case *models.DisableUserCommand:
+		action := "user.enable"
+		if cmd.IsDisabled {
+			action = "user.disable"
+		}
+		return action, "user", id(cmd.UserId), 0, true
+	case *models.DeleteUserCommand:
+		return "user.delete", "user", id(cmd.UserId), 0, true
+	}
+
+	return "", "", "", 0, false
+}
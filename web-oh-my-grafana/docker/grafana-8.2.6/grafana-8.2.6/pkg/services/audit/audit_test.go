@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"name":     "Prometheus",
+		"url":      "http://localhost:9090",
+		"jsonData": map[string]interface{}{"httpMethod": "GET", "timeout": 30},
+		"tags":     []string{"a"},
+	}
+	after := map[string]interface{}{
+		"name":     "Prometheus",
+		"url":      "http://prometheus:9090",
+		"jsonData": map[string]interface{}{"httpMethod": "POST", "timeout": 30},
+		"tags":     []string{"a", "b"},
+		"readOnly": true,
+	}
+
+	diff, err := Diff(before, after)
+	require.NoError(t, err)
+
+	var changes []Change
+	require.NoError(t, json.Unmarshal([]byte(diff), &changes))
+	assert.Equal(t, []Change{
+		{Path: "jsonData.httpMethod", Before: "GET", After: "POST"},
+		{Path: "readOnly", After: true},
+		{Path: "tags", Before: []interface{}{"a"}, After: []interface{}{"a", "b"}},
+		{Path: "url", Before: "http://localhost:9090", After: "http://prometheus:9090"},
+	}, changes)
+
+	t.Run("created resources diff every value", func(t *testing.T) {
+		diff, err := Diff(nil, map[string]interface{}{"name": "Loki"})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"path":"name","after":"Loki"}]`, diff)
+	})
+
+	t.Run("unchanged resources have no diff", func(t *testing.T) {
+		diff, err := Diff(before, before)
+		require.NoError(t, err)
+		assert.Equal(t, "[]", diff)
+	})
+
+	t.Run("sensitive values are redacted", func(t *testing.T) {
+		diff, err := Diff(map[string]interface{}{
+			"password":          "old",
+			"basicAuthPassword": "",
+			"jsonData":          map[string]interface{}{"token": "abc", "keyField": "host"},
+			"secureJsonData":    map[string]interface{}{"accessKey": "AKIA"},
+		}, map[string]interface{}{
+			"password":          "new",
+			"basicAuthPassword": "hunter2",
+			"jsonData":          map[string]interface{}{"token": "abd", "keyField": "hostname"},
+		})
+		require.NoError(t, err)
+		assert.NotContains(t, diff, "old")
+		assert.NotContains(t, diff, "hunter2")
+		assert.NotContains(t, diff, "AKIA")
+		assert.NotContains(t, diff, "abc")
+
+		var changes []Change
+		require.NoError(t, json.Unmarshal([]byte(diff), &changes))
+		assert.Equal(t, []Change{
+			{Path: "basicAuthPassword", Before: "", After: redactedValue},
+			{Path: "jsonData.keyField", Before: "host", After: "hostname"},
+			{Path: "jsonData.token", Before: redactedValue, After: redactedValue},
+			{Path: "password", Before: redactedValue, After: redactedValue},
+			{Path: "secureJsonData", Before: redactedValue},
+		}, changes)
+	})
+}
+
+func setupTestService(t *testing.T, outputs ...string) (*AuditService, *bus.InProcBus) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.Audit.Enabled = true
+	cfg.Audit.Outputs = outputs
+	cfg.Audit.FilePath = filepath.Join(t.TempDir(), "audit.log")
+	cfg.Audit.FileMaxSizeShift = 28
+
+	b := bus.New()
+	s, err := ProvideService(cfg, sqlstore.InitTestDB(t), b)
+	require.NoError(t, err)
+	return s, b
+}
+
+func TestProvideService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.Audit.Enabled = true
+	cfg.Audit.Outputs = []string{"kafka"}
+
+	_, err := ProvideService(cfg, nil, bus.New())
+	require.EqualError(t, err, `unknown audit output "kafka"`)
+}
+
+func TestRecordAndSearch(t *testing.T) {
+	s, _ := setupTestService(t, "sql", "file")
+	ctx := context.Background()
+
+	now := time.Now()
+	t.Cleanup(func() {
+		getTime = time.Now
+	})
+
+	events := []*models.AuditEvent{
+		{OrgId: 1, ActorType: models.AuditActorUser, ActorId: 1, ActorLogin: "admin", Action: "datasource.update", ResourceType: "datasource", ResourceUid: "prom"},
+		{OrgId: 1, ActorType: models.AuditActorAPIKey, ActorId: 5, ActorLogin: "deploy", Action: "dashboard.save", ResourceType: "dashboard", ResourceUid: "home"},
+		{OrgId: 2, ActorType: models.AuditActorUser, ActorId: 1, ActorLogin: "admin", Action: "team.delete", ResourceType: "team", ResourceUid: "4"},
+	}
+	for i, e := range events {
+		created := now.Add(time.Duration(i) * time.Hour)
+		getTime = func() time.Time {
+			return created
+		}
+		require.NoError(t, s.Record(ctx, e))
+	}
+
+	search := func(query models.SearchAuditEventsQuery) []string {
+		require.NoError(t, s.Search(ctx, &query))
+		actions := make([]string, len(query.Result.Events))
+		for i, e := range query.Result.Events {
+			actions[i] = e.Action
+		}
+		return actions
+	}
+
+	assert.Equal(t, []string{"team.delete", "dashboard.save", "datasource.update"}, search(models.SearchAuditEventsQuery{}))
+	assert.Equal(t, []string{"dashboard.save", "datasource.update"}, search(models.SearchAuditEventsQuery{OrgId: 1}))
+	assert.Equal(t, []string{"team.delete", "datasource.update"}, search(models.SearchAuditEventsQuery{ActorLogin: "admin"}))
+	assert.Equal(t, []string{"dashboard.save"}, search(models.SearchAuditEventsQuery{ResourceType: "dashboard", ResourceUid: "home"}))
+	assert.Equal(t, []string{"team.delete", "dashboard.save"}, search(models.SearchAuditEventsQuery{From: now.Add(30 * time.Minute)}))
+	assert.Equal(t, []string{"datasource.update"}, search(models.SearchAuditEventsQuery{To: now.Add(30 * time.Minute)}))
+
+	t.Run("paginates the results", func(t *testing.T) {
+		query := models.SearchAuditEventsQuery{Limit: 2, Page: 2}
+		require.NoError(t, s.Search(ctx, &query))
+		require.Len(t, query.Result.Events, 1)
+		assert.EqualValues(t, 3, query.Result.TotalCount)
+		assert.Equal(t, "datasource.update", query.Result.Events[0].Action)
+	})
+
+	t.Run("writes JSON lines to the file output", func(t *testing.T) {
+		data, err := os.ReadFile(s.Cfg.Audit.FilePath)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 3)
+		var dto models.AuditEventDTO
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &dto))
+		assert.Equal(t, "datasource.update", dto.Action)
+		assert.Equal(t, "prom", dto.ResourceUid)
+	})
+}
+
+func TestDispatchHook(t *testing.T) {
+	s, b := setupTestService(t, "sql")
+	b.AddHandlerCtx(func(ctx context.Context, cmd *models.DeleteTeamCommand) error {
+		return nil
+	})
+
+	t.Run("records commands dispatched on behalf of an actor", func(t *testing.T) {
+		ctx := WithActor(context.Background(), ProvisioningActor)
+		require.NoError(t, b.DispatchCtx(ctx, &models.DeleteTeamCommand{OrgId: 1, Id: 3}))
+
+		query := models.SearchAuditEventsQuery{}
+		require.NoError(t, s.Search(context.Background(), &query))
+		require.Len(t, query.Result.Events, 1)
+		e := query.Result.Events[0]
+		assert.Equal(t, models.AuditActorProvisioning, e.ActorType)
+		assert.Equal(t, "team.delete", e.Action)
+		assert.Equal(t, "team", e.ResourceType)
+		assert.Equal(t, "3", e.ResourceUid)
+		assert.EqualValues(t, 1, e.OrgId)
+	})
+
+	t.Run("ignores commands without an actor", func(t *testing.T) {
+		require.NoError(t, b.DispatchCtx(context.Background(), &models.DeleteTeamCommand{OrgId: 1, Id: 4}))
+
+		query := models.SearchAuditEventsQuery{ResourceUid: "4"}
+		require.NoError(t, s.Search(context.Background(), &query))
+		require.Empty(t, query.Result.Events)
+	})
+
+	t.Run("records events sent through the bus", func(t *testing.T) {
+		err := b.DispatchCtx(context.Background(), &models.RecordAuditEventCommand{Event: &models.AuditEvent{
+			OrgId: 1, ActorType: models.AuditActorProvisioning, Action: "dashboard.provision", ResourceType: "dashboard", ResourceUid: "home",
+		}})
+		require.NoError(t, err)
+
+		query := models.SearchAuditEventsQuery{Action: "dashboard.provision"}
+		require.NoError(t, s.Search(context.Background(), &query))
+		require.Len(t, query.Result.Events, 1)
+	})
+}
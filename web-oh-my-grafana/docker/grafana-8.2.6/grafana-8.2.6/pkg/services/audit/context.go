@@ -0,0 +1,104 @@
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type requestEventKey struct{}
+type actorKey struct{}
+
+// Actor identifies who made a change outside of an HTTP request.
+type Actor struct {
+	Type  string
+	Id    int64
+	Login string
+}
+
+// ProvisioningActor is the actor of changes made by provisioning.
+var ProvisioningActor = Actor{Type: models.AuditActorProvisioning, Login: "provisioning"}
+
+// WithActor returns a copy of ctx in which the audited commands dispatched
+// through the bus are recorded as made by actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+// requestEvent is the event of an audited HTTP request. Handlers and the bus
+// hook fill in the changed resource while the request is handled, and the
+// middleware records it once the request succeeded.
+type requestEvent struct {
+	mu    sync.Mutex
+	event models.AuditEvent
+}
+
+func requestEventFromContext(ctx context.Context) *requestEvent {
+	if ctx == nil {
+		return nil
+	}
+	e, _ := ctx.Value(requestEventKey{}).(*requestEvent)
+	return e
+}
+
+// IsRecording returns whether the request of ctx is audited. Handlers can use
+// it to skip loading the state of a resource only needed by SetChanges.
+func IsRecording(ctx context.Context) bool {
+	return requestEventFromContext(ctx) != nil
+}
+
+// SetAction sets the action and the resource of the audit event of the
+// request of ctx, overriding what was derived from its route and commands.
+func SetAction(ctx context.Context, action, resourceType, resourceUID string) {
+	e := requestEventFromContext(ctx)
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if action != "" {
+		e.event.Action = action
+	}
+	e.event.ResourceType = resourceType
+	e.event.ResourceUid = resourceUID
+}
+
+// SetChanges records the difference between the state of the resource before
+// and after the request of ctx. Before is nil for created resources and
+// after is nil for deleted ones.
+func SetChanges(ctx context.Context, before, after interface{}) {
+	e := requestEventFromContext(ctx)
+	if e == nil {
+		return
+	}
+
+	diff, err := Diff(before, after)
+	if err != nil {
+		logger.Warn("Failed to compute audit diff", "error", err)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.event.Diff = diff
+}
+
+// setDefaults sets the action and resource of the event unless a handler or
+// a previous command already did.
+func (e *requestEvent) setDefaults(action, resourceType, resourceUID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.event.ResourceType != "" {
+		return
+	}
+	e.event.Action = action
+	e.event.ResourceType = resourceType
+	e.event.ResourceUid = resourceUID
+}
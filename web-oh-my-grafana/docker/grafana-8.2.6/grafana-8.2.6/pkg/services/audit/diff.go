@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// redactedValue replaces the values of sensitive keys in the changes.
+const redactedValue = "[REDACTED]"
+
+// sensitiveKeys are the keys whose values are never written to the audit
+// log, wherever they are in the resource.
+var sensitiveKeys = map[string]bool{
+	"secureJsonData":    true,
+	"password":          true,
+	"basicAuthPassword": true,
+	"token":             true,
+	"apiKey":            true,
+	"clientSecret":      true,
+	"privateKey":        true,
+	"secretKey":         true,
+}
+
+func isSensitiveKey(key string) bool {
+	return sensitiveKeys[key]
+}
+
+// redact hides a value while keeping whether it was set.
+func redact(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return redactedValue
+}
+
+// Change is a value changed in a resource, Path is the dot separated path of
+// the value in the JSON representation of the resource.
+type Change struct {
+	Path   string      `json:"path"`
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Diff returns the JSON encoded list of changes between the JSON
+// representations of before and after. Objects are compared key by key,
+// any other value, including arrays, is compared as a whole. Values of the
+// keys holding credentials, such as password or secureJsonData, are redacted.
+func Diff(before, after interface{}) (string, error) {
+	b, err := toJSONValue(before)
+	if err != nil {
+		return "", err
+	}
+	a, err := toJSONValue(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := diffValues("", b, a, []Change{})
+	out, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func diffValues(path string, before, after interface{}, changes []Change) []Change {
+	beforeObj, beforeIsObj := before.(map[string]interface{})
+	afterObj, afterIsObj := after.(map[string]interface{})
+
+	// created or deleted objects are compared with an empty object, so that
+	// every key shows up as a change
+	if beforeIsObj && after == nil {
+		afterObj, afterIsObj = map[string]interface{}{}, true
+	}
+	if afterIsObj && before == nil {
+		beforeObj, beforeIsObj = map[string]interface{}{}, true
+	}
+
+	if !beforeIsObj || !afterIsObj {
+		if !reflect.DeepEqual(before, after) {
+			changes = append(changes, Change{Path: path, Before: before, After: after})
+		}
+		return changes
+	}
+
+	keys := make([]string, 0, len(beforeObj)+len(afterObj))
+	for k := range beforeObj {
+		keys = append(keys, k)
+	}
+	for k := range afterObj {
+		if _, ok := beforeObj[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := k
+		if path != "" {
+			p = path + "." + k
+		}
+		if isSensitiveKey(k) {
+			if !reflect.DeepEqual(beforeObj[k], afterObj[k]) {
+				changes = append(changes, Change{Path: p, Before: redact(beforeObj[k]), After: redact(afterObj[k])})
+			}
+			continue
+		}
+		changes = diffValues(p, beforeObj[k], afterObj[k], changes)
+	}
+
+	return changes
+}
@@ -0,0 +1,110 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// skippedPaths are the API paths that change no state even though they are
+// not requested with GET, such as queries and data source resources.
+var skippedPaths = []string{
+	"/api/ds/query",
+	"/api/tsdb/query",
+	"/api/frontend-metrics",
+	"/api/live/",
+	"/api/search/sorting",
+}
+
+// skippedPathParts are skipped wherever they occur in an API path.
+var skippedPathParts = []string{
+	"/proxy/",
+	"/resources",
+	"/health",
+}
+
+func isAudited(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	path := req.URL.Path
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	for _, p := range skippedPaths {
+		if strings.HasPrefix(path, p) {
+			return false
+		}
+	}
+	for _, p := range skippedPathParts {
+		if strings.Contains(path, p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *AuditService) Middleware(c *models.ReqContext) {
+	if !s.enabled() || !isAudited(c.Req) {
+		return
+	}
+
+	e := &requestEvent{event: models.AuditEvent{
+		OrgId:     c.OrgId,
+		IpAddress: c.RemoteAddr(),
+	}}
+	s.setActor(c, &e.event)
+	c.Req = c.Req.WithContext(context.WithValue(c.Req.Context(), requestEventKey{}, e))
+
+	c.Next()
+
+	if status := c.Resp.Status(); status >= 400 {
+		return
+	}
+
+	e.mu.Lock()
+	event := e.event
+	e.mu.Unlock()
+
+	if event.Action == "" {
+		route, ok := middleware.RouteOperationNameFromContext(c.Req.Context())
+		if !ok {
+			route = c.Req.URL.Path
+		}
+		event.Action = c.Req.Method + " " + route
+	}
+
+	if err := s.Record(c.Req.Context(), &event); err != nil {
+		c.Logger.Error("Failed to record audit event", "action", event.Action, "error", err)
+	}
+}
+
+func (s *AuditService) setActor(c *models.ReqContext, event *models.AuditEvent) {
+	switch {
+	case !c.IsSignedIn || c.SignedInUser == nil:
+		event.ActorType = models.AuditActorAnonymous
+	case c.IsServiceAccount:
+		event.ActorType = models.AuditActorServiceAccount
+		event.ActorId = c.UserId
+		event.ActorLogin = c.Login
+	case c.ApiKeyId != 0:
+		event.ActorType = models.AuditActorAPIKey
+		event.ActorId = c.ApiKeyId
+		query := models.GetApiKeyByIdQuery{ApiKeyId: c.ApiKeyId}
+		if err := s.Bus.DispatchCtx(c.Req.Context(), &query); err == nil {
+			event.ActorLogin = query.Result.Name
+		}
+	case c.IsAnonymous:
+		event.ActorType = models.AuditActorAnonymous
+	default:
+		event.ActorType = models.AuditActorUser
+		event.ActorId = c.UserId
+		event.ActorLogin = c.Login
+	}
+}
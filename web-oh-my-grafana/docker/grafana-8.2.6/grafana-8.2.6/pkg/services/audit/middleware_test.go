@@ -0,0 +1,93 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestMiddleware(t *testing.T) {
+	s, b := setupTestService(t, "sql")
+	b.AddHandlerCtx(func(ctx context.Context, cmd *models.UpdateDataSourceCommand) error {
+		cmd.Result = &models.DataSource{Uid: "prom"}
+		return nil
+	})
+
+	m := macaron.New()
+	m.Use(func(c *macaron.Context) {
+		c.Map(&models.ReqContext{
+			Context:      c,
+			IsSignedIn:   true,
+			SignedInUser: &models.SignedInUser{UserId: 2, OrgId: 1, Login: "editor"},
+			Logger:       log.New("test"),
+		})
+	})
+	m.Use(s.Middleware)
+	m.Put("/api/datasources/:id", middleware.ProvideRouteOperationName("/api/datasources/:id"), func(c *models.ReqContext) {
+		SetChanges(c.Req.Context(), map[string]string{"url": "http://a"}, map[string]string{"url": "http://b"})
+		require.NoError(t, b.DispatchCtx(c.Req.Context(), &models.UpdateDataSourceCommand{OrgId: 1, Id: 1}))
+		c.Resp.WriteHeader(http.StatusOK)
+	})
+	m.Post("/api/teams", func(c *models.ReqContext) {
+		c.Resp.WriteHeader(http.StatusConflict)
+	})
+	m.Post("/api/ds/query", func(c *models.ReqContext) {
+		c.Resp.WriteHeader(http.StatusOK)
+	})
+	m.Post("/api/playlists", middleware.ProvideRouteOperationName("/api/playlists"), func(c *models.ReqContext) {
+		c.Resp.WriteHeader(http.StatusOK)
+	})
+
+	request := func(method, path string) {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-Real-IP", "10.0.0.1")
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	search := func() []*models.AuditEventDTO {
+		query := models.SearchAuditEventsQuery{}
+		require.NoError(t, s.Search(context.Background(), &query))
+		return query.Result.Events
+	}
+
+	t.Run("records the changes of successful requests", func(t *testing.T) {
+		request(http.MethodPut, "/api/datasources/1")
+
+		events := search()
+		require.Len(t, events, 1)
+		e := events[0]
+		assert.Equal(t, models.AuditActorUser, e.ActorType)
+		assert.EqualValues(t, 2, e.ActorId)
+		assert.Equal(t, "editor", e.ActorLogin)
+		assert.EqualValues(t, 1, e.OrgId)
+		assert.Equal(t, "datasource.update", e.Action)
+		assert.Equal(t, "datasource", e.ResourceType)
+		assert.Equal(t, "prom", e.ResourceUid)
+		assert.Equal(t, "10.0.0.1", e.IpAddress)
+		assert.JSONEq(t, `[{"path":"url","before":"http://a","after":"http://b"}]`, string(e.Diff))
+	})
+
+	t.Run("defaults the action to the route", func(t *testing.T) {
+		request(http.MethodPost, "/api/playlists")
+
+		events := search()
+		require.Len(t, events, 2)
+		assert.Equal(t, "POST /api/playlists", events[0].Action)
+		assert.Empty(t, events[0].ResourceType)
+	})
+
+	t.Run("ignores failed, read and query requests", func(t *testing.T) {
+		request(http.MethodPost, "/api/teams")
+		request(http.MethodGet, "/api/datasources/1")
+		request(http.MethodPost, "/api/ds/query")
+
+		require.Len(t, search(), 2)
+	})
+}
@@ -0,0 +1,103 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/inconshreveable/log15"
+)
+
+// Output writes audit events to a destination.
+type Output interface {
+	Write(ctx context.Context, event *models.AuditEvent) error
+}
+
+// OutputFactory creates an output from the configuration.
+type OutputFactory func(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (Output, error)
+
+var outputFactories = map[string]OutputFactory{
+	"sql":    newSQLOutput,
+	"file":   newFileOutput,
+	"syslog": newSyslogOutput,
+}
+
+// RegisterOutput makes an output available to the outputs setting of the
+// audit section.
+func RegisterOutput(name string, factory OutputFactory) {
+	outputFactories[name] = factory
+}
+
+// sqlOutput stores audit events in the audit_event table, the only output
+// that can be searched.
+type sqlOutput struct {
+	sqlStore *sqlstore.SQLStore
+}
+
+func newSQLOutput(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (Output, error) {
+	return &sqlOutput{sqlStore: sqlStore}, nil
+}
+
+func (o *sqlOutput) Write(ctx context.Context, event *models.AuditEvent) error {
+	return o.sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Insert(event)
+		return err
+	})
+}
+
+// handlerOutput writes audit events as JSON lines to a log handler.
+type handlerOutput struct {
+	handler log15.Handler
+}
+
+// jsonLineFormat formats records which message is an encoded audit event.
+var jsonLineFormat = log15.FormatFunc(func(r *log15.Record) []byte {
+	return append([]byte(r.Msg), '\n')
+})
+
+func (o *handlerOutput) Write(ctx context.Context, event *models.AuditEvent) error {
+	data, err := json.Marshal(event.ToDTO())
+	if err != nil {
+		return err
+	}
+
+	return o.handler.Log(&log15.Record{
+		Time: event.Created,
+		Lvl:  log15.LvlInfo,
+		Msg:  string(data),
+	})
+}
+
+func newFileOutput(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (Output, error) {
+	w := log.NewFileWriter()
+	w.Filename = cfg.Audit.FilePath
+	w.Format = jsonLineFormat
+	w.Rotate = cfg.Audit.FileRotate
+	w.Maxsize = 1 << uint(cfg.Audit.FileMaxSizeShift)
+	w.Daily = cfg.Audit.FileDailyRotate
+	w.Maxdays = cfg.Audit.FileMaxDays
+
+	if err := os.MkdirAll(filepath.Dir(w.Filename), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	if err := w.Init(); err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+
+	return &handlerOutput{handler: w}, nil
+}
+
+func newSyslogOutput(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (Output, error) {
+	sec := cfg.Raw.Section("audit.syslog")
+	if sec.Key("tag").String() == "" {
+		sec.Key("tag").SetValue("grafana-audit")
+	}
+
+	return &handlerOutput{handler: log.NewSyslog(sec, jsonLineFormat)}, nil
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// FakeAuditService is a Service for tests, it keeps the recorded events in
+// memory and returns them all when searched.
+type FakeAuditService struct {
+	Events []*models.AuditEvent
+}
+
+func NewFakeAuditService() *FakeAuditService {
+	return &FakeAuditService{}
+}
+
+func (s *FakeAuditService) Middleware(c *models.ReqContext) {}
+
+func (s *FakeAuditService) Record(ctx context.Context, event *models.AuditEvent) error {
+	s.Events = append(s.Events, event)
+	return nil
+}
+
+func (s *FakeAuditService) Search(ctx context.Context, query *models.SearchAuditEventsQuery) error {
+	query.Result = models.SearchAuditEventsResult{
+		TotalCount: int64(len(s.Events)),
+		Events:     make([]*models.AuditEventDTO, len(s.Events)),
+		Page:       1,
+		PerPage:    len(s.Events),
+	}
+	for i, e := range s.Events {
+		query.Result.Events[i] = e.ToDTO()
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
@@ -30,6 +31,7 @@ func ProvideUserAuthTokenService(sqlStore *sqlstore.SQLStore, serverLockService
 		ServerLockService: serverLockService,
 		Cfg:               cfg,
 		log:               log.New("auth"),
+		policyCache:       localcache.New(sessionPolicyCacheTTL, 2*sessionPolicyCacheTTL),
 	}
 	return s
 }
@@ -39,6 +41,7 @@ type UserAuthTokenService struct {
 	ServerLockService *serverlock.ServerLockService
 	Cfg               *setting.Cfg
 	log               log.Logger
+	policyCache       *localcache.CacheService
 }
 
 func (s *UserAuthTokenService) ActiveTokenCount(ctx context.Context) (int64, error) {
@@ -139,6 +142,10 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 		}
 	}
 
+	if err := s.enforceSessionPolicy(ctx, &model); err != nil {
+		return nil, err
+	}
+
 	if model.AuthToken != hashedToken && model.PrevAuthToken == hashedToken && model.AuthTokenSeen {
 		modelCopy := model
 		modelCopy.AuthTokenSeen = false
@@ -217,10 +224,21 @@ func (s *UserAuthTokenService) TryRotateToken(ctx context.Context, token *models
 
 	now := getTime()
 
+	// Rotations record the use of the token, idle timeouts need them more
+	// often.
+	rotationInterval := time.Duration(s.Cfg.TokenRotationIntervalMinutes) * time.Minute
+	policy, err := s.userSessionPolicy(ctx, model.UserId)
+	if err != nil {
+		return false, err
+	}
+	if idleInterval := time.Duration(policy.IdleTimeoutSeconds) * time.Second / 2; idleInterval > 0 && idleInterval < rotationInterval {
+		rotationInterval = idleInterval
+	}
+
 	var needsRotation bool
 	rotatedAt := time.Unix(model.RotatedAt, 0)
 	if model.AuthTokenSeen {
-		needsRotation = rotatedAt.Before(now.Add(-time.Duration(s.Cfg.TokenRotationIntervalMinutes) * time.Minute))
+		needsRotation = rotatedAt.Before(now.Add(-rotationInterval))
 	} else {
 		needsRotation = rotatedAt.Before(now.Add(-urgentRotateTime))
 	}
@@ -440,6 +458,100 @@ func (s *UserAuthTokenService) GetUserRevokedTokens(ctx context.Context, userId
 	return result, err
 }
 
+func (s *UserAuthTokenService) SearchTokens(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+	result := &models.SearchUserTokensResult{Tokens: []*models.UserTokenSearchHit{}}
+	if query.PerPage <= 0 {
+		query.PerPage = 1000
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	where, params := s.tokenFilterSQL(&query.Filter)
+	from := ` FROM user_auth_token INNER JOIN ` + s.SQLStore.Dialect.Quote("user") + ` AS u ON u.id = user_auth_token.user_id WHERE ` + where
+
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var hits []*userAuthTokenSearchHit
+		rawSQL := `SELECT user_auth_token.*, u.login, u.email` + from +
+			` ORDER BY ` + lastSeenSQL + ` DESC, user_auth_token.id DESC ` +
+			s.SQLStore.Dialect.LimitOffset(int64(query.PerPage), int64((query.Page-1)*query.PerPage))
+		if err := dbSession.SQL(rawSQL, params...).Find(&hits); err != nil {
+			return err
+		}
+
+		for _, hit := range hits {
+			token := &models.UserTokenSearchHit{Login: hit.Login, Email: hit.Email}
+			if err := hit.Token.toUserToken(&token.UserToken); err != nil {
+				return err
+			}
+			result.Tokens = append(result.Tokens, token)
+		}
+
+		_, err := dbSession.SQL(`SELECT COUNT(*)`+from, params...).Get(&result.TotalCount)
+		return err
+	})
+
+	return result, err
+}
+
+func (s *UserAuthTokenService) RevokeTokens(ctx context.Context, filter *models.UserTokenFilter) (int64, error) {
+	var affected int64
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		where, params := s.tokenFilterSQL(filter)
+		res, err := dbSession.Exec(append([]interface{}{`DELETE FROM user_auth_token WHERE ` + where}, params...)...)
+		if err != nil {
+			return err
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.Debug("user auth tokens revoked", "filter", filter, "count", affected)
+
+	return affected, nil
+}
+
+// lastSeenSQL is the last time a token was used, see lastSeen.
+const lastSeenSQL = `(CASE WHEN user_auth_token.seen_at > user_auth_token.rotated_at THEN user_auth_token.seen_at ELSE user_auth_token.rotated_at END)`
+
+// tokenFilterSQL returns the condition selecting the active tokens matching a
+// filter.
+func (s *UserAuthTokenService) tokenFilterSQL(filter *models.UserTokenFilter) (string, []interface{}) {
+	conditions := []string{"user_auth_token.created_at > ?", "user_auth_token.rotated_at > ?", "user_auth_token.revoked_at = 0"}
+	params := []interface{}{s.createdAfterParam(), s.rotatedAfterParam()}
+
+	if filter.UserId != 0 {
+		conditions = append(conditions, "user_auth_token.user_id = ?")
+		params = append(params, filter.UserId)
+	}
+	if filter.ClientIp != "" {
+		conditions = append(conditions, "user_auth_token.client_ip = ?")
+		params = append(params, filter.ClientIp)
+	}
+	if filter.UserAgent != "" {
+		conditions = append(conditions, "user_auth_token.user_agent "+s.SQLStore.Dialect.LikeStr()+" ?")
+		params = append(params, "%"+filter.UserAgent+"%")
+	}
+	if filter.SeenFrom > 0 {
+		conditions = append(conditions, lastSeenSQL+" >= ?")
+		params = append(params, filter.SeenFrom/1000)
+	}
+	if filter.SeenTo > 0 {
+		conditions = append(conditions, lastSeenSQL+" <= ?")
+		params = append(params, filter.SeenTo/1000)
+	}
+	if filter.ExcludeTokenId != 0 {
+		conditions = append(conditions, "user_auth_token.id <> ?")
+		params = append(params, filter.ExcludeTokenId)
+	}
+
+	return strings.Join(conditions, " AND "), params
+}
+
 func (s *UserAuthTokenService) createdAfterParam() int64 {
 	return getTime().Add(-s.Cfg.LoginMaxLifetime).Unix()
 }
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 )
@@ -71,3 +72,18 @@ func (uat *userAuthToken) toUserToken(ut *models.UserToken) error {
 
 	return nil
 }
+
+type userAuthTokenSearchHit struct {
+	Token userAuthToken `xorm:"extends"`
+	Login string
+	Email string
+}
+
+type orgSessionPolicy struct {
+	Id                    int64
+	OrgId                 int64
+	IdleTimeoutSeconds    int64
+	MaxConcurrentSessions int64
+	Created               time.Time
+	Updated               time.Time
+}
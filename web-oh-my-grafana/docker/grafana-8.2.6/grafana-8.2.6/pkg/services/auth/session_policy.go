@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// sessionPolicyCacheTTL is how long the session policy of a user is cached,
+// the changes made on other instances apply after it.
+const sessionPolicyCacheTTL = time.Minute
+
+var ErrInvalidSessionPolicy = errors.New("session policy limits cannot be negative")
+
+func (s *UserAuthTokenService) GetSessionPolicy(ctx context.Context, orgId int64) (*models.SessionPolicy, error) {
+	policy := &models.SessionPolicy{OrgId: orgId}
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var model orgSessionPolicy
+		exists, err := dbSession.Where("org_id = ?", orgId).Get(&model)
+		if err != nil || !exists {
+			return err
+		}
+
+		policy.IdleTimeoutSeconds = model.IdleTimeoutSeconds
+		policy.MaxConcurrentSessions = model.MaxConcurrentSessions
+		return nil
+	})
+
+	return policy, err
+}
+
+func (s *UserAuthTokenService) SetSessionPolicy(ctx context.Context, policy *models.SessionPolicy) error {
+	if policy.IdleTimeoutSeconds < 0 || policy.MaxConcurrentSessions < 0 {
+		return ErrInvalidSessionPolicy
+	}
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		var model orgSessionPolicy
+		exists, err := dbSession.Where("org_id = ?", policy.OrgId).Get(&model)
+		if err != nil {
+			return err
+		}
+
+		model.IdleTimeoutSeconds = policy.IdleTimeoutSeconds
+		model.MaxConcurrentSessions = policy.MaxConcurrentSessions
+		model.Updated = getTime()
+		if exists {
+			_, err = dbSession.ID(model.Id).Cols("idle_timeout_seconds", "max_concurrent_sessions", "updated").Update(&model)
+			return err
+		}
+
+		model.OrgId = policy.OrgId
+		model.Created = model.Updated
+		_, err = dbSession.Insert(&model)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// The policies of users are cached by user, not by org.
+	s.policyCache.Flush()
+	s.log.Debug("session policy updated", "orgId", policy.OrgId, "idleTimeoutSeconds", policy.IdleTimeoutSeconds,
+		"maxConcurrentSessions", policy.MaxConcurrentSessions)
+
+	return nil
+}
+
+// userSessionPolicy returns the strictest session policy of the orgs of a
+// user.
+func (s *UserAuthTokenService) userSessionPolicy(ctx context.Context, userId int64) (*models.SessionPolicy, error) {
+	cacheKey := fmt.Sprintf("session-policy-%d", userId)
+	if cached, ok := s.policyCache.Get(cacheKey); ok {
+		return cached.(*models.SessionPolicy), nil
+	}
+
+	var policies []*orgSessionPolicy
+	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		rawSQL := `SELECT org_session_policy.* FROM org_session_policy
+			INNER JOIN org_user ON org_user.org_id = org_session_policy.org_id
+			WHERE org_user.user_id = ?`
+		return dbSession.SQL(rawSQL, userId).Find(&policies)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.SessionPolicy{}
+	for _, policy := range policies {
+		result.IdleTimeoutSeconds = stricterLimit(result.IdleTimeoutSeconds, policy.IdleTimeoutSeconds)
+		result.MaxConcurrentSessions = stricterLimit(result.MaxConcurrentSessions, policy.MaxConcurrentSessions)
+	}
+
+	s.policyCache.Set(cacheKey, result, sessionPolicyCacheTTL)
+	return result, nil
+}
+
+// enforceSessionPolicy expires the token when it has been idle for too long,
+// and revokes it when the user has too many newer sessions.
+func (s *UserAuthTokenService) enforceSessionPolicy(ctx context.Context, model *userAuthToken) error {
+	policy, err := s.userSessionPolicy(ctx, model.UserId)
+	if err != nil {
+		return err
+	}
+
+	now := getTime().Unix()
+	if policy.IdleTimeoutSeconds > 0 && lastSeen(model) <= now-policy.IdleTimeoutSeconds {
+		return &models.TokenExpiredError{
+			UserID:  model.UserId,
+			TokenID: model.Id,
+		}
+	}
+
+	if policy.MaxConcurrentSessions == 0 {
+		return nil
+	}
+
+	var newer int64
+	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		query := dbSession.Where(`user_id = ? AND (created_at > ? OR (created_at = ? AND id > ?))
+			AND created_at > ? AND rotated_at > ? AND revoked_at = 0`,
+			model.UserId, model.CreatedAt, model.CreatedAt, model.Id,
+			s.createdAfterParam(), s.rotatedAfterParam())
+		if policy.IdleTimeoutSeconds > 0 {
+			query = query.And("(seen_at > ? OR rotated_at > ?)", now-policy.IdleTimeoutSeconds, now-policy.IdleTimeoutSeconds)
+		}
+		newer, err = query.Count(&userAuthToken{})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if newer < policy.MaxConcurrentSessions {
+		return nil
+	}
+
+	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		_, err := dbSession.Exec("UPDATE user_auth_token SET revoked_at = ? WHERE id = ?", now, model.Id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("user auth token revoked, too many sessions", "tokenId", model.Id, "userId", model.UserId,
+		"maxConcurrentSessions", policy.MaxConcurrentSessions)
+
+	return &models.TokenRevokedError{
+		UserID:                model.UserId,
+		TokenID:               model.Id,
+		MaxConcurrentSessions: policy.MaxConcurrentSessions,
+	}
+}
+
+// lastSeen returns the last time a token was used. A token is rotated by a
+// request and seen by the first request after it.
+func lastSeen(model *userAuthToken) int64 {
+	if model.SeenAt > model.RotatedAt {
+		return model.SeenAt
+	}
+	return model.RotatedAt
+}
+
+// stricterLimit returns the lowest limit, zero is no limit.
+func stricterLimit(a, b int64) int64 {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
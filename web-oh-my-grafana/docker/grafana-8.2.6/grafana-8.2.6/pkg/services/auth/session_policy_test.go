@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPolicy(t *testing.T) {
+	ctx := createTestContext(t)
+	s := ctx.tokenService
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	origGetTime := getTime
+	t.Cleanup(func() { getTime = origGetTime })
+	getTime = func() time.Time { return now }
+
+	user, err := ctx.sqlstore.CreateUser(context.Background(), models.CreateUserCommand{Login: "alice"})
+	require.NoError(t, err)
+
+	t.Run("policies default to no limits", func(t *testing.T) {
+		policy, err := s.GetSessionPolicy(context.Background(), user.OrgId)
+		require.NoError(t, err)
+		assert.Equal(t, &models.SessionPolicy{OrgId: user.OrgId}, policy)
+
+		err = s.SetSessionPolicy(context.Background(), &models.SessionPolicy{OrgId: user.OrgId, IdleTimeoutSeconds: -1})
+		require.ErrorIs(t, err, ErrInvalidSessionPolicy)
+	})
+
+	t.Run("applies the strictest policy of the orgs of the user", func(t *testing.T) {
+		other, err := ctx.sqlstore.CreateOrgWithMember("other", user.Id)
+		require.NoError(t, err)
+		require.NoError(t, s.SetSessionPolicy(context.Background(), &models.SessionPolicy{
+			OrgId: user.OrgId, IdleTimeoutSeconds: 3600, MaxConcurrentSessions: 2,
+		}))
+		require.NoError(t, s.SetSessionPolicy(context.Background(), &models.SessionPolicy{
+			OrgId: other.Id, IdleTimeoutSeconds: 1800,
+		}))
+
+		policy, err := s.userSessionPolicy(context.Background(), user.Id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1800), policy.IdleTimeoutSeconds)
+		assert.Equal(t, int64(2), policy.MaxConcurrentSessions)
+	})
+
+	t.Run("expires idle tokens", func(t *testing.T) {
+		token, err := s.CreateToken(context.Background(), user, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+
+		getTime = func() time.Time { return now.Add(29 * time.Minute) }
+		_, err = s.LookupToken(context.Background(), token.UnhashedToken)
+		require.NoError(t, err)
+
+		// Rotated more often than the rotation interval to record the use.
+		getTime = func() time.Time { return now.Add(59 * time.Minute) }
+		rotated, err := s.TryRotateToken(context.Background(), token, net.ParseIP("192.168.10.11"), "some user agent")
+		require.NoError(t, err)
+		require.True(t, rotated)
+		_, err = s.LookupToken(context.Background(), token.UnhashedToken)
+		require.NoError(t, err)
+
+		getTime = func() time.Time { return now.Add(90 * time.Minute) }
+		_, err = s.LookupToken(context.Background(), token.UnhashedToken)
+		var expiredErr *models.TokenExpiredError
+		require.True(t, errors.As(err, &expiredErr))
+		getTime = func() time.Time { return now }
+	})
+
+	t.Run("revokes the oldest sessions", func(t *testing.T) {
+		require.NoError(t, s.RevokeAllUserTokens(context.Background(), user.Id))
+
+		var tokens []*models.UserToken
+		for i := 0; i < 3; i++ {
+			getTime = func() time.Time { return now.Add(time.Duration(i) * time.Second) }
+			token, err := s.CreateToken(context.Background(), user, net.ParseIP("192.168.10.11"), "some user agent")
+			require.NoError(t, err)
+			tokens = append(tokens, token)
+		}
+
+		_, err := s.LookupToken(context.Background(), tokens[0].UnhashedToken)
+		var revokedErr *models.TokenRevokedError
+		require.True(t, errors.As(err, &revokedErr))
+		assert.Equal(t, int64(2), revokedErr.MaxConcurrentSessions)
+
+		for _, token := range tokens[1:] {
+			_, err := s.LookupToken(context.Background(), token.UnhashedToken)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestSearchAndRevokeTokens(t *testing.T) {
+	ctx := createTestContext(t)
+	s := ctx.tokenService
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	origGetTime := getTime
+	t.Cleanup(func() { getTime = origGetTime })
+
+	alice, err := ctx.sqlstore.CreateUser(context.Background(), models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	bob, err := ctx.sqlstore.CreateUser(context.Background(), models.CreateUserCommand{Login: "bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+
+	createToken := func(user *models.User, ip, userAgent string, createdAt time.Time) *models.UserToken {
+		getTime = func() time.Time { return createdAt }
+		token, err := s.CreateToken(context.Background(), user, net.ParseIP(ip), userAgent)
+		require.NoError(t, err)
+		return token
+	}
+	firefox := createToken(alice, "10.0.0.1", "Mozilla/5.0 (X11; Linux x86_64; rv:92.0) Gecko/20100101 Firefox/92.0", now.Add(-2*time.Hour))
+	chrome := createToken(alice, "10.0.0.2", "Mozilla/5.0 (X11; Linux x86_64) Chrome/94.0.4606.71 Safari/537.36", now.Add(-time.Hour))
+	bobs := createToken(bob, "10.0.0.1", "curl/7.68.0", now)
+	getTime = func() time.Time { return now }
+
+	search := func(filter models.UserTokenFilter) []int64 {
+		t.Helper()
+		result, err := s.SearchTokens(context.Background(), &models.SearchUserTokensQuery{Filter: filter})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(result.Tokens)), result.TotalCount)
+		ids := []int64{}
+		for _, token := range result.Tokens {
+			ids = append(ids, token.Id)
+		}
+		return ids
+	}
+
+	t.Run("lists the sessions of all users, last seen first", func(t *testing.T) {
+		result, err := s.SearchTokens(context.Background(), &models.SearchUserTokensQuery{})
+		require.NoError(t, err)
+		require.Len(t, result.Tokens, 3)
+		assert.Equal(t, bobs.Id, result.Tokens[0].Id)
+		assert.Equal(t, "bob", result.Tokens[0].Login)
+		assert.Equal(t, "bob@example.com", result.Tokens[0].Email)
+
+		result, err = s.SearchTokens(context.Background(), &models.SearchUserTokensQuery{Page: 2, PerPage: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Tokens, 1)
+		assert.Equal(t, firefox.Id, result.Tokens[0].Id)
+	})
+
+	t.Run("filters the sessions", func(t *testing.T) {
+		assert.Equal(t, []int64{bobs.Id, firefox.Id}, search(models.UserTokenFilter{ClientIp: "10.0.0.1"}))
+		assert.Equal(t, []int64{chrome.Id}, search(models.UserTokenFilter{UserAgent: "Chrome"}))
+		assert.Equal(t, []int64{chrome.Id, firefox.Id}, search(models.UserTokenFilter{UserId: alice.Id}))
+		assert.Equal(t, []int64{chrome.Id, firefox.Id}, search(models.UserTokenFilter{
+			SeenTo: now.Add(-time.Minute).UnixNano() / int64(time.Millisecond),
+		}))
+		assert.Equal(t, []int64{bobs.Id, chrome.Id}, search(models.UserTokenFilter{
+			SeenFrom: now.Add(-90*time.Minute).UnixNano() / int64(time.Millisecond),
+		}))
+	})
+
+	t.Run("revokes the filtered sessions", func(t *testing.T) {
+		count, err := s.RevokeTokens(context.Background(), &models.UserTokenFilter{ClientIp: "10.0.0.1", ExcludeTokenId: bobs.Id})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.Equal(t, []int64{bobs.Id, chrome.Id}, search(models.UserTokenFilter{}))
+	})
+}
@@ -18,6 +18,10 @@ type FakeUserAuthTokenService struct {
 	GetUserTokensProvider        func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	GetUserRevokedTokensProvider func(ctx context.Context, userId int64) ([]*models.UserToken, error)
 	BatchRevokedTokenProvider    func(ctx context.Context, userIds []int64) error
+	SearchTokensProvider         func(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error)
+	RevokeTokensProvider         func(ctx context.Context, filter *models.UserTokenFilter) (int64, error)
+	GetSessionPolicyProvider     func(ctx context.Context, orgId int64) (*models.SessionPolicy, error)
+	SetSessionPolicyProvider     func(ctx context.Context, policy *models.SessionPolicy) error
 }
 
 func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
@@ -55,6 +59,18 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		GetUserTokensProvider: func(ctx context.Context, userId int64) ([]*models.UserToken, error) {
 			return nil, nil
 		},
+		SearchTokensProvider: func(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+			return &models.SearchUserTokensResult{}, nil
+		},
+		RevokeTokensProvider: func(ctx context.Context, filter *models.UserTokenFilter) (int64, error) {
+			return 0, nil
+		},
+		GetSessionPolicyProvider: func(ctx context.Context, orgId int64) (*models.SessionPolicy, error) {
+			return &models.SessionPolicy{OrgId: orgId}, nil
+		},
+		SetSessionPolicyProvider: func(ctx context.Context, policy *models.SessionPolicy) error {
+			return nil
+		},
 	}
 }
 
@@ -104,3 +120,19 @@ func (s *FakeUserAuthTokenService) GetUserRevokedTokens(ctx context.Context, use
 func (s *FakeUserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.BatchRevokedTokenProvider(ctx, userIds)
 }
+
+func (s *FakeUserAuthTokenService) SearchTokens(ctx context.Context, query *models.SearchUserTokensQuery) (*models.SearchUserTokensResult, error) {
+	return s.SearchTokensProvider(ctx, query)
+}
+
+func (s *FakeUserAuthTokenService) RevokeTokens(ctx context.Context, filter *models.UserTokenFilter) (int64, error) {
+	return s.RevokeTokensProvider(ctx, filter)
+}
+
+func (s *FakeUserAuthTokenService) GetSessionPolicy(ctx context.Context, orgId int64) (*models.SessionPolicy, error) {
+	return s.GetSessionPolicyProvider(ctx, orgId)
+}
+
+func (s *FakeUserAuthTokenService) SetSessionPolicy(ctx context.Context, policy *models.SessionPolicy) error {
+	return s.SetSessionPolicyProvider(ctx, policy)
+}
@@ -1,21 +1,49 @@
 package contexthandler
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/login"
+	"github.com/grafana/grafana/pkg/middleware/cookies"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/jmespath/go-jmespath"
 )
 
 const InvalidJWT = "Invalid JWT"
 
+// jwtURLParam is the query parameter holding the token when URL login is
+// enabled.
+const jwtURLParam = "auth_token"
+
+// jwtUserSyncTTL is how long a JWT user with the same claims is not synced
+// again.
+const jwtUserSyncTTL = 5 * time.Minute
+
+// jwtTeamSyncTTL is how long the teams of a JWT user with the same groups
+// are not synced again.
+const jwtTeamSyncTTL = 5 * time.Minute
+
 func (h *ContextHandler) initContextWithJWT(ctx *models.ReqContext, orgId int64) bool {
 	if !h.Cfg.JWTAuthEnabled || h.Cfg.JWTAuthHeaderName == "" {
 		return false
 	}
 
 	jwtToken := ctx.Req.Header.Get(h.Cfg.JWTAuthHeaderName)
+	fromURL := false
+	if jwtToken == "" && h.Cfg.JWTAuthURLLogin {
+		jwtToken = ctx.Req.URL.Query().Get(jwtURLParam)
+		fromURL = true
+	}
 	if jwtToken == "" {
 		return false
 	}
@@ -42,6 +70,21 @@ func (h *ContextHandler) initContextWithJWT(ctx *models.ReqContext, orgId int64)
 		return true
 	}
 
+	if h.jwtUserSyncEnabled() {
+		userID, err := h.syncJWTUser(ctx, h.jwtExternalUser(ctx, claims, query.Login, query.Email))
+		if err != nil {
+			if errors.Is(err, login.ErrInvalidCredentials) {
+				ctx.Logger.Debug("Failed to sync user using JWT claims",
+					"email_claim", query.Email, "username_claim", query.Login, "error", err)
+			} else {
+				ctx.Logger.Error("Failed to sync user using JWT claims", "error", err)
+			}
+			ctx.JsonApiErr(401, InvalidJWT, err)
+			return true
+		}
+		query = models.GetSignedInUserQuery{UserId: userID, OrgId: orgId}
+	}
+
 	if err := bus.DispatchCtx(ctx.Req.Context(), &query); err != nil {
 		if errors.Is(err, models.ErrUserNotFound) {
 			ctx.Logger.Debug(
@@ -60,5 +103,190 @@ func (h *ContextHandler) initContextWithJWT(ctx *models.ReqContext, orgId int64)
 	ctx.SignedInUser = query.Result
 	ctx.IsSignedIn = true
 
+	// The teams of synced users are synced with the user, from their groups.
+	if key := h.Cfg.JWTAuthGroupsClaim; key != "" && !h.jwtUserSyncEnabled() {
+		h.syncJWTTeams(ctx, claimStrings(claims[key]))
+	}
+
+	// The requests of the page embedded with the token in its URL don't have
+	// the token, they are authenticated with a session.
+	if fromURL {
+		h.createJWTSession(ctx)
+	}
+
 	return true
 }
+
+// jwtUserSyncEnabled returns whether the claims of the tokens create or update
+// the users rather than only identifying them.
+func (h *ContextHandler) jwtUserSyncEnabled() bool {
+	return h.Cfg.JWTAuthAutoSignUp || h.Cfg.JWTAuthRoleAttributePath != "" || len(h.Cfg.JWTAuthOrgMapping) > 0
+}
+
+// jwtExternalUser maps the claims of a token to the user, their org roles and
+// groups.
+func (h *ContextHandler) jwtExternalUser(ctx *models.ReqContext, claims models.JWTClaims, login, email string) *models.ExternalUserInfo {
+	extUser := &models.ExternalUserInfo{
+		AuthModule: models.AuthModuleJWT,
+		Login:      login,
+		Email:      email,
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+	if extUser.Login == "" {
+		extUser.Login = email
+	}
+	extUser.AuthId, _ = claims["sub"].(string)
+	if extUser.AuthId == "" {
+		extUser.AuthId = extUser.Login
+	}
+	if key := h.Cfg.JWTAuthNameClaim; key != "" {
+		extUser.Name, _ = claims[key].(string)
+	}
+	if key := h.Cfg.JWTAuthGroupsClaim; key != "" {
+		extUser.Groups = claimStrings(claims[key])
+	}
+
+	role := models.RoleType(setting.AutoAssignOrgRole)
+	if path := h.Cfg.JWTAuthRoleAttributePath; path != "" {
+		value, err := jmespath.Search(path, map[string]interface{}(claims))
+		if err != nil {
+			ctx.Logger.Warn("Failed to search JWT claims for the role", "path", path, "error", err)
+		}
+		if claimRole, _ := value.(string); models.RoleType(claimRole).IsValid() {
+			role = models.RoleType(claimRole)
+		}
+	}
+
+	switch {
+	case len(h.Cfg.JWTAuthOrgMapping) > 0:
+		var orgs []string
+		if path := h.Cfg.JWTAuthOrgAttributePath; path != "" {
+			value, err := jmespath.Search(path, map[string]interface{}(claims))
+			if err != nil {
+				ctx.Logger.Warn("Failed to search JWT claims for the orgs", "path", path, "error", err)
+			}
+			orgs = claimStrings(value)
+		}
+		for _, org := range orgs {
+			for _, orgID := range h.Cfg.JWTAuthOrgMapping[org] {
+				extUser.OrgRoles[orgID] = role
+			}
+		}
+	case h.Cfg.JWTAuthRoleAttributePath != "":
+		// The same organization the role of an OAuth user is assigned in.
+		orgID := int64(1)
+		if setting.AutoAssignOrg && setting.AutoAssignOrgId > 0 {
+			orgID = int64(setting.AutoAssignOrgId)
+		}
+		extUser.OrgRoles[orgID] = role
+	}
+
+	return extUser
+}
+
+// syncJWTUser creates or updates the user of the claims and returns their ID.
+// Every request is authenticated with the token, so the user is not synced
+// again while the claims don't change.
+func (h *ContextHandler) syncJWTUser(ctx *models.ReqContext, extUser *models.ExternalUserInfo) (int64, error) {
+	data, err := json.Marshal(extUser)
+	if err != nil {
+		return 0, err
+	}
+	cacheKey := fmt.Sprintf("jwt-user-sync-%x", sha256.Sum256(data))
+
+	if userID, err := h.RemoteCache.Get(cacheKey); err == nil {
+		if id, ok := userID.(int64); ok {
+			// The user may have been disabled since they were synced.
+			query := &models.GetUserByIdQuery{Id: id}
+			if err := bus.DispatchCtx(ctx.Req.Context(), query); err != nil {
+				return 0, err
+			}
+			if query.Result.IsDisabled {
+				return 0, login.ErrInvalidCredentials
+			}
+			return id, nil
+		}
+	}
+
+	cmd := &models.UpsertUserCommand{
+		ReqContext:    ctx,
+		ExternalUser:  extUser,
+		SignupAllowed: h.Cfg.JWTAuthAutoSignUp,
+	}
+	if err := bus.DispatchCtx(ctx.Req.Context(), cmd); err != nil {
+		return 0, err
+	}
+	// Do not expose disabled status, as for the other external users.
+	if cmd.Result.IsDisabled {
+		return 0, login.ErrInvalidCredentials
+	}
+
+	if err := h.RemoteCache.Set(cacheKey, cmd.Result.Id, jwtUserSyncTTL); err != nil {
+		ctx.Logger.Warn("Failed to cache the sync of the JWT user", "error", err)
+	}
+	return cmd.Result.Id, nil
+}
+
+// syncJWTTeams syncs the teams of the user with the groups of their token when
+// the user itself isn't synced. Every request is authenticated with the token,
+// so the sync is skipped while the groups of the user don't change.
+func (h *ContextHandler) syncJWTTeams(ctx *models.ReqContext, groups []string) {
+	sorted := append([]string{}, groups...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	cacheKey := fmt.Sprintf("jwt-team-sync-%d-%x", ctx.UserId, sum)
+
+	if _, err := h.RemoteCache.Get(cacheKey); err == nil {
+		return
+	}
+
+	user := &models.User{Id: ctx.UserId, Login: ctx.Login}
+	extUser := &models.ExternalUserInfo{AuthModule: models.AuthModuleJWT, Login: ctx.Login, Groups: groups}
+	if err := h.TeamSyncService.SyncTeams(user, extUser); err != nil {
+		ctx.Logger.Error("Failed to sync the teams of the JWT user", "error", err)
+		return
+	}
+
+	if err := h.RemoteCache.Set(cacheKey, true, jwtTeamSyncTTL); err != nil {
+		ctx.Logger.Warn("Failed to cache the team sync of the JWT user", "error", err)
+	}
+}
+
+// createJWTSession signs in the user of a token from the URL with a session.
+func (h *ContextHandler) createJWTSession(ctx *models.ReqContext) {
+	addr := ctx.RemoteAddr()
+	ip, err := network.GetIPFromAddress(addr)
+	if err != nil {
+		ctx.Logger.Debug("Failed to get client IP address", "addr", addr, "err", err)
+		ip = nil
+	}
+
+	reqCtx := context.WithValue(ctx.Req.Context(), models.RequestURIKey{}, ctx.Req.RequestURI)
+	user := &models.User{Id: ctx.UserId, Login: ctx.Login, OrgId: ctx.OrgId}
+	token, err := h.AuthTokenService.CreateToken(reqCtx, user, ip, ctx.Req.UserAgent())
+	if err != nil {
+		ctx.Logger.Error("Failed to create a session for the JWT user", "error", err)
+		return
+	}
+	ctx.UserToken = token
+	cookies.WriteSessionCookie(ctx, h.Cfg, token.UnhashedToken, h.Cfg.LoginMaxLifetime)
+}
+
+// claimStrings returns the values of a claim holding a string or an array of
+// strings.
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
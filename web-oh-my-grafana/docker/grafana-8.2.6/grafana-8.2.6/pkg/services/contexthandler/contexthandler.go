@@ -17,8 +17,11 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/contexthandler/authproxy"
 	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/passwordpolicy"
 	"github.com/grafana/grafana/pkg/services/rendering"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/teamsync"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/opentracing/opentracing-go"
@@ -34,26 +37,38 @@ const (
 
 const ServiceName = "ContextHandler"
 
+// apiKeyLastUsedInterval limits how often the last use of an API key is
+// written to the database.
+const apiKeyLastUsedInterval = time.Minute
+
 func ProvideService(cfg *setting.Cfg, tokenService models.UserTokenService, jwtService models.JWTService,
-	remoteCache *remotecache.RemoteCache, renderService rendering.Service, sqlStore *sqlstore.SQLStore) *ContextHandler {
+	remoteCache *remotecache.RemoteCache, renderService rendering.Service, sqlStore *sqlstore.SQLStore,
+	twoFactorService twofactor.Service, teamSyncService teamsync.Service,
+	passwordPolicyService passwordpolicy.Service) *ContextHandler {
 	return &ContextHandler{
-		Cfg:              cfg,
-		AuthTokenService: tokenService,
-		JWTAuthService:   jwtService,
-		RemoteCache:      remoteCache,
-		RenderService:    renderService,
-		SQLStore:         sqlStore,
+		Cfg:                   cfg,
+		AuthTokenService:      tokenService,
+		JWTAuthService:        jwtService,
+		RemoteCache:           remoteCache,
+		RenderService:         renderService,
+		SQLStore:              sqlStore,
+		TwoFactorService:      twoFactorService,
+		TeamSyncService:       teamSyncService,
+		PasswordPolicyService: passwordPolicyService,
 	}
 }
 
 // ContextHandler is a middleware.
 type ContextHandler struct {
-	Cfg              *setting.Cfg
-	AuthTokenService models.UserTokenService
-	JWTAuthService   models.JWTService
-	RemoteCache      *remotecache.RemoteCache
-	RenderService    rendering.Service
-	SQLStore         *sqlstore.SQLStore
+	Cfg                   *setting.Cfg
+	AuthTokenService      models.UserTokenService
+	JWTAuthService        models.JWTService
+	RemoteCache           *remotecache.RemoteCache
+	RenderService         rendering.Service
+	SQLStore              *sqlstore.SQLStore
+	TwoFactorService      twofactor.Service
+	TeamSyncService       teamsync.Service
+	PasswordPolicyService passwordpolicy.Service
 
 	// GetTime returns the current time.
 	// Stubbable by tests.
@@ -218,6 +233,20 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 		return true
 	}
 
+	if apikey.LastUsedAt == nil || getTime().Sub(*apikey.LastUsedAt) > apiKeyLastUsedInterval {
+		lastUsedAt := getTime()
+		if err := bus.DispatchCtx(reqContext.Req.Context(), &models.UpdateApiKeyLastUsedCommand{
+			Id:         apikey.Id,
+			LastUsedAt: lastUsedAt,
+		}); err != nil {
+			reqContext.Logger.Warn("Failed to update last use of API key", "id", apikey.Id, "err", err)
+		}
+	}
+
+	if apikey.ServiceAccountId != nil {
+		return h.initContextWithServiceAccount(reqContext, apikey)
+	}
+
 	reqContext.IsSignedIn = true
 	reqContext.SignedInUser = &models.SignedInUser{}
 	reqContext.OrgRole = apikey.Role
@@ -226,6 +255,31 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 	return true
 }
 
+// initContextWithServiceAccount signs in the service account owning the
+// token, so that the request is made in its name and with its permissions.
+func (h *ContextHandler) initContextWithServiceAccount(reqContext *models.ReqContext, apikey *models.ApiKey) bool {
+	userQuery := models.GetUserByIdQuery{Id: *apikey.ServiceAccountId}
+	if err := bus.DispatchCtx(reqContext.Req.Context(), &userQuery); err != nil {
+		reqContext.JsonApiErr(401, InvalidAPIKey, err)
+		return true
+	}
+	if userQuery.Result.IsDisabled {
+		reqContext.JsonApiErr(401, "Service account is disabled", nil)
+		return true
+	}
+
+	query := models.GetSignedInUserQuery{UserId: *apikey.ServiceAccountId, OrgId: apikey.OrgId}
+	if err := bus.DispatchCtx(reqContext.Req.Context(), &query); err != nil {
+		reqContext.JsonApiErr(401, InvalidAPIKey, err)
+		return true
+	}
+
+	reqContext.IsSignedIn = true
+	reqContext.SignedInUser = query.Result
+	reqContext.ApiKeyId = apikey.Id
+	return true
+}
+
 func (h *ContextHandler) initContextWithBasicAuth(reqContext *models.ReqContext, orgID int64) bool {
 	if !h.Cfg.BasicAuthEnabled {
 		return false
@@ -266,6 +320,31 @@ func (h *ContextHandler) initContextWithBasicAuth(reqContext *models.ReqContext,
 
 	user := authQuery.User
 
+	// basic auth can't ask for a second factor, so users who have to use
+	// one can only log in through the login page
+	if authQuery.AuthModule == "grafana" {
+		status, err := h.TwoFactorService.GetStatus(ctx, user.Id)
+		if err != nil {
+			reqContext.JsonApiErr(500, "Failed to get two-factor authentication status", err)
+			return true
+		}
+		if status.Enabled || status.Required {
+			reqContext.JsonApiErr(401, "Basic auth is not allowed for users with two-factor authentication", nil)
+			return true
+		}
+
+		// nor for a new password, so expired passwords can't be used
+		required, err := h.PasswordPolicyService.ChangeRequired(ctx, user)
+		if err != nil {
+			reqContext.JsonApiErr(500, "Failed to check the password policy", err)
+			return true
+		}
+		if required {
+			reqContext.JsonApiErr(401, "Password change required, log in with the login page to change it", nil)
+			return true
+		}
+	}
+
 	query := models.GetSignedInUserQuery{UserId: user.Id, OrgId: orgID}
 	if err := bus.DispatchCtx(ctx, &query); err != nil {
 		reqContext.Logger.Error(
@@ -16,7 +16,7 @@ func TestDashboardSnapshotsService(t *testing.T) {
 
 	s := &Service{
 		SQLStore:          sqlStore,
-		EncryptionService: ossencryption.ProvideService(),
+		EncryptionService: &ossencryption.Service{},
 	}
 
 	origSecret := setting.SecretKey
@@ -0,0 +1,278 @@
+package dashboardusage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	dayLayout = "2006-01-02"
+
+	// viewsBufferSize is the number of views that can be waiting to be
+	// written to the database, views are dropped when the buffer is full.
+	viewsBufferSize = 10000
+	flushInterval   = 10 * time.Second
+	maxWriteRetries = 3
+)
+
+var getTime = time.Now
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, searchService *search.SearchService) *DashboardUsageService {
+	s := &DashboardUsageService{
+		Cfg:      cfg,
+		SQLStore: sqlStore,
+		log:      log.New("dashboard-usage"),
+		views:    make(chan dashboardView, viewsBufferSize),
+	}
+
+	searchService.RegisterSortOption(SortViewsDesc)
+	searchService.RegisterSortOption(SortViewsAsc)
+	searchService.RegisterSortOption(SortLastViewedDesc)
+
+	return s
+}
+
+type Service interface {
+	RecordView(ctx context.Context, orgID, dashboardID, userID int64) error
+	GetStaleDashboards(ctx context.Context, query *models.GetStaleDashboardsQuery) error
+}
+
+type DashboardUsageService struct {
+	Cfg      *setting.Cfg
+	SQLStore *sqlstore.SQLStore
+
+	log   log.Logger
+	views chan dashboardView
+}
+
+type dashboardView struct {
+	orgID       int64
+	dashboardID int64
+	userID      int64
+	viewedAt    time.Time
+}
+
+type userViewsKey struct {
+	dashboardID int64
+	userID      int64
+}
+
+type userViews struct {
+	orgID        int64
+	views        int64
+	lastViewedAt int64
+}
+
+type dayViewsKey struct {
+	dashboardID int64
+	day         string
+}
+
+type dayViews struct {
+	orgID int64
+	views int64
+}
+
+// viewsBatch aggregates the views waiting to be written, so that a
+// dashboard viewed many times between two flushes costs a single update.
+type viewsBatch struct {
+	byUser map[userViewsKey]*userViews
+	byDay  map[dayViewsKey]*dayViews
+}
+
+func newViewsBatch() *viewsBatch {
+	return &viewsBatch{
+		byUser: map[userViewsKey]*userViews{},
+		byDay:  map[dayViewsKey]*dayViews{},
+	}
+}
+
+func (b *viewsBatch) add(v dashboardView) {
+	uk := userViewsKey{dashboardID: v.dashboardID, userID: v.userID}
+	u, ok := b.byUser[uk]
+	if !ok {
+		u = &userViews{orgID: v.orgID}
+		b.byUser[uk] = u
+	}
+	u.views++
+	if ts := v.viewedAt.Unix(); ts > u.lastViewedAt {
+		u.lastViewedAt = ts
+	}
+
+	dk := dayViewsKey{dashboardID: v.dashboardID, day: v.viewedAt.UTC().Format(dayLayout)}
+	d, ok := b.byDay[dk]
+	if !ok {
+		d = &dayViews{orgID: v.orgID}
+		b.byDay[dk] = d
+	}
+	d.views++
+}
+
+func (b *viewsBatch) empty() bool {
+	return len(b.byUser) == 0 && len(b.byDay) == 0
+}
+
+// RecordView registers that the user viewed the dashboard. The view is
+// queued and written by Run, so that viewing a dashboard never waits on
+// the database.
+func (s *DashboardUsageService) RecordView(ctx context.Context, orgID, dashboardID, userID int64) error {
+	if !s.Cfg.DashboardUsageTrackingEnabled || dashboardID == 0 {
+		return nil
+	}
+
+	select {
+	case s.views <- dashboardView{orgID: orgID, dashboardID: dashboardID, userID: userID, viewedAt: getTime()}:
+		return nil
+	default:
+		return errors.New("dashboard views buffer is full")
+	}
+}
+
+// Run writes the queued views to the database, both in the per user
+// counters and in the daily aggregate for the dashboard.
+func (s *DashboardUsageService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				s.log.Error("Failed to write dashboard views", "error", err)
+			}
+		case <-ctx.Done():
+			// write the views that are still queued before shutting down
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.flush(flushCtx); err != nil {
+				s.log.Error("Failed to write dashboard views", "error", err)
+			}
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// flush drains the queued views and writes them to the database.
+func (s *DashboardUsageService) flush(ctx context.Context) error {
+	batch := newViewsBatch()
+	for drained := false; !drained; {
+		select {
+		case v := <-s.views:
+			batch.add(v)
+		default:
+			drained = true
+		}
+	}
+	if batch.empty() {
+		return nil
+	}
+
+	// Another Grafana instance sharing the database can insert the same
+	// counter in between our update and insert, in which case the unique
+	// index rejects the insert. The transaction is then run again so that
+	// the update finds the row.
+	for retry := 0; ; retry++ {
+		err := s.writeBatch(ctx, batch)
+		if err == nil || retry >= maxWriteRetries || !s.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+			return err
+		}
+	}
+}
+
+func (s *DashboardUsageService) writeBatch(ctx context.Context, batch *viewsBatch) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		for k, u := range batch.byUser {
+			res, err := sess.Exec("UPDATE dashboard_usage_by_user SET views = views + ?, last_viewed_at = ? WHERE dashboard_id = ? AND user_id = ?",
+				u.views, u.lastViewedAt, k.dashboardID, k.userID)
+			if err := insertIfNotUpdated(sess, res, err, &models.DashboardUsageByUser{
+				OrgId:        u.orgID,
+				DashboardId:  k.dashboardID,
+				UserId:       k.userID,
+				Views:        u.views,
+				LastViewedAt: u.lastViewedAt,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for k, d := range batch.byDay {
+			res, err := sess.Exec("UPDATE dashboard_usage_by_day SET views = views + ? WHERE dashboard_id = ? AND day = ?",
+				d.views, k.dashboardID, k.day)
+			if err := insertIfNotUpdated(sess, res, err, &models.DashboardUsageByDay{
+				OrgId:       d.orgID,
+				DashboardId: k.dashboardID,
+				Day:         k.day,
+				Views:       d.views,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// insertIfNotUpdated inserts row when the update that returned res did not
+// match any row.
+func insertIfNotUpdated(sess *sqlstore.DBSession, res sql.Result, err error, row interface{}) error {
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+	_, err = sess.Insert(row)
+	return err
+}
+
+// GetStaleDashboards returns the dashboards of an organization that have not
+// been viewed since query.OlderThan. Dashboards created after that point in
+// time are never considered stale.
+func (s *DashboardUsageService) GetStaleDashboards(ctx context.Context, query *models.GetStaleDashboardsQuery) error {
+	return s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		limit := query.Limit
+		if limit < 1 {
+			limit = 1000
+		}
+
+		rawSQL := `SELECT
+			dashboard.id,
+			dashboard.uid,
+			dashboard.title,
+			dashboard.slug,
+			dashboard.folder_id,
+			dashboard.created,
+			COALESCE(SUM(dashboard_usage_by_user.views), 0) AS views,
+			COALESCE(MAX(dashboard_usage_by_user.last_viewed_at), 0) AS last_viewed_at
+		FROM dashboard
+		LEFT OUTER JOIN dashboard_usage_by_user ON dashboard_usage_by_user.dashboard_id = dashboard.id
+		WHERE dashboard.org_id = ? AND dashboard.is_folder = ` + s.SQLStore.Dialect.BooleanStr(false) + ` AND dashboard.created < ?
+		GROUP BY dashboard.id, dashboard.uid, dashboard.title, dashboard.slug, dashboard.folder_id, dashboard.created
+		HAVING COALESCE(MAX(dashboard_usage_by_user.last_viewed_at), 0) < ?
+		ORDER BY last_viewed_at ASC, dashboard.title ASC ` + s.SQLStore.Dialect.Limit(int64(limit))
+
+		result := make([]*models.StaleDashboard, 0)
+		if err := sess.SQL(rawSQL, query.OrgId, query.OlderThan, query.OlderThan.Unix()).Find(&result); err != nil {
+			return err
+		}
+
+		for _, dash := range result {
+			dash.Url = models.GetDashboardUrl(dash.Uid, dash.Slug)
+		}
+
+		query.Result = result
+		return nil
+	})
+}
+
+var _ Service = &DashboardUsageService{}
+var _ registry.BackgroundService = &DashboardUsageService{}
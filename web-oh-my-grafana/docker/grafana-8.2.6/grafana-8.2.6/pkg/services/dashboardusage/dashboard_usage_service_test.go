@@ -0,0 +1,170 @@
+package dashboardusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardUsageService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.DashboardUsageTrackingEnabled = true
+	service := ProvideService(cfg, sqlStore, search.ProvideService(cfg, bus.New()))
+
+	popular := insertTestDashboard(t, sqlStore, "Popular")
+	unpopular := insertTestDashboard(t, sqlStore, "Unpopular")
+	unseen := insertTestDashboard(t, sqlStore, "Unseen")
+
+	now := time.Now()
+	origGetTime := getTime
+	t.Cleanup(func() {
+		getTime = origGetTime
+	})
+	getTime = func() time.Time {
+		return now.AddDate(0, 0, 2)
+	}
+
+	for _, userID := range []int64{1, 1, 2} {
+		err := service.RecordView(context.Background(), 1, popular.Id, userID)
+		require.NoError(t, err)
+	}
+	err := service.RecordView(context.Background(), 1, unpopular.Id, 1)
+	require.NoError(t, err)
+	err = service.flush(context.Background())
+	require.NoError(t, err)
+
+	t.Run("Views are recorded per user and per day", func(t *testing.T) {
+		var byUser []models.DashboardUsageByUser
+		err := sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Where("dashboard_id = ?", popular.Id).Asc("user_id").Find(&byUser)
+		})
+		require.NoError(t, err)
+		require.Len(t, byUser, 2)
+		require.Equal(t, int64(2), byUser[0].Views)
+		require.Equal(t, int64(1), byUser[1].Views)
+		require.Equal(t, getTime().Unix(), byUser[0].LastViewedAt)
+
+		var byDay []models.DashboardUsageByDay
+		err = sqlStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			return sess.Where("dashboard_id = ?", popular.Id).Find(&byDay)
+		})
+		require.NoError(t, err)
+		require.Len(t, byDay, 1)
+		require.Equal(t, int64(3), byDay[0].Views)
+		require.Equal(t, getTime().UTC().Format(dayLayout), byDay[0].Day)
+	})
+
+	t.Run("Views are not recorded when tracking is disabled", func(t *testing.T) {
+		cfg.DashboardUsageTrackingEnabled = false
+		t.Cleanup(func() {
+			cfg.DashboardUsageTrackingEnabled = true
+		})
+
+		err := service.RecordView(context.Background(), 1, unseen.Id, 1)
+		require.NoError(t, err)
+		err = service.flush(context.Background())
+		require.NoError(t, err)
+
+		query := models.GetStaleDashboardsQuery{OrgId: 1, OlderThan: now.AddDate(0, 0, 1)}
+		err = service.GetStaleDashboards(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 1)
+		require.Equal(t, unseen.Id, query.Result[0].Id)
+	})
+
+	t.Run("Views are dropped when the buffer is full", func(t *testing.T) {
+		origViews := service.views
+		t.Cleanup(func() {
+			service.views = origViews
+		})
+		service.views = make(chan dashboardView, 1)
+
+		err := service.RecordView(context.Background(), 1, unseen.Id, 1)
+		require.NoError(t, err)
+		err = service.RecordView(context.Background(), 1, unseen.Id, 1)
+		require.Error(t, err)
+
+		// drain the buffer without writing, the view must not count below
+		<-service.views
+	})
+
+	t.Run("Stale dashboards excludes recently viewed and recently created dashboards", func(t *testing.T) {
+		query := models.GetStaleDashboardsQuery{OrgId: 1, OlderThan: now.AddDate(0, 0, 3)}
+		err := service.GetStaleDashboards(context.Background(), &query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 3)
+		require.Equal(t, unseen.Id, query.Result[0].Id)
+		require.Equal(t, int64(0), query.Result[0].LastViewedAt)
+		require.Equal(t, popular.Id, query.Result[1].Id)
+		require.Equal(t, int64(3), query.Result[1].Views)
+
+		query = models.GetStaleDashboardsQuery{OrgId: 1, OlderThan: now.AddDate(0, 0, -1)}
+		err = service.GetStaleDashboards(context.Background(), &query)
+		require.NoError(t, err)
+		require.Empty(t, query.Result)
+	})
+
+	t.Run("Search can be sorted by views and last viewed", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+		query := &search.FindPersistedDashboardsQuery{SignedInUser: user, Sort: SortViewsDesc}
+		err := sqlstore.SearchDashboards(query)
+		require.NoError(t, err)
+		require.Len(t, query.Result, 3)
+		require.Equal(t, []string{"Popular", "Unpopular", "Unseen"}, hitTitles(query.Result))
+		require.Equal(t, int64(3), query.Result[0].SortMeta)
+		require.Equal(t, "views", query.Result[0].SortMetaName)
+
+		query = &search.FindPersistedDashboardsQuery{SignedInUser: user, Sort: SortViewsAsc}
+		err = sqlstore.SearchDashboards(query)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Unseen", "Unpopular", "Popular"}, hitTitles(query.Result))
+
+		err = service.RecordView(context.Background(), 1, unseen.Id, 1)
+		require.NoError(t, err)
+		getTime = func() time.Time {
+			return now.AddDate(0, 0, 1)
+		}
+		err = service.RecordView(context.Background(), 1, unpopular.Id, 1)
+		require.NoError(t, err)
+		err = service.flush(context.Background())
+		require.NoError(t, err)
+
+		query = &search.FindPersistedDashboardsQuery{SignedInUser: user, Sort: SortLastViewedDesc}
+		err = sqlstore.SearchDashboards(query)
+		require.NoError(t, err)
+		require.Equal(t, now.AddDate(0, 0, 2).Unix(), query.Result[0].SortMeta)
+		require.Equal(t, []string{"Popular", "Unseen", "Unpopular"}, hitTitles(query.Result))
+	})
+}
+
+func hitTitles(hits search.HitList) []string {
+	titles := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		titles = append(titles, hit.Title)
+	}
+	return titles
+}
+
+func insertTestDashboard(t *testing.T, sqlStore *sqlstore.SQLStore, title string) *models.Dashboard {
+	t.Helper()
+
+	dash, err := sqlStore.SaveDashboard(models.SaveDashboardCommand{
+		OrgId: 1,
+		Dashboard: simplejson.NewFromAny(map[string]interface{}{
+			"title": title,
+		}),
+	})
+	require.NoError(t, err)
+
+	return dash
+}
@@ -1,6 +1,25 @@
 package encryption
 
+// EnvelopePrefix starts payloads encrypted with a data key. Payloads
+// encrypted with the secret start with an alphanumeric salt, so the two
+// formats can't be confused.
+const EnvelopePrefix = '#'
+
+// IsEnvelopeEncrypted returns true if payload has been encrypted with a data
+// key rather than with the secret key.
+func IsEnvelopeEncrypted(payload []byte) bool {
+	return len(payload) > 0 && payload[0] == EnvelopePrefix
+}
+
 type Service interface {
 	Encrypt([]byte, string) ([]byte, error)
 	Decrypt([]byte, string) ([]byte, error)
+
+	// EncryptJsonData encrypts every value of secure JSON data.
+	EncryptJsonData(map[string]string, string) (map[string][]byte, error)
+	// DecryptJsonData decrypts every value of secure JSON data.
+	DecryptJsonData(map[string][]byte, string) (map[string]string, error)
+	// GetDecryptedValue returns the decrypted value of a key of secure
+	// JSON data, or fallback if the key is missing or can't be decrypted.
+	GetDecryptedValue(sjd map[string][]byte, key, fallback, secret string) string
 }
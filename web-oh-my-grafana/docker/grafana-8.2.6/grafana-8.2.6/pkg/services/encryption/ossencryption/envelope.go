@@ -0,0 +1,246 @@
+package ossencryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DataKeyStore stores the data keys used for envelope encryption.
+type DataKeyStore interface {
+	GetDataKey(ctx context.Context, name string) (*models.DataKey, error)
+	GetDataKeys(ctx context.Context) ([]*models.DataKey, error)
+	CreateDataKey(ctx context.Context, dataKey *models.DataKey) error
+	UpdateDataKey(ctx context.Context, dataKey *models.DataKey) error
+}
+
+const (
+	dataKeyLength = 32
+	// dataKeyRotationPeriod is how long a data key is used to encrypt new
+	// payloads.
+	dataKeyRotationPeriod = 24 * time.Hour
+	// dataKeyCacheTTL and maxCachedDataKeys bound the decrypted data keys
+	// kept in memory.
+	dataKeyCacheTTL   = 15 * time.Minute
+	maxCachedDataKeys = 1000
+)
+
+var getTime = time.Now
+
+type currentDataKey struct {
+	name    string
+	key     []byte
+	created time.Time
+}
+
+type cachedDataKey struct {
+	key     []byte
+	expires time.Time
+}
+
+// envelopeEncrypt encrypts payload with the current data key. The payload
+// is stored as #<data key name>#<ciphertext>.
+func (s *Service) envelopeEncrypt(ctx context.Context, payload []byte) ([]byte, error) {
+	name, dataKey, err := s.currentDataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := sealGCM(dataKey, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]byte, 0, len(name)+2+len(ciphertext))
+	result = append(result, encryption.EnvelopePrefix)
+	result = append(result, name...)
+	result = append(result, encryption.EnvelopePrefix)
+	return append(result, ciphertext...), nil
+}
+
+// currentDataKey returns the data key used to encrypt new payloads. A data
+// key is shared by all the payloads encrypted during dataKeyRotationPeriod,
+// after which a new one is created, so that encrypting a payload doesn't
+// cost a call to the key provider and a new row in the database.
+func (s *Service) currentDataKey(ctx context.Context) (string, []byte, error) {
+	s.currentMtx.Lock()
+	defer s.currentMtx.Unlock()
+
+	if s.current != nil && getTime().Sub(s.current.created) < dataKeyRotationPeriod {
+		return s.current.name, s.current.key, nil
+	}
+
+	dataKey := make([]byte, dataKeyLength)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", nil, err
+	}
+
+	keyID, encryptedKey, err := s.provider.Encrypt(ctx, dataKey)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt data key: %w", err)
+	}
+
+	name := util.GenerateShortUID()
+	err = s.store.CreateDataKey(ctx, &models.DataKey{
+		Name:               name,
+		Provider:           s.provider.Name(),
+		KeyEncryptionKeyId: keyID,
+		EncryptedData:      encryptedKey,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to store data key: %w", err)
+	}
+
+	s.current = &currentDataKey{name: name, key: dataKey, created: getTime()}
+	return name, dataKey, nil
+}
+
+func (s *Service) envelopeDecrypt(ctx context.Context, payload []byte) ([]byte, error) {
+	end := bytes.IndexByte(payload[1:], encryption.EnvelopePrefix)
+	if end < 1 {
+		return nil, errors.New("envelope encrypted payload has no data key name")
+	}
+	name := string(payload[1 : end+1])
+
+	dataKey, err := s.dataKey(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return openGCM(dataKey, payload[end+2:])
+}
+
+// dataKey returns the decrypted data key with the given name. Decrypted
+// data keys are kept in memory for dataKeyCacheTTL to avoid calling the key
+// provider for every decryption.
+func (s *Service) dataKey(ctx context.Context, name string) ([]byte, error) {
+	s.currentMtx.Lock()
+	current := s.current
+	s.currentMtx.Unlock()
+	if current != nil && current.name == name {
+		return current.key, nil
+	}
+
+	if dataKey, ok := s.cachedDataKey(name); ok {
+		return dataKey, nil
+	}
+
+	if s.provider == nil {
+		return nil, errors.New("payload has been encrypted with envelope encryption but no encryption provider is configured")
+	}
+
+	stored, err := s.store.GetDataKey(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key %q: %w", name, err)
+	}
+	if stored.Provider != s.provider.Name() {
+		return nil, fmt.Errorf("data key %q has been encrypted by the %s encryption provider, but %s is configured",
+			name, stored.Provider, s.provider.Name())
+	}
+
+	dataKey, err := s.provider.Decrypt(ctx, stored.KeyEncryptionKeyId, stored.EncryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key %q: %w", name, err)
+	}
+	s.cacheDataKey(name, dataKey)
+
+	return dataKey, nil
+}
+
+func (s *Service) cachedDataKey(name string) ([]byte, bool) {
+	s.dataKeysMtx.RLock()
+	defer s.dataKeysMtx.RUnlock()
+
+	cached, ok := s.dataKeys[name]
+	if !ok || getTime().After(cached.expires) {
+		return nil, false
+	}
+	return cached.key, true
+}
+
+// cacheDataKey keeps a decrypted data key in memory. The cache holds at
+// most maxCachedDataKeys keys, expired keys are evicted first and then the
+// ones closest to expiring.
+func (s *Service) cacheDataKey(name string, dataKey []byte) {
+	s.dataKeysMtx.Lock()
+	defer s.dataKeysMtx.Unlock()
+
+	if s.dataKeys == nil {
+		s.dataKeys = make(map[string]cachedDataKey)
+	}
+
+	now := getTime()
+	if len(s.dataKeys) >= maxCachedDataKeys {
+		for n, cached := range s.dataKeys {
+			if now.After(cached.expires) {
+				delete(s.dataKeys, n)
+			}
+		}
+	}
+	for len(s.dataKeys) >= maxCachedDataKeys {
+		oldest := ""
+		for n, cached := range s.dataKeys {
+			if oldest == "" || cached.expires.Before(s.dataKeys[oldest].expires) {
+				oldest = n
+			}
+		}
+		delete(s.dataKeys, oldest)
+	}
+
+	s.dataKeys[name] = cachedDataKey{key: dataKey, expires: now.Add(dataKeyCacheTTL)}
+}
+
+// ReEncryptDataKeys encrypts the data keys that aren't encrypted with the
+// active key encryption key of the provider with it, and returns how many
+// have been re-encrypted. This completes a rotation of the key encryption
+// key without re-encrypting any of the payloads, after which the previous
+// key encryption key can be retired.
+func (s *Service) ReEncryptDataKeys(ctx context.Context) (int, error) {
+	if s.provider == nil {
+		return 0, errors.New("envelope encryption is not enabled, no encryption provider is configured")
+	}
+
+	activeID, err := s.provider.ActiveKeyID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	dataKeys, err := s.store.GetDataKeys(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, stored := range dataKeys {
+		if stored.Provider == s.provider.Name() && stored.KeyEncryptionKeyId == activeID {
+			continue
+		}
+
+		dataKey, err := s.dataKey(ctx, stored.Name)
+		if err != nil {
+			return count, err
+		}
+
+		keyID, encryptedKey, err := s.provider.Encrypt(ctx, dataKey)
+		if err != nil {
+			return count, fmt.Errorf("failed to encrypt data key %q: %w", stored.Name, err)
+		}
+
+		stored.KeyEncryptionKeyId = keyID
+		stored.EncryptedData = encryptedKey
+		if err := s.store.UpdateDataKey(ctx, stored); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
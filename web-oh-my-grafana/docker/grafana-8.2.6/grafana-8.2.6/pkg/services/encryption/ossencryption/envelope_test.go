@@ -0,0 +1,215 @@
+package ossencryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyfile(t *testing.T, path string, keyIDs ...string) {
+	t.Helper()
+
+	lines := make([]string, 0, len(keyIDs))
+	for _, id := range keyIDs {
+		key := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%-32s", id)))
+		lines = append(lines, id+":"+key)
+	}
+	err := ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600)
+	require.NoError(t, err)
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	keyfile := filepath.Join(t.TempDir(), "keys")
+	writeKeyfile(t, keyfile, "key-1")
+
+	cfg := setting.NewCfg()
+	cfg.EncryptionProvider = "keyfile"
+	cfg.EncryptionKeyfilePath = keyfile
+	svc, err := ProvideService(cfg, sqlStore)
+	require.NoError(t, err)
+
+	legacy, err := (&Service{}).Encrypt([]byte("legacy"), "1234")
+	require.NoError(t, err)
+
+	encrypted, err := svc.Encrypt([]byte("grafana"), "1234")
+	require.NoError(t, err)
+	require.True(t, encryption.IsEnvelopeEncrypted(encrypted))
+
+	t.Run("decrypting envelope encrypted payload", func(t *testing.T) {
+		decrypted, err := svc.Decrypt(encrypted, "1234")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+
+		// a new instance has to decrypt the data key
+		svc, err := ProvideService(cfg, sqlStore)
+		require.NoError(t, err)
+		decrypted, err = svc.Decrypt(encrypted, "")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("decrypting payload encrypted with the secret", func(t *testing.T) {
+		decrypted, err := svc.Decrypt(legacy, "1234")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("legacy"), decrypted)
+	})
+
+	t.Run("payloads share a data key until it is rotated", func(t *testing.T) {
+		other, err := svc.Encrypt([]byte("grafana"), "1234")
+		require.NoError(t, err)
+		assert.Equal(t, encrypted[:10], other[:10])
+
+		dataKeys, err := sqlStore.GetDataKeys(context.Background())
+		require.NoError(t, err)
+		require.Len(t, dataKeys, 1)
+
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		getTime = func() time.Time {
+			return origGetTime().Add(dataKeyRotationPeriod)
+		}
+
+		other, err = svc.Encrypt([]byte("grafana"), "1234")
+		require.NoError(t, err)
+		assert.NotEqual(t, encrypted[:10], other[:10])
+
+		dataKeys, err = sqlStore.GetDataKeys(context.Background())
+		require.NoError(t, err)
+		require.Len(t, dataKeys, 2)
+	})
+
+	t.Run("secure json data is envelope encrypted", func(t *testing.T) {
+		secureJSONData, err := svc.EncryptJsonData(map[string]string{"password": "grafana"}, setting.SecretKey)
+		require.NoError(t, err)
+		assert.True(t, encryption.IsEnvelopeEncrypted(secureJSONData["password"]))
+
+		decrypted, err := svc.DecryptJsonData(secureJSONData, setting.SecretKey)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"password": "grafana"}, decrypted)
+	})
+
+	t.Run("rotating the key encryption key", func(t *testing.T) {
+		writeKeyfile(t, keyfile, "key-1", "key-2")
+		svc, err := ProvideService(cfg, sqlStore)
+		require.NoError(t, err)
+
+		count, err := svc.ReEncryptDataKeys(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		count, err = svc.ReEncryptDataKeys(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 0, count)
+
+		// the payloads can be decrypted once the previous key is retired
+		writeKeyfile(t, keyfile, "key-2")
+		svc, err = ProvideService(cfg, sqlStore)
+		require.NoError(t, err)
+		decrypted, err := svc.Decrypt(encrypted, "")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("grafana"), decrypted)
+	})
+
+	t.Run("decrypted data keys cache is bounded", func(t *testing.T) {
+		svc, err := ProvideService(cfg, sqlStore)
+		require.NoError(t, err)
+
+		for i := 0; i < maxCachedDataKeys+10; i++ {
+			svc.cacheDataKey(fmt.Sprintf("key-%d", i), []byte("data key"))
+		}
+		assert.Len(t, svc.dataKeys, maxCachedDataKeys)
+		_, ok := svc.cachedDataKey(fmt.Sprintf("key-%d", maxCachedDataKeys+9))
+		assert.True(t, ok)
+
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		getTime = func() time.Time {
+			return origGetTime().Add(dataKeyCacheTTL + time.Minute)
+		}
+		_, ok = svc.cachedDataKey(fmt.Sprintf("key-%d", maxCachedDataKeys+9))
+		assert.False(t, ok)
+	})
+
+	t.Run("decrypting envelope encrypted payload without provider should return error", func(t *testing.T) {
+		svc, err := ProvideService(setting.NewCfg(), sqlStore)
+		require.NoError(t, err)
+
+		_, err = svc.Decrypt(encrypted, "1234")
+		require.Error(t, err)
+	})
+}
+
+func TestKMSProvider(t *testing.T) {
+	activeKey := "v1"
+	keys := map[string]byte{"v1": 1, "v2": 2}
+	xor := func(data []byte, key byte) []byte {
+		result := make([]byte, len(data))
+		for i, b := range data {
+			result[i] = b ^ key
+		}
+		return result
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req kmsRequest
+		if r.Method == http.MethodPost {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		}
+
+		var resp kmsResponse
+		switch r.URL.Path {
+		case "/v1/keys/grafana":
+			resp.ActiveKeyID = activeKey
+		case "/v1/keys/grafana/encrypt":
+			resp.KeyID = activeKey
+			resp.Ciphertext = xor(req.Plaintext, keys[activeKey])
+		case "/v1/keys/grafana/decrypt":
+			resp.Plaintext = xor(req.Ciphertext, keys[req.KeyID])
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := newKMSProvider(server.URL, "grafana")
+	require.NoError(t, err)
+
+	keyID, encrypted, err := provider.Encrypt(context.Background(), []byte("data key"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", keyID)
+
+	activeKey = "v2"
+	active, err := provider.ActiveKeyID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v2", active)
+
+	decrypted, err := provider.Decrypt(context.Background(), keyID, encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data key"), decrypted)
+
+	missing, err := newKMSProvider(server.URL+"/missing", "grafana")
+	require.NoError(t, err)
+	_, err = missing.ActiveKeyID(context.Background())
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package ossencryption
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -8,20 +9,103 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"golang.org/x/crypto/pbkdf2"
 )
 
-type Service struct{}
+var logger = log.New("encryption")
 
-func ProvideService() *Service {
-	return &Service{}
+// Service encrypts payloads with the given secret, or with envelope
+// encryption if a key provider is configured. Payloads encrypted with the
+// secret can always be decrypted, so enabling envelope encryption doesn't
+// require re-encrypting existing secrets.
+type Service struct {
+	store    DataKeyStore
+	provider KeyProvider
+
+	currentMtx sync.Mutex
+	current    *currentDataKey
+
+	dataKeysMtx sync.RWMutex
+	dataKeys    map[string]cachedDataKey
+}
+
+func ProvideService(cfg *setting.Cfg, store DataKeyStore) (*Service, error) {
+	provider, err := newKeyProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		store:    store,
+		provider: provider,
+		dataKeys: make(map[string]cachedDataKey),
+	}, nil
 }
 
 const saltLength = 8
 
 func (s *Service) Decrypt(payload []byte, secret string) ([]byte, error) {
+	if encryption.IsEnvelopeEncrypted(payload) {
+		return s.envelopeDecrypt(context.Background(), payload)
+	}
+
+	return s.legacyDecrypt(payload, secret)
+}
+
+func (s *Service) Encrypt(payload []byte, secret string) ([]byte, error) {
+	if s.provider != nil {
+		return s.envelopeEncrypt(context.Background(), payload)
+	}
+
+	return s.legacyEncrypt(payload, secret)
+}
+
+func (s *Service) EncryptJsonData(kv map[string]string, secret string) (map[string][]byte, error) {
+	encrypted := make(map[string][]byte)
+	for key, value := range kv {
+		encryptedData, err := s.Encrypt([]byte(value), secret)
+		if err != nil {
+			return nil, err
+		}
+
+		encrypted[key] = encryptedData
+	}
+	return encrypted, nil
+}
+
+func (s *Service) DecryptJsonData(sjd map[string][]byte, secret string) (map[string]string, error) {
+	decrypted := make(map[string]string)
+	for key, data := range sjd {
+		decryptedData, err := s.Decrypt(data, secret)
+		if err != nil {
+			return nil, err
+		}
+
+		decrypted[key] = string(decryptedData)
+	}
+	return decrypted, nil
+}
+
+func (s *Service) GetDecryptedValue(sjd map[string][]byte, key, fallback, secret string) string {
+	if value, ok := sjd[key]; ok {
+		decryptedData, err := s.Decrypt(value, secret)
+		if err != nil {
+			logger.Error("Failed to decrypt secure json data", "key", key, "error", err)
+			return fallback
+		}
+		return string(decryptedData)
+	}
+	return fallback
+}
+
+// legacyDecrypt decrypts a payload encrypted with a key derived from secret.
+func (s *Service) legacyDecrypt(payload []byte, secret string) ([]byte, error) {
 	if len(payload) < saltLength {
 		return nil, fmt.Errorf("unable to compute salt")
 	}
@@ -52,7 +136,7 @@ func (s *Service) Decrypt(payload []byte, secret string) ([]byte, error) {
 	return payloadDst, nil
 }
 
-func (s *Service) Encrypt(payload []byte, secret string) ([]byte, error) {
+func (s *Service) legacyEncrypt(payload []byte, secret string) ([]byte, error) {
 	salt, err := util.GetRandomString(saltLength)
 	if err != nil {
 		return nil, err
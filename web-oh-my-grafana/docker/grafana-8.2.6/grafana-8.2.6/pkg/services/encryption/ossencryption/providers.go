@@ -0,0 +1,283 @@
+package ossencryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// KeyProvider encrypts data keys with a key encryption key (KEK) that is
+// managed outside of the database. Providers can hold several key
+// encryption keys so that a new one can be introduced without losing
+// access to the data keys encrypted with the previous ones.
+type KeyProvider interface {
+	// Name is stored along with each data key to know which provider
+	// is able to decrypt it.
+	Name() string
+	// ActiveKeyID returns the id of the key encryption key used by Encrypt.
+	ActiveKeyID(ctx context.Context) (string, error)
+	// Encrypt encrypts a data key with the active key encryption key and
+	// returns its id along with the ciphertext.
+	Encrypt(ctx context.Context, dataKey []byte) (string, []byte, error)
+	// Decrypt decrypts a data key encrypted with the key encryption key keyID.
+	Decrypt(ctx context.Context, keyID string, encrypted []byte) ([]byte, error)
+}
+
+const (
+	keyfileProviderName = "keyfile"
+	kmsProviderName     = "kms"
+)
+
+// newKeyProvider returns the provider configured by
+// [security.encryption] provider, or nil if envelope encryption is disabled.
+func newKeyProvider(cfg *setting.Cfg) (KeyProvider, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	switch cfg.EncryptionProvider {
+	case "":
+		return nil, nil
+	case keyfileProviderName:
+		return newKeyfileProvider(cfg.EncryptionKeyfilePath)
+	case kmsProviderName:
+		return newKMSProvider(cfg.EncryptionKMSURL, cfg.EncryptionKMSKeyID)
+	default:
+		return nil, fmt.Errorf("unknown encryption provider %q", cfg.EncryptionProvider)
+	}
+}
+
+// keyfileProvider reads key encryption keys from a local file. Each line of
+// the file is a key id and a base64 encoded 32 bytes key separated by a
+// colon. The last key of the file is the active one; keys are rotated by
+// appending a new key to the file.
+type keyfileProvider struct {
+	keys     map[string][]byte
+	activeID string
+}
+
+func newKeyfileProvider(path string) (*keyfileProvider, error) {
+	if path == "" {
+		return nil, errors.New("keyfile_path is required by the keyfile encryption provider")
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes
+	// from Grafana's configuration file.
+	content, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+
+	p := &keyfileProvider{keys: make(map[string][]byte)}
+	for n, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid key on line %d of encryption key file, expected <key id>:<base64 key>", n+1)
+		}
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid key on line %d of encryption key file, keys must be 32 bytes encoded with base64", n+1)
+		}
+
+		p.keys[parts[0]] = key
+		p.activeID = parts[0]
+	}
+
+	if p.activeID == "" {
+		return nil, fmt.Errorf("encryption key file %s contains no key", path)
+	}
+
+	return p, nil
+}
+
+func (p *keyfileProvider) Name() string {
+	return keyfileProviderName
+}
+
+func (p *keyfileProvider) ActiveKeyID(context.Context) (string, error) {
+	return p.activeID, nil
+}
+
+func (p *keyfileProvider) Encrypt(_ context.Context, dataKey []byte) (string, []byte, error) {
+	encrypted, err := sealGCM(p.keys[p.activeID], dataKey)
+	return p.activeID, encrypted, err
+}
+
+func (p *keyfileProvider) Decrypt(_ context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key encryption key %q is not in the encryption key file", keyID)
+	}
+
+	return openGCM(key, encrypted)
+}
+
+// kmsProvider delegates encryption of data keys to a key management service
+// reachable over HTTP. The service is expected to implement:
+//
+//	GET  <url>/v1/keys/<key id>         -> {"activeKeyId": "<key version id>"}
+//	POST <url>/v1/keys/<key id>/encrypt {"plaintext": "<base64>"}
+//	                                     -> {"keyId": "<key version id>", "ciphertext": "<base64>"}
+//	POST <url>/v1/keys/<key id>/decrypt {"keyId": "<key version id>", "ciphertext": "<base64>"}
+//	                                     -> {"plaintext": "<base64>"}
+//
+// Rotation of the key encryption key is handled by the service, which
+// creates a new version of the key and keeps the previous ones for
+// decryption.
+type kmsProvider struct {
+	url    string
+	keyID  string
+	client *http.Client
+}
+
+func newKMSProvider(kmsURL, keyID string) (*kmsProvider, error) {
+	if kmsURL == "" || keyID == "" {
+		return nil, errors.New("kms_url and kms_key_id are required by the kms encryption provider")
+	}
+
+	return &kmsProvider{
+		url:    strings.TrimSuffix(kmsURL, "/") + "/v1/keys/" + url.PathEscape(keyID),
+		keyID:  keyID,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type kmsRequest struct {
+	KeyID      string `json:"keyId,omitempty"`
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext []byte `json:"ciphertext,omitempty"`
+}
+
+type kmsResponse struct {
+	ActiveKeyID string `json:"activeKeyId"`
+	KeyID       string `json:"keyId"`
+	Plaintext   []byte `json:"plaintext"`
+	Ciphertext  []byte `json:"ciphertext"`
+}
+
+func (p *kmsProvider) Name() string {
+	return kmsProviderName
+}
+
+func (p *kmsProvider) ActiveKeyID(ctx context.Context) (string, error) {
+	resp, err := p.do(ctx, http.MethodGet, "", nil)
+	if err != nil {
+		return "", err
+	}
+
+	return resp.ActiveKeyID, nil
+}
+
+func (p *kmsProvider) Encrypt(ctx context.Context, dataKey []byte) (string, []byte, error) {
+	resp, err := p.do(ctx, http.MethodPost, "/encrypt", &kmsRequest{Plaintext: dataKey})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return resp.KeyID, resp.Ciphertext, nil
+}
+
+func (p *kmsProvider) Decrypt(ctx context.Context, keyID string, encrypted []byte) ([]byte, error) {
+	resp, err := p.do(ctx, http.MethodPost, "/decrypt", &kmsRequest{KeyID: keyID, Ciphertext: encrypted})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Plaintext, nil
+}
+
+func (p *kmsProvider) do(ctx context.Context, method, path string, body *kmsRequest) (*kmsResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.url+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the KMS: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("KMS responded with status %d", res.StatusCode)
+	}
+
+	var resp kmsResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to parse the response of the KMS: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// sealGCM encrypts payload with AES-GCM and prepends the nonce to the
+// ciphertext.
+func sealGCM(key, payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, payload, nil), nil
+}
+
+func openGCM(key, payload []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, errors.New("payload too short")
+	}
+
+	return gcm.Open(nil, payload[:gcm.NonceSize()], payload[gcm.NonceSize():], nil)
+}
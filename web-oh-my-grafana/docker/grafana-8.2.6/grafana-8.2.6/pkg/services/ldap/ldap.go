@@ -345,6 +345,13 @@ func (server *Server) validateGrafanaUser(user *models.ExternalUserInfo) error {
 	return nil
 }
 
+const (
+	// defaultNestedGroupSearchFilter finds the groups a group is a member of
+	defaultNestedGroupSearchFilter = "(member=%s)"
+	// maxNestedGroupsDepth limits the levels of nested groups resolved for a user
+	maxNestedGroupsDepth = 10
+)
+
 // getSearchRequest returns LDAP search request for users
 func (server *Server) getSearchRequest(
 	base string,
@@ -584,15 +591,85 @@ func (server *Server) serializeUsers(
 func (server *Server) getMemberOf(result *ldap.Entry) (
 	[]string, error,
 ) {
+	var memberOf []string
 	if server.Config.GroupSearchFilter == "" {
-		memberOf := getArrayAttribute(server.Config.Attr.MemberOf, result)
+		memberOf = getArrayAttribute(server.Config.Attr.MemberOf, result)
+	} else {
+		var err error
+		memberOf, err = server.requestMemberOf(result)
+		if err != nil {
+			return nil, err
+		}
+	}
 
+	if !server.Config.NestedGroups {
 		return memberOf, nil
 	}
 
-	memberOf, err := server.requestMemberOf(result)
-	if err != nil {
-		return nil, err
+	return server.requestNestedGroups(memberOf)
+}
+
+// requestNestedGroups adds the groups the given groups are members of, and
+// the groups those are members of, up to maxNestedGroupsDepth levels. Nested
+// groups are identified by their DN.
+func (server *Server) requestNestedGroups(memberOf []string) ([]string, error) {
+	var config = server.Config
+	var searchBaseDNs []string
+
+	if len(config.GroupSearchBaseDNs) > 0 {
+		searchBaseDNs = config.GroupSearchBaseDNs
+	} else {
+		searchBaseDNs = config.SearchBaseDNs
+	}
+
+	filterFormat := config.NestedGroupSearchFilter
+	if filterFormat == "" {
+		filterFormat = defaultNestedGroupSearchFilter
+	}
+
+	seen := make(map[string]struct{}, len(memberOf))
+	for _, group := range memberOf {
+		seen[strings.ToLower(group)] = struct{}{}
+	}
+
+	groups := memberOf
+	for depth := 0; depth < maxNestedGroupsDepth && len(groups) > 0; depth++ {
+		search := ""
+		for _, group := range groups {
+			search += strings.ReplaceAll(filterFormat, "%s", ldap.EscapeFilter(group))
+		}
+		filter := fmt.Sprintf("(|%s)", search)
+
+		server.log.Debug("Searching for nested groups", "filter", filter, "depth", depth)
+
+		var parents []string
+		for _, groupSearchBase := range searchBaseDNs {
+			groupSearchReq := ldap.SearchRequest{
+				BaseDN:       groupSearchBase,
+				Scope:        ldap.ScopeWholeSubtree,
+				DerefAliases: ldap.NeverDerefAliases,
+				Attributes:   []string{"dn"},
+				Filter:       filter,
+			}
+
+			groupSearchResult, err := server.Connection.Search(&groupSearchReq)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, group := range groupSearchResult.Entries {
+				key := strings.ToLower(group.DN)
+				if _, exists := seen[key]; exists {
+					// groups can be members of each other
+					continue
+				}
+				seen[key] = struct{}{}
+				parents = append(parents, group.DN)
+			}
+		}
+
+		memberOf = append(memberOf, parents...)
+		groups = parents
 	}
 
 	return memberOf, nil
@@ -1,6 +1,7 @@
 package ldap
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -252,3 +253,83 @@ func TestServer_binds(t *testing.T) {
 		assert.False(t, server.shouldAdminBind())
 	})
 }
+
+func TestServer_getMemberOfNestedGroups(t *testing.T) {
+	// devs is in engineering, which is in staff, which is in engineering again
+	parents := map[string][]string{
+		"cn=devs,ou=groups,dc=grafana,dc=org":        {"cn=engineering,ou=groups,dc=grafana,dc=org"},
+		"cn=engineering,ou=groups,dc=grafana,dc=org": {"cn=staff,ou=groups,dc=grafana,dc=org"},
+		"cn=staff,ou=groups,dc=grafana,dc=org":       {"CN=Engineering,ou=groups,dc=grafana,dc=org"},
+	}
+
+	var filters []string
+	connection := &MockConnection{}
+	connection.setSearchFunc(func(request *ldap.SearchRequest) (*ldap.SearchResult, error) {
+		filters = append(filters, request.Filter)
+
+		result := &ldap.SearchResult{}
+		for child, groups := range parents {
+			if !strings.Contains(request.Filter, "(member="+child+")") {
+				continue
+			}
+			for _, group := range groups {
+				result.Entries = append(result.Entries, &ldap.Entry{DN: group})
+			}
+		}
+		return result, nil
+	})
+
+	server := &Server{
+		Config: &ServerConfig{
+			Attr:               AttributeMap{MemberOf: "memberOf"},
+			GroupSearchBaseDNs: []string{"ou=groups,dc=grafana,dc=org"},
+			NestedGroups:       true,
+		},
+		Connection: connection,
+		log:        log.New("test-logger"),
+	}
+
+	entry := &ldap.Entry{
+		DN: "cn=roel,ou=users,dc=grafana,dc=org",
+		Attributes: []*ldap.EntryAttribute{
+			{Name: "memberOf", Values: []string{"cn=devs,ou=groups,dc=grafana,dc=org"}},
+		},
+	}
+
+	t.Run("resolves the groups of groups", func(t *testing.T) {
+		memberOf, err := server.getMemberOf(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"cn=devs,ou=groups,dc=grafana,dc=org",
+			"cn=engineering,ou=groups,dc=grafana,dc=org",
+			"cn=staff,ou=groups,dc=grafana,dc=org",
+		}, memberOf)
+		// the cycle back to engineering ends the search
+		assert.Len(t, filters, 3)
+	})
+
+	t.Run("uses the nested group search filter", func(t *testing.T) {
+		filters = nil
+		server.Config.NestedGroupSearchFilter = "(member:1.2.840.113556.1.4.1941:=%s)"
+		defer func() { server.Config.NestedGroupSearchFilter = "" }()
+
+		memberOf, err := server.getMemberOf(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cn=devs,ou=groups,dc=grafana,dc=org"}, memberOf)
+		assert.Equal(t, []string{"(|(member:1.2.840.113556.1.4.1941:=cn=devs,ou=groups,dc=grafana,dc=org))"}, filters)
+	})
+
+	t.Run("doesn't search without nested groups", func(t *testing.T) {
+		filters = nil
+		server.Config.NestedGroups = false
+		defer func() { server.Config.NestedGroups = true }()
+
+		memberOf, err := server.getMemberOf(entry)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cn=devs,ou=groups,dc=grafana,dc=org"}, memberOf)
+		assert.Empty(t, filters)
+	})
+}
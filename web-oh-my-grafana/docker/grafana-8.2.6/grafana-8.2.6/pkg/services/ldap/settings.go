@@ -39,6 +39,9 @@ type ServerConfig struct {
 	GroupSearchFilterUserAttribute string   `toml:"group_search_filter_user_attribute"`
 	GroupSearchBaseDNs             []string `toml:"group_search_base_dns"`
 
+	NestedGroups            bool   `toml:"nested_groups"`
+	NestedGroupSearchFilter string `toml:"nested_group_search_filter"`
+
 	Groups []*GroupToOrgRole `toml:"group_mappings"`
 }
 
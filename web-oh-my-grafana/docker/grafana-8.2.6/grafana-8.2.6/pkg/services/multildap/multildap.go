@@ -51,6 +51,10 @@ type IMultiLDAP interface {
 		[]*models.ExternalUserInfo, error,
 	)
 
+	UsersWithServerErrors(logins []string) (
+		[]*models.ExternalUserInfo, []*ServerStatus, error,
+	)
+
 	User(login string) (
 		*models.ExternalUserInfo, ldap.ServerConfig, error,
 	)
@@ -228,6 +232,58 @@ func (multiples *MultiLDAP) Users(logins []string) (
 	return result, nil
 }
 
+// UsersWithServerErrors gets users from every LDAP server. Unlike Users, it
+// doesn't stop at the first server that fails, and returns the status of the
+// servers that couldn't be dialed, bound or searched instead, so that the
+// users of an unavailable server can be told apart from missing users.
+func (multiples *MultiLDAP) UsersWithServerErrors(logins []string) (
+	[]*models.ExternalUserInfo,
+	[]*ServerStatus,
+	error,
+) {
+	var result []*models.ExternalUserInfo
+	var serverErrors []*ServerStatus
+
+	if len(multiples.configs) == 0 {
+		return nil, nil, ErrNoLDAPServers
+	}
+
+	for _, config := range multiples.configs {
+		users, err := searchServer(config, logins)
+		if err != nil {
+			logger.Warn("Failed to search LDAP server", "host", config.Host, "port", config.Port, "error", err)
+			serverErrors = append(serverErrors, &ServerStatus{
+				Host:      config.Host,
+				Port:      config.Port,
+				Available: false,
+				Error:     err,
+			})
+			continue
+		}
+		result = append(result, users...)
+	}
+
+	return result, serverErrors, nil
+}
+
+// searchServer gets users from a single LDAP server.
+func searchServer(config *ldap.ServerConfig, logins []string) ([]*models.ExternalUserInfo, error) {
+	server := newLDAP(config)
+
+	if err := server.Dial(); err != nil {
+		logDialFailure(err, config)
+		return nil, err
+	}
+
+	defer server.Close()
+
+	if err := server.Bind(); err != nil {
+		return nil, err
+	}
+
+	return server.Users(logins)
+}
+
 // isSilentError evaluates an error and tells whenever we should fail the LDAP request
 // immediately or if we should continue into other LDAP servers
 func isSilentError(err error) bool {
@@ -444,6 +444,62 @@ func TestMultiLDAP(t *testing.T) {
 				teardown()
 			})
 		})
+
+		Convey("UsersWithServerErrors()", func() {
+			Convey("Should return error for absent config list", func() {
+				setup()
+
+				multi := New([]*ldap.ServerConfig{})
+				_, _, err := multi.UsersWithServerErrors([]string{"test"})
+
+				So(err, ShouldEqual, ErrNoLDAPServers)
+
+				teardown()
+			})
+
+			Convey("Should report the servers that couldn't be bound", func() {
+				mock := setup()
+
+				expected := errors.New("Bind error")
+				mock.bindErrReturn = expected
+
+				multi := New([]*ldap.ServerConfig{
+					{Host: "one"}, {Host: "two"},
+				})
+				users, serverErrors, err := multi.UsersWithServerErrors([]string{"test"})
+
+				So(err, ShouldBeNil)
+				So(users, ShouldBeEmpty)
+				So(mock.bindCalledTimes, ShouldEqual, 2)
+				So(mock.closeCalledTimes, ShouldEqual, 2)
+				So(serverErrors, ShouldHaveLength, 2)
+				So(serverErrors[0].Host, ShouldEqual, "one")
+				So(serverErrors[0].Error, ShouldEqual, expected)
+				So(serverErrors[1].Host, ShouldEqual, "two")
+
+				teardown()
+			})
+
+			Convey("Should get users from every server", func() {
+				mock := setup()
+
+				mock.usersFirstReturn = []*models.ExternalUserInfo{{Login: "one"}}
+				mock.usersRestReturn = []*models.ExternalUserInfo{{Login: "two"}}
+
+				multi := New([]*ldap.ServerConfig{
+					{}, {},
+				})
+				users, serverErrors, err := multi.UsersWithServerErrors([]string{"test"})
+
+				So(err, ShouldBeNil)
+				So(serverErrors, ShouldBeEmpty)
+				So(users, ShouldHaveLength, 2)
+				So(users[0].Login, ShouldEqual, "one")
+				So(users[1].Login, ShouldEqual, "two")
+
+				teardown()
+			})
+		})
 	})
 }
 
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -45,6 +46,7 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
+	audit.SetAction(c.Req.Context(), "alert_rule_namespace.delete", "alert_rule_namespace", namespace.Uid)
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "namespace rules deleted"})
 }
@@ -68,6 +70,7 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 	for _, uid := range uids {
 		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
 	}
+	audit.SetAction(c.Req.Context(), "alert_rule_group.delete", "alert_rule_group", namespace.Uid+"/"+ruleGroup)
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group deleted"})
 }
@@ -247,6 +250,7 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 			Condition: r.GrafanaManagedAlert.Condition,
 			OrgID:     c.SignedInUser.OrgId,
 			Data:      r.GrafanaManagedAlert.Data,
+			Variables: r.GrafanaManagedAlert.Variables,
 		}
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
 			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
@@ -275,6 +279,11 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		}
 	}
 
+	var before map[string]*ngmodels.AlertRule
+	if audit.IsRecording(c.Req.Context()) {
+		before = srv.ruleGroupRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name)
+	}
+
 	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
 		OrgID:           c.SignedInUser.OrgId,
 		NamespaceUID:    namespace.Uid,
@@ -292,9 +301,34 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		srv.manager.RemoveByRuleUID(c.OrgId, uid)
 	}
 
+	if audit.IsRecording(c.Req.Context()) {
+		audit.SetAction(c.Req.Context(), "alert_rule_group.update", "alert_rule_group", namespace.Uid+"/"+ruleGroupConfig.Name)
+		audit.SetChanges(c.Req.Context(), before, srv.ruleGroupRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroupConfig.Name))
+	}
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
 }
 
+// ruleGroupRules returns the rules of a group by UID, the state audited when
+// the group is changed.
+func (srv RulerSrv) ruleGroupRules(orgID int64, namespaceUID, ruleGroup string) map[string]*ngmodels.AlertRule {
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        orgID,
+		NamespaceUID: namespaceUID,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		srv.log.Warn("failed to get group alert rules", "err", err)
+		return nil
+	}
+
+	rules := make(map[string]*ngmodels.AlertRule, len(q.Result))
+	for _, r := range q.Result {
+		rules[r.UID] = r
+	}
+	return rules
+}
+
 func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
@@ -303,6 +337,7 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			Title:           r.Title,
 			Condition:       r.Condition,
 			Data:            r.Data,
+			Variables:       r.Variables,
 			Updated:         r.Updated,
 			IntervalSeconds: r.IntervalSeconds,
 			Version:         r.Version,
@@ -228,6 +228,7 @@ func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand,
 		Condition: cmd.Condition,
 		OrgID:     c.SignedInUser.OrgId,
 		Data:      cmd.Data,
+		Variables: cmd.Variables,
 	}
 	if err := validateCondition(evalCond, c.SignedInUser, c.SkipCache, datasourceCache); err != nil {
 		return ErrResp(http.StatusBadRequest, err, "invalid condition")
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/querycache"
 
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -112,6 +113,8 @@ type AlertExecCtx struct {
 	OrgID              int64
 	ExpressionsEnabled bool
 	Log                log.Logger
+	// Variables are the values of the template variables of the queries.
+	Variables map[string]string
 
 	Ctx context.Context
 }
@@ -122,9 +125,9 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 		OrgId: ctx.OrgID,
 		Headers: map[string]string{
 			// Some data sources check this in query method as sometimes alerting needs special considerations.
-			"FromAlert":    "true",
-			"X-Cache-Skip": "true",
+			"FromAlert": "true",
 		},
+		Variables: ctx.Variables,
 	}
 
 	for i := range data {
@@ -254,7 +257,8 @@ func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, no
 		Cfg:         &setting.Cfg{ExpressionsEnabled: ctx.ExpressionsEnabled},
 		DataService: dataService,
 	}
-	return exprService.TransformData(ctx.Ctx, queryDataReq)
+	// Alerting evaluates the current data, not the cached query responses.
+	return exprService.TransformData(querycache.WithoutCache(ctx.Ctx), queryDataReq)
 }
 
 // evaluateExecutionResult takes the ExecutionResult which includes data.Frames returned
@@ -431,15 +435,40 @@ func (evalResults Results) AsDataFrame() data.Frame {
 }
 
 // ConditionEval executes conditions and evaluates the result.
+// The conditions with custom variables are executed once per combination of
+// the values of the variables, the alert instances of each combination being
+// labeled with its values.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
 	alertCtx, cancelFn := context.WithTimeout(context.Background(), e.Cfg.UnifiedAlerting.EvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	if err := models.ValidateAlertVariables(condition.Variables); err != nil {
+		return evaluateExecutionResult(ExecutionResults{Error: err}, now), nil
+	}
+
+	evalResults := make(Results, 0)
+	for _, combination := range models.ExpandAlertVariables(condition.Variables) {
+		alertExecCtx := AlertExecCtx{
+			OrgID:              condition.OrgID,
+			Ctx:                alertCtx,
+			ExpressionsEnabled: e.Cfg.ExpressionsEnabled,
+			Log:                e.Log,
+			Variables:          combination.Values,
+		}
 
-	execResult := executeCondition(alertExecCtx, condition, now, dataService)
+		execResult := executeCondition(alertExecCtx, condition, now, dataService)
 
-	evalResults := evaluateExecutionResult(execResult, now)
+		for _, r := range evaluateExecutionResult(execResult, now) {
+			if len(combination.Labels) > 0 {
+				labels := r.Instance.Copy()
+				for name, value := range combination.Labels {
+					labels[name] = value
+				}
+				r.Instance = labels
+			}
+			evalResults = append(evalResults, r)
+		}
+	}
 	return evalResults, nil
 }
 
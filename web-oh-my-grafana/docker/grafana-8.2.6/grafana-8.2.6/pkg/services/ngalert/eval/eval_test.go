@@ -1,11 +1,15 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
 )
@@ -294,3 +298,30 @@ func TestEvaluateExecutionResult(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExprRequestVariables(t *testing.T) {
+	ctx := AlertExecCtx{OrgID: 1, Variables: map[string]string{"host": "web-1"}}
+	req, err := GetExprRequest(ctx, []models.AlertQuery{
+		{
+			RefID:             "A",
+			DatasourceUID:     "ds",
+			Model:             json.RawMessage(`{"expr": "up{instance=\"$host\"}"}`),
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(time.Hour)},
+		},
+	}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"host": "web-1"}, req.Variables)
+}
+
+func TestConditionEvalInvalidVariables(t *testing.T) {
+	evaluator := Evaluator{Cfg: setting.NewCfg(), Log: log.New("test")}
+	results, err := evaluator.ConditionEval(&models.Condition{
+		Condition: "A",
+		Data:      []models.AlertQuery{{RefID: "A"}},
+		Variables: []models.AlertVariable{{Name: "host", Type: models.CustomAlertVariable}},
+	}, time.Now(), nil)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, Error, results[0].State)
+	require.EqualError(t, results[0].Error, `custom variable "host" has no values`)
+}
@@ -70,6 +70,8 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Variables are the template variables of the queries.
+	Variables []AlertVariable
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -123,6 +125,8 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// Variables are the template variables of the queries.
+	Variables []AlertVariable
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -194,6 +198,9 @@ type Condition struct {
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
+
+	// Variables are the template variables of the data source queries.
+	Variables []AlertVariable `json:"variables,omitempty"`
 }
 
 // IsValid checks the condition's validity.
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AlertVariableType is the type of a template variable of an alert rule.
+type AlertVariableType string
+
+const (
+	// ConstantAlertVariable is a variable with a single value.
+	ConstantAlertVariable AlertVariableType = "constant"
+	// CustomAlertVariable is a variable with a list of values, each of them
+	// evaluated as a separate dimension of the alert rule.
+	CustomAlertVariable AlertVariableType = "custom"
+)
+
+// MaxAlertVariableCombinations is the maximum number of combinations of the
+// values of the custom variables of an alert rule, each combination being a
+// separate evaluation of its queries.
+const MaxAlertVariableCombinations = 100
+
+var alertVariableNameRegExp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// customValueRegExp matches the comma separated values of custom variables,
+// where commas are escaped with a backslash.
+var customValueRegExp = regexp.MustCompile(`(?:\\,|[^,])+`)
+
+// AlertVariable is a template variable of the queries of an alert rule.
+type AlertVariable struct {
+	Name string            `json:"name" yaml:"name"`
+	Type AlertVariableType `json:"type" yaml:"type"`
+	// Query is the value of a constant variable, or the comma separated
+	// values of a custom variable.
+	Query string `json:"query" yaml:"query"`
+}
+
+// Values returns the values of the variable.
+func (v AlertVariable) Values() []string {
+	if v.Type != CustomAlertVariable {
+		return []string{v.Query}
+	}
+
+	values := make([]string, 0)
+	for _, value := range customValueRegExp.FindAllString(v.Query, -1) {
+		value = strings.TrimSpace(strings.ReplaceAll(value, `\,`, ","))
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// AlertVariableCombination is a combination of the values of the variables
+// of an alert rule.
+type AlertVariableCombination struct {
+	// Values are the values of all the variables.
+	Values map[string]string
+	// Labels are the values of the custom variables, which identify the
+	// alert instances of the combination.
+	Labels map[string]string
+}
+
+// ValidateAlertVariables checks the names, types and values of variables.
+func ValidateAlertVariables(vars []AlertVariable) error {
+	names := make(map[string]struct{}, len(vars))
+	combinations := 1
+	for _, v := range vars {
+		if !alertVariableNameRegExp.MatchString(v.Name) {
+			return fmt.Errorf("invalid variable name %q", v.Name)
+		}
+		if strings.HasPrefix(v.Name, "__") {
+			return fmt.Errorf("variable name %q is reserved for the built-in variables", v.Name)
+		}
+		if _, ok := names[v.Name]; ok {
+			return fmt.Errorf("duplicate variable %q", v.Name)
+		}
+		names[v.Name] = struct{}{}
+
+		switch v.Type {
+		case ConstantAlertVariable:
+		case CustomAlertVariable:
+			count := len(v.Values())
+			if count == 0 {
+				return fmt.Errorf("custom variable %q has no values", v.Name)
+			}
+			combinations *= count
+			if combinations > MaxAlertVariableCombinations {
+				return fmt.Errorf("the custom variables have more than %d combinations of values", MaxAlertVariableCombinations)
+			}
+		default:
+			return fmt.Errorf("variable %q has an unsupported type %q", v.Name, v.Type)
+		}
+	}
+	return nil
+}
+
+// ExpandAlertVariables returns the combinations of the values of variables,
+// with a single combination when they have no custom variables.
+func ExpandAlertVariables(vars []AlertVariable) []AlertVariableCombination {
+	combinations := []AlertVariableCombination{{
+		Values: make(map[string]string, len(vars)),
+		Labels: make(map[string]string),
+	}}
+
+	for _, v := range vars {
+		values := v.Values()
+		if v.Type != CustomAlertVariable {
+			for _, c := range combinations {
+				c.Values[v.Name] = values[0]
+			}
+			continue
+		}
+
+		expanded := make([]AlertVariableCombination, 0, len(combinations)*len(values))
+		for _, c := range combinations {
+			for _, value := range values {
+				next := AlertVariableCombination{
+					Values: make(map[string]string, len(vars)),
+					Labels: make(map[string]string, len(c.Labels)+1),
+				}
+				for name, value := range c.Values {
+					next.Values[name] = value
+				}
+				for name, value := range c.Labels {
+					next.Labels[name] = value
+				}
+				next.Values[v.Name] = value
+				next.Labels[v.Name] = value
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+	return combinations
+}
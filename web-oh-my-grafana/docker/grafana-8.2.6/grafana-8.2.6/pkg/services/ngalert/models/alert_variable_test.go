@@ -0,0 +1,113 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertVariableValues(t *testing.T) {
+	assert.Equal(t, []string{"a, b"}, AlertVariable{Name: "c", Type: ConstantAlertVariable, Query: "a, b"}.Values())
+	assert.Equal(t, []string{"web-1", "web,2", "db"},
+		AlertVariable{Name: "c", Type: CustomAlertVariable, Query: `web-1, web\,2,, db `}.Values())
+}
+
+func TestValidateAlertVariables(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		vars        []AlertVariable
+		expectedErr string
+	}{
+		{
+			desc: "given valid variables",
+			vars: []AlertVariable{
+				{Name: "env", Type: ConstantAlertVariable, Query: "prod"},
+				{Name: "host", Type: CustomAlertVariable, Query: "web-1,web-2"},
+			},
+		},
+		{
+			desc:        "given an invalid name",
+			vars:        []AlertVariable{{Name: "my-var", Type: ConstantAlertVariable}},
+			expectedErr: `invalid variable name "my-var"`,
+		},
+		{
+			desc:        "given the name of a built-in variable",
+			vars:        []AlertVariable{{Name: "__interval", Type: ConstantAlertVariable, Query: "1m"}},
+			expectedErr: `variable name "__interval" is reserved for the built-in variables`,
+		},
+		{
+			desc: "given duplicate names",
+			vars: []AlertVariable{
+				{Name: "env", Type: ConstantAlertVariable, Query: "prod"},
+				{Name: "env", Type: ConstantAlertVariable, Query: "dev"},
+			},
+			expectedErr: `duplicate variable "env"`,
+		},
+		{
+			desc:        "given an unsupported type",
+			vars:        []AlertVariable{{Name: "host", Type: "query", Query: "SELECT host FROM hosts"}},
+			expectedErr: `variable "host" has an unsupported type "query"`,
+		},
+		{
+			desc:        "given a custom variable without values",
+			vars:        []AlertVariable{{Name: "host", Type: CustomAlertVariable, Query: " , "}},
+			expectedErr: `custom variable "host" has no values`,
+		},
+		{
+			desc: "given too many combinations",
+			vars: []AlertVariable{
+				{Name: "a", Type: CustomAlertVariable, Query: strings.Repeat("x,", 11)},
+				{Name: "b", Type: CustomAlertVariable, Query: strings.Repeat("y,", 10)},
+			},
+			expectedErr: fmt.Sprintf("the custom variables have more than %d combinations of values", MaxAlertVariableCombinations),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := ValidateAlertVariables(tc.vars)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestExpandAlertVariables(t *testing.T) {
+	t.Run("given no variables", func(t *testing.T) {
+		combinations := ExpandAlertVariables(nil)
+		require.Len(t, combinations, 1)
+		assert.Empty(t, combinations[0].Values)
+		assert.Empty(t, combinations[0].Labels)
+	})
+
+	t.Run("given constant and custom variables", func(t *testing.T) {
+		combinations := ExpandAlertVariables([]AlertVariable{
+			{Name: "env", Type: ConstantAlertVariable, Query: "prod"},
+			{Name: "host", Type: CustomAlertVariable, Query: "web-1,web-2"},
+			{Name: "dc", Type: CustomAlertVariable, Query: "eu,us"},
+		})
+		assert.Equal(t, []AlertVariableCombination{
+			{
+				Values: map[string]string{"env": "prod", "host": "web-1", "dc": "eu"},
+				Labels: map[string]string{"host": "web-1", "dc": "eu"},
+			},
+			{
+				Values: map[string]string{"env": "prod", "host": "web-1", "dc": "us"},
+				Labels: map[string]string{"host": "web-1", "dc": "us"},
+			},
+			{
+				Values: map[string]string{"env": "prod", "host": "web-2", "dc": "eu"},
+				Labels: map[string]string{"host": "web-2", "dc": "eu"},
+			},
+			{
+				Values: map[string]string{"env": "prod", "host": "web-2", "dc": "us"},
+				Labels: map[string]string{"host": "web-2", "dc": "us"},
+			},
+		}, combinations)
+	})
+}
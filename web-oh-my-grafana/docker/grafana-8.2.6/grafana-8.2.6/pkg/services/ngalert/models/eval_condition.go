@@ -8,9 +8,10 @@ import (
 
 // EvalAlertConditionCommand is the command for evaluating a condition
 type EvalAlertConditionCommand struct {
-	Condition string       `json:"condition"`
-	Data      []AlertQuery `json:"data"`
-	Now       time.Time    `json:"now"`
+	Condition string          `json:"condition"`
+	Data      []AlertQuery    `json:"data"`
+	Variables []AlertVariable `json:"variables,omitempty"`
+	Now       time.Time       `json:"now"`
 }
 
 func (cmd *EvalAlertConditionCommand) UnmarshalJSON(b []byte) error {
@@ -31,5 +32,5 @@ func (cmd *EvalAlertConditionCommand) validate() error {
 		return fmt.Errorf("missing data")
 	}
 
-	return nil
+	return ValidateAlertVariables(cmd.Variables)
 }
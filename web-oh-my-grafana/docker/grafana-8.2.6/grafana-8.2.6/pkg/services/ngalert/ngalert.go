@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -39,18 +40,19 @@ const (
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, kvStore kvstore.KVStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, m *metrics.NGAlert) (*AlertNG, error) {
+	quotaService *quota.QuotaService, encryptionService encryption.Service, m *metrics.NGAlert) (*AlertNG, error) {
 	ng := &AlertNG{
-		Cfg:             cfg,
-		DataSourceCache: dataSourceCache,
-		RouteRegister:   routeRegister,
-		SQLStore:        sqlStore,
-		KVStore:         kvStore,
-		DataService:     dataService,
-		DataProxy:       dataProxy,
-		QuotaService:    quotaService,
-		Metrics:         m,
-		Log:             log.New("ngalert"),
+		Cfg:               cfg,
+		DataSourceCache:   dataSourceCache,
+		RouteRegister:     routeRegister,
+		SQLStore:          sqlStore,
+		KVStore:           kvStore,
+		DataService:       dataService,
+		DataProxy:         dataProxy,
+		QuotaService:      quotaService,
+		EncryptionService: encryptionService,
+		Metrics:           m,
+		Log:               log.New("ngalert"),
 	}
 
 	if ng.IsDisabled() {
@@ -66,18 +68,19 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 
 // AlertNG is the service for evaluating the condition of an alert definition.
 type AlertNG struct {
-	Cfg             *setting.Cfg
-	DataSourceCache datasources.CacheService
-	RouteRegister   routing.RouteRegister
-	SQLStore        *sqlstore.SQLStore
-	KVStore         kvstore.KVStore
-	DataService     *tsdb.Service
-	DataProxy       *datasourceproxy.DataSourceProxyService
-	QuotaService    *quota.QuotaService
-	Metrics         *metrics.NGAlert
-	Log             log.Logger
-	schedule        schedule.ScheduleService
-	stateManager    *state.Manager
+	Cfg               *setting.Cfg
+	DataSourceCache   datasources.CacheService
+	RouteRegister     routing.RouteRegister
+	SQLStore          *sqlstore.SQLStore
+	KVStore           kvstore.KVStore
+	DataService       *tsdb.Service
+	DataProxy         *datasourceproxy.DataSourceProxyService
+	QuotaService      *quota.QuotaService
+	EncryptionService encryption.Service
+	Metrics           *metrics.NGAlert
+	Log               log.Logger
+	schedule          schedule.ScheduleService
+	stateManager      *state.Manager
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
@@ -100,7 +103,8 @@ func (ng *AlertNG) init() error {
 	}
 
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
-	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, multiOrgMetrics, log.New("ngalert.multiorg.alertmanager"))
+	ng.MultiOrgAlertmanager, err = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.KVStore, ng.EncryptionService.GetDecryptedValue,
+		multiOrgMetrics, log.New("ngalert.multiorg.alertmanager"))
 	if err != nil {
 		return err
 	}
@@ -152,6 +156,7 @@ func (ng *AlertNG) init() error {
 		AdminConfigStore:     store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		EncryptionService:    ng.EncryptionService,
 	}
 	api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -20,6 +20,7 @@ import (
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/logging"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -48,7 +49,8 @@ func setupAMTest(t *testing.T) *Alertmanager {
 	}
 
 	kvStore := newFakeKVStore(t)
-	am, err := newAlertmanager(1, cfg, s, kvStore, &NilPeer{}, m)
+	encryptionService := &ossencryption.Service{}
+	am, err := newAlertmanager(1, cfg, s, kvStore, &NilPeer{}, encryptionService.GetDecryptedValue, m)
 	require.NoError(t, err)
 	return am
 }
@@ -14,6 +14,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestNewAlertmanagerNotifier(t *testing.T) {
@@ -54,7 +55,8 @@ func TestNewAlertmanagerNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			sn, err := NewAlertmanagerNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			sn, err := NewAlertmanagerNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expectedInitError != "" {
 				require.Equal(t, c.expectedInitError, err.Error())
 				return
@@ -133,7 +135,8 @@ func TestAlertmanagerNotifier_Notify(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			sn, err := NewAlertmanagerNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			sn, err := NewAlertmanagerNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			require.NoError(t, err)
 
 			var body []byte
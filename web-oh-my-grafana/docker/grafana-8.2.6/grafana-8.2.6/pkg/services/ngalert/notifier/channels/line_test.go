@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestLineNotifier(t *testing.T) {
@@ -88,7 +89,8 @@ func TestLineNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewLineNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			pn, err := NewLineNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
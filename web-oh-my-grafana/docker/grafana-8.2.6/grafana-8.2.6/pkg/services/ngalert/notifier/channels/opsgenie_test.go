@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestOpsgenieNotifier(t *testing.T) {
@@ -168,7 +169,8 @@ func TestOpsgenieNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewOpsgenieNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			pn, err := NewOpsgenieNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
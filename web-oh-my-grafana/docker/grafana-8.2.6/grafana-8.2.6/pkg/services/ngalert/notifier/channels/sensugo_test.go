@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestSensuGoNotifier(t *testing.T) {
@@ -139,7 +140,8 @@ func TestSensuGoNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			sn, err := NewSensuGoNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			sn, err := NewSensuGoNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestSlackNotifier(t *testing.T) {
@@ -170,7 +171,8 @@ func TestSlackNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewSlackNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			pn, err := NewSlackNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
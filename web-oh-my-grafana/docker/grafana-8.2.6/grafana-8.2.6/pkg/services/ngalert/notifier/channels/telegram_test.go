@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 )
 
 func TestTelegramNotifier(t *testing.T) {
@@ -94,7 +95,8 @@ func TestTelegramNotifier(t *testing.T) {
 				Settings: settingsJSON,
 			}
 
-			pn, err := NewTelegramNotifier(m, tmpl)
+			encryptionService := &ossencryption.Service{}
+			pn, err := NewTelegramNotifier(m, tmpl, encryptionService.GetDecryptedValue)
 			if c.expInitError != "" {
 				require.Error(t, err)
 				require.Equal(t, c.expInitError, err.Error())
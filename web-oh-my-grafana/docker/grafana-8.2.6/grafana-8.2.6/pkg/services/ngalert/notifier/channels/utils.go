@@ -64,13 +64,9 @@ type NotificationChannelConfig struct {
 	SecureSettings        securejsondata.SecureJsonData `json:"secureSettings"`
 }
 
-// DecryptedValue returns decrypted value from secureSettings
-func (an *NotificationChannelConfig) DecryptedValue(field string, fallback string) string {
-	if value, ok := an.SecureSettings.DecryptedValue(field); ok {
-		return value
-	}
-	return fallback
-}
+// GetDecryptedValueFn returns the decrypted value of a key of secure
+// settings, or fallback if the key is missing, see encryption.Service.
+type GetDecryptedValueFn func(sjd map[string][]byte, key string, fallback string, secret string) string
 
 type httpCfg struct {
 	body     []byte
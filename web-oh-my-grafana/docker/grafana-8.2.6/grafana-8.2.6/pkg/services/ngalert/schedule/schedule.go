@@ -456,6 +456,7 @@ func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRul
 					Condition: alertRule.Condition,
 					OrgID:     alertRule.OrgID,
 					Data:      alertRule.Data,
+					Variables: alertRule.Variables,
 				}
 				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
 				var (
@@ -148,6 +148,7 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 			Title:           r.GrafanaManagedAlert.Title,
 			Condition:       r.GrafanaManagedAlert.Condition,
 			Data:            r.GrafanaManagedAlert.Data,
+			Variables:       r.GrafanaManagedAlert.Variables,
 			UID:             util.GenerateShortUID(),
 			IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
 			NamespaceUID:    cmd.NamespaceUID,
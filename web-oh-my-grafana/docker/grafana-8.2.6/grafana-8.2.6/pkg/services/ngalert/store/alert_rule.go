@@ -298,6 +298,7 @@ func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 				For:              r.New.For,
 				Annotations:      r.New.Annotations,
 				Labels:           r.New.Labels,
+				Variables:        r.New.Variables,
 			})
 		}
 
@@ -485,6 +486,10 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 		return fmt.Errorf("%w: title is empty", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if err := ngmodels.ValidateAlertVariables(alertRule.Variables); err != nil {
+		return fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err)
+	}
+
 	if alertRule.IntervalSeconds%int64(st.BaseInterval.Seconds()) != 0 || alertRule.IntervalSeconds <= 0 {
 		return fmt.Errorf("%w: interval (%v) should be non-zero and divided exactly by scheduler interval: %v", ngmodels.ErrAlertRuleFailedValidation, time.Duration(alertRule.IntervalSeconds)*time.Second, st.BaseInterval)
 	}
@@ -540,6 +545,7 @@ func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
 				Title:           r.GrafanaManagedAlert.Title,
 				Condition:       r.GrafanaManagedAlert.Condition,
 				Data:            r.GrafanaManagedAlert.Data,
+				Variables:       r.GrafanaManagedAlert.Variables,
 				UID:             r.GrafanaManagedAlert.UID,
 				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
 				NamespaceUID:    cmd.NamespaceUID,
@@ -0,0 +1,189 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var getTime = time.Now
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore) (*PasswordPolicyService, error) {
+	s := &PasswordPolicyService{
+		Cfg:      cfg,
+		SQLStore: sqlStore,
+	}
+
+	if cfg.PasswordPolicy.Enabled && cfg.PasswordPolicy.BreachedPasswordsFile != "" {
+		breached, err := readBreachedPasswords(cfg.PasswordPolicy.BreachedPasswordsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read breached passwords file: %w", err)
+		}
+		s.breached = breached
+	}
+
+	return s, nil
+}
+
+type Service interface {
+	// Validate returns an error wrapping one of the models.ErrPassword
+	// errors when the password doesn't satisfy the policy. The user is nil
+	// when the password is set for a new user.
+	Validate(ctx context.Context, user *models.User, password string) error
+	// ChangeRequired reports whether the user has to change their password
+	// before they can log in with it.
+	ChangeRequired(ctx context.Context, user *models.User) (bool, error)
+}
+
+// PasswordPolicyService enforces the password policy of the users logging
+// in with the Grafana login form.
+type PasswordPolicyService struct {
+	Cfg      *setting.Cfg
+	SQLStore *sqlstore.SQLStore
+
+	// breached holds the lowercased passwords of the breached passwords file.
+	breached map[string]struct{}
+}
+
+func (s *PasswordPolicyService) Validate(ctx context.Context, user *models.User, password string) error {
+	policy := s.Cfg.PasswordPolicy
+	if !policy.Enabled {
+		return nil
+	}
+
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return fmt.Errorf("%w, it must be at least %d characters long", models.ErrPasswordTooShort, policy.MinLength)
+	}
+
+	if missing := missingCharacters(policy, password); len(missing) > 0 {
+		return fmt.Errorf("%w, it must contain %s", models.ErrPasswordMissingCharacters, strings.Join(missing, ", "))
+	}
+
+	if _, ok := s.breached[strings.ToLower(password)]; ok {
+		return models.ErrPasswordBreached
+	}
+
+	if user == nil || user.Id == 0 || policy.HistoryCount == 0 {
+		return nil
+	}
+
+	var history []models.UserPasswordHistory
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		return sess.Where("user_id = ?", user.Id).Desc("id").Limit(policy.HistoryCount).Find(&history)
+	})
+	if err != nil {
+		return err
+	}
+	// Users created before the history was recorded only have their
+	// current password.
+	if len(history) == 0 && user.Password != "" {
+		history = append(history, models.UserPasswordHistory{Password: user.Password, Salt: user.Salt})
+	}
+
+	for _, previous := range history {
+		encoded, err := util.EncodePassword(password, previous.Salt)
+		if err != nil {
+			return err
+		}
+		if subtle.ConstantTimeCompare([]byte(encoded), []byte(previous.Password)) == 1 {
+			return models.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+func (s *PasswordPolicyService) ChangeRequired(ctx context.Context, user *models.User) (bool, error) {
+	if user.PasswordChangeRequired {
+		return true, nil
+	}
+
+	policy := s.Cfg.PasswordPolicy
+	if !policy.Enabled || policy.MaxAge == 0 {
+		return false, nil
+	}
+
+	// Passwords set before the history was recorded are as old as the user.
+	changed := user.Created
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		latest := models.UserPasswordHistory{}
+		has, err := sess.Where("user_id = ?", user.Id).Desc("id").Get(&latest)
+		if has {
+			changed = latest.Created
+		}
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return getTime().Sub(changed) > policy.MaxAge, nil
+}
+
+func missingCharacters(policy setting.PasswordPolicySettings, password string) []string {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing []string
+	if policy.RequireUppercase && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if policy.RequireLowercase && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if policy.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if policy.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	return missing
+}
+
+// readBreachedPasswords reads a file of passwords, one per line. Empty
+// lines and lines starting with # are ignored.
+func readBreachedPasswords(path string) (map[string]struct{}, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `path` comes from Grafana configuration file
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached[strings.ToLower(line)] = struct{}{}
+	}
+
+	return breached, scanner.Err()
+}
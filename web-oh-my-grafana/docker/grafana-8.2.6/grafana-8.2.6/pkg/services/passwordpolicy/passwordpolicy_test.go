@@ -0,0 +1,139 @@
+package passwordpolicy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestService(t *testing.T) (*PasswordPolicyService, *sqlstore.SQLStore) {
+	t.Helper()
+
+	breached := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(breached, []byte("# common passwords\nPassword1234\n\nQwerty123456\n"), 0600))
+
+	cfg := setting.NewCfg()
+	cfg.PasswordPolicy = setting.PasswordPolicySettings{
+		Enabled:               true,
+		MinLength:             10,
+		RequireUppercase:      true,
+		RequireLowercase:      true,
+		RequireDigit:          true,
+		BreachedPasswordsFile: breached,
+		HistoryCount:          2,
+		MaxAge:                90 * 24 * time.Hour,
+	}
+
+	sqlStore := sqlstore.InitTestDB(t)
+	s, err := ProvideService(cfg, sqlStore)
+	require.NoError(t, err)
+	return s, sqlStore
+}
+
+func changePassword(t *testing.T, user *models.User, password string) {
+	t.Helper()
+
+	encoded, err := util.EncodePassword(password, user.Salt)
+	require.NoError(t, err)
+	require.NoError(t, sqlstore.ChangeUserPassword(&models.ChangeUserPasswordCommand{UserId: user.Id, NewPassword: encoded}))
+	user.Password = encoded
+}
+
+func TestValidate(t *testing.T) {
+	s, sqlStore := setupTestService(t)
+	ctx := context.Background()
+
+	t.Run("checks the length and the characters", func(t *testing.T) {
+		assert.ErrorIs(t, s.Validate(ctx, nil, "Sh0rt"), models.ErrPasswordTooShort)
+		err := s.Validate(ctx, nil, "alllowercase1")
+		assert.ErrorIs(t, err, models.ErrPasswordMissingCharacters)
+		assert.Contains(t, err.Error(), "an uppercase letter")
+		assert.ErrorIs(t, s.Validate(ctx, nil, "NoDigitsHere"), models.ErrPasswordMissingCharacters)
+		assert.NoError(t, s.Validate(ctx, nil, "Corr3ctHorse"))
+	})
+
+	t.Run("rejects breached passwords", func(t *testing.T) {
+		assert.ErrorIs(t, s.Validate(ctx, nil, "Password1234"), models.ErrPasswordBreached)
+		assert.ErrorIs(t, s.Validate(ctx, nil, "Qwerty123456"), models.ErrPasswordBreached)
+		// the list is matched case-insensitively
+		assert.ErrorIs(t, s.Validate(ctx, nil, "pASSWORD1234"), models.ErrPasswordBreached)
+	})
+
+	t.Run("rejects the last passwords of the user", func(t *testing.T) {
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "reuse", Password: "First1234567"})
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, s.Validate(ctx, user, "First1234567"), models.ErrPasswordReused)
+
+		changePassword(t, user, "Second123456")
+		changePassword(t, user, "Third1234567")
+		assert.ErrorIs(t, s.Validate(ctx, user, "Second123456"), models.ErrPasswordReused)
+		assert.ErrorIs(t, s.Validate(ctx, user, "Third1234567"), models.ErrPasswordReused)
+		// only the last two passwords are checked
+		assert.NoError(t, s.Validate(ctx, user, "First1234567"))
+	})
+
+	t.Run("accepts any password when disabled", func(t *testing.T) {
+		s.Cfg.PasswordPolicy.Enabled = false
+		t.Cleanup(func() { s.Cfg.PasswordPolicy.Enabled = true })
+
+		assert.NoError(t, s.Validate(ctx, nil, "Qwerty123456"))
+	})
+}
+
+func TestChangeRequired(t *testing.T) {
+	s, sqlStore := setupTestService(t)
+	ctx := context.Background()
+
+	user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "expiry", Password: "First1234567"})
+	require.NoError(t, err)
+
+	required, err := s.ChangeRequired(ctx, user)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	t.Run("passwords expire after the max age", func(t *testing.T) {
+		getTime = func() time.Time { return time.Now().Add(91 * 24 * time.Hour) }
+		t.Cleanup(func() { getTime = time.Now })
+
+		required, err := s.ChangeRequired(ctx, user)
+		require.NoError(t, err)
+		assert.True(t, required)
+
+		changePassword(t, user, "Second123456")
+		getTime = func() time.Time { return time.Now().Add(89 * 24 * time.Hour) }
+		required, err = s.ChangeRequired(ctx, user)
+		require.NoError(t, err)
+		assert.False(t, required)
+	})
+
+	t.Run("administrators can require a change", func(t *testing.T) {
+		encoded, err := util.EncodePassword("Temporary123", user.Salt)
+		require.NoError(t, err)
+		err = sqlstore.ChangeUserPassword(&models.ChangeUserPasswordCommand{
+			UserId:                 user.Id,
+			NewPassword:            encoded,
+			PasswordChangeRequired: true,
+		})
+		require.NoError(t, err)
+
+		query := models.GetUserByIdQuery{Id: user.Id}
+		require.NoError(t, sqlstore.GetUserById(ctx, &query))
+		required, err := s.ChangeRequired(ctx, query.Result)
+		require.NoError(t, err)
+		assert.True(t, required)
+
+		changePassword(t, user, "Fourth123456")
+		require.NoError(t, sqlstore.GetUserById(ctx, &query))
+		assert.False(t, query.Result.PasswordChangeRequired)
+	})
+}
@@ -0,0 +1,26 @@
+package passwordpolicy
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// FakePasswordPolicyService is a Service for tests. Every password is
+// valid unless Err is set, and users only have to change their password
+// when it is required on the user.
+type FakePasswordPolicyService struct {
+	Err error
+}
+
+func NewFakePasswordPolicyService() *FakePasswordPolicyService {
+	return &FakePasswordPolicyService{}
+}
+
+func (s *FakePasswordPolicyService) Validate(ctx context.Context, user *models.User, password string) error {
+	return s.Err
+}
+
+func (s *FakePasswordPolicyService) ChangeRequired(ctx context.Context, user *models.User) (bool, error) {
+	return user.PasswordChangeRequired, nil
+}
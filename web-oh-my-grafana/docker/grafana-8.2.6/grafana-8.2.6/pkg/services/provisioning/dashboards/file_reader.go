@@ -16,6 +16,7 @@ import (
 	dboards "github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -265,9 +266,11 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
 		}
-		if _, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(dash, dp); err != nil {
+		saved, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(dash, dp)
+		if err != nil {
 			return provisioningMetadata, err
 		}
+		fr.recordAuditEvent(saved)
 	} else {
 		fr.log.Warn("Not saving new dashboard due to restricted database access", "provisioner", fr.Cfg.Name,
 			"file", path, "folderId", dash.Dashboard.FolderId)
@@ -276,6 +279,22 @@ func (fr *FileReader) saveDashboard(path string, folderID int64, fileInfo os.Fil
 	return provisioningMetadata, nil
 }
 
+// recordAuditEvent records the provisioning of a dashboard, which isn't saved
+// through an audited bus command.
+func (fr *FileReader) recordAuditEvent(dash *models.Dashboard) {
+	cmd := &models.RecordAuditEventCommand{Event: &models.AuditEvent{
+		OrgId:        dash.OrgId,
+		ActorType:    audit.ProvisioningActor.Type,
+		ActorLogin:   audit.ProvisioningActor.Login,
+		Action:       "dashboard.provision",
+		ResourceType: "dashboard",
+		ResourceUid:  dash.Uid,
+	}}
+	if err := bus.Dispatch(cmd); err != nil && !errors.Is(err, bus.ErrHandlerNotFound) {
+		fr.log.Warn("Failed to record audit event", "uid", dash.Uid, "error", err)
+	}
+}
+
 func getProvisionedDashboardsByPath(service dashboards.DashboardProvisioningService, name string) (
 	map[string]*models.DashboardProvisioning, error) {
 	arr, err := service.GetProvisionedDashboardData(name)
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -38,7 +39,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 		bus.AddHandler("test", mockGetOrg)
 
 		Convey("apply default values when missing", func() {
-			dc := newDatasourceProvisioner(logger)
+			dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 			err := dc.applyChanges(withoutDefaults)
 			if err != nil {
 				t.Fatalf("applyChanges return an error %v", err)
@@ -51,7 +52,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 
 		Convey("One configured datasource", func() {
 			Convey("no datasource in database", func() {
-				dc := newDatasourceProvisioner(logger)
+				dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 				err := dc.applyChanges(twoDatasourcesConfig)
 				if err != nil {
 					t.Fatalf("applyChanges return an error %v", err)
@@ -68,7 +69,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 				}
 
 				Convey("should update one datasource", func() {
-					dc := newDatasourceProvisioner(logger)
+					dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 					err := dc.applyChanges(twoDatasourcesConfig)
 					if err != nil {
 						t.Fatalf("applyChanges return an error %v", err)
@@ -81,7 +82,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 			})
 
 			Convey("Two datasources with is_default", func() {
-				dc := newDatasourceProvisioner(logger)
+				dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 				err := dc.applyChanges(doubleDatasourcesConfig)
 				Convey("should raise error", func() {
 					So(err, ShouldEqual, ErrInvalidConfigToManyDefault)
@@ -90,7 +91,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 		})
 
 		Convey("Multiple datasources in different organizations with isDefault in each organization", func() {
-			dc := newDatasourceProvisioner(logger)
+			dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 			err := dc.applyChanges(multipleOrgsWithDefault)
 			Convey("should not raise error", func() {
 				So(err, ShouldBeNil)
@@ -110,7 +111,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 				}
 
 				Convey("should have two new datasources", func() {
-					dc := newDatasourceProvisioner(logger)
+					dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 					err := dc.applyChanges(twoDatasourcesConfigPurgeOthers)
 					if err != nil {
 						t.Fatalf("applyChanges return an error %v", err)
@@ -131,7 +132,7 @@ func TestDatasourceAsConfig(t *testing.T) {
 				}
 
 				Convey("should have two new datasources", func() {
-					dc := newDatasourceProvisioner(logger)
+					dc := newDatasourceProvisioner(&ossencryption.Service{}, logger)
 					err := dc.applyChanges(twoDatasourcesConfig)
 					if err != nil {
 						t.Fatalf("applyChanges return an error %v", err)
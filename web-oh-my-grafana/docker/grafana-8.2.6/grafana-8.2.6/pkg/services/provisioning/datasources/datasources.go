@@ -1,6 +1,7 @@
 package datasources
 
 import (
+	"context"
 	"errors"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -8,6 +9,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -18,27 +22,30 @@ var (
 
 // Provision scans a directory for provisioning config files
 // and provisions the datasource in those files.
-func Provision(configDirectory string) error {
-	dc := newDatasourceProvisioner(log.New("provisioning.datasources"))
+func Provision(configDirectory string, encryptionService encryption.Service) error {
+	dc := newDatasourceProvisioner(encryptionService, log.New("provisioning.datasources"))
 	return dc.applyChanges(configDirectory)
 }
 
 // DatasourceProvisioner is responsible for provisioning datasources based on
 // configuration read by the `configReader`
 type DatasourceProvisioner struct {
-	log         log.Logger
-	cfgProvider *configReader
+	log               log.Logger
+	cfgProvider       *configReader
+	encryptionService encryption.Service
 }
 
-func newDatasourceProvisioner(log log.Logger) DatasourceProvisioner {
+func newDatasourceProvisioner(encryptionService encryption.Service, log log.Logger) DatasourceProvisioner {
 	return DatasourceProvisioner{
-		log:         log,
-		cfgProvider: &configReader{log: log},
+		log:               log,
+		cfgProvider:       &configReader{log: log},
+		encryptionService: encryptionService,
 	}
 }
 
 func (dc *DatasourceProvisioner) apply(cfg *configs) error {
-	if err := dc.deleteDatasources(cfg.DeleteDatasources); err != nil {
+	ctx := audit.WithActor(context.Background(), audit.ProvisioningActor)
+	if err := dc.deleteDatasources(ctx, cfg.DeleteDatasources); err != nil {
 		return err
 	}
 
@@ -49,16 +56,23 @@ func (dc *DatasourceProvisioner) apply(cfg *configs) error {
 			return err
 		}
 
+		encryptedSecureJsonData, encryptErr := dc.encryptionService.EncryptJsonData(ds.SecureJSONData, setting.SecretKey)
+		if encryptErr != nil {
+			return encryptErr
+		}
+
 		if errors.Is(err, models.ErrDataSourceNotFound) {
 			dc.log.Info("inserting datasource from configuration ", "name", ds.Name, "uid", ds.UID)
 			insertCmd := createInsertCommand(ds)
-			if err := bus.Dispatch(insertCmd); err != nil {
+			insertCmd.EncryptedSecureJsonData = encryptedSecureJsonData
+			if err := bus.DispatchCtx(ctx, insertCmd); err != nil {
 				return err
 			}
 		} else {
 			dc.log.Debug("updating datasource from configuration", "name", ds.Name, "uid", ds.UID)
 			updateCmd := createUpdateCommand(ds, cmd.Result.Id)
-			if err := bus.Dispatch(updateCmd); err != nil {
+			updateCmd.EncryptedSecureJsonData = encryptedSecureJsonData
+			if err := bus.DispatchCtx(ctx, updateCmd); err != nil {
 				return err
 			}
 		}
@@ -82,10 +96,10 @@ func (dc *DatasourceProvisioner) applyChanges(configPath string) error {
 	return nil
 }
 
-func (dc *DatasourceProvisioner) deleteDatasources(dsToDelete []*deleteDatasourceConfig) error {
+func (dc *DatasourceProvisioner) deleteDatasources(ctx context.Context, dsToDelete []*deleteDatasourceConfig) error {
 	for _, ds := range dsToDelete {
 		cmd := &models.DeleteDataSourceCommand{OrgID: ds.OrgID, Name: ds.Name}
-		if err := bus.Dispatch(cmd); err != nil {
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
 			return err
 		}
 
@@ -1,43 +1,54 @@
 package notifiers
 
 import (
+	"context"
+
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // Provision alert notifiers
-func Provision(configDirectory string) error {
-	dc := newNotificationProvisioner(log.New("provisioning.notifiers"))
+func Provision(configDirectory string, encryptionService encryption.Service) error {
+	dc := newNotificationProvisioner(encryptionService, log.New("provisioning.notifiers"))
 	return dc.applyChanges(configDirectory)
 }
 
 // NotificationProvisioner is responsible for provsioning alert notifiers
 type NotificationProvisioner struct {
-	log         log.Logger
-	cfgProvider *configReader
+	log               log.Logger
+	cfgProvider       *configReader
+	encryptionService encryption.Service
 }
 
-func newNotificationProvisioner(log log.Logger) NotificationProvisioner {
+func newNotificationProvisioner(encryptionService encryption.Service, log log.Logger) NotificationProvisioner {
 	return NotificationProvisioner{
-		log:         log,
-		cfgProvider: &configReader{log: log},
+		log: log,
+		cfgProvider: &configReader{
+			encryptionService: encryptionService,
+			log:               log,
+		},
+		encryptionService: encryptionService,
 	}
 }
 
 func (dc *NotificationProvisioner) apply(cfg *notificationsAsConfig) error {
-	if err := dc.deleteNotifications(cfg.DeleteNotifications); err != nil {
+	ctx := audit.WithActor(context.Background(), audit.ProvisioningActor)
+	if err := dc.deleteNotifications(ctx, cfg.DeleteNotifications); err != nil {
 		return err
 	}
 
-	if err := dc.mergeNotifications(cfg.Notifications); err != nil {
+	if err := dc.mergeNotifications(ctx, cfg.Notifications); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (dc *NotificationProvisioner) deleteNotifications(notificationToDelete []*deleteNotificationConfig) error {
+func (dc *NotificationProvisioner) deleteNotifications(ctx context.Context, notificationToDelete []*deleteNotificationConfig) error {
 	for _, notification := range notificationToDelete {
 		dc.log.Info("Deleting alert notification", "name", notification.Name, "uid", notification.UID)
 
@@ -59,7 +70,7 @@ func (dc *NotificationProvisioner) deleteNotifications(notificationToDelete []*d
 
 		if getNotification.Result != nil {
 			cmd := &models.DeleteAlertNotificationWithUidCommand{Uid: getNotification.Result.Uid, OrgId: getNotification.OrgId}
-			if err := bus.Dispatch(cmd); err != nil {
+			if err := bus.DispatchCtx(ctx, cmd); err != nil {
 				return err
 			}
 		}
@@ -68,7 +79,7 @@ func (dc *NotificationProvisioner) deleteNotifications(notificationToDelete []*d
 	return nil
 }
 
-func (dc *NotificationProvisioner) mergeNotifications(notificationToMerge []*notificationFromConfig) error {
+func (dc *NotificationProvisioner) mergeNotifications(ctx context.Context, notificationToMerge []*notificationFromConfig) error {
 	for _, notification := range notificationToMerge {
 		if notification.OrgID == 0 && notification.OrgName != "" {
 			getOrg := &models.GetOrgByNameQuery{Name: notification.OrgName}
@@ -86,6 +97,11 @@ func (dc *NotificationProvisioner) mergeNotifications(notificationToMerge []*not
 			return err
 		}
 
+		encryptedSecureSettings, err := dc.encryptionService.EncryptJsonData(notification.SecureSettings, setting.SecretKey)
+		if err != nil {
+			return err
+		}
+
 		if cmd.Result == nil {
 			dc.log.Debug("inserting alert notification from configuration", "name", notification.Name, "uid", notification.UID)
 			insertCmd := &models.CreateAlertNotificationCommand{
@@ -99,9 +115,11 @@ func (dc *NotificationProvisioner) mergeNotifications(notificationToMerge []*not
 				DisableResolveMessage: notification.DisableResolveMessage,
 				Frequency:             notification.Frequency,
 				SendReminder:          notification.SendReminder,
+
+				EncryptedSecureSettings: encryptedSecureSettings,
 			}
 
-			if err := bus.Dispatch(insertCmd); err != nil {
+			if err := bus.DispatchCtx(ctx, insertCmd); err != nil {
 				return err
 			}
 		} else {
@@ -117,9 +135,11 @@ func (dc *NotificationProvisioner) mergeNotifications(notificationToMerge []*not
 				DisableResolveMessage: notification.DisableResolveMessage,
 				Frequency:             notification.Frequency,
 				SendReminder:          notification.SendReminder,
+
+				EncryptedSecureSettings: encryptedSecureSettings,
 			}
 
-			if err := bus.Dispatch(updateCmd); err != nil {
+			if err := bus.DispatchCtx(ctx, updateCmd); err != nil {
 				return err
 			}
 		}
@@ -7,15 +7,18 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // Provision scans a directory for provisioning config files
 // and provisions the app in those files.
-func Provision(configDirectory string, pluginManager plugins.Manager) error {
+func Provision(configDirectory string, pluginManager plugins.Manager, encryptionService encryption.Service) error {
 	logger := log.New("provisioning.plugins")
 	ap := PluginProvisioner{
-		log:         logger,
-		cfgProvider: newConfigReader(logger, pluginManager),
+		log:               logger,
+		cfgProvider:       newConfigReader(logger, pluginManager),
+		encryptionService: encryptionService,
 	}
 	return ap.applyChanges(configDirectory)
 }
@@ -23,8 +26,9 @@ func Provision(configDirectory string, pluginManager plugins.Manager) error {
 // PluginProvisioner is responsible for provisioning apps based on
 // configuration read by the `configReader`
 type PluginProvisioner struct {
-	log         log.Logger
-	cfgProvider configReader
+	log               log.Logger
+	cfgProvider       configReader
+	encryptionService encryption.Service
 }
 
 func (ap *PluginProvisioner) apply(cfg *pluginsAsConfig) error {
@@ -49,6 +53,11 @@ func (ap *PluginProvisioner) apply(cfg *pluginsAsConfig) error {
 			app.PluginVersion = query.Result.PluginVersion
 		}
 
+		encryptedSecureJsonData, err := ap.encryptionService.EncryptJsonData(app.SecureJSONData, setting.SecretKey)
+		if err != nil {
+			return err
+		}
+
 		ap.log.Info("Updating app from configuration ", "type", app.PluginID, "enabled", app.Enabled)
 		cmd := &models.UpdatePluginSettingCmd{
 			OrgId:          app.OrgID,
@@ -58,6 +67,8 @@ func (ap *PluginProvisioner) apply(cfg *pluginsAsConfig) error {
 			JsonData:       app.JSONData,
 			SecureJsonData: app.SecureJSONData,
 			PluginVersion:  app.PluginVersion,
+
+			EncryptedSecureJsonData: encryptedSecureJsonData,
 		}
 		if err := bus.Dispatch(cmd); err != nil {
 			return err
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
 	"github.com/stretchr/testify/require"
 )
 
@@ -57,7 +58,7 @@ func TestPluginProvisioner(t *testing.T) {
 			},
 		}
 		reader := &testConfigReader{result: cfg}
-		ap := PluginProvisioner{log: log.New("test"), cfgProvider: reader}
+		ap := PluginProvisioner{log: log.New("test"), cfgProvider: reader, encryptionService: &ossencryption.Service{}}
 		err := ap.applyChanges("")
 		require.NoError(t, err)
 		require.Len(t, sentCommands, 4)
@@ -0,0 +1,448 @@
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// Cache statuses of the query responses.
+	StatusHit    = "HIT"
+	StatusMiss   = "MISS"
+	StatusBypass = "BYPASS"
+
+	// maxTTL is how long the generation of the cache of a data source is
+	// kept, cached results must expire before it.
+	maxTTL = 24 * time.Hour
+)
+
+var (
+	getTime = time.Now
+	logger  = log.New("querycache")
+
+	requestsTotal      *prometheus.CounterVec
+	storageErrorsTotal prometheus.Counter
+)
+
+func init() {
+	remotecache.Register(&cachedResponse{})
+
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_requests_total",
+		Help:      "Number of data source queries checked against the query cache, by data source type and result",
+	}, []string{"datasource_type", "result"})
+
+	storageErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_storage_errors_total",
+		Help:      "Number of query cache reads or writes that failed, sending the query to the data source",
+	})
+}
+
+func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache,
+	queryLimits *querylimits.QueryLimitsService) *QueryCacheService {
+	s := &QueryCacheService{
+		Cfg:         cfg,
+		QueryLimits: queryLimits,
+		storage:     remoteCache,
+	}
+	if cfg.QueryCache.Storage == setting.QueryCacheStorageLocal {
+		s.storage = &localStorage{cache: localcache.New(time.Minute, 10*time.Minute)}
+	}
+	return s
+}
+
+// HandleRequestFunc runs a data source query.
+//nolint: staticcheck // plugins.DataQuery deprecated
+type HandleRequestFunc func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error)
+
+type Service interface {
+	// HandleRequest returns the cached response of the query, or runs it
+	// with handle and caches its response.
+	//nolint: staticcheck // plugins.DataQuery deprecated
+	HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery, handle HandleRequestFunc) (plugins.DataResponse, error)
+	// Flush removes the cached responses of a data source.
+	Flush(ds *models.DataSource) error
+}
+
+// QueryCacheService caches the responses of the data source queries for
+// the TTL of their data source. Queries are cached by data source, query
+// models and time range. Relative time ranges are cached for the TTL, so that
+// the dashboards refreshed during the same TTL share a response.
+type QueryCacheService struct {
+	Cfg         *setting.Cfg
+	QueryLimits *querylimits.QueryLimitsService
+
+	storage remotecache.CacheStorage
+	group   singleflight.Group
+}
+
+// cachedResponse is a response with data frames only, the legacy series and
+// tables can't be decoded.
+type cachedResponse struct {
+	Results []cachedResult
+}
+
+type cachedResult struct {
+	RefID  string
+	Meta   []byte
+	Frames [][]byte
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *QueryCacheService) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery,
+	handle HandleRequestFunc) (plugins.DataResponse, error) {
+	ttl := s.ttl(ds)
+	if ttl == 0 || !cacheable(ctx, ds, query) {
+		setStatus(ctx, StatusBypass)
+		return handle(ctx, ds, query)
+	}
+
+	key, err := s.key(ds, query, ttl)
+	if err != nil {
+		storageErrorsTotal.Inc()
+		logger.Warn("Failed to get query cache key", "datasource", ds.Uid, "error", err)
+		setStatus(ctx, StatusBypass)
+		return handle(ctx, ds, query)
+	}
+
+	if resp, ok := s.get(key); ok {
+		requestsTotal.WithLabelValues(ds.Type, "hit").Inc()
+		setStatus(ctx, StatusHit)
+		return resp, nil
+	}
+
+	requestsTotal.WithLabelValues(ds.Type, "miss").Inc()
+	setStatus(ctx, StatusMiss)
+
+	// Dashboards refreshed at the same time only query the data source once
+	// per instance. The query is shared, so it must not be canceled when the
+	// request that started it is, it only ends with the query timeout of the
+	// data source.
+	result := s.group.DoChan(key, func() (interface{}, error) {
+		shared, cancel := s.sharedContext(ctx, ds)
+		defer cancel()
+		resp, err := handle(shared, ds, query)
+		if err != nil {
+			return nil, err
+		}
+		s.set(key, resp, ttl)
+		return resp, nil
+	})
+	select {
+	case res := <-result:
+		if res.Err != nil {
+			return plugins.DataResponse{}, res.Err
+		}
+		return res.Val.(plugins.DataResponse), nil
+	case <-ctx.Done():
+		return plugins.DataResponse{}, ctx.Err()
+	}
+}
+
+// sharedContext returns a context with the values of ctx that isn't canceled
+// with it, but at the query timeout of the data source.
+func (s *QueryCacheService) sharedContext(ctx context.Context, ds *models.DataSource) (context.Context, context.CancelFunc) {
+	var timeout time.Duration
+	if s.QueryLimits != nil {
+		timeout = s.QueryLimits.GetLimits(ds).Timeout
+	}
+	if timeout == 0 {
+		timeout = time.Duration(s.Cfg.DataProxyTimeout) * time.Second
+	}
+	if timeout <= 0 {
+		return detachedContext{ctx}, func() {}
+	}
+	return context.WithTimeout(detachedContext{ctx}, timeout)
+}
+
+func (s *QueryCacheService) Flush(ds *models.DataSource) error {
+	// Responses are cached by generation, a new generation makes the
+	// responses of the previous ones unreachable until they expire.
+	return s.storage.Set(generationKey(ds), getTime().UnixNano(), maxTTL)
+}
+
+// ttl returns how long the responses of a data source are cached, zero when
+// they aren't.
+func (s *QueryCacheService) ttl(ds *models.DataSource) time.Duration {
+	if !s.Cfg.QueryCache.Enabled {
+		return 0
+	}
+
+	ttl := s.Cfg.QueryCache.DefaultTTL
+	if ds.JsonData != nil {
+		if value := ds.JsonData.Get("queryCacheTTL").MustString(""); value != "" {
+			parsed, err := gtime.ParseDuration(value)
+			if err != nil {
+				logger.Warn("Invalid queryCacheTTL of data source, not caching", "datasource", ds.Uid, "queryCacheTTL", value)
+				return 0
+			}
+			ttl = parsed
+		}
+	}
+
+	if ttl < time.Second {
+		return 0
+	}
+	if ttl > maxTTL {
+		return maxTTL
+	}
+	return ttl
+}
+
+// cacheable reports whether the response of a query is the same for every
+// user who can query the data source.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func cacheable(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) bool {
+	if query.TimeRange == nil || query.Debug {
+		return false
+	}
+	if skip, _ := ctx.Value(skipKey{}).(bool); skip {
+		return false
+	}
+	// Responses depend on the token of the user.
+	if ds.JsonData != nil && ds.JsonData.Get("oauthPassThru").MustBool(false) {
+		return false
+	}
+	return true
+}
+
+// key returns the cache key of a query, from the current generation of the
+// cache of its data source, its models and its time range. Relative time
+// ranges are keyed by their raw value and the current time rounded down to
+// the TTL, absolute ones by their exact bounds.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *QueryCacheService) key(ds *models.DataSource, query plugins.DataQuery, ttl time.Duration) (string, error) {
+	var generation int64
+	value, err := s.storage.Get(generationKey(ds))
+	switch {
+	case err == nil:
+		generation, _ = value.(int64)
+	case !errors.Is(err, remotecache.ErrCacheItemNotFound):
+		return "", err
+	}
+
+	type subQuery struct {
+		RefID         string           `json:"refId"`
+		MaxDataPoints int64            `json:"maxDataPoints"`
+		IntervalMS    int64            `json:"intervalMs"`
+		QueryType     string           `json:"queryType"`
+		Model         *simplejson.Json `json:"model"`
+	}
+	key := struct {
+		OrgID      int64      `json:"orgId"`
+		UID        string     `json:"uid"`
+		Version    int        `json:"version"`
+		Generation int64      `json:"generation"`
+		From       string     `json:"from"`
+		To         string     `json:"to"`
+		Bucket     int64      `json:"bucket,omitempty"`
+		Queries    []subQuery `json:"queries"`
+	}{
+		OrgID:      ds.OrgId,
+		UID:        ds.Uid,
+		Version:    ds.Version,
+		Generation: generation,
+	}
+	if isRelative(query.TimeRange.From) || isRelative(query.TimeRange.To) {
+		key.From = query.TimeRange.From
+		key.To = query.TimeRange.To
+		key.Bucket = roundDown(getTime().UnixNano()/int64(time.Millisecond), ttl)
+	} else {
+		key.From = fmt.Sprint(query.TimeRange.GetFromAsMsEpoch())
+		key.To = fmt.Sprint(query.TimeRange.GetToAsMsEpoch())
+	}
+	for _, q := range query.Queries {
+		key.Queries = append(key.Queries, subQuery{
+			RefID:         q.RefID,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMS:    q.IntervalMS,
+			QueryType:     q.QueryType,
+			Model:         q.Model,
+		})
+	}
+
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return "query-cache-" + hex.EncodeToString(sum[:]), nil
+}
+
+//nolint: staticcheck // plugins.DataResponse deprecated
+func (s *QueryCacheService) get(key string) (plugins.DataResponse, bool) {
+	value, err := s.storage.Get(key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			storageErrorsTotal.Inc()
+			logger.Warn("Failed to get cached query response", "error", err)
+		}
+		return plugins.DataResponse{}, false
+	}
+	cached, ok := value.(*cachedResponse)
+	if !ok {
+		return plugins.DataResponse{}, false
+	}
+
+	resp := plugins.DataResponse{
+		Results: make(map[string]plugins.DataQueryResult, len(cached.Results)),
+	}
+	for _, result := range cached.Results {
+		qr := plugins.DataQueryResult{
+			RefID:      result.RefID,
+			Dataframes: plugins.NewEncodedDataFrames(result.Frames),
+		}
+		if result.Meta != nil {
+			meta, err := simplejson.NewJson(result.Meta)
+			if err != nil {
+				return plugins.DataResponse{}, false
+			}
+			qr.Meta = meta
+		}
+		resp.Results[result.RefID] = qr
+	}
+	return resp, true
+}
+
+// set caches a response when all its results are data frames.
+//nolint: staticcheck // plugins.DataResponse deprecated
+func (s *QueryCacheService) set(key string, resp plugins.DataResponse, ttl time.Duration) {
+	cached := &cachedResponse{}
+	var size int
+	for refID, result := range resp.Results {
+		if result.Error != nil || result.ErrorString != "" || len(result.Series) > 0 || len(result.Tables) > 0 {
+			return
+		}
+
+		entry := cachedResult{RefID: refID}
+		if result.Dataframes != nil {
+			frames, err := result.Dataframes.Encoded()
+			if err != nil {
+				return
+			}
+			entry.Frames = frames
+		}
+		if result.Meta != nil {
+			meta, err := result.Meta.MarshalJSON()
+			if err != nil {
+				return
+			}
+			entry.Meta = meta
+		}
+
+		size += len(entry.Meta)
+		for _, frame := range entry.Frames {
+			size += len(frame)
+		}
+		cached.Results = append(cached.Results, entry)
+	}
+
+	if size > s.Cfg.QueryCache.MaxEntrySize {
+		logger.Debug("Query response too large to be cached", "size", size)
+		return
+	}
+	if err := s.storage.Set(key, cached, ttl); err != nil {
+		storageErrorsTotal.Inc()
+		logger.Warn("Failed to cache query response", "error", err)
+	}
+}
+
+func generationKey(ds *models.DataSource) string {
+	return fmt.Sprintf("query-cache-generation-%d-%s", ds.OrgId, ds.Uid)
+}
+
+// isRelative reports whether a bound of a time range is relative to now, like
+// now-6h or now/d.
+func isRelative(bound string) bool {
+	return strings.HasPrefix(strings.TrimSpace(bound), "now")
+}
+
+func roundDown(ms int64, ttl time.Duration) int64 {
+	step := ttl.Milliseconds()
+	return ms - ms%step
+}
+
+// detachedContext keeps the values of a context, but is never canceled.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (c detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (c detachedContext) Done() <-chan struct{}             { return nil }
+func (c detachedContext) Err() error                        { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
+
+type skipKey struct{}
+
+// WithoutCache returns a context in which HandleRequest always runs the
+// queries, for alerting which must evaluate the current data.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+type statusKey struct{}
+
+// Status is the cache status of the last query run with a context returned
+// by WithStatus.
+type Status struct {
+	Value string
+}
+
+// WithStatus returns a context in which HandleRequest reports whether the
+// response came from the cache.
+func WithStatus(ctx context.Context) (context.Context, *Status) {
+	status := &Status{}
+	return context.WithValue(ctx, statusKey{}, status), status
+}
+
+func setStatus(ctx context.Context, value string) {
+	if status, ok := ctx.Value(statusKey{}).(*Status); ok {
+		status.Value = value
+	}
+}
+
+// localStorage keeps the responses in memory when they don't need to be
+// shared between instances.
+type localStorage struct {
+	cache *localcache.CacheService
+}
+
+func (s *localStorage) Get(key string) (interface{}, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return value, nil
+}
+
+func (s *localStorage) Set(key string, value interface{}, expire time.Duration) error {
+	s.cache.Set(key, value, expire)
+	return nil
+}
+
+func (s *localStorage) Delete(key string) error {
+	s.cache.Delete(key)
+	return nil
+}
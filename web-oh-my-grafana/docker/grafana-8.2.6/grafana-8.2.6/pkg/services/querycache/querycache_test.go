@@ -0,0 +1,288 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querylimits"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func TestHandleRequest(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	t.Cleanup(func() { getTime = time.Now })
+
+	cfg := setting.NewCfg()
+	cfg.QueryCache = setting.QueryCacheSettings{
+		Enabled:      true,
+		Storage:      setting.QueryCacheStorageRemoteCache,
+		DefaultTTL:   time.Minute,
+		MaxEntrySize: 1024 * 1024,
+	}
+	remoteCache := remotecache.NewFakeStore(t)
+	// Two services sharing the remote cache act like two Grafana instances.
+	instances := []*QueryCacheService{
+		ProvideService(cfg, remoteCache, nil),
+		ProvideService(cfg, remoteCache, nil),
+	}
+
+	ds := &models.DataSource{Id: 1, OrgId: 1, Uid: "es", Type: "elasticsearch", JsonData: simplejson.New()}
+
+	newRelativeQuery := func(from, to string, expr string) plugins.DataQuery {
+		timeRange := plugins.NewDataTimeRange(from, to)
+		return plugins.DataQuery{
+			TimeRange: &timeRange,
+			Queries: []plugins.DataSubQuery{
+				{
+					RefID:         "A",
+					MaxDataPoints: 100,
+					IntervalMS:    1000,
+					Model:         simplejson.NewFromAny(map[string]interface{}{"query": expr}),
+				},
+			},
+		}
+	}
+	newQuery := func(from, to time.Time, expr string) plugins.DataQuery {
+		return newRelativeQuery(from.Format(time.RFC3339), to.Format(time.RFC3339), expr)
+	}
+
+	calls := 0
+	handle := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+		calls++
+		frame := data.NewFrame("series",
+			data.NewField("time", nil, []time.Time{now}),
+			data.NewField("value", nil, []float64{float64(calls)}),
+		)
+		return plugins.DataResponse{
+			Results: map[string]plugins.DataQueryResult{
+				"A": {
+					RefID:      "A",
+					Meta:       simplejson.NewFromAny(map[string]interface{}{"calls": calls}),
+					Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame}),
+				},
+			},
+		}, nil
+	}
+
+	request := func(t *testing.T, instance int, ds *models.DataSource, query plugins.DataQuery) (float64, string) {
+		t.Helper()
+		ctx, status := WithStatus(context.Background())
+		resp, err := instances[instance].HandleRequest(ctx, ds, query, handle)
+		require.NoError(t, err)
+
+		frames, err := resp.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		return frames[0].Fields[1].At(0).(float64), status.Value
+	}
+
+	t.Run("shares the responses of queries over the same absolute time range", func(t *testing.T) {
+		value, status := request(t, 0, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(1), value)
+		assert.Equal(t, StatusMiss, status)
+
+		value, status = request(t, 1, ds, newQuery(now.Add(-time.Hour+10*time.Second), now.Add(10*time.Second), "errors"))
+		assert.Equal(t, float64(2), value)
+		assert.Equal(t, StatusMiss, status)
+
+		value, status = request(t, 1, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(1), value)
+		assert.Equal(t, StatusHit, status)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("shares the responses of relative time ranges for the TTL", func(t *testing.T) {
+		t.Cleanup(func() { getTime = func() time.Time { return now } })
+
+		value, status := request(t, 0, ds, newRelativeQuery("now-1h", "now", "errors"))
+		assert.Equal(t, float64(3), value)
+		assert.Equal(t, StatusMiss, status)
+
+		getTime = func() time.Time { return now.Add(10 * time.Second) }
+		value, status = request(t, 1, ds, newRelativeQuery("now-1h", "now", "errors"))
+		assert.Equal(t, float64(3), value)
+		assert.Equal(t, StatusHit, status)
+
+		value, status = request(t, 0, ds, newRelativeQuery("now-1h", "now", "warnings"))
+		assert.Equal(t, float64(4), value)
+		assert.Equal(t, StatusMiss, status)
+
+		getTime = func() time.Time { return now.Add(time.Minute) }
+		value, status = request(t, 0, ds, newRelativeQuery("now-1h", "now", "errors"))
+		assert.Equal(t, float64(5), value)
+		assert.Equal(t, StatusMiss, status)
+	})
+
+	t.Run("keeps the query metadata", func(t *testing.T) {
+		resp, err := instances[1].HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, "errors"), handle)
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Results["A"].Meta.Get("calls").MustInt())
+	})
+
+	t.Run("flushing a data source ignores its cached responses", func(t *testing.T) {
+		calls = 10
+		require.NoError(t, instances[1].Flush(ds))
+
+		value, status := request(t, 0, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(11), value)
+		assert.Equal(t, StatusMiss, status)
+
+		value, status = request(t, 1, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(11), value)
+		assert.Equal(t, StatusHit, status)
+	})
+
+	t.Run("doesn't cache the queries of data sources disabling it", func(t *testing.T) {
+		disabled := &models.DataSource{Id: 2, OrgId: 1, Uid: "disabled", Type: "elasticsearch",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheTTL": "0"})}
+		calls = 20
+
+		value, status := request(t, 0, disabled, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(21), value)
+		assert.Equal(t, StatusBypass, status)
+		value, _ = request(t, 0, disabled, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(22), value)
+	})
+
+	t.Run("doesn't cache the queries of data sources forwarding the user token", func(t *testing.T) {
+		oauth := &models.DataSource{Id: 3, OrgId: 1, Uid: "oauth", Type: "elasticsearch",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"oauthPassThru": true})}
+		calls = 30
+
+		_, status := request(t, 0, oauth, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, StatusBypass, status)
+		value, _ := request(t, 0, oauth, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(32), value)
+	})
+
+	t.Run("doesn't cache the queries of alerting", func(t *testing.T) {
+		calls = 35
+		query := newQuery(now.Add(-time.Hour), now, "alerts")
+		ctx, status := WithStatus(WithoutCache(context.Background()))
+
+		_, err := instances[0].HandleRequest(ctx, ds, query, handle)
+		require.NoError(t, err)
+		assert.Equal(t, StatusBypass, status.Value)
+		value, _ := request(t, 0, ds, query)
+		assert.Equal(t, float64(37), value)
+	})
+
+	t.Run("shared queries are not canceled with the request that started them", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		var once sync.Once
+		queryErr := make(chan error, 1)
+		slow := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			once.Do(func() { close(started) })
+			<-release
+			select {
+			case queryErr <- ctx.Err():
+			default:
+			}
+			return handle(ctx, ds, query)
+		}
+		query := newQuery(now.Add(-time.Hour), now, "slow")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		canceled := make(chan error)
+		go func() {
+			_, err := instances[0].HandleRequest(ctx, ds, query, slow)
+			canceled <- err
+		}()
+		<-started
+		shared := make(chan error)
+		go func() {
+			_, err := instances[0].HandleRequest(context.Background(), ds, query, slow)
+			shared <- err
+		}()
+		cancel()
+		assert.ErrorIs(t, <-canceled, context.Canceled)
+
+		close(release)
+		require.NoError(t, <-shared)
+		require.NoError(t, <-queryErr)
+	})
+
+	t.Run("shared queries end with the query timeout of the data source", func(t *testing.T) {
+		limited := &models.DataSource{Id: 5, OrgId: 1, Uid: "limited", Type: "elasticsearch",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"queryTimeout": "20s"})}
+		s := ProvideService(cfg, remoteCache, querylimits.ProvideService(cfg))
+		var deadline time.Time
+		var hasDeadline bool
+		withDeadline := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return handle(ctx, ds, query)
+		}
+
+		start := time.Now()
+		_, err := s.HandleRequest(context.Background(), limited, newQuery(now.Add(-time.Hour), now, "deadline"), withDeadline)
+		require.NoError(t, err)
+		require.True(t, hasDeadline)
+		assert.WithinDuration(t, start.Add(20*time.Second), deadline, 5*time.Second)
+	})
+
+	t.Run("doesn't cache failed queries", func(t *testing.T) {
+		other := &models.DataSource{Id: 4, OrgId: 1, Uid: "failing", Type: "elasticsearch", JsonData: simplejson.New()}
+		failing := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			calls++
+			return plugins.DataResponse{
+				Results: map[string]plugins.DataQueryResult{
+					"A": {RefID: "A", Error: errors.New("cluster unavailable")},
+				},
+			}, nil
+		}
+		calls = 40
+
+		for i := 0; i < 2; i++ {
+			resp, err := instances[0].HandleRequest(context.Background(), other, newQuery(now.Add(-time.Hour), now, "errors"), failing)
+			require.NoError(t, err)
+			assert.Error(t, resp.Results["A"].Error)
+		}
+		assert.Equal(t, 42, calls)
+	})
+
+	t.Run("doesn't cache when disabled", func(t *testing.T) {
+		cfg.QueryCache.Enabled = false
+		t.Cleanup(func() { cfg.QueryCache.Enabled = true })
+		calls = 50
+
+		_, status := request(t, 0, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, StatusBypass, status)
+		value, _ := request(t, 0, ds, newQuery(now.Add(-time.Hour), now, "errors"))
+		assert.Equal(t, float64(52), value)
+	})
+}
+
+func TestTTL(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryCache = setting.QueryCacheSettings{Enabled: true, DefaultTTL: time.Minute}
+	s := ProvideService(cfg, nil, nil)
+
+	tests := []struct {
+		queryCacheTTL string
+		expected      time.Duration
+	}{
+		{queryCacheTTL: "", expected: time.Minute},
+		{queryCacheTTL: "5m", expected: 5 * time.Minute},
+		{queryCacheTTL: "0", expected: 0},
+		{queryCacheTTL: "500ms", expected: 0},
+		{queryCacheTTL: "7d", expected: 24 * time.Hour},
+		{queryCacheTTL: "soon", expected: 0},
+	}
+	for _, tc := range tests {
+		ds := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCacheTTL": tc.queryCacheTTL})}
+		assert.Equal(t, tc.expected, s.ttl(ds), tc.queryCacheTTL)
+	}
+}
@@ -0,0 +1,33 @@
+package querycache
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+// FakeQueryCacheService is a Service for tests. Queries are never cached,
+// and the flushed data sources are recorded.
+type FakeQueryCacheService struct {
+	Err     error
+	Flushed []*models.DataSource
+}
+
+func NewFakeQueryCacheService() *FakeQueryCacheService {
+	return &FakeQueryCacheService{}
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *FakeQueryCacheService) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery,
+	handle HandleRequestFunc) (plugins.DataResponse, error) {
+	return handle(ctx, ds, query)
+}
+
+func (s *FakeQueryCacheService) Flush(ds *models.DataSource) error {
+	if s.Err != nil {
+		return s.Err
+	}
+	s.Flushed = append(s.Flushed, ds)
+	return nil
+}
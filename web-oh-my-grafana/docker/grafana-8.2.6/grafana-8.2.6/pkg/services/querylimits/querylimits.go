@@ -0,0 +1,336 @@
+package querylimits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/semaphore"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	// ErrTooManyConcurrentQueries is returned for the queries that waited
+	// for the other queries of their data source until they timed out.
+	ErrTooManyConcurrentQueries = errors.New("too many concurrent queries of the data source")
+	// ErrQueryTimeout is returned for the queries that didn't complete
+	// within the query timeout of their data source.
+	ErrQueryTimeout = errors.New("data source query timed out")
+	// ErrTooManyRows is returned for the query results over the maximum
+	// number of rows of their data source.
+	ErrTooManyRows = errors.New("query result has too many rows")
+	// ErrResponseTooLarge is returned for the query results over the maximum
+	// response size of their data source.
+	ErrResponseTooLarge = errors.New("query result is too large")
+)
+
+var (
+	logger = log.New("querylimits")
+
+	// defaultAcquireTimeout is how long the queries of a data source without
+	// a query timeout wait for the other queries of their data source.
+	defaultAcquireTimeout = 30 * time.Second
+
+	limitsExceededTotal *prometheus.CounterVec
+)
+
+func init() {
+	limitsExceededTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "datasource_query_limits_exceeded_total",
+		Help:      "Number of data source queries failed by a query limit, by data source type and limit",
+	}, []string{"datasource_type", "limit"})
+}
+
+func ProvideService(cfg *setting.Cfg) *QueryLimitsService {
+	return &QueryLimitsService{
+		Cfg:        cfg,
+		semaphores: map[string]*dataSourceSemaphore{},
+	}
+}
+
+// HandleRequestFunc runs a data source query.
+//nolint: staticcheck // plugins.DataQuery deprecated
+type HandleRequestFunc func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error)
+
+type Service interface {
+	// HandleRequest runs the query with handle within the limits of its
+	// data source. Queries over a limit get an error result instead of
+	// their data.
+	//nolint: staticcheck // plugins.DataQuery deprecated
+	HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery, handle HandleRequestFunc) (plugins.DataResponse, error)
+}
+
+// QueryLimitsService limits the data source queries run by Grafana: how
+// many run at once, how long they run and how large their results are.
+type QueryLimitsService struct {
+	Cfg *setting.Cfg
+
+	mu         sync.Mutex
+	semaphores map[string]*dataSourceSemaphore
+}
+
+// Limits are the query limits of a data source, zero meaning no limit.
+type Limits struct {
+	MaxConcurrentQueries int
+	Timeout              time.Duration
+	MaxRows              int
+	MaxResponseSize      int
+}
+
+type dataSourceSemaphore struct {
+	size int64
+	sem  *semaphore.Weighted
+}
+
+// GetLimits returns the limits of a data source, from its JSON data or the
+// defaults of the [query_limits] settings.
+func (s *QueryLimitsService) GetLimits(ds *models.DataSource) Limits {
+	defaults := s.Cfg.QueryLimits
+	limits := Limits{
+		MaxConcurrentQueries: defaults.MaxConcurrentQueries,
+		Timeout:              defaults.Timeout,
+		MaxRows:              defaults.MaxRows,
+		MaxResponseSize:      defaults.MaxResponseSize,
+	}
+	if ds.JsonData == nil {
+		return limits
+	}
+
+	if value := ds.JsonData.Get("maxConcurrentQueries").MustInt(0); value > 0 {
+		limits.MaxConcurrentQueries = value
+	}
+	if value := ds.JsonData.Get("maxRows").MustInt(0); value > 0 {
+		limits.MaxRows = value
+	}
+	if value := ds.JsonData.Get("maxResponseSizeKB").MustInt(0); value > 0 {
+		limits.MaxResponseSize = value * 1024
+	}
+	if value := ds.JsonData.Get("queryTimeout").MustString(""); value != "" {
+		timeout, err := gtime.ParseDuration(value)
+		if err != nil {
+			logger.Warn("Invalid queryTimeout of data source, using the default", "datasource", ds.Uid, "queryTimeout", value)
+		} else if timeout > 0 {
+			limits.Timeout = timeout
+		}
+	}
+	return limits
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *QueryLimitsService) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery,
+	handle HandleRequestFunc) (plugins.DataResponse, error) {
+	limits := s.GetLimits(ds)
+	if limits == (Limits{}) {
+		return handle(ctx, ds, query)
+	}
+
+	queryCtx := ctx
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	release := func() {}
+	if limits.MaxConcurrentQueries > 0 {
+		sem := s.semaphore(ds, limits.MaxConcurrentQueries)
+		// A request takes a slot per query, up to all of them.
+		weight := int64(len(query.Queries))
+		if weight < 1 {
+			weight = 1
+		}
+		if weight > sem.size {
+			weight = sem.size
+		}
+		acquireCtx, acquireTimeout := queryCtx, limits.Timeout
+		if acquireTimeout == 0 {
+			var cancel context.CancelFunc
+			acquireTimeout = defaultAcquireTimeout
+			acquireCtx, cancel = context.WithTimeout(ctx, acquireTimeout)
+			defer cancel()
+		}
+		if err := sem.sem.Acquire(acquireCtx, weight); err != nil {
+			if ctx.Err() != nil {
+				return plugins.DataResponse{}, ctx.Err()
+			}
+			return s.limitExceeded(ds, query, "concurrency", fmt.Errorf("%w, waited %s for the %d running queries",
+				ErrTooManyConcurrentQueries, acquireTimeout, limits.MaxConcurrentQueries)), nil
+		}
+		release = func() { sem.sem.Release(weight) }
+	}
+
+	if limits.Timeout == 0 {
+		defer release()
+		resp, err := handle(queryCtx, ds, query)
+		if err != nil {
+			return resp, err
+		}
+		return s.checkResults(ds, resp, limits), nil
+	}
+
+	type result struct {
+		resp plugins.DataResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	// The query runs in the background so that it can be abandoned when it
+	// doesn't stop at the timeout, its slot is released once it stops.
+	go func() {
+		defer release()
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("Data source query panic", "datasource", ds.Uid, "error", r, "stack", log.Stack(1))
+				done <- result{err: fmt.Errorf("data source query failed: %v", r)}
+			}
+		}()
+		resp, err := handle(queryCtx, ds, query)
+		done <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			if ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+				return s.timedOut(ds, query, limits), nil
+			}
+			return r.resp, r.err
+		}
+		return s.checkResults(ds, r.resp, limits), nil
+	case <-queryCtx.Done():
+		if ctx.Err() != nil {
+			return plugins.DataResponse{}, ctx.Err()
+		}
+		return s.timedOut(ds, query, limits), nil
+	}
+}
+
+func (s *QueryLimitsService) semaphore(ds *models.DataSource, size int) *dataSourceSemaphore {
+	key := fmt.Sprintf("%d-%s", ds.OrgId, ds.Uid)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The queries still holding the semaphore of a previous limit release
+	// it when they stop.
+	if existing, ok := s.semaphores[key]; ok && existing.size == int64(size) {
+		return existing
+	}
+	sem := &dataSourceSemaphore{size: int64(size), sem: semaphore.NewWeighted(int64(size))}
+	s.semaphores[key] = sem
+	return sem
+}
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *QueryLimitsService) timedOut(ds *models.DataSource, query plugins.DataQuery, limits Limits) plugins.DataResponse {
+	return s.limitExceeded(ds, query, "timeout", fmt.Errorf("%w after %s", ErrQueryTimeout, limits.Timeout))
+}
+
+// limitExceeded returns an error result for every query of a request.
+//nolint: staticcheck // plugins.DataQuery deprecated
+func (s *QueryLimitsService) limitExceeded(ds *models.DataSource, query plugins.DataQuery, limit string, err error) plugins.DataResponse {
+	limitsExceededTotal.WithLabelValues(ds.Type, limit).Add(float64(len(query.Queries)))
+	logger.Debug("Data source query limit exceeded", "datasource", ds.Uid, "limit", limit, "error", err)
+
+	resp := plugins.DataResponse{
+		Results: make(map[string]plugins.DataQueryResult, len(query.Queries)),
+	}
+	for _, q := range query.Queries {
+		resp.Results[q.RefID] = errorResult(q.RefID, err)
+	}
+	return resp
+}
+
+// checkResults replaces the results over the row or size limits with an
+// error result.
+//nolint: staticcheck // plugins.DataResponse deprecated
+func (s *QueryLimitsService) checkResults(ds *models.DataSource, resp plugins.DataResponse, limits Limits) plugins.DataResponse {
+	if limits.MaxRows == 0 && limits.MaxResponseSize == 0 {
+		return resp
+	}
+
+	for refID, result := range resp.Results {
+		if result.Error != nil {
+			continue
+		}
+
+		if limits.MaxRows > 0 {
+			rows, err := countRows(result)
+			if err != nil {
+				logger.Warn("Failed to count the rows of a query result", "datasource", ds.Uid, "error", err)
+				continue
+			}
+			if rows > limits.MaxRows {
+				limitsExceededTotal.WithLabelValues(ds.Type, "rows").Inc()
+				resp.Results[refID] = errorResult(refID, fmt.Errorf("%w: %d rows, the limit is %d",
+					ErrTooManyRows, rows, limits.MaxRows))
+				continue
+			}
+		}
+
+		if limits.MaxResponseSize > 0 && result.Dataframes != nil {
+			encoded, err := result.Dataframes.Encoded()
+			if err != nil {
+				logger.Warn("Failed to encode a query result", "datasource", ds.Uid, "error", err)
+				continue
+			}
+			size := 0
+			for _, frame := range encoded {
+				size += len(frame)
+			}
+			if size > limits.MaxResponseSize {
+				limitsExceededTotal.WithLabelValues(ds.Type, "response_size").Inc()
+				resp.Results[refID] = errorResult(refID, fmt.Errorf("%w: %d KB, the limit is %d KB",
+					ErrResponseTooLarge, size/1024, limits.MaxResponseSize/1024))
+			}
+		}
+	}
+	return resp
+}
+
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func countRows(result plugins.DataQueryResult) (int, error) {
+	rows := 0
+	if result.Dataframes != nil {
+		frames, err := result.Dataframes.Decoded()
+		if err != nil {
+			return 0, err
+		}
+		for _, frame := range frames {
+			rows += frame.Rows()
+		}
+	}
+	for _, series := range result.Series {
+		rows += len(series.Points)
+	}
+	for _, table := range result.Tables {
+		rows += len(table.Rows)
+	}
+	return rows, nil
+}
+
+// errorResult returns the result of a query that exceeded a limit, with the
+// error in the notices of its frame for the panels to show.
+//nolint: staticcheck // plugins.DataQueryResult deprecated
+func errorResult(refID string, err error) plugins.DataQueryResult {
+	frame := data.NewFrame("")
+	frame.RefID = refID
+	frame.AppendNotices(data.Notice{
+		Severity: data.NoticeSeverityError,
+		Text:     err.Error(),
+	})
+	return plugins.DataQueryResult{
+		RefID:      refID,
+		Error:      err,
+		Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame}),
+	}
+}
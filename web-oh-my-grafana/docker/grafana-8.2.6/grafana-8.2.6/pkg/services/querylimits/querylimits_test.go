@@ -0,0 +1,233 @@
+package querylimits
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint: staticcheck // plugins.DataQuery deprecated
+func TestHandleRequest(t *testing.T) {
+	newDataSource := func(jsonData map[string]interface{}) *models.DataSource {
+		return &models.DataSource{Id: 1, OrgId: 1, Uid: "es", Type: "elasticsearch", JsonData: simplejson.NewFromAny(jsonData)}
+	}
+	newQuery := func(refIDs ...string) plugins.DataQuery {
+		query := plugins.DataQuery{}
+		for _, refID := range refIDs {
+			query.Queries = append(query.Queries, plugins.DataSubQuery{RefID: refID})
+		}
+		return query
+	}
+	rowsHandler := func(rows int) HandleRequestFunc {
+		return func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			resp := plugins.DataResponse{Results: map[string]plugins.DataQueryResult{}}
+			for _, q := range query.Queries {
+				frame := data.NewFrame("series", data.NewField("value", nil, make([]float64, rows)))
+				resp.Results[q.RefID] = plugins.DataQueryResult{
+					RefID:      q.RefID,
+					Dataframes: plugins.NewDecodedDataFrames(data.Frames{frame}),
+				}
+			}
+			return resp, nil
+		}
+	}
+	requireLimitError := func(t *testing.T, result plugins.DataQueryResult, target error) {
+		t.Helper()
+		require.True(t, errors.Is(result.Error, target), "unexpected error %v", result.Error)
+		frames, err := result.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityError, frames[0].Meta.Notices[0].Severity)
+		assert.Equal(t, result.Error.Error(), frames[0].Meta.Notices[0].Text)
+	}
+
+	t.Run("runs queries without limits as they are", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		resp, err := s.HandleRequest(context.Background(), newDataSource(nil), newQuery("A"), rowsHandler(10))
+		require.NoError(t, err)
+		assert.NoError(t, resp.Results["A"].Error)
+	})
+
+	t.Run("fails the queries running longer than the timeout", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		ds := newDataSource(map[string]interface{}{"queryTimeout": "50ms"})
+		unblock := make(chan struct{})
+		defer close(unblock)
+		// The handler ignores the cancellation of the context.
+		blocking := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			<-unblock
+			return plugins.DataResponse{}, nil
+		}
+
+		resp, err := s.HandleRequest(context.Background(), ds, newQuery("A", "B"), blocking)
+		require.NoError(t, err)
+		requireLimitError(t, resp.Results["A"], ErrQueryTimeout)
+		requireLimitError(t, resp.Results["B"], ErrQueryTimeout)
+	})
+
+	t.Run("returns the cancellation of the request", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		ds := newDataSource(map[string]interface{}{"queryTimeout": "1m"})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		handler := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			<-ctx.Done()
+			return plugins.DataResponse{}, ctx.Err()
+		}
+
+		_, err := s.HandleRequest(ctx, ds, newQuery("A"), handler)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("limits the concurrent queries of a data source", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.QueryLimits.MaxConcurrentQueries = 2
+		cfg.QueryLimits.Timeout = time.Minute
+		s := ProvideService(cfg)
+		ds := newDataSource(nil)
+
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+		handler := func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+			mu.Lock()
+			running += len(query.Queries)
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			running -= len(query.Queries)
+			mu.Unlock()
+			return rowsHandler(1)(ctx, ds, query)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := s.HandleRequest(context.Background(), ds, newQuery("A"), handler)
+				assert.NoError(t, err)
+				assert.NoError(t, resp.Results["A"].Error)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 2, maxRunning)
+	})
+
+	t.Run("fails the queries waiting for a slot longer than the timeout", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		ds := newDataSource(map[string]interface{}{"maxConcurrentQueries": 1, "queryTimeout": "50ms"})
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		go func() {
+			_, _ = s.HandleRequest(context.Background(), ds, newQuery("A"),
+				func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+					close(started)
+					<-unblock
+					return plugins.DataResponse{}, nil
+				})
+		}()
+		<-started
+		defer close(unblock)
+
+		resp, err := s.HandleRequest(context.Background(), ds, newQuery("B"), rowsHandler(1))
+		require.NoError(t, err)
+		requireLimitError(t, resp.Results["B"], ErrTooManyConcurrentQueries)
+	})
+
+	t.Run("fails the queries waiting for a slot longer than the default timeout without a query timeout", func(t *testing.T) {
+		origTimeout := defaultAcquireTimeout
+		defaultAcquireTimeout = 50 * time.Millisecond
+		t.Cleanup(func() { defaultAcquireTimeout = origTimeout })
+
+		s := ProvideService(setting.NewCfg())
+		ds := newDataSource(map[string]interface{}{"maxConcurrentQueries": 1})
+		started := make(chan struct{})
+		unblock := make(chan struct{})
+		go func() {
+			_, _ = s.HandleRequest(context.Background(), ds, newQuery("A"),
+				func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+					close(started)
+					<-unblock
+					return plugins.DataResponse{}, nil
+				})
+		}()
+		<-started
+		defer close(unblock)
+
+		resp, err := s.HandleRequest(context.Background(), ds, newQuery("B"), rowsHandler(1))
+		require.NoError(t, err)
+		requireLimitError(t, resp.Results["B"], ErrTooManyConcurrentQueries)
+	})
+
+	t.Run("fails the results with too many rows", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.QueryLimits.MaxRows = 100
+		s := ProvideService(cfg)
+
+		resp, err := s.HandleRequest(context.Background(), newDataSource(nil), newQuery("A"), rowsHandler(100))
+		require.NoError(t, err)
+		assert.NoError(t, resp.Results["A"].Error)
+
+		resp, err = s.HandleRequest(context.Background(), newDataSource(nil), newQuery("A"), rowsHandler(101))
+		require.NoError(t, err)
+		requireLimitError(t, resp.Results["A"], ErrTooManyRows)
+
+		resp, err = s.HandleRequest(context.Background(), newDataSource(map[string]interface{}{"maxRows": 1000}), newQuery("A"), rowsHandler(101))
+		require.NoError(t, err)
+		assert.NoError(t, resp.Results["A"].Error)
+	})
+
+	t.Run("fails the results larger than the response size", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		ds := newDataSource(map[string]interface{}{"maxResponseSizeKB": 1})
+
+		resp, err := s.HandleRequest(context.Background(), ds, newQuery("A"), rowsHandler(10))
+		require.NoError(t, err)
+		assert.NoError(t, resp.Results["A"].Error)
+
+		resp, err = s.HandleRequest(context.Background(), ds, newQuery("A"), rowsHandler(1000))
+		require.NoError(t, err)
+		requireLimitError(t, resp.Results["A"], ErrResponseTooLarge)
+	})
+}
+
+func TestGetLimits(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryLimits = setting.QueryLimitsSettings{
+		MaxConcurrentQueries: 10,
+		Timeout:              30 * time.Second,
+		MaxRows:              1000,
+	}
+	s := ProvideService(cfg)
+
+	limits := s.GetLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+		"maxConcurrentQueries": 2,
+		"queryTimeout":         "2m",
+		"maxResponseSizeKB":    512,
+	})})
+	assert.Equal(t, Limits{
+		MaxConcurrentQueries: 2,
+		Timeout:              2 * time.Minute,
+		MaxRows:              1000,
+		MaxResponseSize:      512 * 1024,
+	}, limits)
+
+	limits = s.GetLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+		"queryTimeout": "soon",
+	})})
+	assert.Equal(t, 30*time.Second, limits.Timeout)
+}
@@ -2,6 +2,7 @@ package quota
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -20,6 +21,9 @@ func ProvideService(cfg *setting.Cfg, tokenService models.UserTokenService) *Quo
 type QuotaService struct {
 	AuthTokenService models.UserTokenService
 	Cfg              *setting.Cfg
+
+	rateMtx     sync.Mutex
+	rateWindows map[rateKey]*rateWindow
 }
 
 func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool, error) {
@@ -92,6 +96,17 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 			if query.Result.Used >= query.Result.Limit {
 				return true, nil
 			}
+		case "org_rate":
+			if !c.IsSignedIn {
+				continue
+			}
+			reached, err := qs.rateQuotaReached(c.OrgId, scope)
+			if err != nil {
+				return true, err
+			}
+			if reached {
+				return true, nil
+			}
 		case "user":
 			if !c.IsSignedIn || c.UserId == 0 {
 				continue
@@ -168,7 +183,45 @@ func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, erro
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.AlertRule},
 		)
 		return scopes, nil
+	case "data_source_query":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org_rate", Target: target, DefaultLimit: qs.Cfg.Quota.OrgRate.DataSourceQuery},
+		)
+		return scopes, nil
+	case "api_request":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org_rate", Target: target, DefaultLimit: qs.Cfg.Quota.OrgRate.ApiRequest},
+		)
+		return scopes, nil
 	default:
 		return scopes, ErrInvalidQuotaTarget
 	}
 }
+
+// FolderQuotaReached checks the quota of a folder, in addition to the
+// scopes checked by QuotaReached. Only dashboards are limited per folder.
+func (qs *QuotaService) FolderQuotaReached(c *models.ReqContext, target string, folderID int64) (bool, error) {
+	if !qs.Cfg.Quota.Enabled || c == nil || folderID == 0 || !c.IsSignedIn {
+		return false, nil
+	}
+	if target != "dashboard" {
+		return false, ErrInvalidQuotaTarget
+	}
+
+	c.Logger.Debug("Checking quota", "target", target, "scope", "folder", "folderId", folderID)
+
+	query := models.GetFolderQuotaByTargetQuery{
+		OrgId:    c.OrgId,
+		FolderId: folderID,
+		Target:   target,
+		Default:  qs.Cfg.Quota.Folder.Dashboard,
+	}
+	if err := bus.DispatchCtx(c.Req.Context(), &query); err != nil {
+		return true, err
+	}
+	if query.Result.Limit < 0 {
+		return false, nil
+	}
+
+	return query.Result.Used >= query.Result.Limit, nil
+}
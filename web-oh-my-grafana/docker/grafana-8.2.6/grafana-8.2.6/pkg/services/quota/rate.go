@@ -0,0 +1,157 @@
+package quota
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var timeNow = time.Now
+
+type rateKey struct {
+	orgID  int64
+	target string
+}
+
+// rateWindow counts the usage of a rate quota within a fixed window. The
+// limit is looked up once per window so that counting doesn't require
+// a database query on every request.
+type rateWindow struct {
+	start time.Time
+	limit int64
+	used  int64
+}
+
+// IsRateTarget returns true if the target is counted within a time
+// window rather than by the number of stored items.
+func IsRateTarget(target string) bool {
+	return target == "data_source_query" || target == "api_request"
+}
+
+// rateQuotaReached consumes one unit of the rate quota and reports whether
+// the limit was already reached within the current window. Usage is kept
+// in memory and is therefore counted per Grafana instance.
+func (qs *QuotaService) rateQuotaReached(orgID int64, scope models.QuotaScope) (bool, error) {
+	w, err := qs.currentRateWindow(orgID, scope)
+	if err != nil {
+		return true, err
+	}
+
+	qs.rateMtx.Lock()
+	defer qs.rateMtx.Unlock()
+
+	if w.limit < 0 {
+		return false, nil
+	}
+	if w.used >= w.limit {
+		return true, nil
+	}
+	w.used++
+
+	return false, nil
+}
+
+func (qs *QuotaService) currentRateWindow(orgID int64, scope models.QuotaScope) (*rateWindow, error) {
+	key := rateKey{orgID: orgID, target: scope.Target}
+	start := timeNow().Truncate(qs.Cfg.Quota.RateWindow)
+
+	qs.rateMtx.Lock()
+	w, ok := qs.rateWindows[key]
+	qs.rateMtx.Unlock()
+	if ok && w.start.Equal(start) {
+		return w, nil
+	}
+
+	query := models.GetOrgQuotaLimitQuery{OrgId: orgID, Target: scope.Target, Default: scope.DefaultLimit}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	qs.rateMtx.Lock()
+	defer qs.rateMtx.Unlock()
+
+	if qs.rateWindows == nil {
+		qs.rateWindows = make(map[rateKey]*rateWindow)
+	}
+	// another request might have started the window in the meantime
+	if w, ok := qs.rateWindows[key]; ok && w.start.Equal(start) {
+		return w, nil
+	}
+	w = &rateWindow{start: start, limit: query.Result}
+	qs.rateWindows[key] = w
+
+	return w, nil
+}
+
+// RateQuotaResetsAt returns when the current window of a rate quota ends.
+func (qs *QuotaService) RateQuotaResetsAt() time.Time {
+	return timeNow().Truncate(qs.Cfg.Quota.RateWindow).Add(qs.Cfg.Quota.RateWindow)
+}
+
+// GetQuotaStatus reports the limit and usage of every quota applicable to
+// the organization: the org quotas, the dashboard quotas of its folders
+// and the rate quotas of the current window.
+func (qs *QuotaService) GetQuotaStatus(orgID int64) ([]*models.QuotaStatusDTO, error) {
+	result := make([]*models.QuotaStatusDTO, 0)
+
+	orgQuery := models.GetOrgQuotasQuery{OrgId: orgID, UnifiedAlertingEnabled: qs.Cfg.UnifiedAlerting.Enabled}
+	if err := bus.Dispatch(&orgQuery); err != nil {
+		return nil, err
+	}
+	for _, q := range orgQuery.Result {
+		result = append(result, &models.QuotaStatusDTO{
+			Scope:  "org",
+			Target: q.Target,
+			Limit:  q.Limit,
+			Used:   q.Used,
+		})
+	}
+
+	folderQuery := models.GetFolderQuotasQuery{OrgId: orgID, Target: "dashboard", Default: qs.Cfg.Quota.Folder.Dashboard}
+	if err := bus.Dispatch(&folderQuery); err != nil {
+		return nil, err
+	}
+	for _, q := range folderQuery.Result {
+		result = append(result, &models.QuotaStatusDTO{
+			Scope:     "folder",
+			Target:    q.Target,
+			FolderId:  q.FolderId,
+			FolderUid: q.FolderUid,
+			Limit:     q.Limit,
+			Used:      q.Used,
+		})
+	}
+
+	rateQuotas := qs.Cfg.Quota.OrgRate.ToMap()
+	targets := make([]string, 0, len(rateQuotas))
+	for target := range rateQuotas {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	resetsAt := qs.RateQuotaResetsAt()
+	for _, target := range targets {
+		scope := models.QuotaScope{Name: "org_rate", Target: target, DefaultLimit: rateQuotas[target]}
+		w, err := qs.currentRateWindow(orgID, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		qs.rateMtx.Lock()
+		status := &models.QuotaStatusDTO{
+			Scope:    "org_rate",
+			Target:   target,
+			Limit:    w.limit,
+			Used:     w.used,
+			Window:   qs.Cfg.Quota.RateWindow.String(),
+			ResetsAt: &resetsAt,
+		}
+		qs.rateMtx.Unlock()
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,207 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// lockStripes is the number of mutexes serializing the bucket updates of
+// this instance. Updates from other instances sharing the remote cache are
+// not serialized, so concurrent requests may exceed a limit by a few.
+const lockStripes = 64
+
+var (
+	getTime = time.Now
+	logger  = log.New("ratelimit")
+
+	requestsTotal      *prometheus.CounterVec
+	storageErrorsTotal prometheus.Counter
+)
+
+func init() {
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "rate_limit_requests_total",
+		Help:      "Number of requests checked against a rate limit policy, by policy and result",
+	}, []string{"policy", "result"})
+
+	storageErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "rate_limit_storage_errors_total",
+		Help:      "Number of rate limit bucket reads or writes that failed, letting the request through",
+	})
+}
+
+func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache) *RateLimitService {
+	s := &RateLimitService{
+		Cfg:     cfg,
+		storage: remoteCache,
+	}
+	if cfg.RateLimit.Storage == setting.RateLimitStorageLocal {
+		s.storage = &localStorage{cache: localcache.New(time.Minute, 10*time.Minute)}
+	}
+	return s
+}
+
+type Service interface {
+	// Middleware rejects the API requests over the limits of the rate limit policies.
+	Middleware(c *models.ReqContext)
+}
+
+// RateLimitService limits the requests of each user, API key or organization
+// with the generic cell rate algorithm. A bucket is a single timestamp, the
+// theoretical arrival time of the next request, kept in the remote cache so
+// that all Grafana instances share it.
+type RateLimitService struct {
+	Cfg *setting.Cfg
+
+	storage remotecache.CacheStorage
+	locks   [lockStripes]sync.Mutex
+}
+
+func (s *RateLimitService) Middleware(c *models.ReqContext) {
+	if !s.Cfg.RateLimit.Enabled || !strings.HasPrefix(c.Req.URL.Path, "/api/") {
+		return
+	}
+
+	now := getTime()
+	for _, policy := range s.Cfg.RateLimit.Policies {
+		if !matchesRoutes(policy.Routes, c.Req.URL.Path) {
+			continue
+		}
+		key := bucketKey(c, policy.Key)
+		if key == "" {
+			continue
+		}
+
+		retryAfter, err := s.take(policy, key, now)
+		if err != nil {
+			storageErrorsTotal.Inc()
+			logger.Warn("Failed to check rate limit", "policy", policy.Name, "key", key, "error", err)
+			continue
+		}
+		if retryAfter > 0 {
+			requestsTotal.WithLabelValues(policy.Name, "limited").Inc()
+			c.Resp.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+			c.JsonApiErr(http.StatusTooManyRequests, "Rate limit reached", nil)
+			return
+		}
+		requestsTotal.WithLabelValues(policy.Name, "allowed").Inc()
+	}
+}
+
+// take consumes a request from the bucket and returns how long to wait
+// before retrying when the bucket is empty.
+func (s *RateLimitService) take(policy setting.RateLimitPolicy, key string, now time.Time) (time.Duration, error) {
+	cacheKey := fmt.Sprintf("ratelimit-%s-%s", policy.Name, key)
+	mu := s.lock(cacheKey)
+	mu.Lock()
+	defer mu.Unlock()
+
+	tat := now
+	value, err := s.storage.Get(cacheKey)
+	switch {
+	case err == nil:
+		if ns, ok := value.(int64); ok && time.Unix(0, ns).After(now) {
+			tat = time.Unix(0, ns)
+		}
+	case !errors.Is(err, remotecache.ErrCacheItemNotFound):
+		return 0, err
+	}
+
+	interval := time.Duration(float64(time.Second) / policy.RPS)
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-interval * time.Duration(policy.Burst))
+	if now.Before(allowAt) {
+		return allowAt.Sub(now), nil
+	}
+
+	// Some storages expire items with a precision of a second, keep the
+	// bucket a second longer than needed rather than resetting it early.
+	ttl := time.Duration(math.Ceil(newTAT.Sub(now).Seconds()))*time.Second + time.Second
+	return 0, s.storage.Set(cacheKey, newTAT.UnixNano(), ttl)
+}
+
+func (s *RateLimitService) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &s.locks[h.Sum32()%lockStripes]
+}
+
+// bucketKey returns the bucket of the request for a policy key, or an empty
+// string when the policy doesn't apply to the requester.
+func bucketKey(c *models.ReqContext, key string) string {
+	if c.SignedInUser == nil {
+		return ""
+	}
+
+	switch key {
+	case setting.RateLimitKeyUser:
+		if c.IsSignedIn && c.ApiKeyId == 0 && c.UserId != 0 {
+			return fmt.Sprintf("user:%d", c.UserId)
+		}
+	case setting.RateLimitKeyAPIKey:
+		if c.ApiKeyId != 0 {
+			return fmt.Sprintf("api_key:%d", c.ApiKeyId)
+		}
+	case setting.RateLimitKeyOrg:
+		if c.OrgId != 0 {
+			return fmt.Sprintf("org:%d", c.OrgId)
+		}
+	}
+	return ""
+}
+
+// matchesRoutes reports whether an API path belongs to a route group.
+func matchesRoutes(routes string, path string) bool {
+	switch routes {
+	case setting.RateLimitRoutesAPI:
+		return true
+	case setting.RateLimitRoutesDatasourceProxy:
+		return strings.HasPrefix(path, "/api/datasources/proxy/") ||
+			(strings.HasPrefix(path, "/api/datasources/") && strings.Contains(path, "/resources"))
+	case setting.RateLimitRoutesQuery:
+		return strings.HasPrefix(path, "/api/ds/query") || strings.HasPrefix(path, "/api/tsdb/query")
+	}
+	return false
+}
+
+// localStorage keeps the buckets in memory when they don't need to be
+// shared between instances.
+type localStorage struct {
+	cache *localcache.CacheService
+}
+
+func (s *localStorage) Get(key string) (interface{}, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return value, nil
+}
+
+func (s *localStorage) Set(key string, value interface{}, expire time.Duration) error {
+	s.cache.Set(key, value, expire)
+	return nil
+}
+
+func (s *localStorage) Delete(key string) error {
+	s.cache.Delete(key)
+	return nil
+}
@@ -0,0 +1,142 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	t.Cleanup(func() { getTime = time.Now })
+
+	cfg := setting.NewCfg()
+	cfg.RateLimit = setting.RateLimitSettings{
+		Enabled: true,
+		Storage: setting.RateLimitStorageRemoteCache,
+		Policies: []setting.RateLimitPolicy{
+			{Name: "users", Routes: setting.RateLimitRoutesAPI, Key: setting.RateLimitKeyUser, RPS: 1, Burst: 2},
+			{Name: "proxy", Routes: setting.RateLimitRoutesDatasourceProxy, Key: setting.RateLimitKeyAPIKey, RPS: 0.5, Burst: 1},
+		},
+	}
+	remoteCache := remotecache.NewFakeStore(t)
+	// Two services sharing the remote cache act like two Grafana instances.
+	instances := []*RateLimitService{
+		ProvideService(cfg, remoteCache),
+		ProvideService(cfg, remoteCache),
+	}
+
+	request := func(instance int, user *models.SignedInUser, path string) *httptest.ResponseRecorder {
+		m := macaron.New()
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{
+				Context:      c,
+				IsSignedIn:   true,
+				SignedInUser: user,
+				Logger:       log.New("test"),
+			})
+		})
+		m.Use(instances[instance].Middleware)
+		m.Any("/*", func(c *models.ReqContext) {
+			c.Resp.WriteHeader(http.StatusOK)
+		})
+
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("limits the requests of a user once the burst is used", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 1, OrgId: 1}
+		assert.Equal(t, http.StatusOK, request(0, user, "/api/dashboards/home").Code)
+		assert.Equal(t, http.StatusOK, request(1, user, "/api/dashboards/home").Code)
+
+		rec := request(0, user, "/api/dashboards/home")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+		other := &models.SignedInUser{UserId: 2, OrgId: 1}
+		assert.Equal(t, http.StatusOK, request(1, other, "/api/dashboards/home").Code)
+
+		now = now.Add(time.Second)
+		assert.Equal(t, http.StatusOK, request(1, user, "/api/dashboards/home").Code)
+		assert.Equal(t, http.StatusTooManyRequests, request(0, user, "/api/dashboards/home").Code)
+	})
+
+	t.Run("limits the data source proxy requests of an API key", func(t *testing.T) {
+		key := &models.SignedInUser{ApiKeyId: 3, OrgId: 1}
+		assert.Equal(t, http.StatusOK, request(0, key, "/api/datasources/proxy/1/api/v1/query").Code)
+		assert.Equal(t, http.StatusOK, request(0, key, "/api/search").Code)
+
+		rec := request(1, key, "/api/datasources/1/resources/labels")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("ignores the requests outside of the API", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 4, OrgId: 1}
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, request(0, user, "/d/abc").Code)
+		}
+	})
+
+	t.Run("lets every request through when disabled", func(t *testing.T) {
+		cfg.RateLimit.Enabled = false
+		t.Cleanup(func() { cfg.RateLimit.Enabled = true })
+
+		user := &models.SignedInUser{UserId: 1, OrgId: 1}
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, request(0, user, "/api/dashboards/home").Code)
+		}
+	})
+}
+
+func TestLocalStorage(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.RateLimit.Storage = setting.RateLimitStorageLocal
+	s := ProvideService(cfg, nil)
+
+	policy := setting.RateLimitPolicy{Name: "orgs", RPS: 10, Burst: 1}
+	now := time.Now()
+
+	retryAfter, err := s.take(policy, "org:1", now)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+
+	retryAfter, err = s.take(policy, "org:1", now)
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+
+	retryAfter, err = s.take(policy, "org:2", now)
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter)
+}
+
+func TestMatchesRoutes(t *testing.T) {
+	tests := []struct {
+		routes string
+		path   string
+		match  bool
+	}{
+		{setting.RateLimitRoutesAPI, "/api/datasources/proxy/1/", true},
+		{setting.RateLimitRoutesDatasourceProxy, "/api/datasources/proxy/1/render", true},
+		{setting.RateLimitRoutesDatasourceProxy, "/api/datasources/1/resources/tags", true},
+		{setting.RateLimitRoutesDatasourceProxy, "/api/datasources/1", false},
+		{setting.RateLimitRoutesQuery, "/api/ds/query", true},
+		{setting.RateLimitRoutesQuery, "/api/tsdb/query", true},
+		{setting.RateLimitRoutesQuery, "/api/dashboards/uid/abc", false},
+	}
+	for _, tc := range tests {
+		assert.Equal(t, tc.match, matchesRoutes(tc.routes, tc.path), "%s %s", tc.routes, tc.path)
+	}
+}
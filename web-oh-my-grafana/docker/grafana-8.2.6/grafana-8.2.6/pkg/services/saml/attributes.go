@@ -0,0 +1,121 @@
+package saml
+
+import (
+	"github.com/crewjam/saml"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// assertionAttributes returns the values of the attributes of the assertion,
+// by name and by friendly name.
+func assertionAttributes(assertion *saml.Assertion) map[string][]string {
+	attributes := map[string][]string{}
+	for _, statement := range assertion.AttributeStatements {
+		for _, attr := range statement.Attributes {
+			values := make([]string, 0, len(attr.Values))
+			for _, v := range attr.Values {
+				values = append(values, v.Value)
+			}
+			attributes[attr.Name] = append(attributes[attr.Name], values...)
+			if attr.FriendlyName != "" && attr.FriendlyName != attr.Name {
+				attributes[attr.FriendlyName] = append(attributes[attr.FriendlyName], values...)
+			}
+		}
+	}
+	return attributes
+}
+
+// externalUser maps the assertion to the user, their organizations and role.
+func (s *SAMLService) externalUser(assertion *saml.Assertion) (*models.ExternalUserInfo, error) {
+	if assertion.Subject == nil || assertion.Subject.NameID == nil || assertion.Subject.NameID.Value == "" {
+		return nil, ErrMissingNameID
+	}
+
+	settings := s.Cfg.SAML
+	attributes := assertionAttributes(assertion)
+	first := func(name string) string {
+		if values := attributes[name]; name != "" && len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	extUser := &models.ExternalUserInfo{
+		AuthModule: models.AuthModuleSAML,
+		AuthId:     assertion.Subject.NameID.Value,
+		Login:      first(settings.AttributeLogin),
+		Email:      first(settings.AttributeEmail),
+		Name:       first(settings.AttributeName),
+		OrgRoles:   map[int64]models.RoleType{},
+	}
+	if settings.AttributeGroups != "" {
+		extUser.Groups = attributes[settings.AttributeGroups]
+	}
+	if extUser.Login == "" {
+		extUser.Login = extUser.Email
+	}
+	if extUser.Login == "" {
+		return nil, ErrMissingLogin
+	}
+
+	var orgs []string
+	if settings.AttributeOrg != "" {
+		orgs = attributes[settings.AttributeOrg]
+	}
+	if len(settings.AllowedOrganizations) > 0 && !containsAny(settings.AllowedOrganizations, orgs) {
+		return nil, ErrOrganizationNotAllowed
+	}
+
+	role := models.RoleType(setting.AutoAssignOrgRole)
+	if settings.AttributeRole != "" {
+		roles := attributes[settings.AttributeRole]
+		role = s.mapRole(roles)
+		if len(settings.RoleValuesGrafanaAdmin) > 0 {
+			isGrafanaAdmin := containsAny(settings.RoleValuesGrafanaAdmin, roles)
+			extUser.IsGrafanaAdmin = &isGrafanaAdmin
+		}
+	}
+
+	switch {
+	case len(s.orgMapping) > 0:
+		for _, org := range orgs {
+			for _, orgID := range s.orgMapping[org] {
+				extUser.OrgRoles[orgID] = role
+			}
+		}
+	case settings.AttributeRole != "":
+		// The same organization the role of an OAuth user is assigned in.
+		orgID := int64(1)
+		if setting.AutoAssignOrg && setting.AutoAssignOrgId > 0 {
+			orgID = int64(setting.AutoAssignOrgId)
+		}
+		extUser.OrgRoles[orgID] = role
+	}
+
+	return extUser, nil
+}
+
+// mapRole returns the highest organization role the role values map to, the
+// Grafana Admins are organization admins.
+func (s *SAMLService) mapRole(roles []string) models.RoleType {
+	settings := s.Cfg.SAML
+	switch {
+	case containsAny(settings.RoleValuesGrafanaAdmin, roles), containsAny(settings.RoleValuesAdmin, roles):
+		return models.ROLE_ADMIN
+	case containsAny(settings.RoleValuesEditor, roles):
+		return models.ROLE_EDITOR
+	default:
+		return models.ROLE_VIEWER
+	}
+}
+
+func containsAny(list []string, values []string) bool {
+	for _, v := range values {
+		for _, item := range list {
+			if item == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/beevik/etree"
+	xrv "github.com/mattermost/xml-roundtrip-validator"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+type signatureAlgorithm struct {
+	uri  string
+	hash crypto.Hash
+}
+
+var signatureAlgorithms = map[string]signatureAlgorithm{
+	"rsa-sha1":   {uri: dsig.RSASHA1SignatureMethod, hash: crypto.SHA1},
+	"rsa-sha256": {uri: dsig.RSASHA256SignatureMethod, hash: crypto.SHA256},
+	"rsa-sha512": {uri: dsig.RSASHA512SignatureMethod, hash: crypto.SHA512},
+}
+
+var errUnsigned = errors.New("SAML message is not signed")
+
+func (s *SAMLService) redirectURL(destination, param string, el *etree.Element, relayState string) (string, error) {
+	return redirectURL(destination, param, el, relayState, s.sp.Key, s.signature)
+}
+
+// redirectURL returns the URL sending the message to destination with the
+// HTTP-Redirect binding. When a signature algorithm is set, the query is signed
+// with key as described in section 3.4.4.1 of the SAML bindings specification.
+func redirectURL(destination, param string, el *etree.Element, relayState string, key *rsa.PrivateKey, alg *signatureAlgorithm) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := deflate(el)
+	if err != nil {
+		return "", err
+	}
+
+	// The order of the parameters is part of the signed value.
+	query := param + "=" + url.QueryEscape(message)
+	if relayState != "" {
+		query += "&RelayState=" + url.QueryEscape(relayState)
+	}
+	if alg != nil {
+		query += "&SigAlg=" + url.QueryEscape(alg.uri)
+		signature, err := signQuery(key, alg.hash, query)
+		if err != nil {
+			return "", err
+		}
+		query += "&Signature=" + url.QueryEscape(signature)
+	}
+
+	if u.RawQuery != "" {
+		u.RawQuery += "&" + query
+	} else {
+		u.RawQuery = query
+	}
+
+	return u.String(), nil
+}
+
+func deflate(el *etree.Element) (string, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(el)
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := doc.WriteTo(w); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+func signQuery(key *rsa.PrivateKey, hash crypto.Hash, query string) (string, error) {
+	h := hash.New()
+	h.Write([]byte(query))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, hash, h.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// readMessage returns the XML of the SAMLRequest or SAMLResponse param sent
+// by the IdP with the HTTP-Redirect or HTTP-POST binding, after checking its
+// signature. Messages sent with the HTTP-Redirect binding are signed in the
+// query, although an enveloped signature is accepted as well.
+func (s *SAMLService) readMessage(r *http.Request, param string) ([]byte, error) {
+	var data []byte
+	querySigned := false
+
+	if r.Method == http.MethodGet {
+		value := r.URL.Query().Get(param)
+		if value == "" {
+			return nil, fmt.Errorf("missing %s", param)
+		}
+		compressed, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", param, err)
+		}
+		data, err = ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to inflate %s: %w", param, err)
+		}
+
+		if r.URL.Query().Get("Signature") != "" {
+			if err := verifyQuery(r.URL.RawQuery, param, s.idpCerts); err != nil {
+				return nil, err
+			}
+			querySigned = true
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		value := r.PostForm.Get(param)
+		if value == "" {
+			return nil, fmt.Errorf("missing %s", param)
+		}
+		var err error
+		data, err = base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", param, err)
+		}
+	}
+
+	if err := xrv.Validate(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%s contains invalid XML: %w", param, err)
+	}
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(data); err != nil {
+		return nil, err
+	}
+	if doc.Root() == nil {
+		return nil, fmt.Errorf("%s is empty", param)
+	}
+
+	if querySigned {
+		return data, nil
+	}
+
+	// Only the signed element is returned, anything the signature doesn't
+	// cover is dropped.
+	validated, err := verifyEnveloped(doc.Root(), s.idpCerts)
+	if err != nil {
+		return nil, err
+	}
+	signed := etree.NewDocument()
+	signed.SetRoot(validated)
+	return signed.WriteToBytes()
+}
+
+// verifyQuery checks the signature of a query sent with the HTTP-Redirect
+// binding. The signed value is built from the parameters as they were encoded
+// by the sender.
+func verifyQuery(rawQuery, param string, certs []*x509.Certificate) error {
+	raw := map[string]string{}
+	for _, part := range strings.Split(rawQuery, "&") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) == 2 {
+			raw[kv[0]] = kv[1]
+		}
+	}
+
+	signed := param + "=" + raw[param]
+	if relayState, ok := raw["RelayState"]; ok {
+		signed += "&RelayState=" + relayState
+	}
+	signed += "&SigAlg=" + raw["SigAlg"]
+
+	sigAlg, err := url.QueryUnescape(raw["SigAlg"])
+	if err != nil {
+		return err
+	}
+	var hash crypto.Hash
+	for _, alg := range signatureAlgorithms {
+		if alg.uri == sigAlg {
+			hash = alg.hash
+		}
+	}
+	if hash == 0 {
+		return fmt.Errorf("unsupported signature algorithm %q", sigAlg)
+	}
+
+	encoded, err := url.QueryUnescape(raw["Signature"])
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("failed to decode the signature: %w", err)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+	for _, cert := range certs {
+		key, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+		if rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil {
+			return nil
+		}
+	}
+
+	return errors.New("invalid signature of the SAML message")
+}
+
+// verifyEnveloped checks the enveloped signature of el and returns the
+// element covered by the signature.
+func verifyEnveloped(el *etree.Element, certs []*x509.Certificate) (*etree.Element, error) {
+	if el.FindElement("./Signature") == nil {
+		return nil, errUnsigned
+	}
+
+	ctx := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{Roots: certs})
+	ctx.IdAttribute = "ID"
+	validated, err := ctx.Validate(el)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature of the SAML message: %w", err)
+	}
+
+	return validated, nil
+}
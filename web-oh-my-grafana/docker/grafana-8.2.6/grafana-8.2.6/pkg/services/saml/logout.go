@@ -0,0 +1,96 @@
+package saml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/crewjam/saml"
+)
+
+func (s *SAMLService) LogoutRequestURL(nameID string) (string, error) {
+	sloURL := s.sp.GetSLOBindingLocation(saml.HTTPRedirectBinding)
+	if sloURL == "" {
+		return "", errors.New("the IdP metadata has no single logout service with the HTTP-Redirect binding")
+	}
+
+	req, err := s.sp.MakeLogoutRequest(sloURL, nameID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.redirectURL(sloURL, "SAMLRequest", req.Element(), "")
+}
+
+func (s *SAMLService) ParseLogoutRequest(r *http.Request) (*saml.LogoutRequest, error) {
+	data, err := s.readMessage(r, "SAMLRequest")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &saml.LogoutRequest{}
+	if err := xml.Unmarshal(data, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateMessage(req.Issuer, req.Destination, req.IssueInstant); err != nil {
+		return nil, err
+	}
+	if req.NameID == nil || req.NameID.Value == "" {
+		return nil, ErrMissingNameID
+	}
+
+	return req, nil
+}
+
+func (s *SAMLService) LogoutResponseURL(logoutRequestID string) (string, error) {
+	sloURL := s.sp.GetSLOBindingLocation(saml.HTTPRedirectBinding)
+	if sloURL == "" {
+		return "", errors.New("the IdP metadata has no single logout service with the HTTP-Redirect binding")
+	}
+
+	resp, err := s.sp.MakeLogoutResponse(sloURL, logoutRequestID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.redirectURL(sloURL, "SAMLResponse", resp.Element(), "")
+}
+
+func (s *SAMLService) ValidateLogoutResponse(r *http.Request) error {
+	data, err := s.readMessage(r, "SAMLResponse")
+	if err != nil {
+		return err
+	}
+
+	resp := &saml.LogoutResponse{}
+	if err := xml.Unmarshal(data, resp); err != nil {
+		return err
+	}
+
+	if err := s.validateMessage(resp.Issuer, resp.Destination, resp.IssueInstant); err != nil {
+		return err
+	}
+	if resp.Status.StatusCode.Value != saml.StatusSuccess {
+		return fmt.Errorf("the IdP failed to log out the user: %s", resp.Status.StatusCode.Value)
+	}
+
+	return nil
+}
+
+// validateMessage checks the fields common to the logout messages of the IdP.
+func (s *SAMLService) validateMessage(issuer *saml.Issuer, destination string, issueInstant time.Time) error {
+	if issuer == nil || issuer.Value != s.sp.IDPMetadata.EntityID {
+		return fmt.Errorf("the issuer of the SAML message isn't the IdP %q", s.sp.IDPMetadata.EntityID)
+	}
+	if destination != "" && destination != s.sp.SloURL.String() {
+		return fmt.Errorf("the destination of the SAML message isn't %q", s.sp.SloURL.String())
+	}
+	if issueInstant.Add(saml.MaxIssueDelay).Before(saml.TimeNow()) {
+		return fmt.Errorf("the SAML message expired at %s", issueInstant.Add(saml.MaxIssueDelay))
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package saml
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/crewjam/saml"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("saml.auth")
+
+	netClient = &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			Dial: (&net.Dialer{
+				Timeout: 30 * time.Second,
+			}).Dial,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		Timeout: 30 * time.Second,
+	}
+)
+
+var (
+	ErrMissingNameID          = errors.New("SAML assertion has no NameID")
+	ErrMissingLogin           = errors.New("SAML assertion has neither a login nor an email")
+	ErrOrganizationNotAllowed = errors.New("user is not a member of an allowed organization")
+)
+
+func ProvideService(cfg *setting.Cfg) (*SAMLService, error) {
+	s := &SAMLService{
+		Cfg: cfg,
+	}
+	if !cfg.SAML.Enabled {
+		return s, nil
+	}
+
+	if err := s.init(); err != nil {
+		return nil, fmt.Errorf("failed to configure SAML authentication: %w", err)
+	}
+
+	return s, nil
+}
+
+type Service interface {
+	IsEnabled() bool
+	IsSingleLogoutEnabled() bool
+	// Metadata returns the SP metadata XML.
+	Metadata() ([]byte, error)
+	// AuthnRequestURL returns the URL sending an authentication request to the
+	// IdP, and the ID of the request to pass to ParseResponse.
+	AuthnRequestURL() (string, string, error)
+	// ParseResponse validates the response of the IdP received by the ACS
+	// endpoint and maps the attributes of its assertion to the user.
+	ParseResponse(r *http.Request, possibleRequestIDs []string) (*models.ExternalUserInfo, error)
+	// LogoutRequestURL returns the URL asking the IdP to end the session of
+	// the user identified by nameID.
+	LogoutRequestURL(nameID string) (string, error)
+	// ParseLogoutRequest validates a logout request sent by the IdP.
+	ParseLogoutRequest(r *http.Request) (*saml.LogoutRequest, error)
+	// LogoutResponseURL returns the URL answering a logout request of the IdP.
+	LogoutResponseURL(logoutRequestID string) (string, error)
+	// ValidateLogoutResponse validates the answer of the IdP to a logout request.
+	ValidateLogoutResponse(r *http.Request) error
+}
+
+// SAMLService implements a SAML 2.0 service provider. Requests are sent to the
+// IdP with the HTTP-Redirect binding, responses are received with the
+// HTTP-POST binding and logout messages with either.
+type SAMLService struct {
+	Cfg *setting.Cfg
+
+	sp        *saml.ServiceProvider
+	signature *signatureAlgorithm
+	idpCerts  []*x509.Certificate
+	// orgMapping maps the IdP organizations to the IDs of Grafana
+	// organizations.
+	orgMapping map[string][]int64
+}
+
+func (s *SAMLService) IsEnabled() bool {
+	return s.sp != nil
+}
+
+func (s *SAMLService) IsSingleLogoutEnabled() bool {
+	return s.IsEnabled() && s.Cfg.SAML.SingleLogout
+}
+
+func (s *SAMLService) init() error {
+	settings := s.Cfg.SAML
+
+	keyPair, err := loadKeyPair(settings)
+	if err != nil {
+		return err
+	}
+	idpMetadata, err := loadIdPMetadata(settings)
+	if err != nil {
+		return err
+	}
+	s.idpCerts, err = idpSigningCerts(idpMetadata)
+	if err != nil {
+		return err
+	}
+
+	if settings.SignatureAlgorithm != "" {
+		alg, ok := signatureAlgorithms[settings.SignatureAlgorithm]
+		if !ok {
+			return fmt.Errorf("unsupported signature algorithm %q", settings.SignatureAlgorithm)
+		}
+		s.signature = &alg
+	}
+
+	if s.orgMapping, err = parseMapping("org_mapping", settings.OrgMapping); err != nil {
+		return err
+	}
+
+	rootURL, err := url.Parse(s.Cfg.AppURL)
+	if err != nil {
+		return err
+	}
+
+	// The maximum issue delay is a package variable of the SAML library, Grafana
+	// is its only service provider.
+	saml.MaxIssueDelay = settings.MaxIssueDelay
+
+	s.sp = &saml.ServiceProvider{
+		Key:                   keyPair.PrivateKey.(*rsa.PrivateKey),
+		Certificate:           keyPair.Leaf,
+		MetadataURL:           *rootURL.ResolveReference(&url.URL{Path: "saml/metadata"}),
+		AcsURL:                *rootURL.ResolveReference(&url.URL{Path: "saml/acs"}),
+		SloURL:                *rootURL.ResolveReference(&url.URL{Path: "saml/slo"}),
+		IDPMetadata:           idpMetadata,
+		AuthnNameIDFormat:     saml.UnspecifiedNameIDFormat,
+		MetadataValidDuration: settings.MetadataValidDuration,
+		AllowIDPInitiated:     settings.AllowIdPInitiated,
+	}
+
+	if s.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding) == "" {
+		return errors.New("the IdP metadata has no single sign-on service with the HTTP-Redirect binding")
+	}
+
+	return nil
+}
+
+func (s *SAMLService) Metadata() ([]byte, error) {
+	return xml.MarshalIndent(s.sp.Metadata(), "", "  ")
+}
+
+func (s *SAMLService) AuthnRequestURL() (string, string, error) {
+	req, err := s.sp.MakeAuthenticationRequest(s.sp.GetSSOBindingLocation(saml.HTTPRedirectBinding))
+	if err != nil {
+		return "", "", err
+	}
+
+	redirectURL, err := s.redirectURL(req.Destination, "SAMLRequest", req.Element(), "")
+	if err != nil {
+		return "", "", err
+	}
+
+	return redirectURL, req.ID, nil
+}
+
+func (s *SAMLService) ParseResponse(r *http.Request, possibleRequestIDs []string) (*models.ExternalUserInfo, error) {
+	// The service provider reads the response from the parsed form.
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	if len(possibleRequestIDs) == 0 && s.Cfg.SAML.RelayState != "" {
+		if r.PostForm.Get("RelayState") != s.Cfg.SAML.RelayState {
+			return nil, errors.New("the relay state of the IdP-initiated login doesn't match relay_state")
+		}
+	}
+
+	assertion, err := s.sp.ParseResponse(r, possibleRequestIDs)
+	if err != nil {
+		var invalidErr *saml.InvalidResponseError
+		if errors.As(err, &invalidErr) {
+			logger.Debug("Invalid SAML response", "error", invalidErr.PrivateErr)
+		}
+		return nil, err
+	}
+
+	return s.externalUser(assertion)
+}
+
+func loadKeyPair(settings setting.SAMLSettings) (tls.Certificate, error) {
+	certPEM, err := readValue("certificate", settings.Certificate, settings.CertificatePath, "")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := readValue("private_key", settings.PrivateKey, settings.PrivateKeyPath, "")
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	keyPair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if _, ok := keyPair.PrivateKey.(*rsa.PrivateKey); !ok {
+		return tls.Certificate{}, errors.New("the private key must be an RSA key")
+	}
+	keyPair.Leaf, err = x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return keyPair, nil
+}
+
+func loadIdPMetadata(settings setting.SAMLSettings) (*saml.EntityDescriptor, error) {
+	data, err := readValue("idp_metadata", settings.IdPMetadata, settings.IdPMetadataPath, settings.IdPMetadataURL)
+	if err != nil {
+		return nil, err
+	}
+
+	entity := &saml.EntityDescriptor{}
+	if err := xml.Unmarshal(data, entity); err == nil {
+		return entity, nil
+	}
+
+	// The metadata of some IdPs is an EntitiesDescriptor, use its first IdP.
+	entities := &saml.EntitiesDescriptor{}
+	if err := xml.Unmarshal(data, entities); err != nil {
+		return nil, fmt.Errorf("failed to parse the IdP metadata: %w", err)
+	}
+	for i, e := range entities.EntityDescriptors {
+		if len(e.IDPSSODescriptors) > 0 {
+			return &entities.EntityDescriptors[i], nil
+		}
+	}
+
+	return nil, errors.New("the IdP metadata has no IdP SSO descriptor")
+}
+
+// readValue returns the value of the setting name configured either as a
+// base64-encoded value, a path or a URL.
+func readValue(name, value, path, valueURL string) ([]byte, error) {
+	set := 0
+	for _, v := range []string{value, path, valueURL} {
+		if v != "" {
+			set++
+		}
+	}
+	if set == 0 {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+	if set > 1 {
+		return nil, fmt.Errorf("only one form of %s can be set", name)
+	}
+
+	switch {
+	case value != "":
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", name, err)
+		}
+		return data, nil
+	case path != "":
+		// We can ignore the gosec G304 warning on this one because the path
+		// comes from the configuration.
+		// nolint:gosec
+		return ioutil.ReadFile(path)
+	default:
+		resp, err := netClient.Get(valueURL)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				logger.Warn("Failed to close response body", "err", err)
+			}
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch %s: %s", name, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+}
+
+// idpSigningCerts returns the certificates the IdP signs its messages with.
+func idpSigningCerts(metadata *saml.EntityDescriptor) ([]*x509.Certificate, error) {
+	whitespace := regexp.MustCompile(`\s+`)
+
+	var certs []*x509.Certificate
+	for _, descriptor := range metadata.IDPSSODescriptors {
+		for _, key := range descriptor.KeyDescriptors {
+			if (key.Use != "" && key.Use != "signing") || key.KeyInfo.Certificate == "" {
+				continue
+			}
+			der, err := base64.StdEncoding.DecodeString(whitespace.ReplaceAllString(key.KeyInfo.Certificate, ""))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the IdP certificate: %w", err)
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, err
+			}
+			certs = append(certs, cert)
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil, errors.New("the IdP metadata has no signing certificate")
+	}
+
+	return certs, nil
+}
+
+// parseMapping parses the Name:Id pairs of the setting name.
+func parseMapping(name string, values []string) (map[string][]int64, error) {
+	mapping := map[string][]int64{}
+	for _, v := range values {
+		i := strings.LastIndex(v, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid %s %q", name, v)
+		}
+		id, err := strconv.ParseInt(v[i+1:], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, v)
+		}
+		mapping[v[:i]] = append(mapping[v[:i]], id)
+	}
+	return mapping, nil
+}
@@ -0,0 +1,477 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/crewjam/saml"
+	samllogger "github.com/crewjam/saml/logger"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata(t *testing.T) {
+	idp := newIdPStub(t)
+	s := setupTestService(t, idp, nil)
+
+	data, err := s.Metadata()
+	require.NoError(t, err)
+
+	metadata := saml.EntityDescriptor{}
+	require.NoError(t, xml.Unmarshal(data, &metadata))
+	assert.Equal(t, "http://grafana.test/saml/metadata", metadata.EntityID)
+	require.Len(t, metadata.SPSSODescriptors, 1)
+	descriptor := metadata.SPSSODescriptors[0]
+	assert.Equal(t, "http://grafana.test/saml/acs", descriptor.AssertionConsumerServices[0].Location)
+	assert.Equal(t, "http://grafana.test/saml/slo", descriptor.SingleLogoutServices[0].Location)
+}
+
+func TestProvideService(t *testing.T) {
+	idp := newIdPStub(t)
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		s, err := ProvideService(setting.NewCfg())
+		require.NoError(t, err)
+		assert.False(t, s.IsEnabled())
+	})
+
+	t.Run("rejects several forms of a setting", func(t *testing.T) {
+		cfg := testConfig(t, idp)
+		cfg.SAML.IdPMetadataPath = "/etc/grafana/idp.xml"
+		_, err := ProvideService(cfg)
+		require.EqualError(t, err, "failed to configure SAML authentication: only one form of idp_metadata can be set")
+	})
+
+	t.Run("rejects unknown signature algorithms", func(t *testing.T) {
+		cfg := testConfig(t, idp)
+		cfg.SAML.SignatureAlgorithm = "dsa-sha1"
+		_, err := ProvideService(cfg)
+		require.EqualError(t, err, `failed to configure SAML authentication: unsupported signature algorithm "dsa-sha1"`)
+	})
+
+	t.Run("reads the IdP metadata from a file", func(t *testing.T) {
+		path := t.TempDir() + "/idp.xml"
+		data, err := xml.Marshal(idp.Metadata())
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path, data, 0600))
+
+		cfg := testConfig(t, idp)
+		cfg.SAML.IdPMetadataURL = ""
+		cfg.SAML.IdPMetadataPath = path
+		s, err := ProvideService(cfg)
+		require.NoError(t, err)
+		assert.Equal(t, idp.MetadataURL.String(), s.sp.IDPMetadata.EntityID)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	idp := newIdPStub(t)
+	s := setupTestService(t, idp, nil)
+
+	redirectURL, requestID, err := s.AuthnRequestURL()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(redirectURL, idp.SSOURL.String()+"?SAMLRequest="))
+
+	t.Run("signs the authentication request in the query", func(t *testing.T) {
+		u, err := url.Parse(redirectURL)
+		require.NoError(t, err)
+		assert.Equal(t, dsig.RSASHA256SignatureMethod, u.Query().Get("SigAlg"))
+		require.NoError(t, verifyQuery(u.RawQuery, "SAMLRequest", []*x509.Certificate{s.sp.Certificate}))
+		require.Error(t, verifyQuery(u.RawQuery, "SAMLRequest", []*x509.Certificate{idp.Certificate}))
+	})
+
+	samlResponse := idp.login(t, redirectURL)
+
+	t.Run("maps the attributes of the assertion", func(t *testing.T) {
+		extUser, err := s.ParseResponse(acsRequest(samlResponse, ""), []string{requestID})
+		require.NoError(t, err)
+
+		isGrafanaAdmin := false
+		assert.Equal(t, &models.ExternalUserInfo{
+			AuthModule:     models.AuthModuleSAML,
+			AuthId:         "alice-id",
+			Login:          "alice",
+			Email:          "alice@example.com",
+			Name:           "Alice",
+			Groups:         []string{"sre", "developers"},
+			OrgRoles:       map[int64]models.RoleType{2: models.ROLE_EDITOR},
+			IsGrafanaAdmin: &isGrafanaAdmin,
+		}, extUser)
+	})
+
+	t.Run("rejects responses to other requests", func(t *testing.T) {
+		_, err := s.ParseResponse(acsRequest(samlResponse, ""), []string{"id-other"})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects IdP-initiated logins by default", func(t *testing.T) {
+		_, err := s.ParseResponse(acsRequest(samlResponse, ""), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestExternalUser(t *testing.T) {
+	idp := newIdPStub(t)
+
+	assertion := func(nameID string, attributes map[string][]string) *saml.Assertion {
+		statement := saml.AttributeStatement{}
+		for name, values := range attributes {
+			attr := saml.Attribute{Name: "urn:oid:" + name, FriendlyName: name}
+			for _, v := range values {
+				attr.Values = append(attr.Values, saml.AttributeValue{Value: v})
+			}
+			statement.Attributes = append(statement.Attributes, attr)
+		}
+		return &saml.Assertion{
+			Subject:             &saml.Subject{NameID: &saml.NameID{Value: nameID}},
+			AttributeStatements: []saml.AttributeStatement{statement},
+		}
+	}
+
+	t.Run("maps the Grafana Admin role", func(t *testing.T) {
+		s := setupTestService(t, idp, nil)
+		extUser, err := s.externalUser(assertion("bob-id", map[string][]string{
+			"mail": {"bob@example.com"},
+			"role": {"editor", "superadmin"},
+			"org":  {"Engineering", "Sales"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "bob@example.com", extUser.Login)
+		assert.Equal(t, map[int64]models.RoleType{2: models.ROLE_ADMIN, 3: models.ROLE_ADMIN}, extUser.OrgRoles)
+		require.NotNil(t, extUser.IsGrafanaAdmin)
+		assert.True(t, *extUser.IsGrafanaAdmin)
+	})
+
+	t.Run("assigns the role in the main organization without org mapping", func(t *testing.T) {
+		s := setupTestService(t, idp, func(settings *setting.SAMLSettings) {
+			settings.OrgMapping = nil
+		})
+		extUser, err := s.externalUser(assertion("bob-id", map[string][]string{
+			"login": {"bob"},
+			"role":  {"admin"},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]models.RoleType{1: models.ROLE_ADMIN}, extUser.OrgRoles)
+	})
+
+	t.Run("rejects users outside of the allowed organizations", func(t *testing.T) {
+		s := setupTestService(t, idp, func(settings *setting.SAMLSettings) {
+			settings.AllowedOrganizations = []string{"Engineering"}
+		})
+		_, err := s.externalUser(assertion("bob-id", map[string][]string{
+			"login": {"bob"},
+			"org":   {"Sales"},
+		}))
+		require.Equal(t, ErrOrganizationNotAllowed, err)
+	})
+
+	t.Run("requires a NameID and a login", func(t *testing.T) {
+		s := setupTestService(t, idp, nil)
+		_, err := s.externalUser(assertion("", map[string][]string{"login": {"bob"}}))
+		require.Equal(t, ErrMissingNameID, err)
+		_, err = s.externalUser(assertion("bob-id", map[string][]string{"displayName": {"Bob"}}))
+		require.Equal(t, ErrMissingLogin, err)
+	})
+}
+
+func TestSingleLogout(t *testing.T) {
+	idp := newIdPStub(t)
+	s := setupTestService(t, idp, nil)
+
+	t.Run("sends a signed logout request to the IdP", func(t *testing.T) {
+		logoutURL, err := s.LogoutRequestURL("alice-id")
+		require.NoError(t, err)
+
+		u, err := url.Parse(logoutURL)
+		require.NoError(t, err)
+		assert.Equal(t, idp.LogoutURL.Path, u.Path)
+		require.NoError(t, verifyQuery(u.RawQuery, "SAMLRequest", []*x509.Certificate{s.sp.Certificate}))
+
+		req := saml.LogoutRequest{}
+		require.NoError(t, xml.Unmarshal(inflate(t, u.Query().Get("SAMLRequest")), &req))
+		assert.Equal(t, "alice-id", req.NameID.Value)
+		assert.Equal(t, "http://grafana.test/saml/metadata", req.Issuer.Value)
+	})
+
+	t.Run("accepts logout requests signed by the IdP", func(t *testing.T) {
+		logoutURL := idp.logoutRequestURL(t, idp.Key.(*rsa.PrivateKey), "alice-id")
+		req, err := s.ParseLogoutRequest(httptest.NewRequest(http.MethodGet, logoutURL, nil))
+		require.NoError(t, err)
+		assert.Equal(t, "alice-id", req.NameID.Value)
+
+		responseURL, err := s.LogoutResponseURL(req.ID)
+		require.NoError(t, err)
+		u, err := url.Parse(responseURL)
+		require.NoError(t, err)
+		resp := saml.LogoutResponse{}
+		require.NoError(t, xml.Unmarshal(inflate(t, u.Query().Get("SAMLResponse")), &resp))
+		assert.Equal(t, req.ID, resp.InResponseTo)
+		assert.Equal(t, saml.StatusSuccess, resp.Status.StatusCode.Value)
+	})
+
+	t.Run("rejects logout requests signed by another key", func(t *testing.T) {
+		otherKey, _ := newKeyPair(t, "attacker.test")
+		logoutURL := idp.logoutRequestURL(t, otherKey, "alice-id")
+		_, err := s.ParseLogoutRequest(httptest.NewRequest(http.MethodGet, logoutURL, nil))
+		require.EqualError(t, err, "invalid signature of the SAML message")
+	})
+
+	t.Run("rejects unsigned logout requests", func(t *testing.T) {
+		logoutURL := idp.logoutRequestURL(t, nil, "alice-id")
+		_, err := s.ParseLogoutRequest(httptest.NewRequest(http.MethodGet, logoutURL, nil))
+		require.Equal(t, errUnsigned, err)
+	})
+
+	t.Run("accepts logout responses posted by the IdP", func(t *testing.T) {
+		resp := saml.LogoutResponse{
+			ID:           "id-response",
+			InResponseTo: "id-request",
+			Version:      "2.0",
+			IssueInstant: saml.TimeNow(),
+			Destination:  s.sp.SloURL.String(),
+			Issuer:       &saml.Issuer{Value: idp.MetadataURL.String()},
+			Status:       saml.Status{StatusCode: saml.StatusCode{Value: saml.StatusSuccess}},
+		}
+		signingContext := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(tls.Certificate{
+			Certificate: [][]byte{idp.Certificate.Raw},
+			PrivateKey:  idp.Key,
+		}))
+		signed, err := signingContext.SignEnveloped(resp.Element())
+		require.NoError(t, err)
+		data, err := xmlDocument(signed)
+		require.NoError(t, err)
+
+		form := url.Values{"SAMLResponse": {base64.StdEncoding.EncodeToString(data)}}
+		req := httptest.NewRequest(http.MethodPost, "http://grafana.test/saml/slo", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		require.NoError(t, s.ValidateLogoutResponse(req))
+	})
+}
+
+func testConfig(t *testing.T, idp *idpStub) *setting.Cfg {
+	t.Helper()
+
+	key, cert := newKeyPair(t, "grafana.test")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://grafana.test/"
+	cfg.SAML = setting.SAMLSettings{
+		Enabled:                true,
+		SingleLogout:           true,
+		AllowSignUp:            true,
+		Certificate:            base64.StdEncoding.EncodeToString(certPEM),
+		PrivateKey:             base64.StdEncoding.EncodeToString(keyPEM),
+		SignatureAlgorithm:     "rsa-sha256",
+		IdPMetadataURL:         idp.MetadataURL.String(),
+		MaxIssueDelay:          90 * time.Second,
+		MetadataValidDuration:  48 * time.Hour,
+		AttributeName:          "displayName",
+		AttributeLogin:         "login",
+		AttributeEmail:         "mail",
+		AttributeGroups:        "groups",
+		AttributeRole:          "role",
+		AttributeOrg:           "org",
+		OrgMapping:             []string{"Engineering:2", "Sales:3"},
+		RoleValuesEditor:       []string{"editor"},
+		RoleValuesAdmin:        []string{"admin"},
+		RoleValuesGrafanaAdmin: []string{"superadmin"},
+	}
+	return cfg
+}
+
+func setupTestService(t *testing.T, idp *idpStub, configure func(*setting.SAMLSettings)) *SAMLService {
+	t.Helper()
+
+	cfg := testConfig(t, idp)
+	if configure != nil {
+		configure(&cfg.SAML)
+	}
+	s, err := ProvideService(cfg)
+	require.NoError(t, err)
+	idp.sp = s.sp
+	return s
+}
+
+func newKeyPair(t *testing.T, commonName string) (*rsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return key, cert
+}
+
+// idpStub is a local IdP with a self-signed certificate, which logs in the
+// user of its session.
+type idpStub struct {
+	*saml.IdentityProvider
+	sp      *saml.ServiceProvider
+	session *saml.Session
+}
+
+func newIdPStub(t *testing.T) *idpStub {
+	t.Helper()
+
+	key, cert := newKeyPair(t, "idp.test")
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	idp := &idpStub{
+		session: &saml.Session{
+			ID:         "session",
+			CreateTime: time.Now(),
+			ExpireTime: time.Now().Add(time.Hour),
+			Index:      "1",
+			NameID:     "alice-id",
+			CustomAttributes: []saml.Attribute{
+				stringAttribute("login", "alice"),
+				stringAttribute("mail", "alice@example.com"),
+				stringAttribute("displayName", "Alice"),
+				stringAttribute("groups", "sre", "developers"),
+				stringAttribute("role", "editor"),
+				stringAttribute("org", "Engineering"),
+			},
+		},
+	}
+	idp.IdentityProvider = &saml.IdentityProvider{
+		Key:                     key,
+		Certificate:             cert,
+		Logger:                  samllogger.DefaultLogger,
+		MetadataURL:             *baseURL.ResolveReference(&url.URL{Path: "/metadata"}),
+		SSOURL:                  *baseURL.ResolveReference(&url.URL{Path: "/sso"}),
+		LogoutURL:               *baseURL.ResolveReference(&url.URL{Path: "/slo"}),
+		ServiceProviderProvider: idp,
+		SessionProvider:         idp,
+	}
+	mux.HandleFunc("/metadata", idp.ServeMetadata)
+	mux.HandleFunc("/sso", idp.ServeSSO)
+
+	return idp
+}
+
+func (idp *idpStub) GetServiceProvider(r *http.Request, serviceProviderID string) (*saml.EntityDescriptor, error) {
+	if idp.sp == nil || serviceProviderID != idp.sp.MetadataURL.String() {
+		return nil, os.ErrNotExist
+	}
+	return idp.sp.Metadata(), nil
+}
+
+func (idp *idpStub) GetSession(w http.ResponseWriter, r *http.Request, req *saml.IdpAuthnRequest) *saml.Session {
+	return idp.session
+}
+
+// login follows the redirect to the IdP and returns the SAMLResponse of the
+// form posting the assertion to the ACS endpoint.
+func (idp *idpStub) login(t *testing.T, redirectURL string) string {
+	t.Helper()
+
+	resp, err := http.Get(redirectURL)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	match := regexp.MustCompile(`name="SAMLResponse" value="([^"]+)"`).FindSubmatch(body)
+	require.NotNil(t, match, "no SAMLResponse in %s", body)
+	return html.UnescapeString(string(match[1]))
+}
+
+// logoutRequestURL returns an IdP-initiated logout request signed with key,
+// or unsigned when key is nil.
+func (idp *idpStub) logoutRequestURL(t *testing.T, key *rsa.PrivateKey, nameID string) string {
+	t.Helper()
+
+	req := saml.LogoutRequest{
+		ID:           fmt.Sprintf("id-%d", time.Now().UnixNano()),
+		Version:      "2.0",
+		IssueInstant: saml.TimeNow(),
+		Destination:  idp.sp.SloURL.String(),
+		Issuer:       &saml.Issuer{Value: idp.MetadataURL.String()},
+		NameID:       &saml.NameID{Value: nameID},
+	}
+	var alg *signatureAlgorithm
+	if key != nil {
+		sha256 := signatureAlgorithms["rsa-sha256"]
+		alg = &sha256
+	}
+
+	u, err := redirectURL(idp.sp.SloURL.String(), "SAMLRequest", req.Element(), "", key, alg)
+	require.NoError(t, err)
+	return u
+}
+
+func stringAttribute(name string, values ...string) saml.Attribute {
+	attr := saml.Attribute{Name: name, NameFormat: "urn:oasis:names:tc:SAML:2.0:attrname-format:basic"}
+	for _, v := range values {
+		attr.Values = append(attr.Values, saml.AttributeValue{Type: "xs:string", Value: v})
+	}
+	return attr
+}
+
+func acsRequest(samlResponse, relayState string) *http.Request {
+	form := url.Values{"SAMLResponse": {samlResponse}}
+	if relayState != "" {
+		form.Set("RelayState", relayState)
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://grafana.test/saml/acs", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func inflate(t *testing.T, message string) []byte {
+	t.Helper()
+
+	compressed, err := base64.StdEncoding.DecodeString(message)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	require.NoError(t, err)
+	return data
+}
+
+func xmlDocument(el *etree.Element) ([]byte, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(el)
+	return doc.WriteToBytes()
+}
@@ -0,0 +1,54 @@
+package saml
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/crewjam/saml"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var errFakeDisabled = errors.New("SAML authentication is not enabled")
+
+// FakeSAMLService is a Service for tests with SAML authentication disabled.
+type FakeSAMLService struct{}
+
+func NewFakeSAMLService() *FakeSAMLService {
+	return &FakeSAMLService{}
+}
+
+func (s *FakeSAMLService) IsEnabled() bool {
+	return false
+}
+
+func (s *FakeSAMLService) IsSingleLogoutEnabled() bool {
+	return false
+}
+
+func (s *FakeSAMLService) Metadata() ([]byte, error) {
+	return nil, errFakeDisabled
+}
+
+func (s *FakeSAMLService) AuthnRequestURL() (string, string, error) {
+	return "", "", errFakeDisabled
+}
+
+func (s *FakeSAMLService) ParseResponse(r *http.Request, possibleRequestIDs []string) (*models.ExternalUserInfo, error) {
+	return nil, errFakeDisabled
+}
+
+func (s *FakeSAMLService) LogoutRequestURL(nameID string) (string, error) {
+	return "", errFakeDisabled
+}
+
+func (s *FakeSAMLService) ParseLogoutRequest(r *http.Request) (*saml.LogoutRequest, error) {
+	return nil, errFakeDisabled
+}
+
+func (s *FakeSAMLService) LogoutResponseURL(logoutRequestID string) (string, error) {
+	return "", errFakeDisabled
+}
+
+func (s *FakeSAMLService) ValidateLogoutResponse(r *http.Request) error {
+	return errFakeDisabled
+}
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -328,6 +327,7 @@ func CreateAlertNotificationCommand(cmd *models.CreateAlertNotificationCommand)
 		for k, v := range cmd.SecureSettings {
 			if v == "" {
 				delete(cmd.SecureSettings, k)
+				delete(cmd.EncryptedSecureSettings, k)
 			}
 		}
 
@@ -337,7 +337,7 @@ func CreateAlertNotificationCommand(cmd *models.CreateAlertNotificationCommand)
 			Name:                  cmd.Name,
 			Type:                  cmd.Type,
 			Settings:              cmd.Settings,
-			SecureSettings:        securejsondata.GetEncryptedJsonData(cmd.SecureSettings),
+			SecureSettings:        cmd.EncryptedSecureSettings,
 			SendReminder:          cmd.SendReminder,
 			DisableResolveMessage: cmd.DisableResolveMessage,
 			Frequency:             frequency,
@@ -397,12 +397,13 @@ func UpdateAlertNotification(cmd *models.UpdateAlertNotificationCommand) error {
 		for k, v := range cmd.SecureSettings {
 			if v == "" {
 				delete(cmd.SecureSettings, k)
+				delete(cmd.EncryptedSecureSettings, k)
 			}
 		}
 
 		current.Updated = time.Now()
 		current.Settings = cmd.Settings
-		current.SecureSettings = securejsondata.GetEncryptedJsonData(cmd.SecureSettings)
+		current.SecureSettings = cmd.EncryptedSecureSettings
 		current.Name = cmd.Name
 		current.Type = cmd.Type
 		current.IsDefault = cmd.IsDefault
@@ -468,7 +469,8 @@ func UpdateAlertNotificationWithUid(cmd *models.UpdateAlertNotificationWithUidCo
 		Settings:              cmd.Settings,
 		SecureSettings:        cmd.SecureSettings,
 
-		OrgId: cmd.OrgId,
+		OrgId:                   cmd.OrgId,
+		EncryptedSecureSettings: cmd.EncryptedSecureSettings,
 	}
 
 	if err := bus.Dispatch(updateNotification); err != nil {
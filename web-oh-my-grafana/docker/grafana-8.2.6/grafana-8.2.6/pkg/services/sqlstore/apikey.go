@@ -14,6 +14,7 @@ func init() {
 	bus.AddHandler("sql", GetApiKeyByName)
 	bus.AddHandlerCtx("sql", DeleteApiKeyCtx)
 	bus.AddHandler("sql", AddApiKey)
+	bus.AddHandlerCtx("sql", UpdateApiKeyLastUsed)
 }
 
 func GetApiKeys(query *models.GetApiKeysQuery) error {
@@ -72,6 +73,8 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 			Created: updated,
 			Updated: updated,
 			Expires: expires,
+
+			ServiceAccountId: cmd.ServiceAccountId,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
@@ -82,6 +85,13 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 	})
 }
 
+func UpdateApiKeyLastUsed(ctx context.Context, cmd *models.UpdateApiKeyLastUsedCommand) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		_, err := sess.Exec("UPDATE api_key SET last_used_at = ? WHERE id = ?", cmd.LastUsedAt, cmd.Id)
+		return err
+	})
+}
+
 func GetApiKeyById(query *models.GetApiKeyByIdQuery) error {
 	var apikey models.ApiKey
 	has, err := x.Id(query.ApiKeyId).Get(&apikey)
@@ -440,10 +440,13 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 		"DELETE FROM annotation WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_provisioning WHERE dashboard_id = ?",
 		"DELETE FROM dashboard_acl WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_usage_by_user WHERE dashboard_id = ?",
+		"DELETE FROM dashboard_usage_by_day WHERE dashboard_id = ?",
 	}
 
 	if dashboard.IsFolder {
 		deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
+		deletes = append(deletes, "DELETE FROM quota WHERE folder_id = ?")
 
 		dashIds := []struct {
 			Id int64
@@ -467,6 +470,8 @@ func deleteDashboard(cmd *models.DeleteDashboardCommand, sess *DBSession) error
 				"DELETE FROM annotation WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_provisioning WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 				"DELETE FROM dashboard_acl WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_usage_by_user WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
+				"DELETE FROM dashboard_usage_by_day WHERE dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND folder_id = ?)",
 			}
 			for _, sql := range childrenDeletes {
 				_, err := sess.Exec(sql, dashboard.OrgId, dashboard.Id)
@@ -30,7 +30,7 @@ func TestDashboardSnapshotDBAccess(t *testing.T) {
 		rawDashboard, err := dashboard.Encode()
 		require.NoError(t, err)
 
-		encryptedDashboard, err := ossencryption.ProvideService().Encrypt(rawDashboard, setting.SecretKey)
+		encryptedDashboard, err := (&ossencryption.Service{}).Encrypt(rawDashboard, setting.SecretKey)
 		require.NoError(t, err)
 
 		cmd := models.CreateDashboardSnapshotCommand{
@@ -50,7 +50,7 @@ func TestDashboardSnapshotDBAccess(t *testing.T) {
 
 			assert.NotNil(t, query.Result)
 
-			decryptedDashboard, err := ossencryption.ProvideService().Decrypt(
+			decryptedDashboard, err := (&ossencryption.Service{}).Decrypt(
 				query.Result.DashboardEncrypted,
 				setting.SecretKey,
 			)
@@ -131,7 +131,7 @@ func TestDashboardSnapshotDBAccess(t *testing.T) {
 		})
 
 		t.Run("Should have encrypted dashboard data", func(t *testing.T) {
-			decryptedDashboard, err := ossencryption.ProvideService().Decrypt(
+			decryptedDashboard, err := (&ossencryption.Service{}).Decrypt(
 				cmd.Result.DashboardEncrypted,
 				setting.SecretKey,
 			)
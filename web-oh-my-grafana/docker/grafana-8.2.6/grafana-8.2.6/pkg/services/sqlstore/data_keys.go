@@ -0,0 +1,57 @@
+package sqlstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GetDataKey returns the data key with the given name.
+func (ss *SQLStore) GetDataKey(ctx context.Context, name string) (*models.DataKey, error) {
+	var dataKey models.DataKey
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		exists, err := sess.Where("name = ?", name).Get(&dataKey)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return models.ErrDataKeyNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dataKey, nil
+}
+
+// GetDataKeys returns all data keys.
+func (ss *SQLStore) GetDataKeys(ctx context.Context) ([]*models.DataKey, error) {
+	dataKeys := make([]*models.DataKey, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.Asc("id").Find(&dataKeys)
+	})
+
+	return dataKeys, err
+}
+
+func (ss *SQLStore) CreateDataKey(ctx context.Context, dataKey *models.DataKey) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		dataKey.Created = time.Now()
+		dataKey.Updated = dataKey.Created
+		_, err := sess.Insert(dataKey)
+		return err
+	})
+}
+
+// UpdateDataKey stores a data key which has been encrypted with another key
+// encryption key.
+func (ss *SQLStore) UpdateDataKey(ctx context.Context, dataKey *models.DataKey) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		dataKey.Updated = time.Now()
+		_, err := sess.ID(dataKey.Id).Cols("provider", "key_encryption_key_id", "encrypted_data", "updated").Update(dataKey)
+		return err
+	})
+}
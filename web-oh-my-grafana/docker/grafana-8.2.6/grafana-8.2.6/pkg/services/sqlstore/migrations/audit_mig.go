@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAuditMigrations(mg *Migrator) {
+	auditEventV1 := Table{
+		Name: "audit_event",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "actor_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "actor_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_type", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "diff", Type: DB_MediumText, Nullable: true},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 50, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"actor_type", "actor_id"}},
+			{Cols: []string{"resource_type", "resource_uid"}},
+		},
+	}
+
+	mg.AddMigration("create audit_event table", NewAddTableMigration(auditEventV1))
+	addTableIndicesMigrations(mg, "v1", auditEventV1)
+}
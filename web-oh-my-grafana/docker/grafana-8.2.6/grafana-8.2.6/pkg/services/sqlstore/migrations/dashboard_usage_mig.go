@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	usageByUserV1 := Table{
+		Name: "dashboard_usage_by_user",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+			{Name: "last_viewed_at", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "last_viewed_at"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_by_user table v1", NewAddTableMigration(usageByUserV1))
+	addTableIndicesMigrations(mg, "v1", usageByUserV1)
+
+	usageByDayV1 := Table{
+		Name: "dashboard_usage_by_day",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_id", Type: DB_BigInt, Nullable: false},
+			{Name: "day", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"dashboard_id", "day"}, Type: UniqueIndex},
+			{Cols: []string{"day"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_by_day table v1", NewAddTableMigration(usageByDayV1))
+	addTableIndicesMigrations(mg, "v1", usageByDayV1)
+}
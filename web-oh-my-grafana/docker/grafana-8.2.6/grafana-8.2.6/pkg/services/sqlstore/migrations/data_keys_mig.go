@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDataKeysMigrations(mg *Migrator) {
+	dataKeyV1 := Table{
+		Name: "data_key",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "name", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "provider", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "key_encryption_key_id", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "encrypted_data", Type: DB_Blob, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"name"}, Type: UniqueIndex},
+			{Cols: []string{"key_encryption_key_id"}},
+		},
+	}
+
+	mg.AddMigration("create data_key table", NewAddTableMigration(dataKeyV1))
+	addTableIndicesMigrations(mg, "v1", dataKeyV1)
+}
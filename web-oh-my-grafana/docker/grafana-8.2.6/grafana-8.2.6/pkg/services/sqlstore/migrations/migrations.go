@@ -54,6 +54,14 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.RerunDashAlertMigration(mg)
 	addKVStoreMigrations(mg)
 	ualert.AddDashboardUIDPanelIDMigration(mg)
+	addDashboardUsageMigrations(mg)
+	addDataKeysMigrations(mg)
+	addTwoFactorMigrations(mg)
+	addServiceAccountsMigrations(mg)
+	addAuditMigrations(mg)
+	addTeamGroupMigrations(mg)
+	addSessionPolicyMigrations(mg)
+	addPasswordPolicyMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
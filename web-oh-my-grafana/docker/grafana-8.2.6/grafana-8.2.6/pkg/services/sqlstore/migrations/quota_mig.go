@@ -28,4 +28,14 @@ func addQuotaMigration(mg *Migrator) {
 	mg.AddMigration("Update quota table charset", NewTableCharsetMigration("quota", []*Column{
 		{Name: "target", Type: DB_NVarchar, Length: 190, Nullable: false},
 	}))
+
+	mg.AddMigration("Add column folder_id to quota", NewAddColumnMigration(quotaV1, &Column{
+		Name: "folder_id", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	// quotas are unique per folder as well now
+	mg.AddMigration("drop index UQE_quota_org_id_user_id_target - v1", NewDropIndexMigration(quotaV1, quotaV1.Indices[0]))
+	mg.AddMigration("create index UQE_quota_org_id_user_id_folder_id_target - v2", NewAddIndexMigration(quotaV1, &Index{
+		Cols: []string{"org_id", "user_id", "folder_id", "target"}, Type: UniqueIndex,
+	}))
 }
@@ -0,0 +1,105 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/util"
+	"xorm.io/xorm"
+)
+
+func addServiceAccountsMigrations(mg *Migrator) {
+	mg.AddMigration("Add is_service_account column to user", NewAddColumnMigration(Table{Name: "user"}, &Column{
+		Name: "is_service_account", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	apiKeyV2 := Table{Name: "api_key"}
+	mg.AddMigration("Add service_account_id to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "service_account_id", Type: DB_BigInt, Nullable: true,
+	}))
+	mg.AddMigration("Add last_used_at to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "last_used_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	permissionV1 := Table{
+		Name: "service_account_permission",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "service_account_id", Type: DB_BigInt, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "scope", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"service_account_id"}},
+		},
+	}
+	mg.AddMigration("create service_account_permission table", NewAddTableMigration(permissionV1))
+	addTableIndicesMigrations(mg, "v1", permissionV1)
+
+	mg.AddMigration("migrate api keys to service accounts", &MigrateAPIKeysToServiceAccounts{})
+}
+
+// MigrateAPIKeysToServiceAccounts creates a service account for every API
+// key, with the role of the key, and turns the key into a token of it.
+type MigrateAPIKeysToServiceAccounts struct {
+	MigrationBase
+}
+
+func (m *MigrateAPIKeysToServiceAccounts) SQL(dialect Dialect) string {
+	return "code migration"
+}
+
+type apiKeyToMigrate struct {
+	Id    int64
+	OrgId int64
+	Name  string
+	Role  string
+}
+
+func (m *MigrateAPIKeysToServiceAccounts) Exec(sess *xorm.Session, mg *Migrator) error {
+	keys := make([]*apiKeyToMigrate, 0)
+	if err := sess.SQL("SELECT id, org_id, name, role FROM api_key WHERE service_account_id IS NULL").Find(&keys); err != nil {
+		return err
+	}
+
+	userTable := mg.Dialect.Quote("user")
+	for _, key := range keys {
+		now := time.Now()
+		login := fmt.Sprintf("sa-%d-%s", key.Id, models.SlugifyTitle(key.Name))
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+		rands, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("INSERT INTO "+userTable+
+			" (version, login, email, name, salt, rands, org_id, is_admin, created, updated, last_seen_at, is_service_account)"+
+			" VALUES (0, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			login, login, key.Name, salt, rands, key.OrgId, false, now, now, now.AddDate(-10, 0, 0), true); err != nil {
+			return err
+		}
+
+		var userID int64
+		if _, err := sess.SQL("SELECT id FROM "+userTable+" WHERE login = ?", login).Get(&userID); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)",
+			key.OrgId, userID, key.Role, now, now); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("UPDATE api_key SET service_account_id = ? WHERE id = ?", userID, key.Id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
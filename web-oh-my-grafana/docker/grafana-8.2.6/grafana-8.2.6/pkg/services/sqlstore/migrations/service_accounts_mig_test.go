@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/sqlstore/sqlutil"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+)
+
+func TestMigrateAPIKeysToServiceAccounts(t *testing.T) {
+	testDB := sqlutil.SQLite3TestDB()
+	x, err := xorm.NewEngine(testDB.DriverName, testDB.ConnStr)
+	require.NoError(t, err)
+	require.NoError(t, NewDialect(x).CleanDB())
+
+	migrations := &OSSMigrations{}
+	mg := NewMigrator(x, &setting.Cfg{})
+	migrations.AddMigration(mg)
+	require.NoError(t, mg.Start())
+
+	// an API key created before the migration
+	now := time.Now()
+	_, err = x.Exec("INSERT INTO api_key (org_id, name, `key`, role, created, updated) VALUES (?, ?, ?, ?, ?, ?)",
+		1, "Deploy key", "hashed", "Editor", now, now)
+	require.NoError(t, err)
+	_, err = x.Exec("DELETE FROM migration_log WHERE migration_id = ?", "migrate api keys to service accounts")
+	require.NoError(t, err)
+
+	mg = NewMigrator(x, &setting.Cfg{})
+	migrations.AddMigration(mg)
+	require.NoError(t, mg.Start())
+
+	var key struct {
+		Id               int64
+		ServiceAccountId int64
+	}
+	has, err := x.SQL("SELECT id, service_account_id FROM api_key WHERE name = ?", "Deploy key").Get(&key)
+	require.NoError(t, err)
+	require.True(t, has)
+	require.NotZero(t, key.ServiceAccountId)
+
+	var user struct {
+		Login            string
+		IsServiceAccount bool
+		Role             string
+	}
+	has, err = x.SQL("SELECT u.login, u.is_service_account, org_user.role FROM `user` as u "+
+		"INNER JOIN org_user ON org_user.user_id = u.id WHERE u.id = ?", key.ServiceAccountId).Get(&user)
+	require.NoError(t, err)
+	require.True(t, has)
+	require.Equal(t, "sa-1-deploy-key", user.Login)
+	require.True(t, user.IsServiceAccount)
+	require.Equal(t, "Editor", user.Role)
+}
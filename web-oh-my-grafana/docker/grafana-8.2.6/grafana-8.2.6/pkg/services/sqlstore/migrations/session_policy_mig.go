@@ -0,0 +1,23 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addSessionPolicyMigrations(mg *Migrator) {
+	sessionPolicyV1 := Table{
+		Name: "org_session_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "idle_timeout_seconds", Type: DB_BigInt, Nullable: false},
+			{Name: "max_concurrent_sessions", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create org session policy table", NewAddTableMigration(sessionPolicyV1))
+	mg.AddMigration("add unique index org_session_policy.org_id", NewAddIndexMigration(sessionPolicyV1, sessionPolicyV1.Indices[0]))
+}
@@ -12,6 +12,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -125,7 +127,7 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 			m.migratedChannelsPerOrg[orgID] = make(map[*notificationChannel]struct{})
 		}
 		m.migratedChannelsPerOrg[orgID][c] = struct{}{}
-		settings, decryptedSecureSettings, err := migrateSettingsToSecureSettings(c.Type, c.Settings, c.SecureSettings)
+		settings, decryptedSecureSettings, err := m.migrateSettingsToSecureSettings(c.Type, c.Settings, c.SecureSettings)
 		if err != nil {
 			return err
 		}
@@ -297,7 +299,7 @@ func (m *migration) addUnmigratedChannels(orgID int64, amConfigs *PostableUserCo
 		}
 
 		m.migratedChannelsPerOrg[orgID][c] = struct{}{}
-		settings, decryptedSecureSettings, err := migrateSettingsToSecureSettings(c.Type, c.Settings, c.SecureSettings)
+		settings, decryptedSecureSettings, err := m.migrateSettingsToSecureSettings(c.Type, c.Settings, c.SecureSettings)
 		if err != nil {
 			return err
 		}
@@ -333,7 +335,7 @@ func (m *migration) generateChannelUID() (string, bool) {
 // Some settings were migrated from settings to secure settings in between.
 // See https://grafana.com/docs/grafana/latest/installation/upgrading/#ensure-encryption-of-existing-alert-notification-channel-secrets.
 // migrateSettingsToSecureSettings takes care of that.
-func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json, secureSettings securejsondata.SecureJsonData) (*simplejson.Json, map[string]string, error) {
+func (m *migration) migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json, secureSettings securejsondata.SecureJsonData) (*simplejson.Json, map[string]string, error) {
 	keys := []string{}
 	switch chanType {
 	case "slack":
@@ -356,7 +358,7 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 		keys = []string{"api_secret"}
 	}
 
-	decryptedSecureSettings := secureSettings.Decrypt()
+	decryptedSecureSettings := m.decryptSecureSettings(chanType, secureSettings)
 	cloneSettings := simplejson.New()
 	settingsMap, err := settings.Map()
 	if err != nil {
@@ -380,6 +382,26 @@ func migrateSettingsToSecureSettings(chanType string, settings *simplejson.Json,
 	return cloneSettings, decryptedSecureSettings, nil
 }
 
+// decryptSecureSettings decrypts secure settings with the secret key, as the
+// migration runs without the encryption service. Envelope encrypted settings
+// need the key provider and are left out.
+func (m *migration) decryptSecureSettings(chanType string, secureSettings securejsondata.SecureJsonData) map[string]string {
+	decrypted := make(map[string]string, len(secureSettings))
+	for k, v := range secureSettings {
+		if encryption.IsEnvelopeEncrypted(v) {
+			m.mg.Logger.Error("alert migration error: envelope encrypted secure setting can't be migrated", "type", chanType, "key", k)
+			continue
+		}
+		d, err := util.Decrypt(v, setting.SecretKey)
+		if err != nil {
+			m.mg.Logger.Error("alert migration error: failed to decrypt secure setting", "type", chanType, "key", k, "error", err)
+			continue
+		}
+		decrypted[k] = string(d)
+	}
+	return decrypted
+}
+
 func getLabelForRouteMatching(ruleUID string) (string, string) {
 	return "rule_uid", ruleUID
 }
@@ -414,9 +436,12 @@ type amConfigsPerOrg = map[int64]*PostableUserConfig
 func (c *PostableUserConfig) EncryptSecureSettings() error {
 	for _, r := range c.AlertmanagerConfig.Receivers {
 		for _, gr := range r.GrafanaManagedReceivers {
-			encryptedData := securejsondata.GetEncryptedJsonData(gr.SecureSettings)
-			for k, v := range encryptedData {
-				gr.SecureSettings[k] = base64.StdEncoding.EncodeToString(v)
+			for k, v := range gr.SecureSettings {
+				encryptedData, err := util.Encrypt([]byte(v), setting.SecretKey)
+				if err != nil {
+					return err
+				}
+				gr.SecureSettings[k] = base64.StdEncoding.EncodeToString(encryptedData)
 			}
 		}
 	}
@@ -231,6 +231,9 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 			Cols: []string{"org_id", "dashboard_uid", "panel_id"},
 		},
 	))
+
+	// add variables column
+	mg.AddMigration("add column variables to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "variables", Type: migrator.DB_Text, Nullable: true}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -274,6 +277,9 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add variables column
+	mg.AddMigration("add column variables to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "variables", Type: migrator.DB_Text, Nullable: true}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/util"
 
 	pb "github.com/prometheus/alertmanager/silence/silencepb"
 	"xorm.io/xorm"
@@ -442,6 +443,18 @@ func (m *migration) validateAlertmanagerConfig(orgID int64, config *PostableUser
 				secureSettings[k] = d
 			}
 
+			// the settings were encrypted with the secret key by the migration
+			decryptFn := func(sjd map[string][]byte, key string, fallback string, secret string) string {
+				if value, ok := sjd[key]; ok {
+					decrypted, err := util.Decrypt(value, secret)
+					if err != nil {
+						return fallback
+					}
+					return string(decrypted)
+				}
+				return fallback
+			}
+
 			var (
 				cfg = &channels.NotificationChannelConfig{
 					UID:                   gr.UID,
@@ -458,13 +471,13 @@ func (m *migration) validateAlertmanagerConfig(orgID int64, config *PostableUser
 			case "email":
 				_, err = channels.NewEmailNotifier(cfg, nil) // Email notifier already has a default template.
 			case "pagerduty":
-				_, err = channels.NewPagerdutyNotifier(cfg, nil)
+				_, err = channels.NewPagerdutyNotifier(cfg, nil, decryptFn)
 			case "pushover":
-				_, err = channels.NewPushoverNotifier(cfg, nil)
+				_, err = channels.NewPushoverNotifier(cfg, nil, decryptFn)
 			case "slack":
-				_, err = channels.NewSlackNotifier(cfg, nil)
+				_, err = channels.NewSlackNotifier(cfg, nil, decryptFn)
 			case "telegram":
-				_, err = channels.NewTelegramNotifier(cfg, nil)
+				_, err = channels.NewTelegramNotifier(cfg, nil, decryptFn)
 			case "victorops":
 				_, err = channels.NewVictoropsNotifier(cfg, nil)
 			case "teams":
@@ -474,21 +487,21 @@ func (m *migration) validateAlertmanagerConfig(orgID int64, config *PostableUser
 			case "kafka":
 				_, err = channels.NewKafkaNotifier(cfg, nil)
 			case "webhook":
-				_, err = channels.NewWebHookNotifier(cfg, nil)
+				_, err = channels.NewWebHookNotifier(cfg, nil, decryptFn)
 			case "sensugo":
-				_, err = channels.NewSensuGoNotifier(cfg, nil)
+				_, err = channels.NewSensuGoNotifier(cfg, nil, decryptFn)
 			case "discord":
 				_, err = channels.NewDiscordNotifier(cfg, nil)
 			case "googlechat":
 				_, err = channels.NewGoogleChatNotifier(cfg, nil)
 			case "LINE":
-				_, err = channels.NewLineNotifier(cfg, nil)
+				_, err = channels.NewLineNotifier(cfg, nil, decryptFn)
 			case "threema":
-				_, err = channels.NewThreemaNotifier(cfg, nil)
+				_, err = channels.NewThreemaNotifier(cfg, nil, decryptFn)
 			case "opsgenie":
-				_, err = channels.NewOpsgenieNotifier(cfg, nil)
+				_, err = channels.NewOpsgenieNotifier(cfg, nil, decryptFn)
 			case "prometheus-alertmanager":
-				_, err = channels.NewAlertmanagerNotifier(cfg, nil)
+				_, err = channels.NewAlertmanagerNotifier(cfg, nil, decryptFn)
 			default:
 				return fmt.Errorf("notifier %s is not supported", gr.Type)
 			}
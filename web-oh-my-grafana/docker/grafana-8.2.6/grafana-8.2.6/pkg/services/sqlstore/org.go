@@ -252,6 +252,7 @@ func DeleteOrg(cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM alert WHERE org_id = ?",
 			"DELETE FROM annotation WHERE org_id = ?",
 			"DELETE FROM kv_store WHERE org_id = ?",
+			"DELETE FROM org_session_policy WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -3,7 +3,6 @@ package sqlstore
 import (
 	"time"
 
-	"github.com/grafana/grafana/pkg/components/securejsondata"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -46,8 +45,6 @@ func GetPluginSettingById(query *models.GetPluginSettingByIdQuery) error {
 }
 
 func UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) error {
-	encryptedJsonData := securejsondata.GetEncryptedJsonData(cmd.SecureJsonData)
-
 	return inTransaction(func(sess *DBSession) error {
 		var pluginSetting models.PluginSetting
 
@@ -65,7 +62,7 @@ func UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) error {
 				Pinned:         cmd.Pinned,
 				JsonData:       cmd.JsonData,
 				PluginVersion:  cmd.PluginVersion,
-				SecureJsonData: encryptedJsonData,
+				SecureJsonData: cmd.EncryptedSecureJsonData,
 				Created:        time.Now(),
 				Updated:        time.Now(),
 			}
@@ -81,7 +78,7 @@ func UpdatePluginSetting(cmd *models.UpdatePluginSettingCmd) error {
 			return err
 		}
 
-		for key, encryptedData := range encryptedJsonData {
+		for key, encryptedData := range cmd.EncryptedSecureJsonData {
 			pluginSetting.SecureJsonData[key] = encryptedData
 		}
 
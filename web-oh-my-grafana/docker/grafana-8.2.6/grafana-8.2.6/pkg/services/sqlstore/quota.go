@@ -22,6 +22,10 @@ func init() {
 	bus.AddHandler("sql", GetUserQuotas)
 	bus.AddHandler("sql", UpdateUserQuota)
 	bus.AddHandler("sql", GetGlobalQuotaByTarget)
+	bus.AddHandler("sql", GetOrgQuotaLimit)
+	bus.AddHandler("sql", GetFolderQuotaByTarget)
+	bus.AddHandler("sql", GetFolderQuotas)
+	bus.AddHandler("sql", UpdateFolderQuota)
 }
 
 type targetCount struct {
@@ -33,7 +37,7 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 		Target: query.Target,
 		OrgId:  query.OrgId,
 	}
-	has, err := x.Get(&quota)
+	has, err := x.Where("folder_id=0").Get(&quota)
 	if err != nil {
 		return err
 	} else if !has {
@@ -70,16 +74,24 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 	quotas := make([]*models.Quota, 0)
 	sess := x.Table("quota")
-	if err := sess.Where("org_id=? AND user_id=0", query.OrgId).Find(&quotas); err != nil {
+	if err := sess.Where("org_id=? AND user_id=0 AND folder_id=0", query.OrgId).Find(&quotas); err != nil {
 		return err
 	}
 
 	defaultQuotas := setting.Quota.Org.ToMap()
 
+	// quotas counted within a time window are stored in the same table,
+	// but their usage isn't the number of rows of a table.
+	countedQuotas := make([]*models.Quota, 0, len(quotas))
 	seenTargets := make(map[string]bool)
 	for _, q := range quotas {
+		if _, ok := defaultQuotas[q.Target]; !ok {
+			continue
+		}
+		countedQuotas = append(countedQuotas, q)
 		seenTargets[q.Target] = true
 	}
+	quotas = countedQuotas
 
 	for t, v := range defaultQuotas {
 		if _, ok := seenTargets[t]; !ok {
@@ -121,7 +133,7 @@ func UpdateOrgQuota(cmd *models.UpdateOrgQuotaCmd) error {
 			Target: cmd.Target,
 			OrgId:  cmd.OrgId,
 		}
-		has, err := sess.Get(&quota)
+		has, err := sess.Where("folder_id=0").Get(&quota)
 		if err != nil {
 			return err
 		}
@@ -282,3 +294,142 @@ func GetGlobalQuotaByTarget(query *models.GetGlobalQuotaByTargetQuery) error {
 
 	return nil
 }
+
+func GetOrgQuotaLimit(query *models.GetOrgQuotaLimitQuery) error {
+	quota := models.Quota{
+		Target: query.Target,
+		OrgId:  query.OrgId,
+	}
+	has, err := x.Where("user_id=0 AND folder_id=0").Get(&quota)
+	if err != nil {
+		return err
+	}
+
+	if !has {
+		query.Result = query.Default
+		return nil
+	}
+
+	query.Result = quota.Limit
+	return nil
+}
+
+func GetFolderQuotaByTarget(query *models.GetFolderQuotaByTargetQuery) error {
+	if query.Target != dashboardTarget {
+		return models.ErrInvalidQuotaTarget
+	}
+
+	quota := models.Quota{
+		Target:   query.Target,
+		OrgId:    query.OrgId,
+		FolderId: query.FolderId,
+	}
+	has, err := x.Get(&quota)
+	if err != nil {
+		return err
+	} else if !has {
+		quota.Limit = query.Default
+	}
+
+	rawSQL := fmt.Sprintf("SELECT COUNT(*) AS count FROM dashboard WHERE org_id=? AND folder_id=? AND is_folder=%s",
+		dialect.BooleanStr(false))
+	resp := make([]*targetCount, 0)
+	if err := x.SQL(rawSQL, query.OrgId, query.FolderId).Find(&resp); err != nil {
+		return err
+	}
+
+	query.Result = &models.FolderQuotaDTO{
+		OrgId:    query.OrgId,
+		FolderId: query.FolderId,
+		Target:   query.Target,
+		Limit:    quota.Limit,
+		Used:     resp[0].Count,
+	}
+
+	return nil
+}
+
+func GetFolderQuotas(query *models.GetFolderQuotasQuery) error {
+	if query.Target != dashboardTarget {
+		return models.ErrInvalidQuotaTarget
+	}
+
+	folders := make([]*models.Dashboard, 0)
+	if err := x.Where(fmt.Sprintf("org_id=? AND is_folder=%s", dialect.BooleanStr(true)), query.OrgId).Cols("id", "uid").Find(&folders); err != nil {
+		return err
+	}
+
+	quotas := make([]*models.Quota, 0)
+	if err := x.Where("org_id=? AND user_id=0 AND folder_id>0 AND target=?", query.OrgId, query.Target).Find(&quotas); err != nil {
+		return err
+	}
+	limits := make(map[int64]int64, len(quotas))
+	for _, q := range quotas {
+		limits[q.FolderId] = q.Limit
+	}
+
+	counts := make([]*struct {
+		FolderId int64
+		Count    int64
+	}, 0)
+	rawSQL := fmt.Sprintf("SELECT folder_id, COUNT(*) AS count FROM dashboard WHERE org_id=? AND is_folder=%s AND folder_id>0 GROUP BY folder_id",
+		dialect.BooleanStr(false))
+	if err := x.SQL(rawSQL, query.OrgId).Find(&counts); err != nil {
+		return err
+	}
+	used := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		used[c.FolderId] = c.Count
+	}
+
+	result := make([]*models.FolderQuotaDTO, 0, len(folders))
+	for _, f := range folders {
+		limit, ok := limits[f.Id]
+		if !ok {
+			limit = query.Default
+		}
+		result = append(result, &models.FolderQuotaDTO{
+			OrgId:     query.OrgId,
+			FolderId:  f.Id,
+			FolderUid: f.Uid,
+			Target:    query.Target,
+			Limit:     limit,
+			Used:      used[f.Id],
+		})
+	}
+
+	query.Result = result
+	return nil
+}
+
+func UpdateFolderQuota(cmd *models.UpdateFolderQuotaCmd) error {
+	return inTransaction(func(sess *DBSession) error {
+		// Check if quota is already defined in the DB
+		quota := models.Quota{
+			Target:   cmd.Target,
+			OrgId:    cmd.OrgId,
+			FolderId: cmd.FolderId,
+		}
+		has, err := sess.Get(&quota)
+		if err != nil {
+			return err
+		}
+		quota.Updated = time.Now()
+		quota.Limit = cmd.Limit
+		if !has {
+			quota.Created = time.Now()
+			// No quota in the DB for this target, so create a new one.
+			if _, err := sess.Insert(&quota); err != nil {
+				return err
+			}
+		} else {
+			// update existing quota entry in the DB.
+			_, err := sess.ID(quota.Id).Update(&quota)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
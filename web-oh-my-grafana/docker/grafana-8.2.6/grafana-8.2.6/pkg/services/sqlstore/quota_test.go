@@ -13,7 +13,7 @@ import (
 )
 
 func TestQuotaCommandsAndQueries(t *testing.T) {
-	InitTestDB(t)
+	sqlStore := InitTestDB(t)
 	userId := int64(1)
 	orgId := int64(0)
 
@@ -29,6 +29,13 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 		User: &setting.UserQuota{
 			Org: 5,
 		},
+		Folder: &setting.FolderQuota{
+			Dashboard: 3,
+		},
+		OrgRate: &setting.OrgRateQuota{
+			DataSourceQuery: 100,
+			ApiRequest:      -1,
+		},
 		Global: &setting.GlobalQuota{
 			Org:        5,
 			User:       5,
@@ -139,6 +146,34 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 		})
 	})
 
+	t.Run("Given saved org rate quota", func(t *testing.T) {
+		orgCmd := models.UpdateOrgQuotaCmd{
+			OrgId:  orgId,
+			Target: "data_source_query",
+			Limit:  50,
+		}
+		err := UpdateOrgQuota(&orgCmd)
+		require.NoError(t, err)
+
+		t.Run("Should be able to get the limit without usage", func(t *testing.T) {
+			query := models.GetOrgQuotaLimitQuery{OrgId: orgId, Target: "data_source_query", Default: 100}
+			err = GetOrgQuotaLimit(&query)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(50), query.Result)
+		})
+
+		t.Run("Should not be listed as a counted org quota", func(t *testing.T) {
+			query := models.GetOrgQuotasQuery{OrgId: orgId}
+			err = GetOrgQuotas(&query)
+
+			require.NoError(t, err)
+			for _, res := range query.Result {
+				require.NotEqual(t, "data_source_query", res.Target)
+			}
+		})
+	})
+
 	t.Run("Given saved user quota for org", func(t *testing.T) {
 		userQuotaCmd := models.UpdateUserQuotaCmd{
 			UserId: userId,
@@ -290,4 +325,63 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, int64(10), query.Result.Limit)
 	})
+
+	t.Run("Given saved folder quota for dashboards", func(t *testing.T) {
+		folder := insertTestDashboard(t, sqlStore, "quota folder", orgId, 0, true)
+		otherFolder := insertTestDashboard(t, sqlStore, "other quota folder", orgId, 0, true)
+		insertTestDashboard(t, sqlStore, "dash in quota folder", orgId, folder.Id, false)
+
+		folderCmd := models.UpdateFolderQuotaCmd{
+			OrgId:    orgId,
+			FolderId: folder.Id,
+			Target:   dashboardTarget,
+			Limit:    1,
+		}
+		err := UpdateFolderQuota(&folderCmd)
+		require.NoError(t, err)
+
+		t.Run("Should be able to get saved quota by folder id and target", func(t *testing.T) {
+			query := models.GetFolderQuotaByTargetQuery{OrgId: orgId, FolderId: folder.Id, Target: dashboardTarget, Default: 3}
+			err = GetFolderQuotaByTarget(&query)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(1), query.Result.Limit)
+			require.Equal(t, int64(1), query.Result.Used)
+		})
+
+		t.Run("Should be able to get default quota by folder id and target", func(t *testing.T) {
+			query := models.GetFolderQuotaByTargetQuery{OrgId: orgId, FolderId: otherFolder.Id, Target: dashboardTarget, Default: 3}
+			err = GetFolderQuotaByTarget(&query)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(3), query.Result.Limit)
+			require.Equal(t, int64(0), query.Result.Used)
+		})
+
+		t.Run("Should not affect the org quota for dashboards", func(t *testing.T) {
+			query := models.GetOrgQuotaByTargetQuery{OrgId: orgId, Target: dashboardTarget, Default: 1}
+			err = GetOrgQuotaByTarget(&query)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(10), query.Result.Limit)
+			require.Equal(t, int64(1), query.Result.Used)
+		})
+
+		t.Run("Should be able to quota list for folders", func(t *testing.T) {
+			query := models.GetFolderQuotasQuery{OrgId: orgId, Target: dashboardTarget, Default: 3}
+			err = GetFolderQuotas(&query)
+
+			require.NoError(t, err)
+			require.Len(t, query.Result, 2)
+			for _, res := range query.Result {
+				if res.FolderId == folder.Id {
+					require.Equal(t, int64(1), res.Limit)
+					require.Equal(t, int64(1), res.Used)
+				} else {
+					require.Equal(t, int64(3), res.Limit)
+					require.Equal(t, int64(0), res.Used)
+				}
+			}
+		})
+	})
 }
@@ -0,0 +1,107 @@
+package sqlstore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The secret key check is a known value encrypted with the secret key and
+// stored in the kv_store table. Decrypting the secrets with a wrong key
+// doesn't fail, so the check is what tells whether a key is the one the
+// secrets of the database are encrypted with.
+const (
+	secretKeyCheckNamespace = "secrets"
+	secretKeyCheckKey       = "secret_key_check"
+	secretKeyCheckValue     = "grafana-secret-key-check"
+)
+
+// ErrSecretKeyMismatch is returned when a key isn't the one the secret key
+// check has been encrypted with.
+var ErrSecretKeyMismatch = errors.New("the secret key doesn't match the key the secrets are encrypted with")
+
+type secretKeyCheck struct {
+	Id        int64
+	OrgId     int64
+	Namespace string
+	Key       string
+	Value     string
+	Created   time.Time
+	Updated   time.Time
+}
+
+func (c *secretKeyCheck) TableName() string {
+	return "kv_store"
+}
+
+// ensureSecretKeyCheck stores the secret key check if the database doesn't
+// have one yet, and warns if the configured secret key doesn't match it.
+func (ss *SQLStore) ensureSecretKeyCheck() error {
+	if setting.SecretKey == "" {
+		return nil
+	}
+
+	return ss.WithTransactionalDbSession(context.Background(), func(sess *DBSession) error {
+		found, err := VerifySecretKey(sess, setting.SecretKey)
+		if errors.Is(err, ErrSecretKeyMismatch) {
+			ss.log.Warn("The configured secret_key doesn't match the key the secrets in the database are encrypted with")
+			return nil
+		}
+		if err != nil || found {
+			return err
+		}
+
+		return StoreSecretKeyCheck(sess, setting.SecretKey)
+	})
+}
+
+// VerifySecretKey decrypts the secret key check with key. It returns false
+// if the database has no secret key check, and ErrSecretKeyMismatch if key
+// isn't the one it has been encrypted with.
+func VerifySecretKey(sess *DBSession, key string) (bool, error) {
+	check := secretKeyCheck{}
+	found, err := sess.Where("org_id = 0 AND namespace = ? AND "+dialect.Quote("key")+" = ?",
+		secretKeyCheckNamespace, secretKeyCheckKey).Get(&check)
+	if err != nil || !found {
+		return false, err
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(check.Value)
+	if err != nil {
+		return true, err
+	}
+	decrypted, err := util.Decrypt(encrypted, key)
+	if err != nil || string(decrypted) != secretKeyCheckValue {
+		return true, ErrSecretKeyMismatch
+	}
+	return true, nil
+}
+
+// StoreSecretKeyCheck encrypts the secret key check with key, replacing the
+// existing one.
+func StoreSecretKeyCheck(sess *DBSession, key string) error {
+	encrypted, err := util.Encrypt([]byte(secretKeyCheckValue), key)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	check := secretKeyCheck{
+		Namespace: secretKeyCheckNamespace,
+		Key:       secretKeyCheckKey,
+		Value:     base64.StdEncoding.EncodeToString(encrypted),
+		Created:   now,
+		Updated:   now,
+	}
+
+	if _, err := sess.Exec("DELETE FROM kv_store WHERE org_id = 0 AND namespace = ? AND "+dialect.Quote("key")+" = ?",
+		secretKeyCheckNamespace, secretKeyCheckKey); err != nil {
+		return err
+	}
+	_, err = sess.Insert(&check)
+	return err
+}
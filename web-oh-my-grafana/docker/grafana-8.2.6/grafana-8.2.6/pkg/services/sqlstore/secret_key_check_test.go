@@ -0,0 +1,34 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretKeyCheck(t *testing.T) {
+	sqlStore := InitTestDB(t)
+
+	err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+		found, err := VerifySecretKey(sess, "key")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		require.NoError(t, StoreSecretKeyCheck(sess, "key"))
+		found, err = VerifySecretKey(sess, "key")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		_, err = VerifySecretKey(sess, "other-key")
+		require.ErrorIs(t, err, ErrSecretKeyMismatch)
+
+		require.NoError(t, StoreSecretKeyCheck(sess, "other-key"))
+		_, err = VerifySecretKey(sess, "other-key")
+		require.NoError(t, err)
+		_, err = VerifySecretKey(sess, "key")
+		require.ErrorIs(t, err, ErrSecretKeyMismatch)
+		return nil
+	})
+	require.NoError(t, err)
+}
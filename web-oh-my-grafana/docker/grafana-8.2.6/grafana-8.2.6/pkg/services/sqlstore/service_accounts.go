@@ -0,0 +1,273 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func init() {
+	bus.AddHandlerCtx("sql", GetServiceAccountPermissions)
+}
+
+const serviceAccountLoginPrefix = "sa-"
+
+// CreateServiceAccount creates a user which can't log in, member of a
+// single organization, to which tokens can be added.
+func (ss *SQLStore) CreateServiceAccount(ctx context.Context, cmd *models.CreateServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		login := serviceAccountLoginPrefix + models.SlugifyTitle(cmd.Name)
+		exists, err := sess.Where("email=? OR login=?", login, login).Get(&models.User{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return models.ErrServiceAccountAlreadyExists
+		}
+
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+		rands, err := util.GetRandomString(10)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user := &models.User{
+			Email:            login,
+			Name:             cmd.Name,
+			Login:            login,
+			Salt:             salt,
+			Rands:            rands,
+			OrgId:            cmd.OrgId,
+			IsServiceAccount: true,
+			Created:          now,
+			Updated:          now,
+			LastSeenAt:       now.AddDate(-10, 0, 0),
+		}
+		sess.UseBool("is_admin", "is_service_account")
+		if _, err := sess.Insert(user); err != nil {
+			return err
+		}
+
+		orgUser := &models.OrgUser{
+			OrgId:   cmd.OrgId,
+			UserId:  user.Id,
+			Role:    cmd.Role,
+			Created: now,
+			Updated: now,
+		}
+		if _, err := sess.Insert(orgUser); err != nil {
+			return err
+		}
+
+		cmd.Result = &models.ServiceAccountDTO{
+			Id:    user.Id,
+			OrgId: cmd.OrgId,
+			Name:  user.Name,
+			Login: user.Login,
+			Role:  cmd.Role,
+		}
+		return nil
+	})
+}
+
+const serviceAccountsSQL = `SELECT
+	u.id          as id,
+	org_user.org_id as org_id,
+	u.name        as name,
+	u.login       as login,
+	org_user.role as role,
+	u.is_disabled as is_disabled,
+	(SELECT COUNT(*) FROM api_key WHERE api_key.service_account_id = u.id) as tokens
+	FROM org_user
+	INNER JOIN %s as u ON u.id = org_user.user_id
+	WHERE org_user.org_id = ? AND u.is_service_account = ?`
+
+func serviceAccountsQuery() string {
+	return fmt.Sprintf(serviceAccountsSQL, dialect.Quote("user"))
+}
+
+func (ss *SQLStore) GetServiceAccounts(ctx context.Context, orgID int64) ([]*models.ServiceAccountDTO, error) {
+	result := make([]*models.ServiceAccountDTO, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		return sess.SQL(serviceAccountsQuery()+" ORDER BY u.name", orgID, dialect.BooleanStr(true)).Find(&result)
+	})
+
+	return result, err
+}
+
+func (ss *SQLStore) GetServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*models.ServiceAccountDTO, error) {
+	result := &models.ServiceAccountDTO{}
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		has, err := sess.SQL(serviceAccountsQuery()+" AND u.id = ?", orgID, dialect.BooleanStr(true), serviceAccountID).Get(result)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return models.ErrServiceAccountNotFound
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+func (ss *SQLStore) UpdateServiceAccount(ctx context.Context, cmd *models.UpdateServiceAccountCommand) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := getServiceAccount(sess, cmd.OrgId, cmd.Id); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user := &models.User{Updated: now}
+		cols := []string{"updated"}
+		if cmd.Name != "" {
+			user.Name = cmd.Name
+			cols = append(cols, "name")
+		}
+		if cmd.IsDisabled != nil {
+			user.IsDisabled = *cmd.IsDisabled
+			cols = append(cols, "is_disabled")
+		}
+		if _, err := sess.ID(cmd.Id).Cols(cols...).UseBool("is_disabled").Update(user); err != nil {
+			return err
+		}
+
+		if cmd.Role != "" {
+			if _, err := sess.Exec("UPDATE org_user SET role = ?, updated = ? WHERE org_id = ? AND user_id = ?",
+				cmd.Role, now, cmd.OrgId, cmd.Id); err != nil {
+				return err
+			}
+			// the role of the tokens is kept in sync for the API keys listing
+			if _, err := sess.Exec("UPDATE api_key SET role = ? WHERE org_id = ? AND service_account_id = ?",
+				cmd.Role, cmd.OrgId, cmd.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteServiceAccount deletes the service account along with its tokens
+// and permissions.
+func (ss *SQLStore) DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := getServiceAccount(sess, orgID, serviceAccountID); err != nil {
+			return err
+		}
+
+		deletes := []string{
+			"DELETE FROM api_key WHERE service_account_id = ?",
+			"DELETE FROM service_account_permission WHERE service_account_id = ?",
+			"DELETE FROM org_user WHERE user_id = ?",
+			"DELETE FROM " + dialect.Quote("user") + " WHERE id = ?",
+		}
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, serviceAccountID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (ss *SQLStore) GetServiceAccountTokens(ctx context.Context, orgID, serviceAccountID int64) ([]*models.ApiKey, error) {
+	result := make([]*models.ApiKey, 0)
+	err := ss.WithDbSession(ctx, func(sess *DBSession) error {
+		if err := getServiceAccount(sess, orgID, serviceAccountID); err != nil {
+			return err
+		}
+
+		return sess.Where("org_id = ? AND service_account_id = ?", orgID, serviceAccountID).Asc("name").Find(&result)
+	})
+
+	return result, err
+}
+
+func (ss *SQLStore) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
+	return ss.WithDbSession(ctx, func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM api_key WHERE id = ? AND org_id = ? AND service_account_id = ?",
+			tokenID, orgID, serviceAccountID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return models.ErrServiceAccountTokenNotFound
+		}
+		return nil
+	})
+}
+
+// SetServiceAccountPermissions replaces the permissions of the service
+// account. Without permissions, the service account has the permissions
+// of its organization role. Only actions granted to organization roles
+// can be granted to a service account.
+func (ss *SQLStore) SetServiceAccountPermissions(ctx context.Context, orgID, serviceAccountID int64, permissions []*models.ServiceAccountPermission) error {
+	for _, p := range permissions {
+		if !accesscontrol.IsOrgAction(p.Action) {
+			return models.ErrServiceAccountPermissionNotOrgScoped
+		}
+	}
+
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		if err := getServiceAccount(sess, orgID, serviceAccountID); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM service_account_permission WHERE org_id = ? AND service_account_id = ?",
+			orgID, serviceAccountID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, p := range permissions {
+			p.Id = 0
+			p.OrgId = orgID
+			p.ServiceAccountId = serviceAccountID
+			p.Created = now
+			if _, err := sess.Insert(p); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetServiceAccountPermissions(ctx context.Context, query *models.GetServiceAccountPermissionsQuery) error {
+	return withDbSession(ctx, x, func(sess *DBSession) error {
+		query.Result = make([]*models.ServiceAccountPermission, 0)
+		return sess.Where("org_id = ? AND service_account_id = ?", query.OrgId, query.ServiceAccountId).
+			Asc("action", "scope").
+			Find(&query.Result)
+	})
+}
+
+func getServiceAccount(sess *DBSession, orgID, serviceAccountID int64) error {
+	user := dialect.Quote("user")
+	count, err := sess.Table("org_user").
+		Join("INNER", user, fmt.Sprintf("org_user.user_id=%s.id", user)).
+		Where(fmt.Sprintf("org_user.org_id = ? AND %s.id = ? AND %s.is_service_account = ?", user, user),
+			orgID, serviceAccountID, dialect.BooleanStr(true)).
+		Count()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return models.ErrServiceAccountNotFound
+	}
+
+	return nil
+}
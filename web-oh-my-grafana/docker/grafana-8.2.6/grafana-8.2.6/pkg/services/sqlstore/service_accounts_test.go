@@ -0,0 +1,120 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccountsDataAccess(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "admin", Email: "admin@localhost"})
+	require.NoError(t, err)
+
+	cmd := models.CreateServiceAccountCommand{OrgId: user.OrgId, Name: "CI Pipeline", Role: models.ROLE_EDITOR}
+	require.NoError(t, ss.CreateServiceAccount(ctx, &cmd))
+	sa := cmd.Result
+	require.Equal(t, "sa-ci-pipeline", sa.Login)
+
+	t.Run("Should not create a service account with the same name", func(t *testing.T) {
+		cmd := models.CreateServiceAccountCommand{OrgId: user.OrgId, Name: "CI Pipeline", Role: models.ROLE_VIEWER}
+		require.Equal(t, models.ErrServiceAccountAlreadyExists, ss.CreateServiceAccount(ctx, &cmd))
+	})
+
+	t.Run("Should list service accounts but not users", func(t *testing.T) {
+		accounts, err := ss.GetServiceAccounts(ctx, user.OrgId)
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		require.Equal(t, sa.Id, accounts[0].Id)
+		require.Equal(t, models.ROLE_EDITOR, accounts[0].Role)
+	})
+
+	t.Run("Should not list service accounts as users", func(t *testing.T) {
+		query := models.SearchUsersQuery{}
+		require.NoError(t, SearchUsers(&query))
+		require.Len(t, query.Result.Users, 1)
+		require.EqualValues(t, 1, query.Result.TotalCount)
+
+		orgQuery := models.GetOrgUsersQuery{OrgId: user.OrgId}
+		require.NoError(t, GetOrgUsers(&orgQuery))
+		require.Len(t, orgQuery.Result, 1)
+		require.Equal(t, "admin", orgQuery.Result[0].Login)
+	})
+
+	t.Run("Should add tokens to the service account", func(t *testing.T) {
+		keyCmd := models.AddApiKeyCommand{OrgId: user.OrgId, Name: "ci-token", Key: "key", Role: sa.Role, ServiceAccountId: &sa.Id}
+		require.NoError(t, AddApiKey(&keyCmd))
+
+		tokens, err := ss.GetServiceAccountTokens(ctx, user.OrgId, sa.Id)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+
+		sa, err := ss.GetServiceAccount(ctx, user.OrgId, sa.Id)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, sa.Tokens)
+	})
+
+	t.Run("Should update the role of the service account and its tokens", func(t *testing.T) {
+		disabled := true
+		err := ss.UpdateServiceAccount(ctx, &models.UpdateServiceAccountCommand{
+			Id: sa.Id, OrgId: user.OrgId, Role: models.ROLE_VIEWER, IsDisabled: &disabled,
+		})
+		require.NoError(t, err)
+
+		updated, err := ss.GetServiceAccount(ctx, user.OrgId, sa.Id)
+		require.NoError(t, err)
+		require.Equal(t, models.ROLE_VIEWER, updated.Role)
+		require.True(t, updated.IsDisabled)
+
+		tokens, err := ss.GetServiceAccountTokens(ctx, user.OrgId, sa.Id)
+		require.NoError(t, err)
+		require.Equal(t, models.ROLE_VIEWER, tokens[0].Role)
+	})
+
+	t.Run("Should replace the permissions of the service account", func(t *testing.T) {
+		err := ss.SetServiceAccountPermissions(ctx, user.OrgId, sa.Id, []*models.ServiceAccountPermission{
+			{Action: "org.users:read", Scope: "users:*"},
+		})
+		require.NoError(t, err)
+
+		query := models.GetServiceAccountPermissionsQuery{OrgId: user.OrgId, ServiceAccountId: sa.Id}
+		require.NoError(t, GetServiceAccountPermissions(ctx, &query))
+		require.Len(t, query.Result, 1)
+		require.Equal(t, "org.users:read", query.Result[0].Action)
+	})
+
+	t.Run("Should not grant permissions outside of the organization", func(t *testing.T) {
+		err := ss.SetServiceAccountPermissions(ctx, user.OrgId, sa.Id, []*models.ServiceAccountPermission{
+			{Action: "users:write", Scope: "global:users:*"},
+		})
+		require.Equal(t, models.ErrServiceAccountPermissionNotOrgScoped, err)
+
+		query := models.GetServiceAccountPermissionsQuery{OrgId: user.OrgId, ServiceAccountId: sa.Id}
+		require.NoError(t, GetServiceAccountPermissions(ctx, &query))
+		require.Len(t, query.Result, 1)
+	})
+
+	t.Run("Should not manage users as service accounts", func(t *testing.T) {
+		_, err := ss.GetServiceAccount(ctx, user.OrgId, user.Id)
+		require.Equal(t, models.ErrServiceAccountNotFound, err)
+		require.Equal(t, models.ErrServiceAccountNotFound, ss.DeleteServiceAccount(ctx, user.OrgId, user.Id))
+	})
+
+	t.Run("Should delete the service account with its tokens", func(t *testing.T) {
+		require.NoError(t, ss.DeleteServiceAccount(ctx, user.OrgId, sa.Id))
+
+		_, err := ss.GetServiceAccount(ctx, user.OrgId, sa.Id)
+		require.Equal(t, models.ErrServiceAccountNotFound, err)
+
+		query := models.GetApiKeysQuery{OrgId: user.OrgId}
+		require.NoError(t, GetApiKeys(&query))
+		require.Len(t, query.Result, 0)
+	})
+}
@@ -148,8 +148,13 @@ func (ss *SQLStore) Sync() error {
 }
 
 // Reset resets database state.
-// If default org and user creation is enabled, it will be ensured they exist in the database.
+// It ensures the secret key check exists, and if default org and user creation is enabled,
+// that they exist in the database.
 func (ss *SQLStore) Reset() error {
+	if err := ss.ensureSecretKeyCheck(); err != nil {
+		return err
+	}
+
 	if ss.skipEnsureDefaultOrgAndUser {
 		return nil
 	}
@@ -125,7 +125,7 @@ func UpdateTeam(cmd *models.UpdateTeamCommand) error {
 	})
 }
 
-// DeleteTeam will delete a team, its member and any permissions connected to the team
+// DeleteTeam will delete a team, its member, its groups and any permissions connected to the team
 func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 	return inTransaction(func(sess *DBSession) error {
 		if _, err := teamExists(cmd.OrgId, cmd.Id, sess); err != nil {
@@ -136,6 +136,7 @@ func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM team_group WHERE org_id=? and team_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -0,0 +1,87 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", AddTeamGroup)
+	bus.AddHandler("sql", RemoveTeamGroup)
+	bus.AddHandler("sql", GetTeamGroups)
+	bus.AddHandler("sql", GetUserTeamGroups)
+}
+
+// AddTeamGroup maps an external group to a team
+func AddTeamGroup(cmd *models.AddTeamGroupCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := teamExists(cmd.OrgId, cmd.TeamId, sess); err != nil {
+			return err
+		}
+
+		if res, err := sess.Query("SELECT 1 from team_group WHERE org_id=? and team_id=? and group_id=?",
+			cmd.OrgId, cmd.TeamId, cmd.GroupId); err != nil {
+			return err
+		} else if len(res) == 1 {
+			return models.ErrTeamGroupAlreadyAdded
+		}
+
+		entity := models.TeamGroup{
+			OrgId:   cmd.OrgId,
+			TeamId:  cmd.TeamId,
+			GroupId: cmd.GroupId,
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+
+		_, err := sess.Insert(&entity)
+		return err
+	})
+}
+
+// RemoveTeamGroup removes the mapping of an external group to a team
+func RemoveTeamGroup(cmd *models.RemoveTeamGroupCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := teamExists(cmd.OrgId, cmd.TeamId, sess); err != nil {
+			return err
+		}
+
+		var rawSQL = "DELETE FROM team_group WHERE org_id=? and team_id=? and group_id=?"
+		res, err := sess.Exec(rawSQL, cmd.OrgId, cmd.TeamId, cmd.GroupId)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if rows == 0 {
+			return models.ErrTeamGroupNotFound
+		}
+
+		return err
+	})
+}
+
+// GetTeamGroups returns the external groups mapped to a team
+func GetTeamGroups(query *models.GetTeamGroupsQuery) error {
+	query.Result = make([]*models.TeamGroupDTO, 0)
+	sess := x.Table("team_group")
+	sess.Where("team_group.org_id=? AND team_group.team_id=?", query.OrgId, query.TeamId)
+	sess.Cols("team_group.org_id", "team_group.team_id", "team_group.group_id")
+	sess.Asc("team_group.group_id")
+
+	return sess.Find(&query.Result)
+}
+
+// GetUserTeamGroups returns the external groups mapped to the teams of the
+// organizations the user is a member of
+func GetUserTeamGroups(query *models.GetUserTeamGroupsQuery) error {
+	query.Result = make([]*models.TeamGroupDTO, 0)
+	sess := x.Table("team_group")
+	sess.Join("INNER", "org_user", "org_user.org_id=team_group.org_id")
+	sess.Where("org_user.user_id=?", query.UserId)
+	sess.Cols("team_group.org_id", "team_group.team_id", "team_group.group_id")
+	sess.Asc("team_group.team_id", "team_group.group_id")
+
+	return sess.Find(&query.Result)
+}
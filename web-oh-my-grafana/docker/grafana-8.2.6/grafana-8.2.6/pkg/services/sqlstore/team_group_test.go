@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamGroupCommandsAndQueries(t *testing.T) {
+	ss := InitTestDB(t)
+	ctx := context.Background()
+
+	user, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	team, err := ss.CreateTeam("SRE", "", user.OrgId)
+	require.NoError(t, err)
+	otherOrg, err := ss.CreateUser(ctx, models.CreateUserCommand{Login: "bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+	otherTeam, err := ss.CreateTeam("Sales", "", otherOrg.OrgId)
+	require.NoError(t, err)
+
+	for _, cmd := range []*models.AddTeamGroupCommand{
+		{OrgId: user.OrgId, TeamId: team.Id, GroupId: "cn=sre,ou=groups,dc=grafana,dc=org"},
+		{OrgId: user.OrgId, TeamId: team.Id, GroupId: "@grafana/sre"},
+		{OrgId: otherOrg.OrgId, TeamId: otherTeam.Id, GroupId: "sales"},
+	} {
+		require.NoError(t, AddTeamGroup(cmd))
+	}
+
+	t.Run("Should not add a group twice", func(t *testing.T) {
+		err := AddTeamGroup(&models.AddTeamGroupCommand{OrgId: user.OrgId, TeamId: team.Id, GroupId: "@grafana/sre"})
+		require.Equal(t, models.ErrTeamGroupAlreadyAdded, err)
+	})
+
+	t.Run("Should not add a group to a team of another org", func(t *testing.T) {
+		err := AddTeamGroup(&models.AddTeamGroupCommand{OrgId: user.OrgId, TeamId: otherTeam.Id, GroupId: "sales"})
+		require.Equal(t, models.ErrTeamNotFound, err)
+	})
+
+	t.Run("Should list the groups of a team", func(t *testing.T) {
+		query := models.GetTeamGroupsQuery{OrgId: user.OrgId, TeamId: team.Id}
+		require.NoError(t, GetTeamGroups(&query))
+		require.Equal(t, []*models.TeamGroupDTO{
+			{OrgId: user.OrgId, TeamId: team.Id, GroupId: "@grafana/sre"},
+			{OrgId: user.OrgId, TeamId: team.Id, GroupId: "cn=sre,ou=groups,dc=grafana,dc=org"},
+		}, query.Result)
+	})
+
+	t.Run("Should list the groups of the teams of the orgs of a user", func(t *testing.T) {
+		query := models.GetUserTeamGroupsQuery{UserId: otherOrg.Id}
+		require.NoError(t, GetUserTeamGroups(&query))
+		require.Equal(t, []*models.TeamGroupDTO{
+			{OrgId: otherOrg.OrgId, TeamId: otherTeam.Id, GroupId: "sales"},
+		}, query.Result)
+	})
+
+	t.Run("Should remove a group", func(t *testing.T) {
+		cmd := models.RemoveTeamGroupCommand{OrgId: user.OrgId, TeamId: team.Id, GroupId: "@grafana/sre"}
+		require.NoError(t, RemoveTeamGroup(&cmd))
+		require.Equal(t, models.ErrTeamGroupNotFound, RemoveTeamGroup(&cmd))
+	})
+
+	t.Run("Should remove the groups of a deleted team", func(t *testing.T) {
+		require.NoError(t, DeleteTeam(&models.DeleteTeamCommand{OrgId: user.OrgId, Id: team.Id}))
+
+		query := models.GetUserTeamGroupsQuery{UserId: user.Id}
+		require.NoError(t, GetUserTeamGroups(&query))
+		require.Empty(t, query.Result)
+	})
+}
@@ -149,6 +149,10 @@ func (ss *SQLStore) createUser(ctx context.Context, sess *DBSession, args userCr
 		return user, err
 	}
 
+	if err := addPasswordHistory(sess, user.Id, user.Password, user.Salt); err != nil {
+		return user, err
+	}
+
 	sess.publishAfterCommit(&events.UserCreated{
 		Timestamp: user.Created,
 		Id:        user.Id,
@@ -216,6 +220,8 @@ func (ss *SQLStore) CreateUser(ctx context.Context, cmd models.CreateUserCommand
 			Created:       time.Now(),
 			Updated:       time.Now(),
 			LastSeenAt:    time.Now().AddDate(-10, 0, 0),
+
+			PasswordChangeRequired: cmd.PasswordChangeRequired,
 		}
 
 		salt, err := util.GetRandomString(10)
@@ -243,6 +249,10 @@ func (ss *SQLStore) CreateUser(ctx context.Context, cmd models.CreateUserCommand
 			return err
 		}
 
+		if err := addPasswordHistory(sess, user.Id, user.Password, user.Salt); err != nil {
+			return err
+		}
+
 		sess.publishAfterCommit(&events.UserCreated{
 			Timestamp: user.Created,
 			Id:        user.Id,
@@ -376,13 +386,23 @@ func UpdateUser(cmd *models.UpdateUserCommand) error {
 
 func ChangeUserPassword(cmd *models.ChangeUserPasswordCommand) error {
 	return inTransaction(func(sess *DBSession) error {
-		user := models.User{
-			Password: cmd.NewPassword,
-			Updated:  time.Now(),
+		user := models.User{}
+		has, err := sess.ID(cmd.UserId).Cols("salt").Get(&user)
+		if err != nil {
+			return err
+		} else if !has {
+			return models.ErrUserNotFound
 		}
 
-		_, err := sess.ID(cmd.UserId).Update(&user)
-		return err
+		user.Password = cmd.NewPassword
+		user.Updated = time.Now()
+		user.PasswordChangeRequired = cmd.PasswordChangeRequired
+
+		if _, err := sess.ID(cmd.UserId).Cols("password", "updated", "password_change_required").Update(&user); err != nil {
+			return err
+		}
+
+		return addPasswordHistory(sess, cmd.UserId, user.Password, user.Salt)
 	})
 }
 
@@ -523,6 +543,7 @@ func GetSignedInUser(ctx context.Context, query *models.GetSignedInUserQuery) er
 		u.name           as name,
 		u.help_flags1    as help_flags1,
 		u.last_seen_at   as last_seen_at,
+		u.is_service_account as is_service_account,
 		(SELECT COUNT(*) FROM org_user where org_user.user_id = u.id) as org_count,
 		org.name         as org_name,
 		org_user.role    as org_role,
@@ -593,6 +614,10 @@ func SearchUsers(query *models.SearchUsersQuery) error {
 		whereParams = append(whereParams, query.OrgId)
 	}
 
+	// service accounts are listed by the service accounts API
+	whereConditions = append(whereConditions, "u.is_service_account = ?")
+	whereParams = append(whereParams, dialect.BooleanStr(false))
+
 	if query.Query != "" {
 		whereConditions = append(whereConditions, "(email "+dialect.LikeStr()+" ? OR name "+dialect.LikeStr()+" ? OR login "+dialect.LikeStr()+" ?)")
 		whereParams = append(whereParams, queryWithWildcards, queryWithWildcards, queryWithWildcards)
@@ -721,6 +746,7 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_password_history WHERE user_id = ?",
 	}
 
 	for _, sql := range deletes {
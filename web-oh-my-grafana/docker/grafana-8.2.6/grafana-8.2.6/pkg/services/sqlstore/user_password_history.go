@@ -0,0 +1,36 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// addPasswordHistory records the current password of a user and removes
+// the ones older than the last setting.MaxPasswordHistory passwords.
+func addPasswordHistory(sess *DBSession, userID int64, password string, salt string) error {
+	if password == "" {
+		return nil
+	}
+
+	entry := models.UserPasswordHistory{
+		UserId:   userID,
+		Password: password,
+		Salt:     salt,
+		Created:  time.Now(),
+	}
+	if _, err := sess.Insert(&entry); err != nil {
+		return err
+	}
+
+	var ids []int64
+	err := sess.Table("user_password_history").Where("user_id = ?", userID).
+		Desc("id").Limit(1, setting.MaxPasswordHistory).Cols("id").Find(&ids)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	_, err = sess.Where("user_id = ?", userID).And("id <= ?", ids[0]).Delete(&models.UserPasswordHistory{})
+	return err
+}
@@ -0,0 +1,37 @@
+package teamsync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/login"
+)
+
+// deprovisionLDAPUser disables a user who is no longer found in LDAP, or no
+// longer in any of the mapped groups, and signs them out of all their
+// sessions. It returns nil when the user is already disabled.
+func (s *TeamSyncService) deprovisionLDAPUser(ctx context.Context, user *ldapUser, reason string,
+	dryRun bool) (*LDAPSyncUserChange, error) {
+	if user.IsDisabled {
+		return nil, nil
+	}
+	// The Grafana admin would be locked out.
+	if user.Login == s.Cfg.AdminUser {
+		logger.Warn("Grafana admin can't be synced with LDAP, not disabling", "user", user.Login, "reason", reason)
+		return nil, nil
+	}
+
+	change := &LDAPSyncUserChange{UserID: user.Id, Login: user.Login, Action: LDAPSyncActionDisable, Reason: reason}
+	if dryRun {
+		return change, nil
+	}
+
+	logger.Info("Disabling LDAP user", "user", user.Login, "reason", reason)
+	if err := login.DisableExternalUser(user.Login); err != nil {
+		return nil, err
+	}
+	if err := s.AuthTokenService.RevokeAllUserTokens(ctx, user.Id); err != nil {
+		return nil, fmt.Errorf("failed to sign out user: %w", err)
+	}
+	return change, nil
+}
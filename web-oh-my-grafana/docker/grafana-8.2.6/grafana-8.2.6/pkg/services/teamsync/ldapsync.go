@@ -0,0 +1,287 @@
+package teamsync
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ldapSyncBatchSize limits the number of users searched in a single LDAP query.
+const ldapSyncBatchSize = 100
+
+// newLDAP creates the LDAP client.
+// Stubbable by tests.
+var newLDAP = multildap.New
+
+// getLDAPConfig reads the LDAP configuration.
+// Stubbable by tests.
+var getLDAPConfig = multildap.GetConfig
+
+type ldapUser struct {
+	Id         int64
+	Login      string
+	IsDisabled bool
+	IsAdmin    bool
+}
+
+// LDAP sync actions of the users in an LDAPSyncReport.
+const (
+	LDAPSyncActionDisable = "disable"
+	LDAPSyncActionEnable  = "enable"
+	LDAPSyncActionUpdate  = "update"
+)
+
+// LDAPSyncReport lists the users changed by an LDAP sync, or the users a dry
+// run would change.
+type LDAPSyncReport struct {
+	DryRun    bool                  `json:"dryRun"`
+	Started   time.Time             `json:"started"`
+	Duration  string                `json:"duration"`
+	Synced    int                   `json:"synced"`
+	Unchanged int                   `json:"unchanged"`
+	Users     []*LDAPSyncUserChange `json:"users"`
+
+	// Skipped is the number of users who weren't deprovisioned because some
+	// of the LDAP servers couldn't be searched.
+	Skipped      int      `json:"skipped"`
+	ServerErrors []string `json:"serverErrors,omitempty"`
+}
+
+// LDAPSyncUserChange is a user changed by an LDAP sync.
+type LDAPSyncUserChange struct {
+	UserID int64  `json:"userId"`
+	Login  string `json:"login"`
+	Action string `json:"action"`
+	Reason string `json:"reason,omitempty"`
+
+	OrgRoles []*LDAPSyncOrgRoleChange `json:"orgRoles,omitempty"`
+	// IsGrafanaAdmin is set when the Grafana admin permission of the user
+	// changes.
+	IsGrafanaAdmin *bool `json:"isGrafanaAdmin,omitempty"`
+}
+
+// LDAPSyncOrgRoleChange is an org role change of a user. The previous role is
+// empty when the user is added to the org, and the new role is empty when the
+// user is removed from it.
+type LDAPSyncOrgRoleChange struct {
+	OrgID    int64           `json:"orgId"`
+	Previous models.RoleType `json:"previous"`
+	Role     models.RoleType `json:"role"`
+}
+
+func (s *TeamSyncService) ldapSyncEnabled() bool {
+	return s.Cfg.LDAPEnabled && s.Cfg.LDAPActiveSyncEnabled
+}
+
+// IsDisabled disables the background LDAP sync when LDAP or its active sync
+// are not enabled.
+func (s *TeamSyncService) IsDisabled() bool {
+	return !s.ldapSyncEnabled()
+}
+
+// Run syncs the LDAP users on the sync_cron schedule. In HA setups, only one
+// of the Grafana instances runs each sync.
+func (s *TeamSyncService) Run(ctx context.Context) error {
+	for {
+		next := s.ldapSchedule.Next(time.Now())
+		// Other instances run the sync at the same time, the lock only needs
+		// to be held for a fraction of the interval between two syncs.
+		maxInterval := s.ldapSchedule.Next(next).Sub(next) / 2
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			err := s.ServerLockService.LockAndExecute(ctx, "ldap team sync", maxInterval, func() {
+				if _, err := s.SyncLDAPUsers(ctx, false); err != nil {
+					logger.Error("Failed to sync LDAP users", "error", err)
+				}
+			})
+			if err != nil {
+				logger.Error("Failed to lock and execute the LDAP sync", "error", err)
+			}
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// SyncLDAPUsers updates the users who logged in with LDAP, their org roles and
+// teams, from the LDAP servers. Users no longer found in LDAP, or no longer in
+// any of the mapped groups, are removed from the teams synced from their
+// groups, disabled and signed out, unless one of the LDAP servers can't be
+// searched. A dry run only reports the changes the sync would make.
+func (s *TeamSyncService) SyncLDAPUsers(ctx context.Context, dryRun bool) (*LDAPSyncReport, error) {
+	ldapConfig, err := getLDAPConfig(s.Cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []*ldapUser
+	err = s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		rawSQL := `SELECT u.id, u.login, u.is_disabled, u.is_admin FROM ` + s.SQLStore.Dialect.Quote("user") + ` AS u
+			INNER JOIN user_auth ON user_auth.user_id = u.id
+			WHERE user_auth.auth_module = ?`
+		return sess.SQL(rawSQL, models.AuthModuleLDAP).Find(&users)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Syncing LDAP users", "count", len(users), "dryRun", dryRun)
+	report := &LDAPSyncReport{DryRun: dryRun, Started: time.Now(), Users: []*LDAPSyncUserChange{}}
+	ldapClient := newLDAP(ldapConfig.Servers)
+	deprovision := true
+
+	for len(users) > 0 {
+		batch := users
+		if len(batch) > ldapSyncBatchSize {
+			batch = users[:ldapSyncBatchSize]
+		}
+		users = users[len(batch):]
+
+		logins := make([]string, len(batch))
+		for i, user := range batch {
+			logins[i] = user.Login
+		}
+		extUsers, serverErrors, err := ldapClient.UsersWithServerErrors(logins)
+		if err != nil {
+			return nil, err
+		}
+		// A user missing from the results might be a user of a server that
+		// couldn't be searched, so the sync stops deprovisioning users.
+		for _, serverError := range serverErrors {
+			deprovision = false
+			report.ServerErrors = append(report.ServerErrors,
+				fmt.Sprintf("%s:%d: %v", serverError.Host, serverError.Port, serverError.Error))
+		}
+
+		found := make(map[string]*models.ExternalUserInfo, len(extUsers))
+		for _, extUser := range extUsers {
+			found[strings.ToLower(extUser.Login)] = extUser
+		}
+
+		for _, user := range batch {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			extUser := found[strings.ToLower(user.Login)]
+			if !deprovision && (extUser == nil || extUser.IsDisabled) {
+				report.Skipped++
+				continue
+			}
+
+			change, err := s.syncLDAPUser(ctx, user, extUser, dryRun)
+			if err != nil {
+				logger.Error("Failed to sync LDAP user", "user", user.Login, "error", err)
+				continue
+			}
+
+			report.Synced++
+			if change == nil {
+				report.Unchanged++
+				continue
+			}
+			report.Users = append(report.Users, change)
+		}
+	}
+
+	report.Duration = time.Since(report.Started).String()
+	if !deprovision {
+		logger.Warn("Skipped deprovisioning LDAP users because some LDAP servers couldn't be searched",
+			"skipped", report.Skipped, "errors", report.ServerErrors)
+	}
+	logger.Info("Synced LDAP users", "duration", report.Duration, "changed", len(report.Users), "dryRun", dryRun)
+	return report, nil
+}
+
+// syncLDAPUser syncs a user with the user found in LDAP, which is nil when
+// the user wasn't found. It returns nil when the user is unchanged.
+func (s *TeamSyncService) syncLDAPUser(ctx context.Context, user *ldapUser, extUser *models.ExternalUserInfo,
+	dryRun bool) (*LDAPSyncUserChange, error) {
+	var reason string
+	switch {
+	case extUser == nil:
+		reason = "not found in LDAP"
+	case extUser.IsDisabled:
+		reason = "not in any of the mapped LDAP groups"
+	}
+	if reason != "" {
+		if !dryRun {
+			logger.Debug("Removing LDAP user from the synced teams", "user", user.Login, "reason", reason)
+			err := s.SyncTeams(&models.User{Id: user.Id, Login: user.Login},
+				&models.ExternalUserInfo{AuthModule: models.AuthModuleLDAP, UserId: user.Id, Login: user.Login})
+			if err != nil {
+				return nil, err
+			}
+		}
+		return s.deprovisionLDAPUser(ctx, user, reason, dryRun)
+	}
+
+	change, err := ldapUserChanges(ctx, user, extUser)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return change, nil
+	}
+
+	cmd := &models.UpsertUserCommand{
+		ExternalUser:  extUser,
+		SignupAllowed: false,
+	}
+	if err := bus.DispatchCtx(ctx, cmd); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// ldapUserChanges compares a user with the user found in LDAP the way the
+// login service syncs them.
+func ldapUserChanges(ctx context.Context, user *ldapUser, extUser *models.ExternalUserInfo) (*LDAPSyncUserChange, error) {
+	change := &LDAPSyncUserChange{UserID: user.Id, Login: user.Login, Action: LDAPSyncActionUpdate}
+	if user.IsDisabled {
+		change.Action = LDAPSyncActionEnable
+	}
+
+	// Org roles aren't synced when none are mapped.
+	if len(extUser.OrgRoles) > 0 {
+		query := &models.GetUserOrgListQuery{UserId: user.Id}
+		if err := bus.DispatchCtx(ctx, query); err != nil {
+			return nil, err
+		}
+
+		inOrg := make(map[int64]bool, len(query.Result))
+		for _, org := range query.Result {
+			inOrg[org.OrgId] = true
+			if role := extUser.OrgRoles[org.OrgId]; role != org.Role {
+				change.OrgRoles = append(change.OrgRoles, &LDAPSyncOrgRoleChange{OrgID: org.OrgId, Previous: org.Role, Role: role})
+			}
+		}
+		for orgID, role := range extUser.OrgRoles {
+			if !inOrg[orgID] {
+				change.OrgRoles = append(change.OrgRoles, &LDAPSyncOrgRoleChange{OrgID: orgID, Role: role})
+			}
+		}
+		sort.Slice(change.OrgRoles, func(i, j int) bool {
+			return change.OrgRoles[i].OrgID < change.OrgRoles[j].OrgID
+		})
+	}
+
+	if extUser.IsGrafanaAdmin != nil && *extUser.IsGrafanaAdmin != user.IsAdmin {
+		change.IsGrafanaAdmin = extUser.IsGrafanaAdmin
+	}
+
+	if change.Action == LDAPSyncActionUpdate && len(change.OrgRoles) == 0 && change.IsGrafanaAdmin == nil {
+		return nil, nil
+	}
+	return change, nil
+}
@@ -0,0 +1,119 @@
+package teamsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/robfig/cron/v3"
+)
+
+var logger = log.New("teamsync")
+
+// cronParser parses the sync_cron setting of LDAP, with or without seconds.
+var cronParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, loginService login.Service,
+	serverLockService *serverlock.ServerLockService, authTokenService models.UserTokenService) (*TeamSyncService, error) {
+	s := &TeamSyncService{
+		Cfg:               cfg,
+		SQLStore:          sqlStore,
+		ServerLockService: serverLockService,
+		AuthTokenService:  authTokenService,
+	}
+
+	if s.ldapSyncEnabled() {
+		schedule, err := cronParser.Parse(cfg.LDAPSyncCron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sync_cron of LDAP %q: %w", cfg.LDAPSyncCron, err)
+		}
+		s.ldapSchedule = schedule
+	}
+
+	// Team sync runs after the external user and its org roles are upserted.
+	loginService.SetTeamSyncFunc(s.SyncTeams)
+
+	return s, nil
+}
+
+// Service keeps the team memberships of users in sync with the groups of
+// their external auth provider.
+type Service interface {
+	SyncTeams(user *models.User, externalUser *models.ExternalUserInfo) error
+	// SyncLDAPUsers syncs the users who logged in with LDAP, or only reports
+	// the changes when dryRun is set.
+	SyncLDAPUsers(ctx context.Context, dryRun bool) (*LDAPSyncReport, error)
+}
+
+type TeamSyncService struct {
+	Cfg               *setting.Cfg
+	SQLStore          *sqlstore.SQLStore
+	ServerLockService *serverlock.ServerLockService
+	AuthTokenService  models.UserTokenService
+
+	ldapSchedule cron.Schedule
+}
+
+// SyncTeams adds the user to the teams mapped to their external groups, and
+// removes them from the teams with group mappings they are no longer in a
+// group of. Only memberships created by team sync are removed, members added
+// by hand are left alone. Teams without group mappings are not synced.
+func (s *TeamSyncService) SyncTeams(user *models.User, externalUser *models.ExternalUserInfo) error {
+	if externalUser.AuthModule == "" {
+		return nil
+	}
+
+	query := models.GetUserTeamGroupsQuery{UserId: user.Id}
+	if err := bus.Dispatch(&query); err != nil {
+		return err
+	}
+
+	type teamKey struct{ orgID, teamID int64 }
+	inTeam := map[teamKey]bool{}
+	for _, teamGroup := range query.Result {
+		key := teamKey{orgID: teamGroup.OrgId, teamID: teamGroup.TeamId}
+		inTeam[key] = inTeam[key] || isMemberOf(externalUser.Groups, teamGroup.GroupId)
+	}
+
+	for key, member := range inTeam {
+		membersQuery := models.GetTeamMembersQuery{OrgId: key.orgID, TeamId: key.teamID, UserId: user.Id}
+		if err := bus.Dispatch(&membersQuery); err != nil {
+			return err
+		}
+
+		switch {
+		case member && len(membersQuery.Result) == 0:
+			logger.Debug("Adding user to team", "user", user.Login, "teamId", key.teamID, "orgId", key.orgID)
+			if err := s.SQLStore.AddTeamMember(user.Id, key.orgID, key.teamID, true, 0); err != nil {
+				return err
+			}
+		case !member && len(membersQuery.Result) > 0 && membersQuery.Result[0].External:
+			logger.Debug("Removing user from team", "user", user.Login, "teamId", key.teamID, "orgId", key.orgID)
+			cmd := models.RemoveTeamMemberCommand{OrgId: key.orgID, TeamId: key.teamID, UserId: user.Id}
+			if err := bus.Dispatch(&cmd); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isMemberOf matches groups case-insensitively, as LDAP does for DNs.
+func isMemberOf(groups []string, groupID string) bool {
+	for _, group := range groups {
+		if strings.EqualFold(group, groupID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,358 @@
+package teamsync
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/multildap"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvideService(t *testing.T) {
+	t.Run("registers the team sync of the login service", func(t *testing.T) {
+		loginService := &fakeLoginService{}
+		s, err := ProvideService(setting.NewCfg(), nil, loginService, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, loginService.teamSync)
+		assert.True(t, s.IsDisabled())
+	})
+
+	t.Run("parses the LDAP sync schedule", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.LDAPEnabled = true
+		cfg.LDAPActiveSyncEnabled = true
+		cfg.LDAPSyncCron = "0 0 1 * * *"
+		s, err := ProvideService(cfg, nil, &fakeLoginService{}, nil, nil)
+		require.NoError(t, err)
+		assert.False(t, s.IsDisabled())
+
+		now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.Local)
+		assert.Equal(t, time.Date(2021, 10, 2, 1, 0, 0, 0, time.Local), s.ldapSchedule.Next(now))
+
+		cfg.LDAPSyncCron = "every day"
+		_, err = ProvideService(cfg, nil, &fakeLoginService{}, nil, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestSyncTeams(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	var revoked []int64
+	authTokenService := auth.NewFakeUserAuthTokenService()
+	authTokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		revoked = append(revoked, userId)
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AdminUser = "admin"
+	s := &TeamSyncService{Cfg: cfg, SQLStore: sqlStore, AuthTokenService: authTokenService}
+	ctx := context.Background()
+
+	user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	other, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "bob", Email: "bob@example.com"})
+	require.NoError(t, err)
+
+	sre := createTeam(t, sqlStore, "SRE", user.OrgId, "cn=sre,ou=groups,dc=grafana,dc=org", "@grafana/sre")
+	developers := createTeam(t, sqlStore, "Developers", user.OrgId, "developers")
+	admins := createTeam(t, sqlStore, "Admins", user.OrgId, "admins")
+	unmapped := createTeam(t, sqlStore, "Unmapped", user.OrgId)
+	otherOrg := createTeam(t, sqlStore, "Other org", other.OrgId, "developers")
+
+	// Alice was added to the Admins team by hand, and to the unmapped team by
+	// another sync.
+	require.NoError(t, sqlStore.AddTeamMember(user.Id, user.OrgId, admins.Id, false, 0))
+	require.NoError(t, sqlStore.AddTeamMember(user.Id, user.OrgId, unmapped.Id, true, 0))
+
+	teams := func() []string {
+		query := models.GetTeamsByUserQuery{OrgId: user.OrgId, UserId: user.Id}
+		require.NoError(t, sqlstore.GetTeamsByUser(&query))
+		names := make([]string, len(query.Result))
+		for i, team := range query.Result {
+			names[i] = team.Name
+		}
+		return names
+	}
+	sync := func(groups ...string) {
+		t.Helper()
+		extUser := &models.ExternalUserInfo{AuthModule: models.AuthModuleLDAP, Login: user.Login, Groups: groups}
+		require.NoError(t, s.SyncTeams(user, extUser))
+	}
+
+	sync("CN=SRE,OU=Groups,DC=grafana,DC=org", "developers")
+	assert.ElementsMatch(t, []string{sre.Name, developers.Name, admins.Name, unmapped.Name}, teams())
+
+	sync("@grafana/sre")
+	assert.ElementsMatch(t, []string{sre.Name, admins.Name, unmapped.Name}, teams())
+
+	sync()
+	assert.ElementsMatch(t, []string{admins.Name, unmapped.Name}, teams())
+
+	t.Run("does not add users to the teams of other orgs", func(t *testing.T) {
+		query := models.GetTeamMembersQuery{OrgId: other.OrgId, TeamId: otherOrg.Id, UserId: user.Id}
+		require.NoError(t, sqlstore.GetTeamMembers(&query))
+		assert.Empty(t, query.Result)
+	})
+
+	t.Run("does not sync users without an auth provider", func(t *testing.T) {
+		require.NoError(t, s.SyncTeams(user, &models.ExternalUserInfo{Groups: []string{"developers"}}))
+		assert.ElementsMatch(t, []string{admins.Name, unmapped.Name}, teams())
+	})
+}
+
+func TestSyncLDAPUsers(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	isAdmin := true
+	origNewLDAP, origGetLDAPConfig := newLDAP, getLDAPConfig
+	t.Cleanup(func() {
+		newLDAP, getLDAPConfig = origNewLDAP, origGetLDAPConfig
+	})
+	getLDAPConfig = func(*setting.Cfg) (*ldap.Config, error) {
+		return &ldap.Config{Servers: []*ldap.ServerConfig{{Host: "ldap.test"}}}, nil
+	}
+	ldapUsers := &fakeMultiLDAP{users: map[string]*models.ExternalUserInfo{
+		"alice": {
+			AuthModule: models.AuthModuleLDAP, Login: "alice", Groups: []string{"developers"},
+			OrgRoles: map[int64]models.RoleType{1: models.ROLE_EDITOR}, IsGrafanaAdmin: &isAdmin,
+		},
+		"dave": {AuthModule: models.AuthModuleLDAP, Login: "dave", IsDisabled: true},
+	}}
+	newLDAP = func([]*ldap.ServerConfig) multildap.IMultiLDAP {
+		return ldapUsers
+	}
+
+	var users []*models.User
+	for _, login := range []string{"alice", "bob", "admin", "dave", "carol"} {
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: login, Email: login + "@example.com"})
+		require.NoError(t, err)
+		users = append(users, user)
+	}
+	// Carol logs in with Grafana, not with LDAP.
+	for _, user := range users[:4] {
+		err := sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(&models.UserAuth{
+				UserId: user.Id, AuthModule: models.AuthModuleLDAP, AuthId: user.Login, Created: time.Now(),
+			})
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	var upserted []string
+	bus.AddHandler("test", func(cmd *models.UpsertUserCommand) error {
+		upserted = append(upserted, cmd.ExternalUser.Login)
+		assert.False(t, cmd.SignupAllowed)
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetExternalUserInfoByLoginQuery) error {
+		for _, user := range users {
+			if user.Login == query.LoginOrEmail {
+				query.Result = &models.ExternalUserInfo{UserId: user.Id, Login: user.Login}
+			}
+		}
+		return nil
+	})
+
+	// Bob was added to the Developers team by the LDAP sync of his groups.
+	developers := createTeam(t, sqlStore, "Developers", users[1].OrgId, "developers")
+	require.NoError(t, sqlStore.AddTeamMember(users[1].Id, users[1].OrgId, developers.Id, true, 0))
+	teamMembers := func() []string {
+		query := models.GetTeamMembersQuery{OrgId: developers.OrgId, TeamId: developers.Id}
+		require.NoError(t, sqlstore.GetTeamMembers(&query))
+		logins := make([]string, len(query.Result))
+		for i, member := range query.Result {
+			logins[i] = member.Login
+		}
+		return logins
+	}
+
+	var revoked []int64
+	authTokenService := auth.NewFakeUserAuthTokenService()
+	authTokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		revoked = append(revoked, userId)
+		return nil
+	}
+
+	cfg := setting.NewCfg()
+	cfg.AdminUser = "admin"
+	s := &TeamSyncService{Cfg: cfg, SQLStore: sqlStore, AuthTokenService: authTokenService}
+	orgsQuery := models.GetUserOrgListQuery{UserId: users[0].Id}
+	require.NoError(t, sqlstore.GetUserOrgList(&orgsQuery))
+	require.Len(t, orgsQuery.Result, 1)
+	previousRole := orgsQuery.Result[0].Role
+
+	t.Run("reports the changes without making them in a dry run", func(t *testing.T) {
+		report, err := s.SyncLDAPUsers(ctx, true)
+		require.NoError(t, err)
+
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Synced)
+		assert.Equal(t, 1, report.Unchanged)
+		assert.Equal(t, []*LDAPSyncUserChange{
+			{
+				UserID: users[0].Id, Login: "alice", Action: LDAPSyncActionUpdate,
+				OrgRoles:       []*LDAPSyncOrgRoleChange{{OrgID: 1, Previous: previousRole, Role: models.ROLE_EDITOR}},
+				IsGrafanaAdmin: &isAdmin,
+			},
+			{UserID: users[1].Id, Login: "bob", Action: LDAPSyncActionDisable, Reason: "not found in LDAP"},
+			{UserID: users[3].Id, Login: "dave", Action: LDAPSyncActionDisable, Reason: "not in any of the mapped LDAP groups"},
+		}, report.Users)
+
+		assert.Empty(t, upserted)
+		assert.Empty(t, revoked)
+		assert.Equal(t, []string{"bob"}, teamMembers())
+		query := models.GetUserByIdQuery{Id: users[1].Id}
+		require.NoError(t, sqlstore.GetUserById(ctx, &query))
+		assert.False(t, query.Result.IsDisabled)
+	})
+
+	ldapUsers.searched = nil
+	report, err := s.SyncLDAPUsers(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Len(t, report.Users, 3)
+
+	assert.Equal(t, []string{"alice"}, upserted)
+	assert.ElementsMatch(t, []string{"alice", "bob", "admin", "dave"}, ldapUsers.searched)
+
+	t.Run("removes the users no longer in LDAP from the synced teams", func(t *testing.T) {
+		assert.Empty(t, teamMembers())
+	})
+
+	t.Run("disables and signs out the users no longer in LDAP", func(t *testing.T) {
+		assert.Equal(t, []int64{users[1].Id, users[3].Id}, revoked)
+
+		for _, user := range []*models.User{users[1], users[3]} {
+			query := models.GetUserByIdQuery{Id: user.Id}
+			require.NoError(t, sqlstore.GetUserById(ctx, &query))
+			assert.True(t, query.Result.IsDisabled)
+		}
+	})
+
+	t.Run("does not disable the Grafana admin", func(t *testing.T) {
+		query := models.GetUserByIdQuery{Id: users[2].Id}
+		require.NoError(t, sqlstore.GetUserById(ctx, &query))
+		assert.False(t, query.Result.IsDisabled)
+	})
+}
+
+func TestSyncLDAPUsers_ServerDown(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	ctx := context.Background()
+
+	origNewLDAP, origGetLDAPConfig := newLDAP, getLDAPConfig
+	t.Cleanup(func() {
+		newLDAP, getLDAPConfig = origNewLDAP, origGetLDAPConfig
+	})
+	getLDAPConfig = func(*setting.Cfg) (*ldap.Config, error) {
+		return &ldap.Config{Servers: []*ldap.ServerConfig{{Host: "ldap-1.test"}, {Host: "ldap-2.test", Port: 389}}}, nil
+	}
+	// Only the first server answers, the users of the second one aren't found.
+	ldapUsers := &fakeMultiLDAP{
+		users: map[string]*models.ExternalUserInfo{
+			"alice": {AuthModule: models.AuthModuleLDAP, Login: "alice"},
+			"dave":  {AuthModule: models.AuthModuleLDAP, Login: "dave", IsDisabled: true},
+		},
+		serverErrors: []*multildap.ServerStatus{
+			{Host: "ldap-2.test", Port: 389, Error: errors.New("connection refused")},
+		},
+	}
+	newLDAP = func([]*ldap.ServerConfig) multildap.IMultiLDAP {
+		return ldapUsers
+	}
+
+	var users []*models.User
+	for _, login := range []string{"alice", "bob", "dave"} {
+		user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: login, Email: login + "@example.com"})
+		require.NoError(t, err)
+		users = append(users, user)
+
+		err = sqlStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+			_, err := sess.Insert(&models.UserAuth{
+				UserId: user.Id, AuthModule: models.AuthModuleLDAP, AuthId: user.Login, Created: time.Now(),
+			})
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	var upserted []string
+	bus.AddHandler("test", func(cmd *models.UpsertUserCommand) error {
+		upserted = append(upserted, cmd.ExternalUser.Login)
+		return nil
+	})
+
+	var revoked []int64
+	authTokenService := auth.NewFakeUserAuthTokenService()
+	authTokenService.RevokeAllUserTokensProvider = func(ctx context.Context, userId int64) error {
+		revoked = append(revoked, userId)
+		return nil
+	}
+
+	s := &TeamSyncService{Cfg: setting.NewCfg(), SQLStore: sqlStore, AuthTokenService: authTokenService}
+	report, err := s.SyncLDAPUsers(ctx, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.Synced)
+	assert.Equal(t, 2, report.Skipped)
+	assert.Equal(t, []string{"ldap-2.test:389: connection refused"}, report.ServerErrors)
+	assert.Equal(t, []string{"alice"}, upserted)
+	assert.Empty(t, revoked)
+
+	for _, user := range users {
+		query := models.GetUserByIdQuery{Id: user.Id}
+		require.NoError(t, sqlstore.GetUserById(ctx, &query))
+		assert.False(t, query.Result.IsDisabled, user.Login)
+	}
+}
+
+func createTeam(t *testing.T, sqlStore *sqlstore.SQLStore, name string, orgID int64, groups ...string) models.Team {
+	t.Helper()
+
+	team, err := sqlStore.CreateTeam(name, "", orgID)
+	require.NoError(t, err)
+	for _, group := range groups {
+		require.NoError(t, sqlstore.AddTeamGroup(&models.AddTeamGroupCommand{OrgId: orgID, TeamId: team.Id, GroupId: group}))
+	}
+	return team
+}
+
+type fakeLoginService struct {
+	login.Service
+	teamSync login.TeamSyncFunc
+}
+
+func (s *fakeLoginService) SetTeamSyncFunc(teamSync login.TeamSyncFunc) {
+	s.teamSync = teamSync
+}
+
+type fakeMultiLDAP struct {
+	multildap.IMultiLDAP
+	users        map[string]*models.ExternalUserInfo
+	serverErrors []*multildap.ServerStatus
+	searched     []string
+}
+
+func (m *fakeMultiLDAP) UsersWithServerErrors(logins []string) ([]*models.ExternalUserInfo, []*multildap.ServerStatus, error) {
+	var result []*models.ExternalUserInfo
+	for _, login := range logins {
+		m.searched = append(m.searched, login)
+		if user, ok := m.users[login]; ok {
+			result = append(result, user)
+		}
+	}
+	return result, m.serverErrors, nil
+}
@@ -0,0 +1,36 @@
+package teamsync
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// FakeTeamSyncService is a Service for tests. It records the users whose
+// teams are synced, and LDAP syncs return Report without changing any user.
+type FakeTeamSyncService struct {
+	Synced []*models.ExternalUserInfo
+	Report *LDAPSyncReport
+	Err    error
+	// DryRuns records the dryRun argument of the LDAP syncs.
+	DryRuns []bool
+}
+
+func NewFakeTeamSyncService() *FakeTeamSyncService {
+	return &FakeTeamSyncService{Report: &LDAPSyncReport{Users: []*LDAPSyncUserChange{}}}
+}
+
+func (s *FakeTeamSyncService) SyncTeams(user *models.User, externalUser *models.ExternalUserInfo) error {
+	s.Synced = append(s.Synced, externalUser)
+	return nil
+}
+
+func (s *FakeTeamSyncService) SyncLDAPUsers(ctx context.Context, dryRun bool) (*LDAPSyncReport, error) {
+	s.DryRuns = append(s.DryRuns, dryRun)
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	report := *s.Report
+	report.DryRun = dryRun
+	return &report, nil
+}
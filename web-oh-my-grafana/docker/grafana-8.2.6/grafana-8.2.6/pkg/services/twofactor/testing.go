@@ -0,0 +1,73 @@
+package twofactor
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// FakeTwoFactorService is a Service for tests. Users haven't enabled
+// two-factor authentication unless Status is set, and every code in
+// ValidCodes is accepted.
+type FakeTwoFactorService struct {
+	Status     models.TwoFactorStatus
+	ValidCodes []string
+	Policies   map[int64]*models.OrgAuthPolicy
+}
+
+func NewFakeTwoFactorService() *FakeTwoFactorService {
+	return &FakeTwoFactorService{Policies: map[int64]*models.OrgAuthPolicy{}}
+}
+
+func (s *FakeTwoFactorService) GetStatus(ctx context.Context, userID int64) (*models.TwoFactorStatus, error) {
+	status := s.Status
+	return &status, nil
+}
+
+func (s *FakeTwoFactorService) Enroll(ctx context.Context, user *models.User) (*models.TwoFactorEnrollment, error) {
+	if s.Status.Enabled {
+		return nil, models.ErrTwoFactorAlreadyEnabled
+	}
+	return &models.TwoFactorEnrollment{Secret: "secret", ProvisioningURI: "otpauth://totp/Grafana:" + user.Login}, nil
+}
+
+func (s *FakeTwoFactorService) Enable(ctx context.Context, user *models.User, code string, ipAddress string) error {
+	if err := s.validate(code); err != nil {
+		return err
+	}
+	s.Status.Enabled = true
+	return nil
+}
+
+func (s *FakeTwoFactorService) Verify(ctx context.Context, user *models.User, code string, ipAddress string) error {
+	if !s.Status.Enabled {
+		return models.ErrTwoFactorNotEnrolled
+	}
+	return s.validate(code)
+}
+
+func (s *FakeTwoFactorService) Disable(ctx context.Context, userID int64) error {
+	s.Status.Enabled = false
+	return nil
+}
+
+func (s *FakeTwoFactorService) GetOrgPolicy(ctx context.Context, orgID int64) (*models.OrgAuthPolicy, error) {
+	if policy, ok := s.Policies[orgID]; ok {
+		return policy, nil
+	}
+	return &models.OrgAuthPolicy{OrgId: orgID}, nil
+}
+
+func (s *FakeTwoFactorService) SaveOrgPolicy(ctx context.Context, policy *models.OrgAuthPolicy) error {
+	s.Policies[policy.OrgId] = policy
+	return nil
+}
+
+func (s *FakeTwoFactorService) validate(code string) error {
+	for _, valid := range s.ValidCodes {
+		if code == valid {
+			return nil
+		}
+	}
+	return models.ErrTwoFactorInvalidCode
+}
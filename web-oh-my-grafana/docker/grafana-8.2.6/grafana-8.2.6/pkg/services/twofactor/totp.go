@@ -0,0 +1,77 @@
+package twofactor
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 TOTP (RFC 6238) is defined with HMAC-SHA1, which authenticator apps expect.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is the number of time steps before and after the current
+	// one in which codes are accepted, to tolerate clock drift.
+	totpSkew = 1
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// timeStep returns the TOTP time step of t.
+func timeStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// generateCode computes the TOTP code of secret for a time step as
+// described by RFC 4226 and RFC 6238.
+func generateCode(secret []byte, step int64) string {
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// validateCode returns the time step of code if it's valid for a step
+// around now that is after lastUsedStep, or 0 otherwise.
+func validateCode(secret []byte, code string, now time.Time, lastUsedStep int64) int64 {
+	if len(code) != totpDigits {
+		return 0
+	}
+
+	current := timeStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(generateCode(secret, step)), []byte(code)) == 1 {
+			return step
+		}
+	}
+
+	return 0
+}
+
+// provisioningURI returns the otpauth URI which authenticator apps read
+// from a QR code to register the secret.
+func provisioningURI(issuer, account string, secret []byte) string {
+	params := url.Values{}
+	params.Set("secret", secretEncoding.EncodeToString(secret))
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
@@ -0,0 +1,381 @@
+package twofactor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	issuer            = "Grafana"
+	secretLength      = 20
+	recoveryCodeCount = 10
+
+	// maxInvalidAttempts and attemptsWindow match the brute force login
+	// protection, failed codes are recorded as failed login attempts.
+	maxInvalidAttempts int64 = 5
+	attemptsWindow           = time.Minute * 5
+)
+
+var getTime = time.Now
+
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, encryptionService encryption.Service) *TwoFactorService {
+	return &TwoFactorService{
+		Cfg:               cfg,
+		SQLStore:          sqlStore,
+		EncryptionService: encryptionService,
+	}
+}
+
+type Service interface {
+	GetStatus(ctx context.Context, userID int64) (*models.TwoFactorStatus, error)
+	Enroll(ctx context.Context, user *models.User) (*models.TwoFactorEnrollment, error)
+	Enable(ctx context.Context, user *models.User, code string, ipAddress string) error
+	Verify(ctx context.Context, user *models.User, code string, ipAddress string) error
+	Disable(ctx context.Context, userID int64) error
+	GetOrgPolicy(ctx context.Context, orgID int64) (*models.OrgAuthPolicy, error)
+	SaveOrgPolicy(ctx context.Context, policy *models.OrgAuthPolicy) error
+}
+
+// TwoFactorService implements TOTP based two-factor authentication for
+// users logging in with a password.
+type TwoFactorService struct {
+	Cfg               *setting.Cfg
+	SQLStore          *sqlstore.SQLStore
+	EncryptionService encryption.Service
+}
+
+// GetStatus returns whether the user has enabled two-factor authentication
+// and whether a policy of one of their organizations requires it.
+func (s *TwoFactorService) GetStatus(ctx context.Context, userID int64) (*models.TwoFactorStatus, error) {
+	status := &models.TwoFactorStatus{}
+
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		tf, err := getUserTwoFactor(sess, userID)
+		if err != nil && err != models.ErrTwoFactorNotEnrolled {
+			return err
+		}
+		if tf != nil && tf.Enabled {
+			status.Enabled = true
+			codes, err := recoveryCodes(tf)
+			if err != nil {
+				return err
+			}
+			status.RecoveryCodesLeft = len(codes)
+		}
+
+		count, err := sess.Table("org_user").
+			Join("INNER", "org_auth_policy", "org_auth_policy.org_id = org_user.org_id").
+			Where("org_user.user_id = ? AND org_user.role = ? AND org_auth_policy.require_two_factor_for_admins = ?",
+				userID, models.ROLE_ADMIN, true).
+			Count()
+		if err != nil {
+			return err
+		}
+		status.Required = count > 0
+		return nil
+	})
+
+	return status, err
+}
+
+// Enroll generates a new secret and recovery codes for the user. They are
+// only used once the enrollment has been confirmed by Enable.
+func (s *TwoFactorService) Enroll(ctx context.Context, user *models.User) (*models.TwoFactorEnrollment, error) {
+	secret := make([]byte, secretLength)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+	// the secret is encrypted in its base32 text form, which allows to
+	// check that it has been decrypted with the right key
+	encryptedSecret, err := s.EncryptionService.Encrypt([]byte(secretEncoding.EncodeToString(secret)), setting.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(10, []byte("abcdefghjkmnpqrstuvwxyz23456789")...)
+		if err != nil {
+			return nil, err
+		}
+		code = code[:5] + "-" + code[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	hashesJSON, err := json.Marshal(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing, err := getUserTwoFactor(sess, user.Id)
+		if err != nil && err != models.ErrTwoFactorNotEnrolled {
+			return err
+		}
+		if existing != nil && existing.Enabled {
+			return models.ErrTwoFactorAlreadyEnabled
+		}
+		if existing != nil {
+			if _, err := sess.ID(existing.Id).Delete(&models.UserTwoFactor{}); err != nil {
+				return err
+			}
+		}
+
+		_, err = sess.Insert(&models.UserTwoFactor{
+			UserId:        user.Id,
+			Secret:        base64.StdEncoding.EncodeToString(encryptedSecret),
+			RecoveryCodes: string(hashesJSON),
+			Created:       getTime(),
+			Updated:       getTime(),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret:          secretEncoding.EncodeToString(secret),
+		ProvisioningURI: provisioningURI(issuer, user.Login, secret),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// Enable confirms the pending enrollment of the user with a code from
+// their authenticator app. Failed attempts count as failed logins, like for
+// Verify.
+func (s *TwoFactorService) Enable(ctx context.Context, user *models.User, code string, ipAddress string) error {
+	if err := s.validateAttempts(ctx, user); err != nil {
+		return err
+	}
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		tf, err := getUserTwoFactor(sess, user.Id)
+		if err == models.ErrTwoFactorNotEnrolled {
+			return models.ErrTwoFactorEnrollmentMissing
+		}
+		if err != nil {
+			return err
+		}
+		if tf.Enabled {
+			return models.ErrTwoFactorAlreadyEnabled
+		}
+
+		step, err := s.validateTOTP(tf, strings.TrimSpace(code))
+		if err != nil {
+			return err
+		}
+		if step == 0 {
+			return models.ErrTwoFactorInvalidCode
+		}
+
+		tf.Enabled = true
+		tf.LastUsedStep = step
+		tf.Updated = getTime()
+		_, err = sess.ID(tf.Id).Cols("enabled", "last_used_step", "updated").Update(tf)
+		return err
+	})
+
+	return s.recordInvalidAttempt(ctx, user, ipAddress, err)
+}
+
+// Verify checks a code from the authenticator app of the user, or one of
+// their recovery codes which can only be used once. Failed attempts count
+// as failed logins for the brute force login protection.
+func (s *TwoFactorService) Verify(ctx context.Context, user *models.User, code string, ipAddress string) error {
+	if err := s.validateAttempts(ctx, user); err != nil {
+		return err
+	}
+
+	err := s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		tf, err := getUserTwoFactor(sess, user.Id)
+		if err != nil {
+			return err
+		}
+		if !tf.Enabled {
+			return models.ErrTwoFactorNotEnrolled
+		}
+
+		code = strings.TrimSpace(code)
+		step, err := s.validateTOTP(tf, code)
+		if err != nil {
+			return err
+		}
+		if step != 0 {
+			tf.LastUsedStep = step
+			tf.Updated = getTime()
+			_, err = sess.ID(tf.Id).Cols("last_used_step", "updated").Update(tf)
+			return err
+		}
+
+		hashes, err := recoveryCodes(tf)
+		if err != nil {
+			return err
+		}
+		hash := hashRecoveryCode(code)
+		for i, h := range hashes {
+			if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) != 1 {
+				continue
+			}
+
+			remaining, err := json.Marshal(append(hashes[:i], hashes[i+1:]...))
+			if err != nil {
+				return err
+			}
+			tf.RecoveryCodes = string(remaining)
+			tf.Updated = getTime()
+			_, err = sess.ID(tf.Id).Cols("recovery_codes", "updated").Update(tf)
+			return err
+		}
+
+		return models.ErrTwoFactorInvalidCode
+	})
+
+	return s.recordInvalidAttempt(ctx, user, ipAddress, err)
+}
+
+// Disable removes the secret and recovery codes of the user.
+func (s *TwoFactorService) Disable(ctx context.Context, userID int64) error {
+	return s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		_, err := sess.Where("user_id = ?", userID).Delete(&models.UserTwoFactor{})
+		return err
+	})
+}
+
+// GetOrgPolicy returns the authentication policy of the organization, or
+// the default policy if none has been saved.
+func (s *TwoFactorService) GetOrgPolicy(ctx context.Context, orgID int64) (*models.OrgAuthPolicy, error) {
+	policy := &models.OrgAuthPolicy{}
+	err := s.SQLStore.WithDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		has, err := sess.Where("org_id = ?", orgID).Get(policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			policy = &models.OrgAuthPolicy{OrgId: orgID}
+		}
+		return nil
+	})
+
+	return policy, err
+}
+
+func (s *TwoFactorService) SaveOrgPolicy(ctx context.Context, policy *models.OrgAuthPolicy) error {
+	return s.SQLStore.WithTransactionalDbSession(ctx, func(sess *sqlstore.DBSession) error {
+		existing := &models.OrgAuthPolicy{}
+		has, err := sess.Where("org_id = ?", policy.OrgId).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		policy.Updated = getTime()
+		if has {
+			policy.Id = existing.Id
+			policy.Created = existing.Created
+			_, err = sess.ID(existing.Id).AllCols().Update(policy)
+			return err
+		}
+
+		policy.Created = policy.Updated
+		_, err = sess.Insert(policy)
+		return err
+	})
+}
+
+// recordInvalidAttempt records a failed login for the user if err is an
+// invalid code, and returns err.
+func (s *TwoFactorService) recordInvalidAttempt(ctx context.Context, user *models.User, ipAddress string, err error) error {
+	if err == models.ErrTwoFactorInvalidCode && !s.Cfg.DisableBruteForceLoginProtection {
+		if err := bus.DispatchCtx(ctx, &models.CreateLoginAttemptCommand{
+			Username:  user.Login,
+			IpAddress: ipAddress,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (s *TwoFactorService) validateAttempts(ctx context.Context, user *models.User) error {
+	if s.Cfg.DisableBruteForceLoginProtection {
+		return nil
+	}
+
+	query := models.GetUserLoginAttemptCountQuery{
+		Username: user.Login,
+		Since:    getTime().Add(-attemptsWindow),
+	}
+	if err := bus.DispatchCtx(ctx, &query); err != nil {
+		return err
+	}
+	if query.Result >= maxInvalidAttempts {
+		return models.ErrTwoFactorTooManyAttempts
+	}
+
+	return nil
+}
+
+// validateTOTP returns the time step of code, or 0 if it isn't valid.
+func (s *TwoFactorService) validateTOTP(tf *models.UserTwoFactor, code string) (int64, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(tf.Secret)
+	if err != nil {
+		return 0, err
+	}
+	decrypted, err := s.EncryptionService.Decrypt(encrypted, setting.SecretKey)
+	if err != nil {
+		return 0, err
+	}
+	secret, err := secretEncoding.DecodeString(string(decrypted))
+	if err != nil {
+		return 0, err
+	}
+
+	return validateCode(secret, code, getTime(), tf.LastUsedStep), nil
+}
+
+func getUserTwoFactor(sess *sqlstore.DBSession, userID int64) (*models.UserTwoFactor, error) {
+	tf := &models.UserTwoFactor{}
+	has, err := sess.Where("user_id = ?", userID).Get(tf)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, models.ErrTwoFactorNotEnrolled
+	}
+
+	return tf, nil
+}
+
+func recoveryCodes(tf *models.UserTwoFactor) ([]string, error) {
+	var hashes []string
+	if err := json.Unmarshal([]byte(tf.RecoveryCodes), &hashes); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code ignoring case and separators.
+// Recovery codes are random, so they don't need a salted password hash.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
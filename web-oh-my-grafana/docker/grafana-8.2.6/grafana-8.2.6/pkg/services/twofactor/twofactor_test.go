@@ -0,0 +1,132 @@
+package twofactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption/ossencryption"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateCode(t *testing.T) {
+	// test vectors of RFC 6238, truncated to 6 digits
+	secret := []byte("12345678901234567890")
+	assert.Equal(t, "287082", generateCode(secret, timeStep(time.Unix(59, 0))))
+	assert.Equal(t, "081804", generateCode(secret, timeStep(time.Unix(1111111109, 0))))
+	assert.Equal(t, "005924", generateCode(secret, timeStep(time.Unix(1234567890, 0))))
+}
+
+func TestTwoFactorService(t *testing.T) {
+	sqlStore := sqlstore.InitTestDB(t)
+	service := ProvideService(setting.NewCfg(), sqlStore, &ossencryption.Service{})
+	ctx := context.Background()
+
+	now := time.Now()
+	origGetTime := getTime
+	t.Cleanup(func() {
+		getTime = origGetTime
+	})
+	getTime = func() time.Time {
+		return now
+	}
+
+	user, err := sqlStore.CreateUser(ctx, models.CreateUserCommand{Login: "admin2fa", Email: "admin2fa@example.com"})
+	require.NoError(t, err)
+
+	enrollment, err := service.Enroll(ctx, user)
+	require.NoError(t, err)
+	require.Len(t, enrollment.RecoveryCodes, recoveryCodeCount)
+	assert.Contains(t, enrollment.ProvisioningURI, "otpauth://totp/Grafana:admin2fa?")
+	assert.Contains(t, enrollment.ProvisioningURI, "secret="+enrollment.Secret)
+
+	secret, err := secretEncoding.DecodeString(enrollment.Secret)
+	require.NoError(t, err)
+	code := func(offset time.Duration) string {
+		return generateCode(secret, timeStep(now.Add(offset)))
+	}
+
+	t.Run("verifying codes requires a confirmed enrollment", func(t *testing.T) {
+		err := service.Verify(ctx, user, code(0), "")
+		require.ErrorIs(t, err, models.ErrTwoFactorNotEnrolled)
+
+		err = service.Enable(ctx, user, "000000", "")
+		require.ErrorIs(t, err, models.ErrTwoFactorInvalidCode)
+
+		err = service.Enable(ctx, user, code(-totpPeriod*time.Second), "")
+		require.NoError(t, err)
+
+		status, err := service.GetStatus(ctx, user.Id)
+		require.NoError(t, err)
+		assert.Equal(t, &models.TwoFactorStatus{Enabled: true, RecoveryCodesLeft: recoveryCodeCount}, status)
+
+		_, err = service.Enroll(ctx, user)
+		require.ErrorIs(t, err, models.ErrTwoFactorAlreadyEnabled)
+	})
+
+	t.Run("codes can only be used once", func(t *testing.T) {
+		err := service.Verify(ctx, user, code(0), "")
+		require.NoError(t, err)
+
+		err = service.Verify(ctx, user, code(0), "")
+		require.ErrorIs(t, err, models.ErrTwoFactorInvalidCode)
+
+		// the code of a previous step isn't accepted after a later one
+		err = service.Verify(ctx, user, code(-totpPeriod*time.Second), "")
+		require.ErrorIs(t, err, models.ErrTwoFactorInvalidCode)
+	})
+
+	t.Run("recovery codes can only be used once", func(t *testing.T) {
+		err := service.Verify(ctx, user, enrollment.RecoveryCodes[0], "")
+		require.NoError(t, err)
+
+		err = service.Verify(ctx, user, enrollment.RecoveryCodes[0], "")
+		require.ErrorIs(t, err, models.ErrTwoFactorInvalidCode)
+
+		status, err := service.GetStatus(ctx, user.Id)
+		require.NoError(t, err)
+		assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesLeft)
+	})
+
+	t.Run("failed codes are limited", func(t *testing.T) {
+		// four failures, including the invalid enrollment code, were
+		// recorded by the previous tests
+		err := service.Verify(ctx, user, "123", "")
+		require.ErrorIs(t, err, models.ErrTwoFactorInvalidCode)
+
+		err = service.Verify(ctx, user, code(totpPeriod*time.Second), "")
+		require.ErrorIs(t, err, models.ErrTwoFactorTooManyAttempts)
+
+		err = service.Enable(ctx, user, code(totpPeriod*time.Second), "")
+		require.ErrorIs(t, err, models.ErrTwoFactorTooManyAttempts)
+	})
+
+	t.Run("org policy requires two-factor authentication for admins", func(t *testing.T) {
+		status, err := service.GetStatus(ctx, user.Id)
+		require.NoError(t, err)
+		assert.False(t, status.Required)
+
+		err = service.SaveOrgPolicy(ctx, &models.OrgAuthPolicy{OrgId: user.OrgId, RequireTwoFactorForAdmins: true})
+		require.NoError(t, err)
+		policy, err := service.GetOrgPolicy(ctx, user.OrgId)
+		require.NoError(t, err)
+		assert.True(t, policy.RequireTwoFactorForAdmins)
+
+		status, err = service.GetStatus(ctx, user.Id)
+		require.NoError(t, err)
+		assert.True(t, status.Required)
+	})
+
+	t.Run("disabling two-factor authentication", func(t *testing.T) {
+		err := service.Disable(ctx, user.Id)
+		require.NoError(t, err)
+
+		status, err := service.GetStatus(ctx, user.Id)
+		require.NoError(t, err)
+		assert.False(t, status.Enabled)
+	})
+}
@@ -252,6 +252,12 @@ type Cfg struct {
 	// CSPTemplate contains the Content Security Policy template.
 	CSPTemplate string
 
+	// Envelope encryption
+	EncryptionProvider    string
+	EncryptionKeyfilePath string
+	EncryptionKMSURL      string
+	EncryptionKMSKeyID    string
+
 	TempDataLifetime                 time.Duration
 	PluginsEnableAlpha               bool
 	PluginsAppsSkipVerifyTLS         bool
@@ -271,7 +277,8 @@ type Cfg struct {
 	MetricsGrafanaEnvironmentInfo    map[string]string
 
 	// Dashboards
-	DefaultHomeDashboardPath string
+	DefaultHomeDashboardPath      string
+	DashboardUsageTrackingEnabled bool
 
 	// Auth
 	LoginCookieName              string
@@ -309,11 +316,22 @@ type Cfg struct {
 	JWTAuthHeaderName    string
 	JWTAuthEmailClaim    string
 	JWTAuthUsernameClaim string
+	JWTAuthGroupsClaim   string
+	JWTAuthNameClaim     string
 	JWTAuthExpectClaims  string
 	JWTAuthJWKSetURL     string
 	JWTAuthCacheTTL      time.Duration
 	JWTAuthKeyFile       string
 	JWTAuthJWKSetFile    string
+	JWTAuthURLLogin      bool
+	JWTAuthAutoSignUp    bool
+	// JMESPath expressions selecting the role and the orgs of the user in
+	// the claims.
+	JWTAuthRoleAttributePath string
+	JWTAuthOrgAttributePath  string
+	// JWTAuthOrgMapping maps the orgs of the claims to the IDs of Grafana
+	// orgs.
+	JWTAuthOrgMapping map[string][]int64
 
 	// Dataproxy
 	SendUserHeader                 bool
@@ -358,6 +376,24 @@ type Cfg struct {
 	// Sentry config
 	Sentry Sentry
 
+	// Audit log
+	Audit AuditSettings
+
+	// Rate limiting
+	RateLimit RateLimitSettings
+
+	// Password policy of built-in users
+	PasswordPolicy PasswordPolicySettings
+
+	// Cache of the data source query results
+	QueryCache QueryCacheSettings
+
+	// Default limits of the data source queries
+	QueryLimits QueryLimitsSettings
+
+	// SAML authentication
+	SAML SAMLSettings
+
 	// Data sources
 	DataSourceLimit int
 
@@ -376,8 +412,10 @@ type Cfg struct {
 	ApplicationInsightsEndpointUrl      string
 
 	// LDAP
-	LDAPEnabled     bool
-	LDAPAllowSignup bool
+	LDAPEnabled           bool
+	LDAPAllowSignup       bool
+	LDAPSyncCron          string
+	LDAPActiveSyncEnabled bool
 
 	Quota QuotaSettings
 
@@ -917,6 +955,7 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardUsageTrackingEnabled = dashboards.Key("usage_tracking_enabled").MustBool(true)
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1043,6 +1082,24 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 
 	cfg.readDateFormats()
 	cfg.readSentryConfig()
+	cfg.readAuditSettings()
+	cfg.readSAMLSettings()
+
+	if err := cfg.readRateLimitSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readPasswordPolicySettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readQueryCacheSettings(); err != nil {
+		return err
+	}
+
+	if err := cfg.readQueryLimitsSettings(); err != nil {
+		return err
+	}
 
 	if err := cfg.readLiveSettings(iniFile); err != nil {
 		return err
@@ -1066,9 +1123,11 @@ func (cfg *Cfg) readLDAPConfig() {
 	ldapSec := cfg.Raw.Section("auth.ldap")
 	LDAPConfigFile = ldapSec.Key("config_file").String()
 	LDAPSyncCron = ldapSec.Key("sync_cron").String()
+	cfg.LDAPSyncCron = LDAPSyncCron
 	LDAPEnabled = ldapSec.Key("enabled").MustBool(false)
 	cfg.LDAPEnabled = LDAPEnabled
 	LDAPActiveSyncEnabled = ldapSec.Key("active_sync_enabled").MustBool(false)
+	cfg.LDAPActiveSyncEnabled = LDAPActiveSyncEnabled
 	LDAPAllowSignup = ldapSec.Key("allow_sign_up").MustBool(true)
 	cfg.LDAPAllowSignup = LDAPAllowSignup
 }
@@ -1228,6 +1287,12 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.AdminUser = valueAsString(security, "admin_user", "")
 	cfg.AdminPassword = valueAsString(security, "admin_password", "")
 
+	encryption := iniFile.Section("security.encryption")
+	cfg.EncryptionProvider = valueAsString(encryption, "provider", "")
+	cfg.EncryptionKeyfilePath = valueAsString(encryption, "keyfile_path", "")
+	cfg.EncryptionKMSURL = valueAsString(encryption, "kms_url", "")
+	cfg.EncryptionKMSKeyID = valueAsString(encryption, "kms_key_id", "")
+
 	return nil
 }
 
@@ -1296,11 +1361,29 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 	cfg.JWTAuthHeaderName = valueAsString(authJWT, "header_name", "")
 	cfg.JWTAuthEmailClaim = valueAsString(authJWT, "email_claim", "")
 	cfg.JWTAuthUsernameClaim = valueAsString(authJWT, "username_claim", "")
+	cfg.JWTAuthGroupsClaim = valueAsString(authJWT, "groups_claim", "")
 	cfg.JWTAuthExpectClaims = valueAsString(authJWT, "expect_claims", "{}")
 	cfg.JWTAuthJWKSetURL = valueAsString(authJWT, "jwk_set_url", "")
 	cfg.JWTAuthCacheTTL = authJWT.Key("cache_ttl").MustDuration(time.Minute * 60)
 	cfg.JWTAuthKeyFile = valueAsString(authJWT, "key_file", "")
 	cfg.JWTAuthJWKSetFile = valueAsString(authJWT, "jwk_set_file", "")
+	cfg.JWTAuthNameClaim = valueAsString(authJWT, "name_claim", "")
+	cfg.JWTAuthURLLogin = authJWT.Key("url_login").MustBool(false)
+	cfg.JWTAuthAutoSignUp = authJWT.Key("auto_sign_up").MustBool(false)
+	cfg.JWTAuthRoleAttributePath = valueAsString(authJWT, "role_attribute_path", "")
+	cfg.JWTAuthOrgAttributePath = valueAsString(authJWT, "org_attribute_path", "")
+	cfg.JWTAuthOrgMapping = map[string][]int64{}
+	for _, v := range util.SplitString(valueAsString(authJWT, "org_mapping", "")) {
+		i := strings.LastIndex(v, ":")
+		if i <= 0 {
+			return fmt.Errorf("invalid [auth.jwt] org_mapping %q", v)
+		}
+		orgID, err := strconv.ParseInt(v[i+1:], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid [auth.jwt] org_mapping %q", v)
+		}
+		cfg.JWTAuthOrgMapping[v[:i]] = append(cfg.JWTAuthOrgMapping[v[:i]], orgID)
+	}
 
 	authProxy := iniFile.Section("auth.proxy")
 	AuthProxyEnabled = authProxy.Key("enabled").MustBool(false)
@@ -0,0 +1,33 @@
+package setting
+
+import (
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type AuditSettings struct {
+	Enabled bool
+	// Outputs lists the names of the outputs audit events are written to,
+	// such as "sql", "file" or "syslog".
+	Outputs []string
+
+	FilePath         string
+	FileRotate       bool
+	FileMaxSizeShift int
+	FileDailyRotate  bool
+	FileMaxDays      int64
+}
+
+func (cfg *Cfg) readAuditSettings() {
+	section := cfg.Raw.Section("audit")
+	cfg.Audit.Enabled = section.Key("enabled").MustBool(false)
+	cfg.Audit.Outputs = util.SplitString(valueAsString(section, "outputs", "sql"))
+
+	fileSection := cfg.Raw.Section("audit.file")
+	cfg.Audit.FilePath = valueAsString(fileSection, "path", filepath.Join(cfg.LogsPath, "audit.log"))
+	cfg.Audit.FileRotate = fileSection.Key("log_rotate").MustBool(true)
+	cfg.Audit.FileMaxSizeShift = fileSection.Key("max_size_shift").MustInt(28)
+	cfg.Audit.FileDailyRotate = fileSection.Key("daily_rotate").MustBool(true)
+	cfg.Audit.FileMaxDays = fileSection.Key("max_days").MustInt64(7)
+}
@@ -0,0 +1,57 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// MaxPasswordHistory is the number of previous passwords kept for each
+// user to prevent their reuse.
+const MaxPasswordHistory = 24
+
+// PasswordPolicySettings are the requirements for the passwords of the
+// users logging in with the Grafana login form.
+type PasswordPolicySettings struct {
+	Enabled bool
+
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+
+	// BreachedPasswordsFile is a file of forbidden passwords, one per line.
+	BreachedPasswordsFile string
+	// HistoryCount is the number of previous passwords that can't be reused.
+	HistoryCount int
+	// MaxAge is how long a password can be used before it has to be
+	// changed, zero means passwords don't expire.
+	MaxAge time.Duration
+}
+
+func (cfg *Cfg) readPasswordPolicySettings() error {
+	section := cfg.Raw.Section("password_policy")
+	policy := &cfg.PasswordPolicy
+	policy.Enabled = section.Key("enabled").MustBool(false)
+	policy.MinLength = section.Key("min_length").MustInt(12)
+	policy.RequireUppercase = section.Key("require_uppercase").MustBool(true)
+	policy.RequireLowercase = section.Key("require_lowercase").MustBool(true)
+	policy.RequireDigit = section.Key("require_digit").MustBool(true)
+	policy.RequireSymbol = section.Key("require_symbol").MustBool(false)
+	policy.BreachedPasswordsFile = valueAsString(section, "breached_passwords_file", "")
+	policy.HistoryCount = section.Key("history_count").MustInt(5)
+
+	if policy.HistoryCount < 0 || policy.HistoryCount > MaxPasswordHistory {
+		return fmt.Errorf("password_policy history_count must be between 0 and %d", MaxPasswordHistory)
+	}
+
+	var err error
+	policy.MaxAge, err = gtime.ParseDuration(valueAsString(section, "max_age", "0"))
+	if err != nil {
+		return fmt.Errorf("invalid password_policy max_age: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+const (
+	QueryCacheStorageRemoteCache = "remote_cache"
+	QueryCacheStorageLocal       = "local"
+)
+
+// QueryCacheSettings configure the cache of the data source query results.
+type QueryCacheSettings struct {
+	Enabled bool
+	// Storage is where the results are kept, either "remote_cache" to share
+	// them between the Grafana instances or "local".
+	Storage string
+	// DefaultTTL is how long results are cached for the data sources without
+	// a queryCacheTTL. The time range of the queries is rounded to it.
+	DefaultTTL time.Duration
+	// MaxEntrySize is the size of the largest result that is cached, in bytes.
+	MaxEntrySize int
+}
+
+func (cfg *Cfg) readQueryCacheSettings() error {
+	section := cfg.Raw.Section("query_cache")
+	cfg.QueryCache.Enabled = section.Key("enabled").MustBool(false)
+	cfg.QueryCache.Storage = valueAsString(section, "storage", QueryCacheStorageRemoteCache)
+	cfg.QueryCache.MaxEntrySize = section.Key("max_entry_size_kb").MustInt(1024) * 1024
+
+	switch cfg.QueryCache.Storage {
+	case QueryCacheStorageRemoteCache, QueryCacheStorageLocal:
+	default:
+		return fmt.Errorf("invalid query cache storage %q", cfg.QueryCache.Storage)
+	}
+
+	var err error
+	cfg.QueryCache.DefaultTTL, err = gtime.ParseDuration(valueAsString(section, "default_ttl", "1m"))
+	if err != nil {
+		return fmt.Errorf("invalid query_cache default_ttl: %w", err)
+	}
+	if cfg.QueryCache.DefaultTTL < time.Second {
+		return fmt.Errorf("query_cache default_ttl must be at least 1s")
+	}
+	if cfg.QueryCache.MaxEntrySize <= 0 {
+		return fmt.Errorf("query_cache max_entry_size_kb must be greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// QueryLimitsSettings are the default limits of the queries of every data
+// source. Data sources can override them in their JSON data. Zero means no
+// limit.
+type QueryLimitsSettings struct {
+	// MaxConcurrentQueries is the number of queries of a data source running
+	// at once, on each Grafana instance.
+	MaxConcurrentQueries int
+	// Timeout is how long a data source request can wait and run.
+	Timeout time.Duration
+	// MaxRows is the number of rows of the result of a query.
+	MaxRows int
+	// MaxResponseSize is the size of the result of a query, in bytes.
+	MaxResponseSize int
+}
+
+func (cfg *Cfg) readQueryLimitsSettings() error {
+	section := cfg.Raw.Section("query_limits")
+	cfg.QueryLimits.MaxConcurrentQueries = section.Key("max_concurrent_queries").MustInt(0)
+	cfg.QueryLimits.MaxRows = section.Key("max_rows").MustInt(0)
+	cfg.QueryLimits.MaxResponseSize = section.Key("max_response_size_kb").MustInt(0) * 1024
+
+	var err error
+	cfg.QueryLimits.Timeout, err = gtime.ParseDuration(valueAsString(section, "query_timeout", "0"))
+	if err != nil {
+		return fmt.Errorf("invalid query_limits query_timeout: %w", err)
+	}
+
+	if cfg.QueryLimits.MaxConcurrentQueries < 0 || cfg.QueryLimits.MaxRows < 0 ||
+		cfg.QueryLimits.MaxResponseSize < 0 || cfg.QueryLimits.Timeout < 0 {
+		return fmt.Errorf("query_limits can't be negative")
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package setting
 
 import (
 	"reflect"
+	"time"
 )
 
 type OrgQuota struct {
@@ -16,6 +17,18 @@ type UserQuota struct {
 	Org int64 `target:"org_user"`
 }
 
+type FolderQuota struct {
+	Dashboard int64 `target:"dashboard"`
+}
+
+// OrgRateQuota holds the limits of the quotas which are counted within
+// a time window, see QuotaSettings.RateWindow, rather than by the number of
+// rows in the database.
+type OrgRateQuota struct {
+	DataSourceQuery int64 `target:"data_source_query"`
+	ApiRequest      int64 `target:"api_request"`
+}
+
 type GlobalQuota struct {
 	Org        int64 `target:"org"`
 	User       int64 `target:"user"`
@@ -34,6 +47,14 @@ func (q *UserQuota) ToMap() map[string]int64 {
 	return quotaToMap(*q)
 }
 
+func (q *FolderQuota) ToMap() map[string]int64 {
+	return quotaToMap(*q)
+}
+
+func (q *OrgRateQuota) ToMap() map[string]int64 {
+	return quotaToMap(*q)
+}
+
 func quotaToMap(q interface{}) map[string]int64 {
 	qMap := make(map[string]int64)
 	typ := reflect.TypeOf(q)
@@ -55,10 +76,13 @@ func quotaToMap(q interface{}) map[string]int64 {
 }
 
 type QuotaSettings struct {
-	Enabled bool
-	Org     *OrgQuota
-	User    *UserQuota
-	Global  *GlobalQuota
+	Enabled    bool
+	Org        *OrgQuota
+	User       *UserQuota
+	Global     *GlobalQuota
+	Folder     *FolderQuota
+	OrgRate    *OrgRateQuota
+	RateWindow time.Duration
 }
 
 func (cfg *Cfg) readQuotaSettings() {
@@ -81,6 +105,21 @@ func (cfg *Cfg) readQuotaSettings() {
 		AlertRule:  alertOrgQuota,
 	}
 
+	// per Folder limits
+	Quota.Folder = &FolderQuota{
+		Dashboard: quota.Key("folder_dashboard").MustInt64(-1),
+	}
+
+	// per ORG rate limits, counted within the rate window
+	Quota.RateWindow = quota.Key("rate_window").MustDuration(time.Minute)
+	if Quota.RateWindow <= 0 {
+		Quota.RateWindow = time.Minute
+	}
+	Quota.OrgRate = &OrgRateQuota{
+		DataSourceQuery: quota.Key("org_data_source_query_rate").MustInt64(-1),
+		ApiRequest:      quota.Key("org_api_request_rate").MustInt64(-1),
+	}
+
 	// per User limits
 	Quota.User = &UserQuota{
 		Org: quota.Key("user_org").MustInt64(10),
@@ -0,0 +1,90 @@
+package setting
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	RateLimitStorageRemoteCache = "remote_cache"
+	RateLimitStorageLocal       = "local"
+
+	// RateLimitRoutesAPI matches every request to the HTTP API.
+	RateLimitRoutesAPI = "api"
+	// RateLimitRoutesDatasourceProxy matches the data source proxy and resource requests.
+	RateLimitRoutesDatasourceProxy = "datasource_proxy"
+	// RateLimitRoutesQuery matches the data source query requests.
+	RateLimitRoutesQuery = "query"
+
+	RateLimitKeyUser   = "user"
+	RateLimitKeyAPIKey = "api_key"
+	RateLimitKeyOrg    = "org"
+)
+
+type RateLimitSettings struct {
+	Enabled bool
+	// Storage is where the buckets are kept, either "remote_cache" to share
+	// them between the Grafana instances or "local".
+	Storage  string
+	Policies []RateLimitPolicy
+}
+
+// RateLimitPolicy limits the requests to a group of routes for each user,
+// API key or organization. It is read from a [rate_limit.<name>] section.
+type RateLimitPolicy struct {
+	Name   string
+	Routes string
+	Key    string
+	RPS    float64
+	Burst  int
+}
+
+func (cfg *Cfg) readRateLimitSettings() error {
+	section := cfg.Raw.Section("rate_limit")
+	cfg.RateLimit.Enabled = section.Key("enabled").MustBool(false)
+	cfg.RateLimit.Storage = valueAsString(section, "storage", RateLimitStorageRemoteCache)
+	cfg.RateLimit.Policies = nil
+
+	switch cfg.RateLimit.Storage {
+	case RateLimitStorageRemoteCache, RateLimitStorageLocal:
+	default:
+		return fmt.Errorf("invalid rate limit storage %q", cfg.RateLimit.Storage)
+	}
+
+	for _, s := range cfg.Raw.Sections() {
+		name := strings.TrimPrefix(s.Name(), "rate_limit.")
+		if name == s.Name() || name == "" {
+			continue
+		}
+
+		policy := RateLimitPolicy{
+			Name:   name,
+			Routes: valueAsString(s, "routes", RateLimitRoutesAPI),
+			Key:    valueAsString(s, "key", RateLimitKeyUser),
+			RPS:    s.Key("requests_per_second").MustFloat64(0),
+		}
+		policy.Burst = s.Key("burst").MustInt(int(math.Max(1, math.Ceil(policy.RPS))))
+
+		switch policy.Routes {
+		case RateLimitRoutesAPI, RateLimitRoutesDatasourceProxy, RateLimitRoutesQuery:
+		default:
+			return fmt.Errorf("rate limit policy %q: invalid routes %q", name, policy.Routes)
+		}
+		switch policy.Key {
+		case RateLimitKeyUser, RateLimitKeyAPIKey, RateLimitKeyOrg:
+		default:
+			return fmt.Errorf("rate limit policy %q: invalid key %q", name, policy.Key)
+		}
+		if policy.RPS <= 0 {
+			return fmt.Errorf("rate limit policy %q: requests_per_second must be greater than 0", name)
+		}
+		if policy.Burst < 1 {
+			return fmt.Errorf("rate limit policy %q: burst must be at least 1", name)
+		}
+
+		cfg.RateLimit.Policies = append(cfg.RateLimit.Policies, policy)
+	}
+
+	return nil
+}
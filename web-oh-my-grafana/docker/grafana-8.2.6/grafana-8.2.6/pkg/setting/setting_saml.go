@@ -0,0 +1,76 @@
+package setting
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type SAMLSettings struct {
+	Enabled           bool
+	SingleLogout      bool
+	AllowIdPInitiated bool
+	AllowSignUp       bool
+	RelayState        string
+
+	// The certificate, private key and IdP metadata are either base64-encoded
+	// values, paths or, for the metadata, a URL. Only one form may be set.
+	Certificate        string
+	CertificatePath    string
+	PrivateKey         string
+	PrivateKeyPath     string
+	SignatureAlgorithm string
+	IdPMetadata        string
+	IdPMetadataPath    string
+	IdPMetadataURL     string
+
+	MaxIssueDelay         time.Duration
+	MetadataValidDuration time.Duration
+
+	AttributeName   string
+	AttributeLogin  string
+	AttributeEmail  string
+	AttributeGroups string
+	AttributeRole   string
+	AttributeOrg    string
+
+	AllowedOrganizations   []string
+	OrgMapping             []string
+	RoleValuesEditor       []string
+	RoleValuesAdmin        []string
+	RoleValuesGrafanaAdmin []string
+}
+
+func (cfg *Cfg) readSAMLSettings() {
+	section := cfg.Raw.Section("auth.saml")
+	cfg.SAML.Enabled = section.Key("enabled").MustBool(false)
+	cfg.SAML.SingleLogout = section.Key("single_logout").MustBool(false)
+	cfg.SAML.AllowIdPInitiated = section.Key("allow_idp_initiated").MustBool(false)
+	cfg.SAML.AllowSignUp = section.Key("allow_sign_up").MustBool(true)
+	cfg.SAML.RelayState = valueAsString(section, "relay_state", "")
+
+	cfg.SAML.Certificate = valueAsString(section, "certificate", "")
+	cfg.SAML.CertificatePath = valueAsString(section, "certificate_path", "")
+	cfg.SAML.PrivateKey = valueAsString(section, "private_key", "")
+	cfg.SAML.PrivateKeyPath = valueAsString(section, "private_key_path", "")
+	cfg.SAML.SignatureAlgorithm = valueAsString(section, "signature_algorithm", "")
+	cfg.SAML.IdPMetadata = valueAsString(section, "idp_metadata", "")
+	cfg.SAML.IdPMetadataPath = valueAsString(section, "idp_metadata_path", "")
+	cfg.SAML.IdPMetadataURL = valueAsString(section, "idp_metadata_url", "")
+
+	cfg.SAML.MaxIssueDelay = section.Key("max_issue_delay").MustDuration(90 * time.Second)
+	cfg.SAML.MetadataValidDuration = section.Key("metadata_valid_duration").MustDuration(48 * time.Hour)
+
+	cfg.SAML.AttributeName = valueAsString(section, "assertion_attribute_name", "displayName")
+	cfg.SAML.AttributeLogin = valueAsString(section, "assertion_attribute_login", "mail")
+	cfg.SAML.AttributeEmail = valueAsString(section, "assertion_attribute_email", "mail")
+	cfg.SAML.AttributeGroups = valueAsString(section, "assertion_attribute_groups", "")
+	cfg.SAML.AttributeRole = valueAsString(section, "assertion_attribute_role", "")
+	cfg.SAML.AttributeOrg = valueAsString(section, "assertion_attribute_org", "")
+
+	cfg.SAML.AllowedOrganizations = util.SplitString(valueAsString(section, "allowed_organizations", ""))
+	cfg.SAML.OrgMapping = util.SplitString(valueAsString(section, "org_mapping", ""))
+	cfg.SAML.RoleValuesEditor = util.SplitString(valueAsString(section, "role_values_editor", ""))
+	cfg.SAML.RoleValuesAdmin = util.SplitString(valueAsString(section, "role_values_admin", ""))
+	cfg.SAML.RoleValuesGrafanaAdmin = util.SplitString(valueAsString(section, "role_values_grafana_admin", ""))
+}
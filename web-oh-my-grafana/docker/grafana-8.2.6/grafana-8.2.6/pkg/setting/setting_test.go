@@ -392,6 +392,143 @@ func TestAuthDurationSettings(t *testing.T) {
 	require.Equal(t, maxLifetimeDurationTest, cfg.LoginMaxLifetime)
 }
 
+func TestJWTOrgMappingSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	sec, err := f.NewSection("auth.jwt")
+	require.NoError(t, err)
+	_, err = sec.NewKey("org_mapping", "engineering:2, engineering:3 sales:4")
+	require.NoError(t, err)
+	err = readAuthSettings(f, cfg)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]int64{"engineering": {2, 3}, "sales": {4}}, cfg.JWTAuthOrgMapping)
+
+	sec.Key("org_mapping").SetValue("engineering")
+	err = readAuthSettings(f, cfg)
+	require.Error(t, err)
+
+	sec.Key("org_mapping").SetValue("engineering:main")
+	err = readAuthSettings(f, cfg)
+	require.Error(t, err)
+}
+
+func TestRateLimitSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	cfg.Raw = f
+	_, err := f.Section("rate_limit").NewKey("enabled", "true")
+	require.NoError(t, err)
+	sec, err := f.NewSection("rate_limit.proxy")
+	require.NoError(t, err)
+	_, err = sec.NewKey("routes", "datasource_proxy")
+	require.NoError(t, err)
+	_, err = sec.NewKey("key", "api_key")
+	require.NoError(t, err)
+	_, err = sec.NewKey("requests_per_second", "2.5")
+	require.NoError(t, err)
+
+	err = cfg.readRateLimitSettings()
+	require.NoError(t, err)
+	require.True(t, cfg.RateLimit.Enabled)
+	require.Equal(t, RateLimitStorageRemoteCache, cfg.RateLimit.Storage)
+	require.Equal(t, []RateLimitPolicy{
+		{Name: "proxy", Routes: "datasource_proxy", Key: "api_key", RPS: 2.5, Burst: 3},
+	}, cfg.RateLimit.Policies)
+
+	sec.Key("key").SetValue("team")
+	err = cfg.readRateLimitSettings()
+	require.Error(t, err)
+
+	sec.Key("key").SetValue("user")
+	sec.Key("requests_per_second").SetValue("0")
+	err = cfg.readRateLimitSettings()
+	require.Error(t, err)
+}
+
+func TestPasswordPolicySettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	cfg.Raw = f
+	sec, err := f.NewSection("password_policy")
+	require.NoError(t, err)
+	_, err = sec.NewKey("max_age", "90d")
+	require.NoError(t, err)
+	_, err = sec.NewKey("history_count", "3")
+	require.NoError(t, err)
+
+	err = cfg.readPasswordPolicySettings()
+	require.NoError(t, err)
+	require.Equal(t, 90*24*time.Hour, cfg.PasswordPolicy.MaxAge)
+	require.Equal(t, 3, cfg.PasswordPolicy.HistoryCount)
+	require.Equal(t, 12, cfg.PasswordPolicy.MinLength)
+
+	sec.Key("history_count").SetValue("25")
+	err = cfg.readPasswordPolicySettings()
+	require.Error(t, err)
+}
+
+func TestQueryCacheSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	cfg.Raw = f
+	sec, err := f.NewSection("query_cache")
+	require.NoError(t, err)
+	_, err = sec.NewKey("enabled", "true")
+	require.NoError(t, err)
+
+	err = cfg.readQueryCacheSettings()
+	require.NoError(t, err)
+	require.True(t, cfg.QueryCache.Enabled)
+	require.Equal(t, QueryCacheStorageRemoteCache, cfg.QueryCache.Storage)
+	require.Equal(t, time.Minute, cfg.QueryCache.DefaultTTL)
+	require.Equal(t, 1024*1024, cfg.QueryCache.MaxEntrySize)
+
+	_, err = sec.NewKey("default_ttl", "500ms")
+	require.NoError(t, err)
+	err = cfg.readQueryCacheSettings()
+	require.Error(t, err)
+
+	sec.Key("default_ttl").SetValue("5m")
+	_, err = sec.NewKey("storage", "memcached")
+	require.NoError(t, err)
+	err = cfg.readQueryCacheSettings()
+	require.Error(t, err)
+}
+
+func TestQueryLimitsSettings(t *testing.T) {
+	f := ini.Empty()
+	cfg := NewCfg()
+	cfg.Raw = f
+
+	err := cfg.readQueryLimitsSettings()
+	require.NoError(t, err)
+	require.Equal(t, QueryLimitsSettings{}, cfg.QueryLimits)
+
+	sec, err := f.NewSection("query_limits")
+	require.NoError(t, err)
+	_, err = sec.NewKey("max_concurrent_queries", "5")
+	require.NoError(t, err)
+	_, err = sec.NewKey("query_timeout", "30s")
+	require.NoError(t, err)
+	_, err = sec.NewKey("max_rows", "100000")
+	require.NoError(t, err)
+	_, err = sec.NewKey("max_response_size_kb", "2048")
+	require.NoError(t, err)
+
+	err = cfg.readQueryLimitsSettings()
+	require.NoError(t, err)
+	require.Equal(t, QueryLimitsSettings{
+		MaxConcurrentQueries: 5,
+		Timeout:              30 * time.Second,
+		MaxRows:              100000,
+		MaxResponseSize:      2048 * 1024,
+	}, cfg.QueryLimits)
+
+	sec.Key("max_rows").SetValue("-1")
+	err = cfg.readQueryLimitsSettings()
+	require.Error(t, err)
+}
+
 func TestGetCDNPath(t *testing.T) {
 	var err error
 	cfg := NewCfg()
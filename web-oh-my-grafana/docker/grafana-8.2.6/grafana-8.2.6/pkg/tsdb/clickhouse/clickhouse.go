@@ -0,0 +1,190 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+var logger = log.New("tsdb.clickhouse")
+
+type Service struct {
+	Cfg *setting.Cfg
+	im  instancemgmt.InstanceManager
+}
+
+func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager, httpClientProvider httpclient.Provider) (*Service, error) {
+	s := &Service{
+		Cfg: cfg,
+		im:  datasource.NewInstanceManager(newInstanceSettings(cfg, httpClientProvider)),
+	}
+	factory := coreplugin.New(backend.ServeOpts{
+		QueryDataHandler:    s,
+		CallResourceHandler: sqleng.NewResourceHandler(s.getDataSourceHandler),
+	})
+
+	if err := manager.Register("clickhouse", factory); err != nil {
+		logger.Error("Failed to register plugin", "error", err)
+	}
+	return s, nil
+}
+
+func newInstanceSettings(cfg *setting.Cfg, httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			User:                    settings.User,
+			Database:                settings.Database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		// The user and password of the data source are sent with the
+		// basic authentication of the HTTP client.
+		opts, err := settings.HTTPClientOptions()
+		if err != nil {
+			return nil, err
+		}
+		client, err := httpClientProvider.New(opts)
+		if err != nil {
+			return nil, err
+		}
+		clientName := fmt.Sprintf("ds%d", settings.ID)
+		RegisterHTTPClient(clientName, client)
+
+		cnnstr, err := generateConnectionString(dsInfo, clientName)
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.Env == setting.Dev {
+			logger.Debug("getEngine", "connection", cnnstr)
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        driverName,
+			ConnectionString:  cnnstr,
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"String", "FixedString", "Enum8", "Enum16"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		queryResultTransformer := clickHouseQueryResultTransformer{
+			log: logger,
+		}
+
+		return sqleng.NewQueryDataHandler(config, &queryResultTransformer, newClickHouseMacroEngine(), logger)
+	}
+}
+
+// generateConnectionString returns the URL of the HTTP interface of a
+// data source, given with or without a scheme.
+func generateConnectionString(dsInfo sqleng.DataSourceInfo, clientName string) (string, error) {
+	location := strings.TrimSpace(dsInfo.URL)
+	if location == "" {
+		return "", errors.New("missing URL of the ClickHouse server")
+	}
+	if !strings.Contains(location, "://") {
+		location = "http://" + location
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL of the ClickHouse server: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "tcp", "clickhouse":
+		return "", errors.New("the native protocol of ClickHouse is not supported, use the URL of its HTTP interface")
+	default:
+		return "", fmt.Errorf("unsupported scheme %q of the ClickHouse server URL", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.New("missing host of the ClickHouse server URL")
+	}
+
+	query := u.Query()
+	if dsInfo.Database != "" {
+		query.Set("database", dsInfo.Database)
+	}
+	query.Set("client", clientName)
+	u.User = nil
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+func (s *Service) getDataSourceHandler(pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// The ClickHouse error codes of the failed authentications.
+var authErrorCodes = map[int]bool{
+	192: true, // UNKNOWN_USER
+	193: true, // WRONG_PASSWORD
+	194: true, // REQUIRED_PASSWORD
+	516: true, // AUTHENTICATION_FAILED
+}
+
+type clickHouseQueryResultTransformer struct {
+	log log.Logger
+}
+
+func (t *clickHouseQueryResultTransformer) TransformQueryError(err error) error {
+	var driverErr *Error
+	if errors.As(err, &driverErr) && (authErrorCodes[driverErr.Code] || driverErr.Code == 0) {
+		t.log.Error("query error", "err", err)
+		return errQueryFailed
+	}
+
+	return err
+}
+
+var errQueryFailed = errors.New("query failed - please inspect Grafana server log for details")
+
+func (t *clickHouseQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	// The ClickHouse driver returns the values with the scan types of
+	// their columns, so there's no string to convert.
+	return nil
+}
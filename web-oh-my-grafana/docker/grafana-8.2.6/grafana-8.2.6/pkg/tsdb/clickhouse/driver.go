@@ -0,0 +1,404 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"xorm.io/core"
+)
+
+// driverName is the database/sql driver of the ClickHouse data sources,
+// running the queries through the HTTP interface of ClickHouse.
+//
+// Its data source names are the URLs of the HTTP interface, with the
+// database in the database parameter, the client parameter naming an HTTP
+// client registered with RegisterHTTPClient and the user and password of
+// the ClickHouse user, if any.
+const driverName = "clickhouse"
+
+// maxErrorSize is the size of the response bodies read for the errors.
+const maxErrorSize = 64 * 1024
+
+func init() {
+	sql.Register(driverName, &clickHouseDriver{})
+	core.RegisterDriver(driverName, &xormDriver{})
+}
+
+// xormDriver lets xorm open engines with the ClickHouse driver. xorm has no
+// ClickHouse dialect, the MySQL one is the closest and is only used for the
+// SQL that xorm generates itself, which the data sources don't run.
+type xormDriver struct{}
+
+func (d *xormDriver) Parse(driverName, dataSourceName string) (*core.Uri, error) {
+	u, err := url.Parse(dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	return &core.Uri{DbType: core.MYSQL, Host: u.Host, DbName: u.Query().Get("database")}, nil
+}
+
+var httpClients sync.Map
+
+// RegisterHTTPClient registers an HTTP client for the connections of the
+// data source names with the client parameter set to name.
+func RegisterHTTPClient(name string, client *http.Client) {
+	httpClients.Store(name, client)
+}
+
+// Error is an error returned by ClickHouse.
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+var errorCodeRegExp = regexp.MustCompile(`^Code: (\d+)`)
+
+func newError(status int, body []byte) error {
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		message = fmt.Sprintf("request failed with status %d", status)
+	}
+	e := &Error{Message: message}
+	if m := errorCodeRegExp.FindStringSubmatch(message); m != nil {
+		e.Code, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+type clickHouseDriver struct{}
+
+func (d *clickHouseDriver) Open(dsn string) (driver.Conn, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ClickHouse URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported ClickHouse protocol %q, only the HTTP interface is supported", u.Scheme)
+	}
+
+	c := &clickHouseConn{client: http.DefaultClient}
+	query := u.Query()
+	if name := query.Get("client"); name != "" {
+		client, ok := httpClients.Load(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown HTTP client %q", name)
+		}
+		c.client = client.(*http.Client)
+		query.Del("client")
+	}
+	if u.User != nil {
+		c.user = u.User.Username()
+		c.password, _ = u.User.Password()
+		u.User = nil
+	}
+	u.RawQuery = query.Encode()
+	c.url = u.String()
+	return c, nil
+}
+
+type clickHouseConn struct {
+	client   *http.Client
+	url      string
+	user     string
+	password string
+}
+
+var errNotSupported = errors.New("clickhouse: not supported by the HTTP interface driver")
+
+func (c *clickHouseConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errNotSupported
+}
+
+func (c *clickHouseConn) Begin() (driver.Tx, error) {
+	return nil, errNotSupported
+}
+
+func (c *clickHouseConn) Close() error {
+	return nil
+}
+
+func (c *clickHouseConn) Ping(ctx context.Context) error {
+	rows, err := c.QueryContext(ctx, "SELECT 1", nil)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// jsonCompactResult is a result in the JSONCompact format.
+type jsonCompactResult struct {
+	Meta []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"meta"`
+	Data [][]json.RawMessage `json:"data"`
+}
+
+func (c *clickHouseConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		return nil, errNotSupported
+	}
+
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	body := bytes.NewBufferString(query)
+	body.WriteString("\nFORMAT JSONCompact")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if c.user != "" {
+		req.Header.Set("X-ClickHouse-User", c.user)
+		req.Header.Set("X-ClickHouse-Key", c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+		return nil, newError(resp.StatusCode, body)
+	}
+
+	var result jsonCompactResult
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		// ClickHouse reports the errors happening after the first rows were
+		// sent at the end of the response.
+		if i := bytes.LastIndex(respBody, []byte("Code: ")); i >= 0 {
+			return nil, newError(resp.StatusCode, respBody[i:])
+		}
+		return nil, fmt.Errorf("failed to read the ClickHouse response: %w", err)
+	}
+
+	location := time.UTC
+	if tz := resp.Header.Get("X-ClickHouse-Timezone"); tz != "" {
+		if loc, err := time.LoadLocation(tz); err == nil {
+			location = loc
+		}
+	}
+
+	rows := &clickHouseRows{data: result.Data}
+	for _, col := range result.Meta {
+		rows.columns = append(rows.columns, parseColumnType(col.Name, col.Type, location))
+	}
+	return rows, nil
+}
+
+type columnType struct {
+	name     string
+	typeName string
+	nullable bool
+	scanType reflect.Type
+	location *time.Location
+}
+
+var (
+	scanTypeInt64   = reflect.TypeOf(int64(0))
+	scanTypeFloat64 = reflect.TypeOf(float64(0))
+	scanTypeString  = reflect.TypeOf("")
+	scanTypeBool    = reflect.TypeOf(false)
+	scanTypeTime    = reflect.TypeOf(time.Time{})
+)
+
+// parseColumnType returns the type of a column from its ClickHouse type,
+// such as Nullable(DateTime('Europe/Berlin')).
+func parseColumnType(name string, chType string, location *time.Location) columnType {
+	col := columnType{name: name, location: location}
+	for {
+		switch {
+		case strings.HasPrefix(chType, "Nullable(") && strings.HasSuffix(chType, ")"):
+			col.nullable = true
+			chType = chType[len("Nullable(") : len(chType)-1]
+			continue
+		case strings.HasPrefix(chType, "LowCardinality(") && strings.HasSuffix(chType, ")"):
+			chType = chType[len("LowCardinality(") : len(chType)-1]
+			continue
+		}
+		break
+	}
+
+	col.typeName = chType
+	params := ""
+	if i := strings.Index(chType, "("); i >= 0 && strings.HasSuffix(chType, ")") {
+		col.typeName = chType[:i]
+		params = chType[i+1 : len(chType)-1]
+	}
+
+	switch col.typeName {
+	case "Int8", "Int16", "Int32", "Int64", "UInt8", "UInt16", "UInt32", "UInt64":
+		col.scanType = scanTypeInt64
+	case "Float32", "Float64", "Decimal", "Decimal32", "Decimal64", "Decimal128", "Decimal256":
+		col.scanType = scanTypeFloat64
+	case "Bool", "Boolean":
+		col.scanType = scanTypeBool
+	case "Date", "Date32":
+		col.scanType = scanTypeTime
+		col.location = time.UTC
+	case "DateTime", "DateTime32", "DateTime64":
+		col.scanType = scanTypeTime
+		// The time zone is the last parameter, after the precision of the
+		// DateTime64 values.
+		if i := strings.LastIndex(params, "'"); i > 0 {
+			if j := strings.LastIndex(params[:i], "'"); j >= 0 {
+				if loc, err := time.LoadLocation(params[j+1 : i]); err == nil {
+					col.location = loc
+				}
+			}
+		}
+	default:
+		// Strings, enums, UUIDs, IP addresses, large integers and the
+		// composite types, as JSON.
+		col.scanType = scanTypeString
+	}
+	return col
+}
+
+type clickHouseRows struct {
+	columns []columnType
+	data    [][]json.RawMessage
+	next    int
+}
+
+func (r *clickHouseRows) Columns() []string {
+	names := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+func (r *clickHouseRows) Close() error {
+	r.data = nil
+	return nil
+}
+
+func (r *clickHouseRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.data) {
+		return io.EOF
+	}
+	row := r.data[r.next]
+	r.next++
+
+	for i, col := range r.columns {
+		if i >= len(row) {
+			dest[i] = nil
+			continue
+		}
+		value, err := col.convert(row[i])
+		if err != nil {
+			return fmt.Errorf("column %q: %w", col.name, err)
+		}
+		dest[i] = value
+	}
+	return nil
+}
+
+func (r *clickHouseRows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.columns[i].typeName
+}
+
+func (r *clickHouseRows) ColumnTypeScanType(i int) reflect.Type {
+	return r.columns[i].scanType
+}
+
+func (r *clickHouseRows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	return r.columns[i].nullable, true
+}
+
+var timeLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// convert returns the value of a column in a JSONCompact row. The 64 bit
+// integers and the decimals may be quoted, depending on the ClickHouse
+// settings.
+func (col columnType) convert(raw json.RawMessage) (driver.Value, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	if col.scanType == scanTypeString {
+		if raw[0] != '"' {
+			return string(raw), nil
+		}
+		var s string
+		err := json.Unmarshal(raw, &s)
+		return s, err
+	}
+
+	text := string(raw)
+	if raw[0] == '"' {
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, err
+		}
+	}
+
+	switch col.scanType {
+	case scanTypeInt64:
+		if col.typeName == "UInt64" {
+			v, err := strconv.ParseUint(text, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if v > math.MaxInt64 {
+				return nil, fmt.Errorf("value %s out of range", text)
+			}
+			return int64(v), nil
+		}
+		return strconv.ParseInt(text, 10, 64)
+	case scanTypeFloat64:
+		return strconv.ParseFloat(text, 64)
+	case scanTypeBool:
+		switch text {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %s", text)
+	case scanTypeTime:
+		if strings.HasPrefix(col.typeName, "DateTime") {
+			// Unix timestamps, with the date_time_output_format setting.
+			if seconds, err := strconv.ParseFloat(text, 64); err == nil {
+				return time.Unix(0, int64(seconds*float64(time.Second))).In(col.location), nil
+			}
+		}
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, text, col.location); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %s", text)
+	}
+	return text, nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/setting"
 	"golang.org/x/oauth2/google"
 )
@@ -66,11 +67,13 @@ const (
 	perSeriesAlignerDefault   string = "ALIGN_MEAN"
 )
 
-func ProvideService(cfg *setting.Cfg, pluginManager plugins.Manager, httpClientProvider httpclient.Provider) *Service {
+func ProvideService(cfg *setting.Cfg, pluginManager plugins.Manager, httpClientProvider httpclient.Provider,
+	encryptionService encryption.Service) *Service {
 	return &Service{
 		PluginManager:      pluginManager,
 		HTTPClientProvider: httpClientProvider,
 		Cfg:                cfg,
+		EncryptionService:  encryptionService,
 	}
 }
 
@@ -78,29 +81,32 @@ type Service struct {
 	PluginManager      plugins.Manager
 	HTTPClientProvider httpclient.Provider
 	Cfg                *setting.Cfg
+	EncryptionService  encryption.Service
 }
 
 // Executor executes queries for the CloudMonitoring datasource.
 type Executor struct {
-	httpClient    *http.Client
-	dsInfo        *models.DataSource
-	pluginManager plugins.Manager
-	cfg           *setting.Cfg
+	httpClient        *http.Client
+	dsInfo            *models.DataSource
+	pluginManager     plugins.Manager
+	cfg               *setting.Cfg
+	encryptionService encryption.Service
 }
 
 // NewExecutor returns an Executor.
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *Service) NewExecutor(dsInfo *models.DataSource) (plugins.DataPlugin, error) {
-	httpClient, err := dsInfo.GetHTTPClient(s.HTTPClientProvider)
+	httpClient, err := dsInfo.GetHTTPClient(s.HTTPClientProvider, s.EncryptionService)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Executor{
-		httpClient:    httpClient,
-		dsInfo:        dsInfo,
-		pluginManager: s.PluginManager,
-		cfg:           s.Cfg,
+		httpClient:        httpClient,
+		dsInfo:            dsInfo,
+		pluginManager:     s.PluginManager,
+		cfg:               s.Cfg,
+		encryptionService: s.EncryptionService,
 	}, nil
 }
 
@@ -548,7 +554,7 @@ func (e *Executor) createRequest(ctx context.Context, dsInfo *models.DataSource,
 		}
 	}
 
-	pluginproxy.ApplyRoute(ctx, req, proxyPass, cloudMonitoringRoute, dsInfo, e.cfg)
+	pluginproxy.ApplyRoute(ctx, req, proxyPass, cloudMonitoringRoute, dsInfo, e.cfg, e.encryptionService)
 
 	return req, nil
 }
@@ -9,13 +9,15 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/adapters"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 )
 
 // nolint:staticcheck // plugins.DataQuery deprecated
-func dataPluginQueryAdapter(pluginID string, handler backend.QueryDataHandler, oAuthService oauthtoken.OAuthTokenService) plugins.DataPluginFunc {
+func dataPluginQueryAdapter(pluginID string, handler backend.QueryDataHandler, oAuthService oauthtoken.OAuthTokenService,
+	encryptionService encryption.Service) plugins.DataPluginFunc {
 	return plugins.DataPluginFunc(func(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
-		instanceSettings, err := modelToInstanceSettings(ds)
+		instanceSettings, err := modelToInstanceSettings(ds, encryptionService)
 		if err != nil {
 			return plugins.DataResponse{}, err
 		}
@@ -93,7 +95,7 @@ func dataPluginQueryAdapter(pluginID string, handler backend.QueryDataHandler, o
 	})
 }
 
-func modelToInstanceSettings(ds *models.DataSource) (*backend.DataSourceInstanceSettings, error) {
+func modelToInstanceSettings(ds *models.DataSource, encryptionService encryption.Service) (*backend.DataSourceInstanceSettings, error) {
 	jsonDataBytes, err := ds.JsonData.MarshalJSON()
 	if err != nil {
 		return nil, err
@@ -108,7 +110,7 @@ func modelToInstanceSettings(ds *models.DataSource) (*backend.DataSourceInstance
 		BasicAuthEnabled:        ds.BasicAuth,
 		BasicAuthUser:           ds.BasicAuthUser,
 		JSONData:                jsonDataBytes,
-		DecryptedSecureJSONData: ds.DecryptedValues(),
+		DecryptedSecureJSONData: ds.DecryptedValues(encryptionService),
 		Updated:                 ds.Updated,
 		UID:                     ds.Uid,
 	}, nil
@@ -13,7 +13,7 @@ type SearchRequestBuilder struct {
 	interval     intervalv2.Interval
 	index        string
 	size         int
-	sort         map[string]interface{}
+	sort         []map[string]interface{}
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -24,7 +24,7 @@ func NewSearchRequestBuilder(version *semver.Version, interval intervalv2.Interv
 	builder := &SearchRequestBuilder{
 		version:     version,
 		interval:    interval,
-		sort:        make(map[string]interface{}),
+		sort:        make([]map[string]interface{}, 0),
 		customProps: make(map[string]interface{}),
 		aggBuilders: make([]AggBuilder, 0),
 	}
@@ -70,18 +70,30 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 	return b
 }
 
-// SortDesc adds a sort to the search request
+// SortDesc adds a descending sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
+	return b.Sort(SortOrderDesc, field, unmappedType)
+}
+
+// Sort adds a sort to the search request, after the sorts already added
+func (b *SearchRequestBuilder) Sort(order SortOrder, field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
-		"order": "desc",
+		"order": string(order),
 	}
 
 	if unmappedType != "" {
 		props["unmapped_type"] = unmappedType
 	}
 
-	b.sort[field] = props
+	b.sort = append(b.sort, map[string]interface{}{field: props})
+
+	return b
+}
 
+// SearchAfter sets the sort values of the document after which the search
+// request returns documents
+func (b *SearchRequestBuilder) SearchAfter(values []interface{}) *SearchRequestBuilder {
+	b.customProps["search_after"] = values
 	return b
 }
 
@@ -58,7 +58,8 @@ func TestSearchRequest(t *testing.T) {
 					})
 
 					Convey("Should have correct sorting", func() {
-						sort, ok := sr.Sort[timeField].(map[string]string)
+						So(sr.Sort, ShouldHaveLength, 1)
+						sort, ok := sr.Sort[0][timeField].(map[string]string)
 						So(ok, ShouldBeTrue)
 						So(sort["order"], ShouldEqual, "desc")
 						So(sort["unmapped_type"], ShouldEqual, "boolean")
@@ -86,7 +87,7 @@ func TestSearchRequest(t *testing.T) {
 						So(err, ShouldBeNil)
 						So(json.Get("size").MustInt(0), ShouldEqual, 200)
 
-						sort := json.GetPath("sort", timeField)
+						sort := json.Get("sort").GetIndex(0).Get(timeField)
 						So(sort.Get("order").MustString(), ShouldEqual, "desc")
 						So(sort.Get("unmapped_type").MustString(), ShouldEqual, "boolean")
 
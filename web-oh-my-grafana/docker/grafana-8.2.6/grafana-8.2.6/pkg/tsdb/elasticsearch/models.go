@@ -54,6 +54,8 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 	"rate":           "Rate",
 }
 
@@ -87,6 +89,12 @@ var scriptableAggType = map[string]string{
 	"bucket_script":  "bucket_script",
 }
 
+var documentQueryType = map[string]string{
+	rawDocumentType: rawDocumentType,
+	rawDataType:     rawDataType,
+	logsType:        logsType,
+}
+
 var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
@@ -112,6 +120,17 @@ func isPipelineAggWithMultipleBucketPaths(metricType string) bool {
 	return false
 }
 
+// isDocumentQuery returns whether a query returns the documents matching it
+// instead of aggregations, which is the case when its first metric is a
+// raw_document, raw_data or logs metric.
+func isDocumentQuery(q *Query) bool {
+	if len(q.Metrics) == 0 {
+		return false
+	}
+	_, ok := documentQueryType[q.Metrics[0].Type]
+	return ok
+}
+
 func describeMetric(metricType, field string) string {
 	text := metricAggType[metricType]
 	if metricType == countType {
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
 	"regexp"
 	"sort"
@@ -20,6 +21,11 @@ const (
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
 	topMetricsType    = "top_metrics"
+	rateType          = "rate"
+	// Document types
+	rawDocumentType = "raw_document"
+	rawDataType     = "raw_data"
+	logsType        = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -75,18 +81,23 @@ func (rp *responseParser) getTimeSeries() (*backend.QueryDataResponse, error) {
 
 		queryRes := backend.DataResponse{}
 
-		props := make(map[string]string)
-		err := rp.processBuckets(res.Aggregations, target, &queryRes, props, 0)
-		if err != nil {
-			return &backend.QueryDataResponse{}, err
+		if isDocumentQuery(target) {
+			queryRes.Frames = rp.processDocuments(res, target)
+		} else {
+			props := make(map[string]string)
+			err := rp.processBuckets(res.Aggregations, target, &queryRes, props, 0)
+			if err != nil {
+				return &backend.QueryDataResponse{}, err
+			}
+			rp.nameFields(queryRes, target)
+			rp.trimDatapoints(queryRes, target)
 		}
-		rp.nameFields(queryRes, target)
-		rp.trimDatapoints(queryRes, target)
 
 		for _, frame := range queryRes.Frames {
-			frame.Meta = &data.FrameMeta{
-				Custom: debugInfo,
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
 			}
+			frame.Meta.Custom = debugInfo
 		}
 		result.Responses[target.RefID] = queryRes
 	}
@@ -260,21 +271,7 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 					key := castToFloat(bucket.Get("key"))
 
 					timeVector = append(timeVector, time.Unix(int64(*key)/1000, 0).UTC())
-
-					for _, stat := range stats.MustArray() {
-						stat := stat.(map[string]interface{})
-
-						metrics, hasMetrics := stat["metrics"]
-						if hasMetrics {
-							metrics := metrics.(map[string]interface{})
-							metricValue, hasMetricValue := metrics[metricField.(string)]
-
-							if hasMetricValue && metricValue != nil {
-								v := metricValue.(float64)
-								values = append(values, &v)
-							}
-						}
-					}
+					values = append(values, topMetricValue(stats, metricField.(string)))
 				}
 
 				frames = append(frames, data.NewFrame("",
@@ -446,6 +443,12 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 			switch metric.Type {
 			case countType:
 				addMetricValue(values, rp.getMetricName(metric.Type), castToFloat(bucket.Get("doc_count")))
+			case topMetricsType:
+				for _, metricField := range metric.Settings.Get("metrics").MustArray() {
+					field := metricField.(string)
+					addMetricValue(values, rp.getMetricName(metric.Type)+" "+field,
+						topMetricValue(bucket.GetPath(metric.ID, "top"), field))
+				}
 			case extendedStatsType:
 				metaKeys := make([]string, 0)
 				meta := metric.Meta.MustMap()
@@ -506,6 +509,177 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 	return nil
 }
 
+// processDocuments converts the hits of a document query to a frame with the
+// time field of the query, followed by the _id, _index, _type, when set, and
+// sort values of the documents and their _source fields flattened with dotted names.
+func (rp *responseParser) processDocuments(res *es.SearchResponse, target *Query) data.Frames {
+	isLogs := target.Metrics[0].Type == logsType
+
+	var hits []map[string]interface{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	docs := make([]map[string]interface{}, 0, len(hits))
+	propNames := make(map[string]struct{})
+	for _, hit := range hits {
+		doc := map[string]interface{}{
+			"_id":    hit["_id"],
+			"_index": hit["_index"],
+		}
+		// Types are removed from Elasticsearch 8.
+		if docType, ok := hit["_type"]; ok {
+			doc["_type"] = docType
+		}
+		if sort, ok := hit["sort"]; ok {
+			doc["sort"] = sort
+		}
+		if source, ok := hit["_source"].(map[string]interface{}); ok {
+			flattenDocument("", source, doc)
+			if isLogs {
+				doc["_source"] = source
+			}
+		}
+		if _, ok := doc[target.TimeField]; !ok {
+			if fields, ok := hit["fields"].(map[string]interface{}); ok {
+				doc[target.TimeField] = fields[target.TimeField]
+			}
+		}
+
+		for name := range doc {
+			propNames[name] = struct{}{}
+		}
+		docs = append(docs, doc)
+	}
+
+	timeVector := make([]*time.Time, 0, len(docs))
+	for _, doc := range docs {
+		timeVector = append(timeVector, parseDocumentTime(doc[target.TimeField]))
+	}
+	fields := []*data.Field{data.NewField(target.TimeField, nil, timeVector)}
+
+	names := make([]string, 0, len(propNames))
+	for name := range propNames {
+		if name != target.TimeField {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, newDocumentField(name, docs))
+	}
+
+	frame := data.NewFrame("", fields...)
+	if isLogs {
+		frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeLogs}
+	}
+	return data.Frames{frame}
+}
+
+// flattenDocument adds the values of doc to result, with the names of the
+// nested fields joined with dots.
+func flattenDocument(prefix string, doc map[string]interface{}, result map[string]interface{}) {
+	for name, value := range doc {
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenDocument(name, nested, result)
+			continue
+		}
+		result[name] = value
+	}
+}
+
+// newDocumentField returns a number or boolean field when all the values of
+// a document field have this type, or else a string field with the other
+// values encoded as JSON.
+func newDocumentField(name string, docs []map[string]interface{}) *data.Field {
+	isNumber, isBool := true, true
+	for _, doc := range docs {
+		switch doc[name].(type) {
+		case nil:
+		case float64:
+			isBool = false
+		case bool:
+			isNumber = false
+		default:
+			isNumber, isBool = false, false
+		}
+	}
+
+	switch {
+	case isNumber:
+		values := make([]*float64, 0, len(docs))
+		for _, doc := range docs {
+			var value *float64
+			if v, ok := doc[name].(float64); ok {
+				value = &v
+			}
+			values = append(values, value)
+		}
+		return data.NewField(name, nil, values)
+	case isBool:
+		values := make([]*bool, 0, len(docs))
+		for _, doc := range docs {
+			var value *bool
+			if v, ok := doc[name].(bool); ok {
+				value = &v
+			}
+			values = append(values, value)
+		}
+		return data.NewField(name, nil, values)
+	default:
+		values := make([]*string, 0, len(docs))
+		for _, doc := range docs {
+			var value *string
+			switch v := doc[name].(type) {
+			case nil:
+			case string:
+				value = &v
+			default:
+				if b, err := json.Marshal(v); err == nil {
+					s := string(b)
+					value = &s
+				}
+			}
+			values = append(values, value)
+		}
+		return data.NewField(name, nil, values)
+	}
+}
+
+// parseDocumentTime parses the time of a document, which is a date string or
+// a number of epoch milliseconds, or an array of them for the doc value
+// fields.
+func parseDocumentTime(v interface{}) *time.Time {
+	if values, ok := v.([]interface{}); ok {
+		if len(values) == 0 {
+			return nil
+		}
+		v = values[0]
+	}
+
+	var t time.Time
+	switch v := v.(type) {
+	case float64:
+		t = time.Unix(0, int64(v*float64(time.Millisecond)))
+	case string:
+		var err error
+		if t, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			ms, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil
+			}
+			t = time.Unix(0, int64(ms*float64(time.Millisecond)))
+		}
+	default:
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
 func extractDataField(name string, v interface{}) *data.Field {
 	switch v.(type) {
 	case *string:
@@ -680,6 +854,18 @@ func (rp *responseParser) getMetricName(metric string) string {
 	return metric
 }
 
+// topMetricValue returns the value of a field of the top document of a
+// top_metrics aggregation, or nil when the bucket has no documents.
+func topMetricValue(top *simplejson.Json, field string) *float64 {
+	for _, stat := range top.MustArray() {
+		metrics := simplejson.NewFromAny(stat).Get("metrics")
+		if value := castToFloat(metrics.Get(field)); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
 func castToFloat(j *simplejson.Json) *float64 {
 	f, err := j.Float64()
 	if err == nil {
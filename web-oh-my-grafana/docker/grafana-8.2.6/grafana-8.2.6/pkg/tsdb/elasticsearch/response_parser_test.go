@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -1059,6 +1060,174 @@ func TestResponseParser(t *testing.T) {
 		v, _ = frame.FloatAt(1, 1)
 		assert.Equal(t, 2., v)
 	})
+
+	t.Run("With top_metrics and missing values", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "top_metrics", "settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value"] }, "id": "1" }],
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "key": 1609459200000, "1": { "top": [] } },
+							{ "key": 1609459210000, "1": { "top": [{ "sort": ["2021-01-01T00:00:10.000Z"], "metrics": { "@value": 5 } }] } }
+						]
+					}
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		require.Equal(t, 2, frame.Fields[0].Len())
+		require.Equal(t, 2, frame.Fields[1].Len())
+		assert.Nil(t, frame.Fields[1].At(0))
+		v, _ := frame.FloatAt(1, 1)
+		assert.Equal(t, 5., v)
+	})
+
+	t.Run("With top_metrics in a table", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "top_metrics", "settings": { "order": "desc", "orderBy": "@timestamp", "metrics": ["@value"] }, "id": "1" }],
+				"bucketAggs": [{ "type": "terms", "field": "host", "id": "2" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"aggregations": {
+					"2": {
+						"buckets": [
+							{ "key": "server1", "1": { "top": [{ "sort": ["2021-01-01T00:00:10.000Z"], "metrics": { "@value": 3 } }] } },
+							{ "key": "server2", "1": { "top": [{ "sort": ["2021-01-01T00:00:10.000Z"], "metrics": { "@value": 4 } }] } }
+						]
+					}
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 2)
+		assert.Equal(t, "host", frames[0].Fields[0].Name)
+		assert.Equal(t, "Top Metrics @value", frames[0].Fields[1].Name)
+		v, _ := frames[0].FloatAt(1, 0)
+		assert.Equal(t, 3., v)
+		v, _ = frames[0].FloatAt(1, 1)
+		assert.Equal(t, 4., v)
+	})
+
+	t.Run("With raw data", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "raw_data", "id": "1" }],
+				"bucketAggs": []
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"hits": {
+					"hits": [
+						{
+							"_id": "1", "_index": "logs-2021", "_type": "_doc", "sort": [1609459210000, 3],
+							"_source": { "@timestamp": "2021-01-01T00:00:10.000Z", "host": { "name": "server1", "up": true }, "value": 3, "tags": ["a", "b"] }
+						},
+						{
+							"_id": "2", "_index": "logs-2021", "sort": [1609459200000, 1],
+							"_source": { "host": { "name": "server2" }, "value": "n/a" },
+							"fields": { "@timestamp": ["2021-01-01T00:00:00.000Z"] }
+						}
+					]
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frames := result.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		frame := frames[0]
+
+		names := make([]string, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			names = append(names, field.Name)
+		}
+		assert.Equal(t, []string{"@timestamp", "_id", "_index", "_type", "host.name", "host.up", "sort", "tags", "value"}, names)
+
+		first := time.Date(2021, 1, 1, 0, 0, 10, 0, time.UTC)
+		second := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, &first, frame.Fields[0].At(0))
+		assert.Equal(t, &second, frame.Fields[0].At(1))
+
+		docType := "_doc"
+		assert.Equal(t, &docType, frame.Fields[3].At(0))
+		assert.Nil(t, frame.Fields[3].At(1))
+
+		up := true
+		assert.Equal(t, &up, frame.Fields[5].At(0))
+		assert.Nil(t, frame.Fields[5].At(1))
+
+		sort := "[1609459210000,3]"
+		assert.Equal(t, &sort, frame.Fields[6].At(0))
+		tags := `["a","b"]`
+		assert.Equal(t, &tags, frame.Fields[7].At(0))
+		value, notAvailable := "3", "n/a"
+		assert.Equal(t, &value, frame.Fields[8].At(0))
+		assert.Equal(t, &notAvailable, frame.Fields[8].At(1))
+		assert.Empty(t, frame.Meta.PreferredVisualization)
+	})
+
+	t.Run("With logs", func(t *testing.T) {
+		targets := map[string]string{
+			"A": `{
+				"timeField": "@timestamp",
+				"metrics": [{ "type": "logs", "id": "1" }]
+			}`,
+		}
+		response := `{
+			"responses": [{
+				"hits": {
+					"hits": [
+						{ "_id": "1", "_source": { "@timestamp": 1609459210000, "message": "hello", "level": "info" } }
+					]
+				}
+			}]
+		}`
+		rp, err := newResponseParserForTest(targets, response)
+		require.NoError(t, err)
+		result, err := rp.getTimeSeries()
+		require.NoError(t, err)
+
+		frame := result.Responses["A"].Frames[0]
+		assert.Equal(t, data.VisType(data.VisTypeLogs), frame.Meta.PreferredVisualization)
+
+		fields := make(map[string]*data.Field)
+		for _, field := range frame.Fields {
+			fields[field.Name] = field
+		}
+		timestamp := time.Date(2021, 1, 1, 0, 0, 10, 0, time.UTC)
+		assert.Equal(t, &timestamp, fields["@timestamp"].At(0))
+		message := "hello"
+		assert.Equal(t, &message, fields["message"].At(0))
+		source := `{"@timestamp":1609459210000,"level":"info","message":"hello"}`
+		assert.Equal(t, &source, fields["_source"].At(0))
+	})
 }
 
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
@@ -13,6 +13,10 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 )
 
+// defaultDocumentSize is the number of documents returned by the document
+// queries without a size.
+const defaultDocumentSize = 500
+
 type timeSeriesQuery struct {
 	client             es.Client
 	dataQueries        []backend.DataQuery
@@ -60,7 +64,16 @@ func (e *timeSeriesQuery) execute() (*backend.QueryDataResponse, error) {
 	}
 
 	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
-	return rp.getTimeSeries()
+	response, err := rp.getTimeSeries()
+	if err != nil {
+		return response, err
+	}
+
+	// keep the errors of the invalid queries
+	for refID, dataResponse := range result.Responses {
+		response.Responses[refID] = dataResponse
+	}
+	return response, nil
 }
 
 // nolint:staticcheck
@@ -81,17 +94,17 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
 
+	if isDocumentQuery(q) {
+		if err := processDocumentQuery(q, b, e.client.GetVersion()); err != nil {
+			result.Responses[q.RefID] = backend.DataResponse{Error: err}
+		}
+		return nil
+	}
+
 	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
-			result.Responses[q.RefID] = backend.DataResponse{
-				Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
-			}
-			return nil
+		result.Responses[q.RefID] = backend.DataResponse{
+			Error: fmt.Errorf("invalid query, missing metrics and aggregations"),
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
 		return nil
 	}
 
@@ -184,6 +197,46 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// processDocumentQuery builds the search request of the raw_document,
+// raw_data and logs queries, which return the documents sorted by their time
+// field and then by their index and id, so that documents with the same time
+// keep their order across pages. Their settings.searchAfter is the sort
+// values of the last document of the previous page.
+func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, version *semver.Version) error {
+	metric := q.Metrics[0]
+
+	sizeSetting := "size"
+	if metric.Type == logsType {
+		sizeSetting = "limit"
+	}
+	setIntPath(metric.Settings, sizeSetting)
+	size := metric.Settings.Get(sizeSetting).MustInt(defaultDocumentSize)
+	if size <= 0 {
+		size = defaultDocumentSize
+	}
+
+	order := es.SortOrder(metric.Settings.Get("order").MustString(string(es.SortOrderDesc)))
+	if order != es.SortOrderDesc && order != es.SortOrderAsc {
+		return fmt.Errorf("invalid %s query sort order %q", metric.Type, order)
+	}
+
+	b.Size(size)
+	b.Sort(order, q.TimeField, "boolean")
+	b.Sort(order, "_index", "")
+	// Before Elasticsearch 6, only the _uid of the documents can be sorted on.
+	if version.Major() < 6 {
+		b.Sort(order, "_uid", "")
+	} else {
+		b.Sort(order, "_id", "")
+	}
+	b.AddDocValueField(q.TimeField)
+
+	if searchAfter := metric.Settings.Get("searchAfter").MustArray(); len(searchAfter) > 0 {
+		b.SearchAfter(searchAfter)
+	}
+	return nil
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
@@ -212,6 +265,13 @@ func (metricAggregation MetricAgg) generateSettingsForDSL(version *semver.Versio
 		setFloatPath(metricAggregation.Settings, "settings", "period")
 	case "serial_diff":
 		setFloatPath(metricAggregation.Settings, "lag")
+	case rateType:
+		// the unit and mode are optional, and empty when not set in the editor
+		for _, key := range []string{"unit", "mode"} {
+			if value, err := metricAggregation.Settings.Get(key).String(); err == nil && value == "" {
+				metricAggregation.Settings.Del(key)
+			}
+		}
 	}
 
 	if isMetricAggregationWithInlineScriptSupport(metricAggregation.Type) {
@@ -378,6 +378,82 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			require.Equal(t, sr.Size, 1337)
 		})
 
+		t.Run("With raw data metric", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "100", "order": "asc", "searchAfter": [1526406600000, "logs-2018.05.15", "7"] } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 100, sr.Size)
+			require.Empty(t, sr.Aggs)
+			require.Equal(t, []map[string]interface{}{
+				{"@timestamp": map[string]string{"order": "asc", "unmapped_type": "boolean"}},
+				{"_index": map[string]string{"order": "asc"}},
+				{"_id": map[string]string{"order": "asc"}},
+			}, sr.Sort)
+			require.Equal(t, []string{"@timestamp"}, sr.CustomProps["docvalue_fields"])
+			require.Equal(t, []interface{}{json.Number("1526406600000"), "logs-2018.05.15", "7"}, sr.CustomProps["search_after"])
+		})
+
+		t.Run("With raw data metric before Elasticsearch 6", func(t *testing.T) {
+			c := newFakeClient("5.0.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data" }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, []map[string]interface{}{
+				{"@timestamp": map[string]string{"order": "desc", "unmapped_type": "boolean"}},
+				{"_index": map[string]string{"order": "desc"}},
+				{"_uid": map[string]string{"order": "desc"}},
+			}, sr.Sort)
+		})
+
+		t.Run("With logs metric", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": 20 } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			require.Equal(t, 20, sr.Size)
+			require.Empty(t, sr.Aggs)
+			require.Equal(t, map[string]interface{}{
+				"@timestamp": map[string]string{"order": "desc", "unmapped_type": "boolean"},
+			}, sr.Sort[0])
+			require.NotContains(t, sr.CustomProps, "search_after")
+		})
+
+		t.Run("With invalid raw data sort order", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			result, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "order": "up" } }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			require.EqualError(t, result.Responses[""].Error, `invalid raw_data query sort order "up"`)
+		})
+
+		t.Run("Without metrics and aggregations", func(t *testing.T) {
+			c := newFakeClient("7.10.0")
+			result, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"metrics": [{ "id": "1", "type": "count" }]
+			}`, from, to, 15*time.Second)
+			require.NoError(t, err)
+			require.EqualError(t, result.Responses[""].Error, "invalid query, missing metrics and aggregations")
+		})
+
 		t.Run("With date histogram agg", func(t *testing.T) {
 			c := newFakeClient("5.0.0")
 			_, err := executeTsdbQuery(c, `{
@@ -933,6 +1009,26 @@ func TestSettingsCasting(t *testing.T) {
 		assert.Equal(t, 1., serialDiffSettings["lag"])
 	})
 
+	t.Run("Removes the empty rate settings", func(t *testing.T) {
+		c := newFakeClient("7.10.0")
+		_, err := executeTsdbQuery(c, `{
+			"timeField": "@timestamp",
+			"bucketAggs": [
+				{ "type": "date_histogram", "field": "@timestamp", "id": "2" }
+			],
+			"metrics": [
+				{ "id": "1", "type": "rate", "field": "@value", "settings": { "unit": "minute", "mode": "" } }
+			]
+		}`, from, to, 15*time.Second)
+		assert.Nil(t, err)
+		sr := c.multisearchRequests[0].Requests[0]
+
+		rateAgg := sr.Aggs[0].Aggregation.Aggs[0].Aggregation.Aggregation.(*es.MetricAggregation)
+		assert.Equal(t, "rate", sr.Aggs[0].Aggregation.Aggs[0].Aggregation.Type)
+		assert.Equal(t, "@value", rateAgg.Field)
+		assert.Equal(t, map[string]interface{}{"unit": "minute"}, rateAgg.Settings)
+	})
+
 	t.Run("Date Histogram Settings", func(t *testing.T) {
 		t.Run("Correctly transforms date_histogram settings", func(t *testing.T) {
 			c := newFakeClient("5.0.0")
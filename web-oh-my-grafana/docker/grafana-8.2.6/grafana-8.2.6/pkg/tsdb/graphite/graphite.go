@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
@@ -41,8 +42,11 @@ func ProvideService(httpClientProvider httpclient.Provider, manager backendplugi
 		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
 	}
 
+	mux := http.NewServeMux()
+	s.registerRoutes(mux)
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: s,
+		QueryDataHandler:    s,
+		CallResourceHandler: httpadapter.New(mux),
 	})
 
 	if err := manager.Register("graphite", factory); err != nil {
@@ -53,6 +57,17 @@ func ProvideService(httpClientProvider httpclient.Provider, manager backendplugi
 	return s, nil
 }
 
+const defaultMaxDataPoints = 500
+
+// consolidationFunctions are the functions supported by the consolidateBy
+// function of Graphite.
+var consolidationFunctions = map[string]struct{}{
+	"average": {}, "avg": {}, "avg_zero": {}, "median": {}, "sum": {}, "min": {}, "max": {},
+	"diff": {}, "stddev": {}, "count": {}, "range": {}, "multiply": {}, "first": {}, "last": {},
+}
+
+var consolidateByRegExp = regexp.MustCompile(`\bconsolidateBy\(`)
+
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
@@ -109,11 +124,15 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		https://graphite-api.readthedocs.io/en/latest/api.html#from-until
 	*/
 	from, until := epochMStoGraphiteTime(q.TimeRange)
+	maxDataPoints := q.MaxDataPoints
+	if maxDataPoints <= 0 {
+		maxDataPoints = defaultMaxDataPoints
+	}
 	formData := url.Values{
 		"from":          []string{from},
 		"until":         []string{until},
 		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
+		"maxDataPoints": []string{strconv.FormatInt(maxDataPoints, 10)},
 	}
 
 	// Calculate and get the last target of Graphite Request
@@ -136,6 +155,10 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			emptyQueries = append(emptyQueries, fmt.Sprintf("Query: %v has no target", model))
 			continue
 		}
+		currTarget, err = applyConsolidateBy(currTarget, model.Get("consolidateBy").MustString())
+		if err != nil {
+			return nil, err
+		}
 		target = fixIntervalFormat(currTarget)
 	}
 
@@ -248,6 +271,8 @@ func (s *Service) toDataFrames(response *http.Response) (frames data.Frames, err
 				tags[name] = value
 			case float64:
 				tags[name] = strconv.FormatFloat(value, 'f', -1, 64)
+			case bool:
+				tags[name] = strconv.FormatBool(value)
 			}
 		}
 
@@ -279,6 +304,20 @@ func (s *Service) createRequest(dsInfo *datasourceInfo, data url.Values) (*http.
 	return req, err
 }
 
+// applyConsolidateBy wraps a target with the consolidateBy function, so that
+// Graphite consolidates the data points above maxDataPoints with the given
+// function instead of averaging them. Targets which already call
+// consolidateBy are left as they are.
+func applyConsolidateBy(target string, function string) (string, error) {
+	if function == "" || consolidateByRegExp.MatchString(target) {
+		return target, nil
+	}
+	if _, ok := consolidationFunctions[function]; !ok {
+		return "", fmt.Errorf("unsupported consolidation function %q", function)
+	}
+	return fmt.Sprintf("consolidateBy(%s, '%s')", target, function), nil
+}
+
 func fixIntervalFormat(target string) string {
 	rMinute := regexp.MustCompile(`'(\d+)m'`)
 	target = rMinute.ReplaceAllStringFunc(target, func(m string) string {
@@ -1,14 +1,20 @@
 package graphite
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/assert"
@@ -84,7 +90,7 @@ func TestFixIntervalFormat(t *testing.T) {
 		[
 			{
 				"target": "target",
-				"tags": { "fooTag": "fooValue", "barTag": "barValue", "int": 100, "float": 3.14 },
+				"tags": { "fooTag": "fooValue", "barTag": "barValue", "int": 100, "float": 3.14, "bool": true },
 				"datapoints": [[50, 1], [null, 2], [100, 3]]
 			}
 		]`
@@ -97,6 +103,7 @@ func TestFixIntervalFormat(t *testing.T) {
 				"barTag": "barValue",
 				"int":    "100",
 				"float":  "3.14",
+				"bool":   "true",
 			}, []*float64{&a, nil, &b}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "target"}),
 		)
 		expectedFrames := data.Frames{expectedFrame}
@@ -112,3 +119,79 @@ func TestFixIntervalFormat(t *testing.T) {
 		}
 	})
 }
+
+func TestApplyConsolidateBy(t *testing.T) {
+	target, err := applyConsolidateBy("apps.*.requests", "")
+	require.NoError(t, err)
+	assert.Equal(t, "apps.*.requests", target)
+
+	target, err = applyConsolidateBy("sumSeries(apps.*.requests)", "max")
+	require.NoError(t, err)
+	assert.Equal(t, "consolidateBy(sumSeries(apps.*.requests), 'max')", target)
+
+	target, err = applyConsolidateBy("consolidateBy(apps.*.requests, 'sum')", "max")
+	require.NoError(t, err)
+	assert.Equal(t, "consolidateBy(apps.*.requests, 'sum')", target)
+
+	_, err = applyConsolidateBy("apps.*.requests", "mean")
+	require.EqualError(t, err, `unsupported consolidation function "mean"`)
+}
+
+func TestQueryDataConsolidation(t *testing.T) {
+	var form url.Values
+	graphite := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		require.NoError(t, req.ParseForm())
+		form = req.PostForm
+		_, err := rw.Write([]byte(`[{"target": "apps.web.requests", "tags": {"name": "apps.web.requests"}, "datapoints": [[1, 1]]}]`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(graphite.Close)
+
+	service := &Service{
+		logger: log.New("tsdb.graphite"),
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return datasourceInfo{HTTPClient: graphite.Client(), URL: graphite.URL, Id: settings.ID}, nil
+		}),
+	}
+	pluginCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}}
+	timeRange := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)}
+
+	t.Run("Should send the max data points and the consolidation function", func(t *testing.T) {
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{{
+				RefID:         "A",
+				MaxDataPoints: 100,
+				TimeRange:     timeRange,
+				JSON:          []byte(`{"target": "apps.*.requests", "consolidateBy": "max"}`),
+			}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "100", form.Get("maxDataPoints"))
+		assert.Equal(t, "consolidateBy(apps.*.requests, 'max')", form.Get("target"))
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		assert.Equal(t, data.Labels{"name": "apps.web.requests"}, resp.Responses["A"].Frames[0].Fields[1].Labels)
+	})
+
+	t.Run("Should default to 500 data points", func(t *testing.T) {
+		_, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries:       []backend.DataQuery{{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"target": "apps.*.requests"}`)}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "500", form.Get("maxDataPoints"))
+		assert.Equal(t, "apps.*.requests", form.Get("target"))
+	})
+
+	t.Run("Should fail on unsupported consolidation functions", func(t *testing.T) {
+		_, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pluginCtx,
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: timeRange,
+				JSON:      []byte(`{"target": "apps.*.requests", "consolidateBy": "mean"}`),
+			}},
+		})
+		require.EqualError(t, err, `unsupported consolidation function "mean"`)
+	})
+}
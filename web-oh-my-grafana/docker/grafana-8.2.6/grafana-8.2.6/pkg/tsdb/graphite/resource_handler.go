@@ -0,0 +1,120 @@
+package graphite
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// functionsInfinityRegExp matches the invalid JSON returned by the
+// /functions endpoint of Graphite 1.1.7, see
+// https://github.com/graphite-project/graphite-web/issues/2609
+var functionsInfinityRegExp = regexp.MustCompile(`"default": ?Infinity`)
+
+// Route definitions shared with the frontend.
+// Check: /public/app/plugins/datasource/graphite/datasource.ts
+func (s *Service) registerRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResourceReq(http.MethodGet))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResourceReq(http.MethodGet))
+	mux.HandleFunc("/metrics/find", s.handleResourceReq(http.MethodGet, http.MethodPost))
+	mux.HandleFunc("/functions", s.handleResourceReq(http.MethodGet))
+}
+
+// handleResourceReq forwards a request to the Graphite endpoint of the same
+// path, with the query parameters and form body of the request.
+func (s *Service) handleResourceReq(methods ...string) func(rw http.ResponseWriter, req *http.Request) {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		s.logger.Debug("Received resource call", "url", req.URL.String(), "method", req.Method)
+
+		if !isAllowedMethod(req.Method, methods) {
+			s.writeResponse(rw, http.StatusMethodNotAllowed, "text/plain", []byte(fmt.Sprintf("method %s is not allowed", req.Method)))
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			s.writeResponse(rw, http.StatusInternalServerError, "text/plain", []byte(fmt.Sprintf("unexpected error %v", err)))
+			return
+		}
+
+		graphiteReq, err := s.createResourceRequest(dsInfo, req)
+		if err != nil {
+			s.writeResponse(rw, http.StatusBadRequest, "text/plain", []byte(err.Error()))
+			return
+		}
+
+		res, err := ctxhttp.Do(req.Context(), dsInfo.HTTPClient, graphiteReq)
+		if err != nil {
+			s.writeResponse(rw, http.StatusBadGateway, "text/plain", []byte(fmt.Sprintf("failed to query Graphite: %v", err)))
+			return
+		}
+		defer func() {
+			if err := res.Body.Close(); err != nil {
+				s.logger.Warn("Failed to close response body", "err", err)
+			}
+		}()
+
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			s.writeResponse(rw, http.StatusBadGateway, "text/plain", []byte(fmt.Sprintf("failed to read the Graphite response: %v", err)))
+			return
+		}
+		if req.URL.Path == "/functions" {
+			body = functionsInfinityRegExp.ReplaceAll(body, []byte(`"default": 1e9999`))
+		}
+
+		s.writeResponse(rw, res.StatusCode, res.Header.Get("Content-Type"), body)
+	}
+}
+
+func (s *Service) createResourceRequest(dsInfo *datasourceInfo, req *http.Request) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, req.URL.Path)
+	u.RawQuery = req.URL.RawQuery
+
+	var body []byte
+	if req.Body != nil {
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read the request body: %w", err)
+		}
+	}
+
+	graphiteReq, err := http.NewRequest(req.Method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		s.logger.Info("Failed to create request", "error", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		graphiteReq.Header.Set("Content-Type", contentType)
+	}
+	return graphiteReq, nil
+}
+
+func (s *Service) writeResponse(rw http.ResponseWriter, code int, contentType string, body []byte) {
+	if contentType != "" {
+		rw.Header().Set("Content-Type", contentType)
+	}
+	rw.WriteHeader(code)
+	if _, err := rw.Write(body); err != nil {
+		s.logger.Error("Failed to write response", "error", err)
+	}
+}
+
+func isAllowedMethod(method string, methods []string) bool {
+	for _, m := range methods {
+		if method == m {
+			return true
+		}
+	}
+	return false
+}
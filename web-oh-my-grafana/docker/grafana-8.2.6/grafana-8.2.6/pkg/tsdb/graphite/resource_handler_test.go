@@ -0,0 +1,108 @@
+package graphite
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeResourceSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestResourceHandler(t *testing.T) {
+	var graphiteReq *http.Request
+	var graphiteBody string
+	graphite := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		graphiteReq, graphiteBody = req, string(body)
+
+		rw.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/graphite/functions":
+			_, err = rw.Write([]byte(`{"sum": {"params": [{"name": "n", "default": Infinity}]}}`))
+		default:
+			_, err = rw.Write([]byte(`["foo", "bar"]`))
+		}
+		require.NoError(t, err)
+	}))
+	t.Cleanup(graphite.Close)
+
+	service := &Service{
+		logger: log.New("tsdb.graphite"),
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return datasourceInfo{HTTPClient: graphite.Client(), URL: graphite.URL + "/graphite", Id: settings.ID}, nil
+		}),
+	}
+	mux := http.NewServeMux()
+	service.registerRoutes(mux)
+	handler := httpadapter.New(mux)
+
+	callResource := func(t *testing.T, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+		t.Helper()
+		req.PluginContext = backend.PluginContext{
+			OrgID:                      1,
+			DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1},
+		}
+		sender := &fakeResourceSender{}
+		require.NoError(t, handler.CallResource(context.Background(), req, sender))
+		require.NotNil(t, sender.resp)
+		return sender.resp
+	}
+
+	t.Run("Should forward the tag requests with their query parameters", func(t *testing.T) {
+		resp := callResource(t, &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/autoComplete/values",
+			URL:    "tags/autoComplete/values?tag=host&expr=env%3Dprod",
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.JSONEq(t, `["foo", "bar"]`, string(resp.Body))
+		assert.Equal(t, "/graphite/tags/autoComplete/values", graphiteReq.URL.Path)
+		assert.Equal(t, "host", graphiteReq.URL.Query().Get("tag"))
+		assert.Equal(t, "env=prod", graphiteReq.URL.Query().Get("expr"))
+	})
+
+	t.Run("Should forward the form body of the metric find requests", func(t *testing.T) {
+		resp := callResource(t, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "metrics/find",
+			URL:     "metrics/find?from=-6h",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte("query=apps.*"),
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.Equal(t, http.MethodPost, graphiteReq.Method)
+		assert.Equal(t, "/graphite/metrics/find", graphiteReq.URL.Path)
+		assert.Equal(t, "-6h", graphiteReq.URL.Query().Get("from"))
+		assert.Equal(t, "application/x-www-form-urlencoded", graphiteReq.Header.Get("Content-Type"))
+		assert.Equal(t, "query=apps.*", graphiteBody)
+	})
+
+	t.Run("Should fix the invalid JSON of the functions", func(t *testing.T) {
+		resp := callResource(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+		require.Equal(t, http.StatusOK, resp.Status)
+		assert.Equal(t, `{"sum": {"params": [{"name": "n", "default": 1e9999}]}}`, string(resp.Body))
+	})
+
+	t.Run("Should reject the unsupported methods", func(t *testing.T) {
+		resp := callResource(t, &backend.CallResourceRequest{Method: http.MethodDelete, Path: "functions", URL: "functions"})
+		require.Equal(t, http.StatusMethodNotAllowed, resp.Status)
+	})
+}
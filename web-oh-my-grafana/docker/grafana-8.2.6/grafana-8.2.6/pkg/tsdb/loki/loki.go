@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,11 +36,12 @@ type Service struct {
 }
 
 func ProvideService(httpClientProvider httpclient.Provider, manager backendplugin.Manager) (*Service, error) {
-	im := datasource.NewInstanceManager(newInstanceSettings(httpClientProvider))
+	plog := log.New("tsdb.loki")
+	im := datasource.NewInstanceManager(newInstanceSettings(httpClientProvider, plog))
 	s := &Service{
 		im:                 im,
 		intervalCalculator: intervalv2.NewCalculator(),
-		plog:               log.New("tsdb.loki"),
+		plog:               plog,
 	}
 
 	factory := coreplugin.New(backend.ServeOpts{
@@ -58,6 +60,10 @@ var (
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 )
 
+// defaultMaxLines is the line limit of log queries when neither the query
+// nor the data source set one, the same as in the query editor.
+const defaultMaxLines = 1000
+
 type datasourceInfo struct {
 	HTTPClient        *http.Client
 	URL               string
@@ -65,6 +71,34 @@ type datasourceInfo struct {
 	BasicAuthUser     string
 	BasicAuthPassword string
 	TimeInterval      string `json:"timeInterval"`
+	MaxLines          int
+	DerivedFields     []*derivedField
+}
+
+type datasourceJSONData struct {
+	TimeInterval  string              `json:"timeInterval"`
+	MaxLines      string              `json:"maxLines"`
+	DerivedFields []*derivedFieldJSON `json:"derivedFields"`
+}
+
+type derivedFieldJSON struct {
+	MatcherRegex    string `json:"matcherRegex"`
+	Name            string `json:"name"`
+	URL             string `json:"url"`
+	URLDisplayLabel string `json:"urlDisplayLabel"`
+	DatasourceUID   string `json:"datasourceUid"`
+}
+
+// derivedField extracts a field from the log lines with the first capture
+// group of its regex.
+type derivedField struct {
+	Name            string
+	Matcher         *regexp.Regexp
+	URL             string
+	URLDisplayLabel string
+	// DatasourceUID is set for links to another data source, which are
+	// resolved by the frontend.
+	DatasourceUID string
 }
 
 type ResponseModel struct {
@@ -73,9 +107,12 @@ type ResponseModel struct {
 	Interval     string `json:"interval"`
 	IntervalMS   int    `json:"intervalMS"`
 	Resolution   int64  `json:"resolution"`
+	MaxLines     int    `json:"maxLines"`
+	Instant      bool   `json:"instant"`
+	Direction    string `json:"direction"`
 }
 
-func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
+func newInstanceSettings(httpClientProvider httpclient.Provider, plog log.Logger) datasource.InstanceFactoryFunc {
 	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		opts, err := settings.HTTPClientOptions()
 		if err != nil {
@@ -92,12 +129,37 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
-		jsonData := datasourceInfo{}
+		jsonData := datasourceJSONData{}
 		err = json.Unmarshal(settings.JSONData, &jsonData)
 		if err != nil {
 			return nil, fmt.Errorf("error reading settings: %w", err)
 		}
 
+		maxLines := defaultMaxLines
+		if jsonData.MaxLines != "" {
+			if value, err := strconv.Atoi(jsonData.MaxLines); err == nil && value > 0 {
+				maxLines = value
+			} else {
+				plog.Warn("Invalid maxLines, using the default", "maxLines", jsonData.MaxLines, "default", defaultMaxLines)
+			}
+		}
+
+		derivedFields := make([]*derivedField, 0, len(jsonData.DerivedFields))
+		for _, field := range jsonData.DerivedFields {
+			matcher, err := regexp.Compile(field.MatcherRegex)
+			if err != nil {
+				plog.Warn("Skipping derived field with an invalid regex", "name", field.Name, "error", err)
+				continue
+			}
+			derivedFields = append(derivedFields, &derivedField{
+				Name:            field.Name,
+				Matcher:         matcher,
+				URL:             field.URL,
+				URLDisplayLabel: field.URLDisplayLabel,
+				DatasourceUID:   field.DatasourceUID,
+			})
+		}
+
 		model := &datasourceInfo{
 			HTTPClient:        client,
 			URL:               settings.URL,
@@ -105,6 +167,8 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			TimeInterval:      jsonData.TimeInterval,
 			BasicAuthUser:     settings.BasicAuthUser,
 			BasicAuthPassword: settings.DecryptedSecureJSONData["basicAuthPassword"],
+			MaxLines:          maxLines,
+			DerivedFields:     derivedFields,
 		}
 		return model, nil
 	}
@@ -112,7 +176,6 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
-	queryRes := backend.DataResponse{}
 
 	dsInfo, err := s.getDSInfo(req.PluginContext)
 	if err != nil {
@@ -137,34 +200,36 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	for _, query := range queries {
-		s.plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr)
+		s.plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "query", query.Expr,
+			"instant", query.Instant)
 		span, _ := opentracing.StartSpanFromContext(ctx, "alerting.loki")
 		span.SetTag("expr", query.Expr)
 		span.SetTag("start_unixnano", query.Start.UnixNano())
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
-		//Currently hard coded as not used - applies to queries which produce a stream response
-		interval := time.Second * 1
-
-		value, err := client.QueryRange(query.Expr, limit, query.Start, query.End, logproto.BACKWARD, query.Step, interval, false)
+		var value *loghttp.QueryResponse
+		if query.Instant {
+			value, err = client.Query(query.Expr, query.MaxLines, query.End, query.Direction, false)
+		} else {
+			//Currently hard coded as not used - applies to queries which produce a stream response
+			interval := time.Second * 1
+			value, err = client.QueryRange(query.Expr, query.MaxLines, query.Start, query.End, query.Direction, query.Step, interval, false)
+		}
 		if err != nil {
 			return result, err
 		}
 
-		frames, err := parseResponse(value, query)
+		frames, err := parseResponse(value, query, dsInfo.DerivedFields)
 		if err != nil {
 			return result, err
 		}
-		queryRes.Frames = frames
-		result.Responses[query.RefID] = queryRes
+		result.Responses[query.RefID] = backend.DataResponse{Frames: frames}
 	}
 	return result, nil
 }
 
-//If legend (using of name or pattern instead of time series name) is used, use that name/pattern for formatting
+// If legend (using of name or pattern instead of time series name) is used, use that name/pattern for formatting
 func formatLegend(metric model.Metric, query *lokiQuery) string {
 	if query.LegendFormat == "" {
 		return metric.String()
@@ -209,6 +274,16 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 
 		step := time.Duration(int64(interval.Value) * resolution)
 
+		maxLines := dsInfo.MaxLines
+		if model.MaxLines > 0 {
+			maxLines = model.MaxLines
+		}
+
+		direction, err := parseDirection(model.Direction)
+		if err != nil {
+			return nil, err
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:         model.Expr,
 			Step:         step,
@@ -216,21 +291,45 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 			Start:        start,
 			End:          end,
 			RefID:        query.RefID,
+			MaxLines:     maxLines,
+			Instant:      model.Instant,
+			Direction:    direction,
 		})
 	}
 
 	return qs, nil
 }
 
-func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames, error) {
-	frames := data.Frames{}
+// parseDirection parses the direction of a query, the newest lines are
+// returned first by default.
+func parseDirection(direction string) (logproto.Direction, error) {
+	switch strings.ToLower(direction) {
+	case "", "backward":
+		return logproto.BACKWARD, nil
+	case "forward":
+		return logproto.FORWARD, nil
+	}
+	return logproto.BACKWARD, fmt.Errorf("invalid direction %q", direction)
+}
 
-	//We are currently processing only matrix results (for alerting)
-	matrix, ok := value.Data.Result.(loghttp.Matrix)
-	if !ok {
-		return frames, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
+func parseResponse(value *loghttp.QueryResponse, query *lokiQuery, derivedFields []*derivedField) (data.Frames, error) {
+	switch result := value.Data.Result.(type) {
+	case loghttp.Matrix:
+		return parseMatrix(result, query), nil
+	case loghttp.Vector:
+		return parseVector(result, query), nil
+	case loghttp.Scalar:
+		return parseScalar(result), nil
+	case loghttp.Streams:
+		return parseStreams(result, derivedFields), nil
 	}
 
+	return data.Frames{}, fmt.Errorf("unsupported result format: %q", value.Data.ResultType)
+}
+
+func parseMatrix(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
 		tags := make(map[string]string, len(v.Metric))
@@ -251,7 +350,78 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames,
 			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
 	}
 
-	return frames, nil
+	return frames
+}
+
+func parseVector(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := data.Frames{}
+
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		tags := make(map[string]string, len(v.Metric))
+		for k, v := range v.Metric {
+			tags[string(k)] = string(v)
+		}
+
+		frames = append(frames, data.NewFrame(name,
+			data.NewField("time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("value", tags, []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+	}
+
+	return frames
+}
+
+func parseScalar(scalar loghttp.Scalar) data.Frames {
+	return data.Frames{data.NewFrame("",
+		data.NewField("time", nil, []time.Time{scalar.Timestamp.Time().UTC()}),
+		data.NewField("value", nil, []float64{float64(scalar.Value)}))}
+}
+
+// parseStreams returns a logs frame for each stream, with the labels of the
+// stream on the line field and a field for each derived field.
+func parseStreams(streams loghttp.Streams, derivedFields []*derivedField) data.Frames {
+	frames := data.Frames{}
+
+	for _, stream := range streams {
+		labels := data.Labels(stream.Labels.Map())
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+		for _, entry := range stream.Entries {
+			timeVector = append(timeVector, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+		}
+
+		frame := data.NewFrame(stream.Labels.String(),
+			data.NewField("ts", nil, timeVector),
+			data.NewField("line", labels, lines))
+		for _, derived := range derivedFields {
+			frame.Fields = append(frame.Fields, derived.field(lines))
+		}
+		frame.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeLogs}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+// field extracts the derived field from the lines, lines without a match have
+// an empty value.
+func (f *derivedField) field(lines []string) *data.Field {
+	values := make([]string, len(lines))
+	for i, line := range lines {
+		if match := f.Matcher.FindStringSubmatch(line); len(match) > 1 {
+			values[i] = match[1]
+		}
+	}
+
+	field := data.NewField(f.Name, nil, values)
+	if f.URL != "" && f.DatasourceUID == "" {
+		field.SetConfig(&data.FieldConfig{
+			Links: []data.DataLink{{Title: f.URLDisplayLabel, URL: f.URL}},
+		})
+	}
+	return field
 }
 
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
@@ -2,14 +2,18 @@ package loki
 
 import (
 	"fmt"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/grafana/loki/pkg/logproto"
 	p "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -73,6 +77,37 @@ func TestLoki(t *testing.T) {
 		require.Equal(t, time.Second*30, models[0].Step)
 	})
 
+	t.Run("parsing query model with log query options", func(t *testing.T) {
+		queryContext := &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON: []byte(`{"expr": "{app=\"backend\"}", "refId": "A", "instant": true, "direction": "FORWARD"}`),
+				},
+				{
+					JSON: []byte(`{"expr": "{app=\"backend\"}", "refId": "B", "maxLines": 20}`),
+				},
+				{
+					JSON: []byte(`{"expr": "{app=\"backend\"}", "refId": "C", "direction": "sideways"}`),
+				},
+			},
+		}
+		service := &Service{intervalCalculator: mockCalculator{}}
+		dsInfo := &datasourceInfo{MaxLines: 500}
+
+		_, err := service.parseQuery(dsInfo, queryContext)
+		require.Error(t, err)
+
+		queryContext.Queries = queryContext.Queries[:2]
+		models, err := service.parseQuery(dsInfo, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].Instant)
+		require.Equal(t, logproto.FORWARD, models[0].Direction)
+		require.Equal(t, 500, models[0].MaxLines)
+		require.False(t, models[1].Instant)
+		require.Equal(t, logproto.BACKWARD, models[1].Direction)
+		require.Equal(t, 20, models[1].MaxLines)
+	})
+
 	t.Run("parsing query model without step parameter", func(t *testing.T) {
 		queryContext := &backend.QueryDataRequest{
 			Queries: []backend.DataQuery{
@@ -117,15 +152,46 @@ func TestLoki(t *testing.T) {
 	})
 }
 
+func TestNewInstanceSettings(t *testing.T) {
+	newInstance := func(t *testing.T, jsonData string) *datasourceInfo {
+		t.Helper()
+
+		instance, err := newInstanceSettings(httpclient.NewProvider(), log.New("test"))(backend.DataSourceInstanceSettings{
+			URL:      "http://localhost:3100",
+			JSONData: []byte(jsonData),
+		})
+		require.NoError(t, err)
+		return instance.(*datasourceInfo)
+	}
+
+	t.Run("uses the maxLines of the data source", func(t *testing.T) {
+		require.Equal(t, 500, newInstance(t, `{"maxLines": "500"}`).MaxLines)
+	})
+
+	t.Run("falls back to the default maxLines when it is invalid", func(t *testing.T) {
+		require.Equal(t, defaultMaxLines, newInstance(t, `{"maxLines": "many"}`).MaxLines)
+		require.Equal(t, defaultMaxLines, newInstance(t, `{"maxLines": "-1"}`).MaxLines)
+	})
+
+	t.Run("skips the derived fields with an invalid regex", func(t *testing.T) {
+		dsInfo := newInstance(t, `{"derivedFields": [
+			{"name": "broken", "matcherRegex": "traceID=(\\w+"},
+			{"name": "traceID", "matcherRegex": "traceID=(\\w+)"}
+		]}`)
+		require.Len(t, dsInfo.DerivedFields, 1)
+		require.Equal(t, "traceID", dsInfo.DerivedFields[0].Name)
+	})
+}
+
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is of an unsupported type", func(t *testing.T) {
 		queryRes := data.Frames{}
 		value := loghttp.QueryResponse{
 			Data: loghttp.QueryResponseData{
-				Result: loghttp.Vector{},
+				ResultType: "unknown",
 			},
 		}
-		res, err := parseResponse(&value, nil)
+		res, err := parseResponse(&value, nil, nil)
 		require.Equal(t, queryRes, res)
 		require.Error(t, err)
 	})
@@ -152,7 +218,7 @@ func TestParseResponse(t *testing.T) {
 		query := &lokiQuery{
 			LegendFormat: "legend {{app}}",
 		}
-		frame, err := parseResponse(&value, query)
+		frame, err := parseResponse(&value, query, nil)
 		require.NoError(t, err)
 
 		labels, err := data.LabelsFromString("app=Application, tag2=tag2")
@@ -174,6 +240,89 @@ func TestParseResponse(t *testing.T) {
 	})
 }
 
+func TestParseResponseOfInstantQueries(t *testing.T) {
+	t.Run("vector", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Vector{
+					{Metric: p.Metric{"app": "backend"}, Value: 3, Timestamp: 5000},
+					{Metric: p.Metric{"app": "frontend"}, Value: 7, Timestamp: 5000},
+				},
+			},
+		}
+
+		frames, err := parseResponse(&value, &lokiQuery{LegendFormat: "{{app}}"}, nil)
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		labels, err := data.LabelsFromString("app=frontend")
+		require.NoError(t, err)
+		field := data.NewField("value", labels, []float64{7})
+		field.SetConfig(&data.FieldConfig{DisplayNameFromDS: "frontend"})
+		expected := data.NewFrame("frontend",
+			data.NewField("time", nil, []time.Time{time.Date(1970, 1, 1, 0, 0, 5, 0, time.UTC)}),
+			field)
+		if diff := cmp.Diff(expected, frames[1], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Scalar{Value: 42, Timestamp: 5000},
+			},
+		}
+
+		frames, err := parseResponse(&value, &lokiQuery{}, nil)
+		require.NoError(t, err)
+
+		expected := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Date(1970, 1, 1, 0, 0, 5, 0, time.UTC)}),
+			data.NewField("value", nil, []float64{42}))
+		if diff := cmp.Diff(data.Frames{expected}, frames, data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestParseResponseOfLogQueries(t *testing.T) {
+	value := loghttp.QueryResponse{
+		Data: loghttp.QueryResponseData{
+			Result: loghttp.Streams{
+				{
+					Labels: loghttp.LabelSet{"app": "backend", "level": "error"},
+					Entries: []loghttp.Entry{
+						{Timestamp: time.Unix(2, 500), Line: "failed request traceID=abc123"},
+						{Timestamp: time.Unix(1, 0), Line: "connection lost"},
+					},
+				},
+			},
+		},
+	}
+	derivedFields := []*derivedField{
+		{Name: "traceID", Matcher: regexp.MustCompile(`traceID=(\w+)`), URL: "http://tracing/${__value.raw}", URLDisplayLabel: "Trace"},
+		{Name: "internal", Matcher: regexp.MustCompile(`traceID=(\w+)`), URL: "${__value.raw}", DatasourceUID: "tempo"},
+	}
+
+	frames, err := parseResponse(&value, &lokiQuery{}, derivedFields)
+	require.NoError(t, err)
+
+	labels := data.Labels{"app": "backend", "level": "error"}
+	traceID := data.NewField("traceID", nil, []string{"abc123", ""})
+	traceID.SetConfig(&data.FieldConfig{Links: []data.DataLink{{Title: "Trace", URL: "http://tracing/${__value.raw}"}}})
+	expected := data.NewFrame(`{app="backend", level="error"}`,
+		data.NewField("ts", nil, []time.Time{time.Unix(2, 500).UTC(), time.Unix(1, 0).UTC()}),
+		data.NewField("line", labels, []string{"failed request traceID=abc123", "connection lost"}),
+		traceID,
+		data.NewField("internal", nil, []string{"abc123", ""}))
+	expected.Meta = &data.FrameMeta{PreferredVisualization: data.VisTypeLogs}
+
+	if diff := cmp.Diff(data.Frames{expected}, frames, data.FrameTestCompareOptions()...); diff != "" {
+		t.Errorf("Result mismatch (-want +got):\n%s", diff)
+	}
+}
+
 type mockCalculator struct {
 	interval intervalv2.Interval
 }
@@ -36,7 +36,8 @@ func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager) (*Service,
 		im: datasource.NewInstanceManager(newInstanceSettings(cfg)),
 	}
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: s,
+		QueryDataHandler:    s,
+		CallResourceHandler: sqleng.NewResourceHandler(s.getDataSourceHandler),
 	})
 
 	if err := manager.Register("mssql", factory); err != nil {
@@ -134,3 +134,10 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return "", fmt.Errorf("unknown macro %v", name)
 	}
 }
+
+// EscapeString escapes the backslashes and single quotes of a string literal,
+// MySQL treating backslashes as escape characters by default.
+func (m *mySQLMacroEngine) EscapeString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -223,3 +223,8 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngineEscapeString(t *testing.T) {
+	engine := &mySQLMacroEngine{logger: log.New("test")}
+	require.Equal(t, `it''s a \\'' test`, engine.EscapeString(`it's a \' test`))
+}
@@ -49,7 +49,8 @@ func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager, httpClientP
 		im: datasource.NewInstanceManager(newInstanceSettings(cfg, httpClientProvider)),
 	}
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: s,
+		QueryDataHandler:    s,
+		CallResourceHandler: sqleng.NewResourceHandler(s.getDataSourceHandler),
 	})
 
 	if err := manager.Register("mysql", factory); err != nil {
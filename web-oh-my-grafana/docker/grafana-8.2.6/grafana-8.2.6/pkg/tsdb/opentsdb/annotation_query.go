@@ -0,0 +1,108 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// isAnnotationQuery returns whether the model of a query has the
+// annotationQuery type.
+func isAnnotationQuery(query backend.DataQuery) bool {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return false
+	}
+	return model.Get("type").MustString() == "annotationQuery"
+}
+
+// executeAnnotationQuery returns the annotations of the series of a metric,
+// or the global annotations. OpenTSDB stores them with its /api/annotation
+// endpoint, which only reads a single annotation, and returns those of a time
+// range with the series of the /api/query endpoint.
+func (s *Service) executeAnnotationQuery(ctx context.Context, dsInfo *datasourceInfo, start, end int64,
+	query backend.DataQuery) (*data.Frame, error) {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return nil, err
+	}
+
+	target := model.Get("target").MustString()
+	if target == "" {
+		return nil, fmt.Errorf("annotation query %s has no metric", query.RefID)
+	}
+	isGlobal := model.Get("isGlobal").MustBool()
+
+	metric := map[string]interface{}{
+		"metric":     target,
+		"aggregator": "sum",
+	}
+	if tags := model.Get("tags").MustMap(); len(tags) > 0 {
+		metric["tags"] = tags
+	}
+
+	request, err := s.createRequest(dsInfo, OpenTsdbQuery{
+		Start:             start,
+		End:               end,
+		Queries:           []map[string]interface{}{metric},
+		GlobalAnnotations: isGlobal,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var responseData []OpenTsdbResponse
+	if err := s.decodeResponse(res, &responseData); err != nil {
+		return nil, err
+	}
+
+	annotations := make([]OpenTsdbAnnotation, 0)
+	for _, val := range responseData {
+		if isGlobal {
+			// the global annotations are the same for all the series
+			annotations = append(annotations, val.GlobalAnnotations...)
+			break
+		}
+		annotations = append(annotations, val.Annotations...)
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].StartTime < annotations[j].StartTime
+	})
+
+	return transformAnnotationsToFrame(annotations, query), nil
+}
+
+func transformAnnotationsToFrame(annotations []OpenTsdbAnnotation, query backend.DataQuery) *data.Frame {
+	frame := data.NewFrame(query.RefID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []*time.Time{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("tsuid", nil, []string{}),
+	)
+
+	for _, a := range annotations {
+		var timeEnd *time.Time
+		if a.EndTime > 0 {
+			t := epochSecondsToTime(a.EndTime)
+			timeEnd = &t
+		}
+		frame.AppendRow(epochSecondsToTime(a.StartTime), timeEnd, a.Description, a.TSUID)
+	}
+
+	return frame
+}
+
+func epochSecondsToTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +28,20 @@ import (
 	"golang.org/x/net/context/ctxhttp"
 )
 
+// filterTypes are the tag filters of OpenTSDB 2.2+.
+var filterTypes = map[string]struct{}{
+	"literal_or":      {},
+	"iliteral_or":     {},
+	"not_literal_or":  {},
+	"not_iliteral_or": {},
+	"wildcard":        {},
+	"iwildcard":       {},
+	"regexp":          {},
+	"not_key":         {},
+}
+
+var nanValueRegExp = regexp.MustCompile(`:\s*NaN\b`)
+
 type Service struct {
 	logger log.Logger
 	im     instancemgmt.InstanceManager
@@ -85,8 +101,16 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
 
+	annotationQueries := make([]backend.DataQuery, 0)
 	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
+		if isAnnotationQuery(query) {
+			annotationQueries = append(annotationQueries, query)
+			continue
+		}
+		metric, err := s.buildMetric(query)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
 		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
 	}
 
@@ -100,19 +124,30 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
-	request, err := s.createRequest(dsInfo, tsdbQuery)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
+	result := backend.NewQueryDataResponse()
+	if len(tsdbQuery.Queries) > 0 {
+		request, err := s.createRequest(dsInfo, tsdbQuery)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
 
-	res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, request)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		res, err := ctxhttp.Do(ctx, dsInfo.HTTPClient, request)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+
+		result, err = s.parseResponse(res)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
 	}
 
-	result, err := s.parseResponse(res)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+	for _, query := range annotationQueries {
+		frame, err := s.executeAnnotationQuery(ctx, dsInfo, tsdbQuery.Start, tsdbQuery.End, query)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+		result.Responses[query.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
 	}
 
 	return result, nil
@@ -144,46 +179,38 @@ func (s *Service) createRequest(dsInfo *datasourceInfo, data OpenTsdbQuery) (*ht
 func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		if err := res.Body.Close(); err != nil {
-			s.logger.Warn("Failed to close response body", "err", err)
-		}
-	}()
-
-	if res.StatusCode/100 != 2 {
-		s.logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
-	}
-
 	var responseData []OpenTsdbResponse
-	err = json.Unmarshal(body, &responseData)
-	if err != nil {
-		s.logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
+	if err := s.decodeResponse(res, &responseData); err != nil {
 		return nil, err
 	}
 
 	frames := data.Frames{}
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-
-		for timeString, value := range val.DataPoints {
+		timestamps := make([]int64, 0, len(val.DataPoints))
+		for timeString := range val.DataPoints {
 			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				s.logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
+			timestamps = append(timestamps, timestamp)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		timeVector := make([]time.Time, 0, len(timestamps))
+		values := make([]*float64, 0, len(timestamps))
+		for _, timestamp := range timestamps {
 			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			values = append(values, val.DataPoints[strconv.FormatInt(timestamp, 10)])
 		}
-		frames = append(frames, data.NewFrame(name,
+
+		var labels data.Labels
+		if len(val.Tags) > 0 {
+			labels = data.Labels(val.Tags)
+		}
+		frames = append(frames, data.NewFrame(val.Metric,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", nil, values)))
+			data.NewField("value", labels, values)))
 	}
 	result := resp.Responses["A"]
 	result.Frames = frames
@@ -191,12 +218,38 @@ func (s *Service) parseResponse(res *http.Response) (*backend.QueryDataResponse,
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
+// decodeResponse decodes the JSON body of a response of OpenTSDB, which
+// writes the NaN values of the nan fill policy as invalid JSON.
+func (s *Service) decodeResponse(res *http.Response, v interface{}) error {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		s.logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	body = nanValueRegExp.ReplaceAll(body, []byte(":null"))
+	if err := json.Unmarshal(body, v); err != nil {
+		s.logger.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
+		return err
+	}
+	return nil
+}
+
+func (s *Service) buildMetric(query backend.DataQuery) (map[string]interface{}, error) {
 	metric := make(map[string]interface{})
 
 	model, err := simplejson.NewJson(query.JSON)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	// Setting metric and aggregator
@@ -210,11 +263,20 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 		if downsampleInterval == "" {
 			downsampleInterval = "1m" // default value for blank
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
-		} else {
+		downsampleAggregator := model.Get("downsampleAggregator").MustString()
+		if downsampleAggregator == "" {
+			downsampleAggregator = "avg"
+		}
+		downsample := downsampleInterval + "-" + downsampleAggregator
+
+		fillPolicy := model.Get("downsampleFillPolicy").MustString()
+		switch fillPolicy {
+		case "", "none":
 			metric["downsample"] = downsample
+		case "nan", "null", "zero":
+			metric["downsample"] = downsample + "-" + fillPolicy
+		default:
+			return nil, fmt.Errorf("query %s: unsupported downsample fill policy %q", query.RefID, fillPolicy)
 		}
 	}
 
@@ -250,10 +312,45 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]interface{} {
 	// Setting filters
 	filters, filtersCheck := model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+		tsdbFilters, err := buildFilters(filters)
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", query.RefID, err)
+		}
+		metric["filters"] = tsdbFilters
 	}
 
-	return metric
+	// Setting explicit tags, which only match the series with exactly the
+	// tags of the filters
+	if model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
+	}
+
+	return metric, nil
+}
+
+// buildFilters returns the tag filters of OpenTSDB 2.2+, with their type,
+// tag key, filter expression and group by flag.
+func buildFilters(filters *simplejson.Json) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0, len(filters.MustArray()))
+	for i := range filters.MustArray() {
+		filter := filters.GetIndex(i)
+		filterType := filter.Get("type").MustString()
+		if _, ok := filterTypes[filterType]; !ok {
+			return nil, fmt.Errorf("unsupported filter type %q", filterType)
+		}
+		tagk := filter.Get("tagk").MustString()
+		if tagk == "" {
+			return nil, fmt.Errorf("filter %s has no tag key", filterType)
+		}
+
+		result = append(result, map[string]interface{}{
+			"type":    filterType,
+			"tagk":    tagk,
+			"filter":  filter.Get("filter").MustString(),
+			"groupBy": filter.Get("groupBy").MustBool(),
+		})
+	}
+	return result, nil
 }
 
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
@@ -1,14 +1,19 @@
 package opentsdb
 
 import (
+	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/assert"
@@ -55,8 +60,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			data.NewField("time", nil, []time.Time{
 				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
 			}),
-			data.NewField("value", nil, []float64{
-				50}),
+			data.NewField("value", nil, []*float64{
+				float64Ptr(50)}),
 		)
 
 		resp := http.Response{Body: ioutil.NopCloser(strings.NewReader(response))}
@@ -85,7 +90,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -107,7 +113,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -128,7 +135,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -154,7 +162,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -185,7 +194,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -220,7 +230,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -239,4 +250,215 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+	t.Run("Build metric without downsampling aggregator and fill policy", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"downsampleInterval": "5m"
+					}`,
+			),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Equal(t, "5m-avg", metric["downsample"])
+	})
+
+	t.Run("Build metric with an unsupported fill policy", func(t *testing.T) {
+		query := backend.DataQuery{
+			RefID: "A",
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"downsampleFillPolicy": "previous"
+					}`,
+			),
+		}
+
+		_, err := service.buildMetric(query)
+		require.EqualError(t, err, `query A: unsupported downsample fill policy "previous"`)
+	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"filters": [
+							{ "type": "literal_or", "tagk": "host", "filter": "web01|web02", "groupBy": true },
+							{ "type": "wildcard", "tagk": "dc", "filter": "eu-*" },
+							{ "type": "regexp", "tagk": "env", "filter": "prod.*", "groupBy": false }
+						]
+					}`,
+			),
+		}
+
+		metric, err := service.buildMetric(query)
+		require.NoError(t, err)
+
+		require.Len(t, metric, 4)
+		require.Equal(t, true, metric["explicitTags"])
+		require.Equal(t, []map[string]interface{}{
+			{"type": "literal_or", "tagk": "host", "filter": "web01|web02", "groupBy": true},
+			{"type": "wildcard", "tagk": "dc", "filter": "eu-*", "groupBy": false},
+			{"type": "regexp", "tagk": "env", "filter": "prod.*", "groupBy": false},
+		}, metric["filters"])
+	})
+
+	t.Run("Build metric with invalid filters", func(t *testing.T) {
+		query := backend.DataQuery{
+			RefID: "B",
+			JSON:  []byte(`{ "metric": "cpu", "filters": [{ "type": "glob", "tagk": "host", "filter": "web*" }] }`),
+		}
+		_, err := service.buildMetric(query)
+		require.EqualError(t, err, `query B: unsupported filter type "glob"`)
+
+		query.JSON = []byte(`{ "metric": "cpu", "filters": [{ "type": "wildcard", "filter": "web*" }] }`)
+		_, err = service.buildMetric(query)
+		require.EqualError(t, err, `query B: filter wildcard has no tag key`)
+	})
+
+	t.Run("Parse response should sort the data points and keep the tags and missing values", func(t *testing.T) {
+		response := `
+		[
+			{
+				"metric": "cpu",
+				"tags": { "host": "web01" },
+				"aggregateTags": ["dc"],
+				"dps": {
+					"1405544206": NaN,
+					"1405544146": 50.0,
+					"1405544266": null,
+					"1405544176": 25.5
+				}
+			}
+		]`
+
+		resp := http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+		result, err := service.parseResponse(&resp)
+		require.NoError(t, err)
+
+		testFrame := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{
+				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 56, 16, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 56, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 57, 46, 0, time.UTC),
+			}),
+			data.NewField("value", data.Labels{"host": "web01"}, []*float64{
+				float64Ptr(50), float64Ptr(25.5), nil, nil}),
+		)
+		if diff := cmp.Diff(testFrame, result.Responses["A"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestOpenTsdbAnnotationQuery(t *testing.T) {
+	var requests []OpenTsdbQuery
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var tsdbQuery OpenTsdbQuery
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&tsdbQuery))
+		requests = append(requests, tsdbQuery)
+
+		_, err := rw.Write([]byte(`[{
+			"metric": "deploys",
+			"dps": { "1405544146": 1 },
+			"annotations": [
+				{ "tsuid": "000001", "description": "deploy v2", "startTime": 1405544206, "endTime": 1405544266 },
+				{ "tsuid": "000001", "description": "deploy v1", "startTime": 1405544146 }
+			],
+			"globalAnnotations": [
+				{ "description": "maintenance", "startTime": 1405544000 }
+			]
+		}]`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{
+		logger: log.New("test"),
+		im: datasource.NewInstanceManager(func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+			return &datasourceInfo{HTTPClient: server.Client(), URL: server.URL}, nil
+		}),
+	}
+	timeRange := backend.TimeRange{
+		From: time.Date(2014, 7, 16, 20, 0, 0, 0, time.UTC),
+		To:   time.Date(2014, 7, 16, 21, 0, 0, 0, time.UTC),
+	}
+	queryData := func(t *testing.T, queries ...backend.DataQuery) *backend.QueryDataResponse {
+		t.Helper()
+		requests = nil
+		for i := range queries {
+			queries[i].TimeRange = timeRange
+		}
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}},
+			Queries:       queries,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("Should return the annotations of the series of a metric", func(t *testing.T) {
+		resp := queryData(t, backend.DataQuery{
+			RefID: "Anno",
+			JSON:  []byte(`{ "type": "annotationQuery", "target": "deploys", "tags": { "app": "grafana" } }`),
+		})
+
+		require.Len(t, requests, 1)
+		assert.False(t, requests[0].GlobalAnnotations)
+		assert.Equal(t, []map[string]interface{}{
+			{"metric": "deploys", "aggregator": "sum", "tags": map[string]interface{}{"app": "grafana"}},
+		}, requests[0].Queries)
+
+		end := time.Date(2014, 7, 16, 20, 57, 46, 0, time.UTC)
+		testFrame := data.NewFrame("Anno",
+			data.NewField("time", nil, []time.Time{
+				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 56, 46, 0, time.UTC),
+			}),
+			data.NewField("timeEnd", nil, []*time.Time{nil, &end}),
+			data.NewField("text", nil, []string{"deploy v1", "deploy v2"}),
+			data.NewField("tsuid", nil, []string{"000001", "000001"}),
+		)
+		if diff := cmp.Diff(testFrame, resp.Responses["Anno"].Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Should return the global annotations with the metric queries", func(t *testing.T) {
+		resp := queryData(t,
+			backend.DataQuery{RefID: "A", JSON: []byte(`{ "metric": "deploys", "aggregator": "sum", "disableDownsampling": true }`)},
+			backend.DataQuery{RefID: "Anno", JSON: []byte(`{ "type": "annotationQuery", "target": "deploys", "isGlobal": true }`)},
+		)
+
+		require.Len(t, requests, 2)
+		assert.False(t, requests[0].GlobalAnnotations)
+		assert.True(t, requests[1].GlobalAnnotations)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+
+		frame := resp.Responses["Anno"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, "maintenance", frame.Fields[2].At(0))
+	})
+
+	t.Run("Should fail on annotation queries without a metric", func(t *testing.T) {
+		_, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1}},
+			Queries:       []backend.DataQuery{{RefID: "Anno", TimeRange: timeRange, JSON: []byte(`{ "type": "annotationQuery" }`)}},
+		})
+		require.EqualError(t, err, "annotation query Anno has no metric")
+	})
 }
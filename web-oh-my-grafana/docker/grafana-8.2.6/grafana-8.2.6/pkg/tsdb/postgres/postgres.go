@@ -30,7 +30,8 @@ func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager) (*Service,
 	}
 	s.im = datasource.NewInstanceManager(s.newInstanceSettings(cfg))
 	factory := coreplugin.New(backend.ServeOpts{
-		QueryDataHandler: s,
+		QueryDataHandler:    s,
+		CallResourceHandler: sqleng.NewResourceHandler(s.getDSInfo),
 	})
 
 	if err := manager.Register("postgres", factory); err != nil {
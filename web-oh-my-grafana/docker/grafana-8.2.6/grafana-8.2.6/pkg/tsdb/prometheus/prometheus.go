@@ -8,6 +8,7 @@ import (
 	"math"
 	"net/http"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -34,15 +35,35 @@ var (
 	safeRes      = 11000
 )
 
+// Result types of the frames, set in the custom meta of the frames so that
+// range and instant results of the same query can be told apart.
+const (
+	resultTypeMatrix   = "matrix"
+	resultTypeVector   = "vector"
+	resultTypeScalar   = "scalar"
+	resultTypeString   = "string"
+	resultTypeExemplar = "exemplar"
+)
+
 type DatasourceInfo struct {
 	ID           int64
 	URL          string
 	HTTPMethod   string
 	TimeInterval string
 
+	ExemplarTraceIDDestinations []ExemplarTraceIDDestination
+
 	promClient apiv1.API
 }
 
+// ExemplarTraceIDDestination links the exemplars with a trace ID label to
+// their trace. Links to another data source are resolved by the frontend.
+type ExemplarTraceIDDestination struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	DatasourceUID string `json:"datasourceUid"`
+}
+
 type QueryModel struct {
 	Expr           string `json:"expr"`
 	LegendFormat   string `json:"legendFormat"`
@@ -51,6 +72,7 @@ type QueryModel struct {
 	StepMode       string `json:"stepMode"`
 	RangeQuery     bool   `json:"range"`
 	InstantQuery   bool   `json:"instant"`
+	ExemplarQuery  bool   `json:"exemplar"`
 	IntervalFactor int64  `json:"intervalFactor"`
 }
 
@@ -116,17 +138,25 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			}
 		}
 
+		exemplarSettings := struct {
+			ExemplarTraceIDDestinations []ExemplarTraceIDDestination `json:"exemplarTraceIdDestinations"`
+		}{}
+		if err := json.Unmarshal(settings.JSONData, &exemplarSettings); err != nil {
+			return nil, fmt.Errorf("invalid exemplar trace ID destinations: %w", err)
+		}
+
 		client, err := createClient(settings.URL, httpCliOpts, httpClientProvider)
 		if err != nil {
 			return nil, err
 		}
 
 		mdl := DatasourceInfo{
-			ID:           settings.ID,
-			URL:          settings.URL,
-			HTTPMethod:   httpMethod,
-			TimeInterval: timeInterval,
-			promClient:   client,
+			ID:                          settings.ID,
+			URL:                         settings.URL,
+			HTTPMethod:                  httpMethod,
+			TimeInterval:                timeInterval,
+			ExemplarTraceIDDestinations: exemplarSettings.ExemplarTraceIDDestinations,
+			promClient:                  client,
 		}
 
 		return mdl, nil
@@ -170,18 +200,47 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+		frames := data.Frames{}
 
-		if err != nil {
-			return &result, err
+		if query.RangeQuery {
+			value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+			if err != nil {
+				return &result, err
+			}
+
+			rangeFrames, err := parseResponse(value, query)
+			if err != nil {
+				return &result, err
+			}
+			frames = append(frames, rangeFrames...)
 		}
 
-		frame, err := parseResponse(value, query)
-		if err != nil {
-			return &result, err
+		if query.InstantQuery {
+			value, _, err := client.Query(ctx, query.Expr, query.End)
+			if err != nil {
+				return &result, err
+			}
+
+			instantFrames, err := parseResponse(value, query)
+			if err != nil {
+				return &result, err
+			}
+			frames = append(frames, instantFrames...)
 		}
+
+		if query.ExemplarQuery {
+			exemplars, err := client.QueryExemplars(ctx, query.Expr, query.Start, query.End)
+			if err != nil {
+				// Exemplar storage is disabled by default in Prometheus, and
+				// the series are still useful without their exemplars.
+				plog.Debug("Failed to query exemplars", "query", query.Expr, "error", err)
+			} else if frame := parseExemplars(exemplars, dsInfo.ExemplarTraceIDDestinations); frame != nil {
+				frames = append(frames, frame)
+			}
+		}
+
 		result.Responses[query.RefId] = backend.DataResponse{
-			Frames: frame,
+			Frames: frames,
 		}
 	}
 
@@ -285,13 +344,19 @@ func (s *Service) parseQuery(queryContext *backend.QueryDataRequest, dsInfo *Dat
 		expr = strings.ReplaceAll(expr, "$__range", strconv.FormatInt(rangeS, 10)+"s")
 		expr = strings.ReplaceAll(expr, "$__rate_interval", intervalv2.FormatDuration(calculateRateInterval(interval, dsInfo.TimeInterval, s.intervalCalculator)))
 
+		// Queries without a type are range queries, as in the query editor.
+		rangeQuery := model.RangeQuery || !model.InstantQuery
+
 		qs = append(qs, &PrometheusQuery{
-			Expr:         expr,
-			Step:         interval,
-			LegendFormat: model.LegendFormat,
-			Start:        query.TimeRange.From,
-			End:          query.TimeRange.To,
-			RefId:        query.RefID,
+			Expr:          expr,
+			Step:          interval,
+			LegendFormat:  model.LegendFormat,
+			Start:         query.TimeRange.From,
+			End:           query.TimeRange.To,
+			RefId:         query.RefID,
+			RangeQuery:    rangeQuery,
+			InstantQuery:  model.InstantQuery,
+			ExemplarQuery: model.ExemplarQuery,
 		})
 	}
 
@@ -299,12 +364,25 @@ func (s *Service) parseQuery(queryContext *backend.QueryDataRequest, dsInfo *Dat
 }
 
 func parseResponse(value model.Value, query *PrometheusQuery) (data.Frames, error) {
-	frames := data.Frames{}
+	switch v := value.(type) {
+	case model.Matrix:
+		return parseMatrix(v, query), nil
+	case model.Vector:
+		return parseVector(v, query), nil
+	case *model.Scalar:
+		return data.Frames{parseScalar(v)}, nil
+	case *model.String:
+		return data.Frames{parseString(v)}, nil
+	}
 
-	matrix, ok := value.(model.Matrix)
-	if !ok {
-		return frames, fmt.Errorf("unsupported result format: %q", value.Type().String())
+	if value == nil {
+		return data.Frames{}, fmt.Errorf("unsupported result format: %v", value)
 	}
+	return data.Frames{}, fmt.Errorf("unsupported result format: %q", value.Type().String())
+}
+
+func parseMatrix(matrix model.Matrix, query *PrometheusQuery) data.Frames {
+	frames := data.Frames{}
 
 	for _, v := range matrix {
 		name := formatLegend(v.Metric, query)
@@ -320,12 +398,119 @@ func parseResponse(value model.Value, query *PrometheusQuery) (data.Frames, erro
 			timeVector = append(timeVector, time.Unix(k.Timestamp.Unix(), 0).UTC())
 			values = append(values, float64(k.Value))
 		}
-		frames = append(frames, data.NewFrame(name,
+		frame := data.NewFrame(name,
 			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name})))
+			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}))
+		frame.Meta = resultTypeMeta(resultTypeMatrix)
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func parseVector(vector model.Vector, query *PrometheusQuery) data.Frames {
+	frames := data.Frames{}
+
+	for _, v := range vector {
+		name := formatLegend(v.Metric, query)
+		tags := make(map[string]string, len(v.Metric))
+		for k, v := range v.Metric {
+			tags[string(k)] = string(v)
+		}
+
+		frame := data.NewFrame(name,
+			data.NewField("time", nil, []time.Time{v.Timestamp.Time().UTC()}),
+			data.NewField("value", tags, []float64{float64(v.Value)}).SetConfig(&data.FieldConfig{DisplayNameFromDS: name}))
+		frame.Meta = resultTypeMeta(resultTypeVector)
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
+
+func parseScalar(scalar *model.Scalar) *data.Frame {
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{scalar.Timestamp.Time().UTC()}),
+		data.NewField("value", nil, []float64{float64(scalar.Value)}))
+	frame.Meta = resultTypeMeta(resultTypeScalar)
+	return frame
+}
+
+func parseString(str *model.String) *data.Frame {
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{str.Timestamp.Time().UTC()}),
+		data.NewField("value", nil, []string{str.Value}))
+	frame.Meta = resultTypeMeta(resultTypeString)
+	return frame
+}
+
+// parseExemplars returns a single frame with all the exemplars, with a field
+// for each label of the exemplars and of their series. The fields of the
+// trace ID labels link to the trace of the exemplars.
+func parseExemplars(results []apiv1.ExemplarQueryResult, destinations []ExemplarTraceIDDestination) *data.Frame {
+	var count int
+	labelNames := map[string]struct{}{}
+	for _, result := range results {
+		for name := range result.SeriesLabels {
+			labelNames[string(name)] = struct{}{}
+		}
+		for _, exemplar := range result.Exemplars {
+			for name := range exemplar.Labels {
+				labelNames[string(name)] = struct{}{}
+			}
+		}
+		count += len(result.Exemplars)
+	}
+	if count == 0 {
+		return nil
 	}
 
-	return frames, nil
+	names := make([]string, 0, len(labelNames))
+	for name := range labelNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	timeVector := make([]time.Time, 0, count)
+	values := make([]float64, 0, count)
+	labelValues := make([][]string, len(names))
+	for _, result := range results {
+		for _, exemplar := range result.Exemplars {
+			timeVector = append(timeVector, exemplar.Timestamp.Time().UTC())
+			values = append(values, float64(exemplar.Value))
+			for i, name := range names {
+				// Labels of the exemplar take precedence over the labels of
+				// their series.
+				value, ok := exemplar.Labels[model.LabelName(name)]
+				if !ok {
+					value = result.SeriesLabels[model.LabelName(name)]
+				}
+				labelValues[i] = append(labelValues[i], string(value))
+			}
+		}
+	}
+
+	frame := data.NewFrame("exemplar",
+		data.NewField("Time", nil, timeVector),
+		data.NewField("Value", nil, values))
+	for i, name := range names {
+		field := data.NewField(name, nil, labelValues[i])
+		for _, destination := range destinations {
+			if destination.Name == name && destination.URL != "" && destination.DatasourceUID == "" {
+				field.SetConfig(&data.FieldConfig{
+					Links: []data.DataLink{{Title: name, URL: destination.URL}},
+				})
+			}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	frame.Meta = resultTypeMeta(resultTypeExemplar)
+
+	return frame
+}
+
+func resultTypeMeta(resultType string) *data.FrameMeta {
+	return &data.FrameMeta{Custom: map[string]string{"resultType": resultType}}
 }
 
 // IsAPIError returns whether err is or wraps a Prometheus error.
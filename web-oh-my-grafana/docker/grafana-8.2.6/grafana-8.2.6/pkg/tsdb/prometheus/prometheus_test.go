@@ -4,9 +4,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	p "github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -65,6 +67,37 @@ func TestPrometheus_parseQuery(t *testing.T) {
 		require.Equal(t, time.Second*30, models[0].Step)
 	})
 
+	t.Run("parsing query model with query types", func(t *testing.T) {
+		timeRange := backend.TimeRange{
+			From: now,
+			To:   now.Add(12 * time.Hour),
+		}
+		dsInfo := &DatasourceInfo{}
+
+		models, err := service.parseQuery(queryContext(`{"expr": "go_goroutines", "refId": "A"}`, timeRange), dsInfo)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.False(t, models[0].InstantQuery)
+		require.False(t, models[0].ExemplarQuery)
+
+		models, err = service.parseQuery(queryContext(`{"expr": "go_goroutines", "refId": "A", "instant": true}`, timeRange), dsInfo)
+		require.NoError(t, err)
+		require.False(t, models[0].RangeQuery)
+		require.True(t, models[0].InstantQuery)
+
+		models, err = service.parseQuery(queryContext(`{
+			"expr": "go_goroutines",
+			"refId": "A",
+			"range": true,
+			"instant": true,
+			"exemplar": true
+		}`, timeRange), dsInfo)
+		require.NoError(t, err)
+		require.True(t, models[0].RangeQuery)
+		require.True(t, models[0].InstantQuery)
+		require.True(t, models[0].ExemplarQuery)
+	})
+
 	t.Run("parsing query model without step parameter", func(t *testing.T) {
 		timeRange := backend.TimeRange{
 			From: now,
@@ -290,11 +323,10 @@ func queryContext(json string, timeRange backend.TimeRange) *backend.QueryDataRe
 }
 
 func TestParseResponse(t *testing.T) {
-	t.Run("value is not of type matrix", func(t *testing.T) {
+	t.Run("value is missing", func(t *testing.T) {
 		//nolint: staticcheck // plugins.DataQueryResult deprecated
 		queryRes := data.Frames{}
-		value := p.Vector{}
-		res, err := parseResponse(value, nil)
+		res, err := parseResponse(nil, nil)
 
 		require.Equal(t, queryRes, res)
 		require.Error(t, err)
@@ -334,4 +366,86 @@ func TestParseResponse(t *testing.T) {
 		testValue := res[0].Fields[0].At(0)
 		require.Equal(t, "UTC", testValue.(time.Time).Location().String())
 	})
+
+	t.Run("vector should be parsed as a frame per series", func(t *testing.T) {
+		value := p.Vector{
+			&p.Sample{Metric: p.Metric{"app": "backend"}, Value: 1, Timestamp: 5000},
+			&p.Sample{Metric: p.Metric{"app": "frontend"}, Value: 2, Timestamp: 5000},
+		}
+		query := &PrometheusQuery{LegendFormat: "{{app}}"}
+		res, err := parseResponse(value, query)
+		require.NoError(t, err)
+
+		require.Len(t, res, 2)
+		require.Equal(t, "frontend", res[1].Name)
+		require.Equal(t, time.Date(1970, 1, 1, 0, 0, 5, 0, time.UTC), res[1].Fields[0].At(0))
+		require.Equal(t, 2.0, res[1].Fields[1].At(0))
+		require.Equal(t, "app=frontend", res[1].Fields[1].Labels.String())
+		require.Equal(t, map[string]string{"resultType": "vector"}, res[1].Meta.Custom)
+	})
+
+	t.Run("scalar and string should be parsed as a single value", func(t *testing.T) {
+		res, err := parseResponse(&p.Scalar{Value: 42, Timestamp: 5000}, &PrometheusQuery{})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, 42.0, res[0].Fields[1].At(0))
+		require.Equal(t, map[string]string{"resultType": "scalar"}, res[0].Meta.Custom)
+
+		res, err = parseResponse(&p.String{Value: "up", Timestamp: 5000}, &PrometheusQuery{})
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		require.Equal(t, time.Date(1970, 1, 1, 0, 0, 5, 0, time.UTC), res[0].Fields[0].At(0))
+		require.Equal(t, "up", res[0].Fields[1].At(0))
+		require.Equal(t, map[string]string{"resultType": "string"}, res[0].Meta.Custom)
+	})
+}
+
+func TestParseExemplars(t *testing.T) {
+	t.Run("no exemplars", func(t *testing.T) {
+		require.Nil(t, parseExemplars([]apiv1.ExemplarQueryResult{{SeriesLabels: p.LabelSet{"job": "api"}}}, nil))
+	})
+
+	t.Run("exemplars should be parsed in a single frame", func(t *testing.T) {
+		results := []apiv1.ExemplarQueryResult{
+			{
+				SeriesLabels: p.LabelSet{"job": "api"},
+				Exemplars: []apiv1.Exemplar{
+					{Labels: p.LabelSet{"traceID": "abc"}, Value: 0.5, Timestamp: 1000},
+					{Labels: p.LabelSet{"traceID": "def", "job": "override"}, Value: 1.5, Timestamp: 2000},
+				},
+			},
+			{
+				SeriesLabels: p.LabelSet{"job": "worker", "queue": "emails"},
+				Exemplars: []apiv1.Exemplar{
+					{Labels: p.LabelSet{"traceID": "ghi"}, Value: 2.5, Timestamp: 3000},
+				},
+			},
+		}
+		destinations := []ExemplarTraceIDDestination{
+			{Name: "traceID", URL: "http://tracing/${__value.raw}"},
+			{Name: "job", DatasourceUID: "tempo"},
+		}
+
+		frame := parseExemplars(results, destinations)
+		require.NotNil(t, frame)
+
+		traceID := data.NewField("traceID", nil, []string{"abc", "def", "ghi"})
+		traceID.SetConfig(&data.FieldConfig{Links: []data.DataLink{{Title: "traceID", URL: "http://tracing/${__value.raw}"}}})
+		expected := data.NewFrame("exemplar",
+			data.NewField("Time", nil, []time.Time{
+				time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 0, 2, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 0, 3, 0, time.UTC),
+			}),
+			data.NewField("Value", nil, []float64{0.5, 1.5, 2.5}),
+			data.NewField("job", nil, []string{"api", "override", "worker"}),
+			data.NewField("queue", nil, []string{"", "", "emails"}),
+			traceID,
+		)
+		expected.Meta = &data.FrameMeta{Custom: map[string]string{"resultType": "exemplar"}}
+
+		if diff := cmp.Diff(expected, frame, data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
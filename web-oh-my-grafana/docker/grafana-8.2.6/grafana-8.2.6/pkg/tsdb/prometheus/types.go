@@ -9,4 +9,8 @@ type PrometheusQuery struct {
 	Start        time.Time
 	End          time.Time
 	RefId        string
+
+	RangeQuery    bool
+	InstantQuery  bool
+	ExemplarQuery bool
 }
@@ -7,7 +7,10 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/querycache"
+	"github.com/grafana/grafana/pkg/services/querylimits"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/cloudmonitoring"
 	_ "github.com/grafana/grafana/pkg/tsdb/postgres"
@@ -20,8 +23,13 @@ func NewService(
 	backendPluginManager backendplugin.Manager,
 	oauthTokenService *oauthtoken.Service,
 	cloudMonitoringService *cloudmonitoring.Service,
+	queryCacheService querycache.Service,
+	queryLimitsService querylimits.Service,
+	encryptionService encryption.Service,
 ) *Service {
-	s := newService(cfg, pluginManager, backendPluginManager, oauthTokenService)
+	s := newService(cfg, pluginManager, backendPluginManager, oauthTokenService, encryptionService)
+	s.QueryCache = queryCacheService
+	s.QueryLimits = queryLimitsService
 
 	// register backend data sources using legacy plugin
 	// contracts/non-SDK contracts
@@ -31,7 +39,7 @@ func NewService(
 }
 
 func newService(cfg *setting.Cfg, manager plugins.Manager, backendPluginManager backendplugin.Manager,
-	oauthTokenService oauthtoken.OAuthTokenService) *Service {
+	oauthTokenService oauthtoken.OAuthTokenService, encryptionService encryption.Service) *Service {
 	return &Service{
 		Cfg:                  cfg,
 		PluginManager:        manager,
@@ -39,6 +47,7 @@ func newService(cfg *setting.Cfg, manager plugins.Manager, backendPluginManager
 		// nolint:staticcheck // plugins.DataPlugin deprecated
 		registry:          map[string]func(*models.DataSource) (plugins.DataPlugin, error){},
 		OAuthTokenService: oauthTokenService,
+		EncryptionService: encryptionService,
 	}
 }
 
@@ -48,12 +57,34 @@ type Service struct {
 	PluginManager        plugins.Manager
 	BackendPluginManager backendplugin.Manager
 	OAuthTokenService    oauthtoken.OAuthTokenService
+	EncryptionService    encryption.Service
+	// QueryCache caches the query responses, queries aren't cached when nil.
+	QueryCache querycache.Service
+	// QueryLimits limits the queries sent to the data sources, queries
+	// aren't limited when nil.
+	QueryLimits querylimits.Service
 	//nolint: staticcheck // plugins.DataPlugin deprecated
 	registry map[string]func(*models.DataSource) (plugins.DataPlugin, error)
 }
 
 //nolint: staticcheck // plugins.DataPlugin deprecated
 func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	if s.QueryCache != nil {
+		return s.QueryCache.HandleRequest(ctx, ds, query, s.handleLimitedRequest)
+	}
+	return s.handleLimitedRequest(ctx, ds, query)
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) handleLimitedRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
+	if s.QueryLimits != nil {
+		return s.QueryLimits.HandleRequest(ctx, ds, query, s.handleRequest)
+	}
+	return s.handleRequest(ctx, ds, query)
+}
+
+//nolint: staticcheck // plugins.DataPlugin deprecated
+func (s *Service) handleRequest(ctx context.Context, ds *models.DataSource, query plugins.DataQuery) (plugins.DataResponse, error) {
 	if factory, exists := s.registry[ds.Type]; exists {
 		var err error
 		plugin, err := factory(ds)
@@ -65,7 +96,7 @@ func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, quer
 
 		return plugin.DataQuery(ctx, ds, query)
 	}
-	return dataPluginQueryAdapter(ds.Type, s.BackendPluginManager, s.OAuthTokenService).DataQuery(ctx, ds, query)
+	return dataPluginQueryAdapter(ds.Type, s.BackendPluginManager, s.OAuthTokenService, s.EncryptionService).DataQuery(ctx, ds, query)
 }
 
 // RegisterQueryHandler registers a query handler factory.
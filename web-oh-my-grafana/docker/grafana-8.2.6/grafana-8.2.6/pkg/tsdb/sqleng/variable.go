@@ -0,0 +1,288 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/plugins"
+)
+
+const (
+	searchFilterMacro       = "$__searchFilter"
+	searchFilterBracedMacro = "${__searchFilter}"
+)
+
+// SQLStringEscaper is implemented by the macro engines of the databases that
+// need more than doubling the single quotes to escape a string literal.
+type SQLStringEscaper interface {
+	EscapeString(s string) string
+}
+
+// VariableQuery is a template variable query of the variable resource.
+type VariableQuery struct {
+	RawSql string `json:"rawSql"`
+	// SearchFilter replaces the $__searchFilter macro, followed by the % wildcard.
+	SearchFilter string `json:"searchFilter"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	// Limit is the number of values of a page, 0 returns all the values.
+	Limit int `json:"limit"`
+	// Page is the 1-based page of values to return.
+	Page int `json:"page"`
+}
+
+// MetricFindValue is a value of a template variable.
+type MetricFindValue struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// VariableQueryResult is a page of values of a template variable query.
+type VariableQueryResult struct {
+	Values []MetricFindValue `json:"values"`
+	// Total is the number of values of all the pages.
+	Total int `json:"total"`
+}
+
+// VariableQuery runs a template variable query, with the values of the
+// __text and __value columns when the query has both, otherwise with the
+// values of all the columns.
+func (e *DataSourceHandler) VariableQuery(ctx context.Context, vq VariableQuery) (*VariableQueryResult, error) {
+	if vq.RawSql == "" {
+		return nil, errors.New("missing rawSql")
+	}
+	if vq.Limit < 0 || vq.Page < 0 {
+		return nil, errors.New("limit and page must not be negative")
+	}
+
+	from, to := vq.From, vq.To
+	if from == "" {
+		from = "now-6h"
+	}
+	if to == "" {
+		to = "now"
+	}
+	tr := plugins.NewDataTimeRange(from, to)
+	timeRange := backend.TimeRange{}
+	var err error
+	if timeRange.From, err = tr.ParseFrom(); err != nil {
+		return nil, fmt.Errorf("invalid from: %w", err)
+	}
+	if timeRange.To, err = tr.ParseTo(); err != nil {
+		return nil, fmt.Errorf("invalid to: %w", err)
+	}
+
+	query := backend.DataQuery{
+		RefID:     "tempvar",
+		TimeRange: timeRange,
+		JSON:      []byte("{}"),
+	}
+	interpolatedQuery, err := Interpolate(query, timeRange, e.dsInfo.JsonData.TimeInterval, vq.RawSql)
+	if err != nil {
+		return nil, fmt.Errorf("interpolation failed: %w", e.transformQueryError(err))
+	}
+	interpolatedQuery, err = e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("interpolation failed: %w", e.transformQueryError(err))
+	}
+
+	// The search filter is replaced last so that it can't contain macros.
+	filterInQuery := strings.Contains(interpolatedQuery, searchFilterMacro) ||
+		strings.Contains(interpolatedQuery, searchFilterBracedMacro)
+	if filterInQuery {
+		filter := e.escapeString(vq.SearchFilter) + "%"
+		interpolatedQuery = strings.ReplaceAll(interpolatedQuery, searchFilterBracedMacro, filter)
+		interpolatedQuery = strings.ReplaceAll(interpolatedQuery, searchFilterMacro, filter)
+	}
+
+	session := e.engine.NewSession()
+	defer session.Close()
+
+	rows, err := session.DB().QueryContext(ctx, interpolatedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("db query error: %w", e.transformQueryError(err))
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	stringConverters := e.queryResultTransformer.GetConverterList()
+	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	if err != nil {
+		return nil, fmt.Errorf("convert frame from rows error: %w", err)
+	}
+
+	values := metricFindValues(frame)
+	if !filterInQuery && vq.SearchFilter != "" {
+		filter := strings.ToLower(vq.SearchFilter)
+		filtered := values[:0]
+		for _, v := range values {
+			if strings.Contains(strings.ToLower(v.Text), filter) {
+				filtered = append(filtered, v)
+			}
+		}
+		values = filtered
+	}
+
+	result := &VariableQueryResult{Values: values, Total: len(values)}
+	if vq.Limit > 0 {
+		page := vq.Page
+		if page == 0 {
+			page = 1
+		}
+		// Compared before multiplying, as large pages and limits overflow.
+		start := len(values)
+		if page-1 <= len(values)/vq.Limit {
+			start = (page - 1) * vq.Limit
+		}
+		end := len(values)
+		if vq.Limit < end-start {
+			end = start + vq.Limit
+		}
+		result.Values = values[start:end]
+	}
+
+	return result, nil
+}
+
+func (e *DataSourceHandler) escapeString(s string) string {
+	if escaper, ok := e.macroEngine.(SQLStringEscaper); ok {
+		return escaper.EscapeString(s)
+	}
+	return strings.ReplaceAll(s, "'", "''")
+}
+
+// metricFindValues returns the distinct values of a frame, with the texts of
+// the first occurrences.
+func metricFindValues(frame *data.Frame) []MetricFindValue {
+	values := make([]MetricFindValue, 0)
+	seen := make(map[string]bool)
+	add := func(text, value string) {
+		if seen[text] {
+			return
+		}
+		seen[text] = true
+		values = append(values, MetricFindValue{Text: text, Value: value})
+	}
+
+	textIndex, valueIndex := -1, -1
+	for i, field := range frame.Fields {
+		switch field.Name {
+		case "__text":
+			textIndex = i
+		case "__value":
+			valueIndex = i
+		}
+	}
+
+	if textIndex != -1 && valueIndex != -1 {
+		for row := 0; row < frame.Rows(); row++ {
+			text, ok := formatVariableValue(frame.Fields[textIndex].At(row))
+			if !ok {
+				continue
+			}
+			value, _ := formatVariableValue(frame.Fields[valueIndex].At(row))
+			add(text, value)
+		}
+		return values
+	}
+
+	for _, field := range frame.Fields {
+		for row := 0; row < field.Len(); row++ {
+			if text, ok := formatVariableValue(field.At(row)); ok {
+				add(text, text)
+			}
+		}
+	}
+	return values
+}
+
+// formatVariableValue formats a value of a frame field, with the times as
+// epoch milliseconds. It returns false for the null values.
+func formatVariableValue(v interface{}) (string, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return "", false
+		}
+		v = rv.Elem().Interface()
+	}
+
+	switch t := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return t, true
+	case time.Time:
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10), true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32), true
+	case json.RawMessage:
+		return string(t), true
+	default:
+		return fmt.Sprint(t), true
+	}
+}
+
+// NewResourceHandler returns the resource handler of the SQL data sources,
+// with the data source handlers of getHandler. The /variable resource runs
+// the VariableQuery posted as JSON and returns its VariableQueryResult.
+func NewResourceHandler(getHandler func(backend.PluginContext) (*DataSourceHandler, error)) backend.CallResourceHandler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/variable", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			writeResourceError(rw, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		var vq VariableQuery
+		if err := json.NewDecoder(req.Body).Decode(&vq); err != nil {
+			writeResourceError(rw, http.StatusBadRequest, fmt.Errorf("invalid variable query: %w", err))
+			return
+		}
+
+		dsHandler, err := getHandler(httpadapter.PluginConfigFromContext(req.Context()))
+		if err != nil {
+			writeResourceError(rw, http.StatusInternalServerError, err)
+			return
+		}
+
+		result, err := dsHandler.VariableQuery(req.Context(), vq)
+		if err != nil {
+			writeResourceError(rw, http.StatusBadRequest, err)
+			return
+		}
+		writeResourceJSON(rw, http.StatusOK, result)
+	})
+	return httpadapter.New(mux)
+}
+
+func writeResourceError(rw http.ResponseWriter, status int, err error) {
+	writeResourceJSON(rw, status, map[string]string{"message": err.Error()})
+}
+
+func writeResourceJSON(rw http.ResponseWriter, status int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_, _ = rw.Write(body)
+}
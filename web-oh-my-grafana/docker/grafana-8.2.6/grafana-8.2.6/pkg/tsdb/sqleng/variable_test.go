@@ -0,0 +1,254 @@
+package sqleng
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/grafana/grafana/pkg/infra/log"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMacroEngine replaces the $__timeFilter(column) macro with a filter of
+// the Unix timestamps of the time range.
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	return strings.ReplaceAll(sql, "$__timeFilter(ts)",
+		fmt.Sprintf("ts BETWEEN %d AND %d", timeRange.From.Unix(), timeRange.To.Unix())), nil
+}
+
+// testVariableResultTransformer converts the TEXT and INTEGER columns of
+// SQLite, whose driver has no scan types before reading the rows.
+type testVariableResultTransformer struct {
+	testQueryResultTransformer
+}
+
+func (t *testVariableResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return []sqlutil.StringConverter{
+		{
+			Name:           "handle TEXT",
+			InputScanKind:  reflect.String,
+			InputTypeName:  "TEXT",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableString,
+				ReplaceFunc: func(in *string) (interface{}, error) {
+					return in, nil
+				},
+			},
+		},
+		{
+			Name:           "handle INTEGER",
+			InputScanKind:  reflect.Int64,
+			InputTypeName:  "INTEGER",
+			ConversionFunc: func(in *string) (*string, error) { return in, nil },
+			Replacer: &sqlutil.StringFieldReplacer{
+				OutputFieldType: data.FieldTypeNullableInt64,
+				ReplaceFunc: func(in *string) (interface{}, error) {
+					if in == nil {
+						return nil, nil
+					}
+					v, err := strconv.ParseInt(*in, 10, 64)
+					if err != nil {
+						return nil, err
+					}
+					return &v, nil
+				},
+			},
+		},
+	}
+}
+
+func newTestVariableHandler(t *testing.T) *DataSourceHandler {
+	t.Helper()
+
+	handler, err := NewQueryDataHandler(DataPluginConfiguration{
+		DriverName:       "sqlite3",
+		ConnectionString: "file:" + filepath.Join(t.TempDir(), "test.db"),
+		RowLimit:         1000,
+	}, &testVariableResultTransformer{}, &testMacroEngine{}, log.New("test"))
+	require.NoError(t, err)
+	t.Cleanup(handler.Dispose)
+
+	_, err = handler.engine.Exec(`CREATE TABLE hosts (name TEXT, id INTEGER, ts INTEGER)`)
+	require.NoError(t, err)
+	_, err = handler.engine.Exec(`INSERT INTO hosts VALUES
+		('web-1', 1, 1521118800), ('web-2', 2, 1521118800), ('db-1', 3, 1521118800),
+		('it''s', 4, 1521118800), ('web-1', 5, 1521118800), (NULL, 6, 1521118800), ('old', 7, 100)`)
+	require.NoError(t, err)
+	return handler
+}
+
+func TestVariableQuery(t *testing.T) {
+	handler := newTestVariableHandler(t)
+	const from, to = "1521118000000", "1521119000000"
+
+	t.Run("Should return the distinct values of all the columns", func(t *testing.T) {
+		result, err := handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT name FROM hosts WHERE $__timeFilter(ts) ORDER BY id",
+			From:   from,
+			To:     to,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.Total)
+		assert.Equal(t, []MetricFindValue{
+			{Text: "web-1", Value: "web-1"},
+			{Text: "web-2", Value: "web-2"},
+			{Text: "db-1", Value: "db-1"},
+			{Text: "it's", Value: "it's"},
+		}, result.Values)
+	})
+
+	t.Run("Should return the __text and __value pairs", func(t *testing.T) {
+		result, err := handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT name AS __text, id AS __value FROM hosts WHERE $__timeFilter(ts) ORDER BY id",
+			From:   from,
+			To:     to,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []MetricFindValue{
+			{Text: "web-1", Value: "1"},
+			{Text: "web-2", Value: "2"},
+			{Text: "db-1", Value: "3"},
+			{Text: "it's", Value: "4"},
+		}, result.Values)
+	})
+
+	t.Run("Should replace the search filter macro with an escaped filter", func(t *testing.T) {
+		result, err := handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql:       "SELECT name FROM hosts WHERE name LIKE '$__searchFilter' ORDER BY id",
+			SearchFilter: "it's",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []MetricFindValue{{Text: "it's", Value: "it's"}}, result.Values)
+
+		result, err = handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT name FROM hosts WHERE name LIKE '${__searchFilter}' ORDER BY id",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5, result.Total)
+	})
+
+	t.Run("Should filter the values of the queries without the search filter macro", func(t *testing.T) {
+		result, err := handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql:       "SELECT name FROM hosts ORDER BY id",
+			SearchFilter: "WEB",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []MetricFindValue{
+			{Text: "web-1", Value: "web-1"},
+			{Text: "web-2", Value: "web-2"},
+		}, result.Values)
+	})
+
+	t.Run("Should return a page of values", func(t *testing.T) {
+		result, err := handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT id FROM hosts ORDER BY id",
+			Limit:  3,
+			Page:   3,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 7, result.Total)
+		assert.Equal(t, []MetricFindValue{{Text: "7", Value: "7"}}, result.Values)
+
+		result, err = handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT id FROM hosts ORDER BY id",
+			Limit:  3,
+			Page:   4,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Values)
+
+		result, err = handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT id FROM hosts ORDER BY id",
+			Limit:  math.MaxInt64,
+			Page:   math.MaxInt64,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.Values)
+
+		result, err = handler.VariableQuery(context.Background(), VariableQuery{
+			RawSql: "SELECT id FROM hosts ORDER BY id",
+			Limit:  math.MaxInt64,
+			Page:   1,
+		})
+		require.NoError(t, err)
+		assert.Len(t, result.Values, 7)
+	})
+
+	t.Run("Should return the query errors", func(t *testing.T) {
+		_, err := handler.VariableQuery(context.Background(), VariableQuery{RawSql: "SELECT name FROM missing"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no such table: missing")
+
+		_, err = handler.VariableQuery(context.Background(), VariableQuery{RawSql: "SELECT 1", From: "yesterday"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid from")
+	})
+}
+
+type fakeResourceSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeResourceSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestVariableResource(t *testing.T) {
+	handler := newTestVariableHandler(t)
+	resourceHandler := NewResourceHandler(func(pluginCtx backend.PluginContext) (*DataSourceHandler, error) {
+		return handler, nil
+	})
+
+	callResource := func(t *testing.T, method string, body string) *backend.CallResourceResponse {
+		t.Helper()
+		sender := &fakeResourceSender{}
+		err := resourceHandler.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: method,
+			Path:   "variable",
+			URL:    "variable",
+			Body:   []byte(body),
+		}, sender)
+		require.NoError(t, err)
+		require.NotNil(t, sender.resp)
+		return sender.resp
+	}
+
+	t.Run("Should return the values of a variable query", func(t *testing.T) {
+		resp := callResource(t, http.MethodPost, `{"rawSql": "SELECT name FROM hosts ORDER BY id", "limit": 2}`)
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result VariableQueryResult
+		require.NoError(t, json.NewDecoder(bytes.NewReader(resp.Body)).Decode(&result))
+		assert.Equal(t, 5, result.Total)
+		assert.Equal(t, []MetricFindValue{
+			{Text: "web-1", Value: "web-1"},
+			{Text: "web-2", Value: "web-2"},
+		}, result.Values)
+	})
+
+	t.Run("Should return the errors as JSON messages", func(t *testing.T) {
+		resp := callResource(t, http.MethodPost, `{"rawSql": ""}`)
+		require.Equal(t, http.StatusBadRequest, resp.Status)
+		assert.JSONEq(t, `{"message": "missing rawSql"}`, string(resp.Body))
+
+		resp = callResource(t, http.MethodGet, "")
+		require.Equal(t, http.StatusMethodNotAllowed, resp.Status)
+	})
+}
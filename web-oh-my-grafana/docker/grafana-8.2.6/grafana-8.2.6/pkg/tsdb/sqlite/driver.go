@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+)
+
+// driverName is the database/sql driver of the SQLite data sources. It wraps
+// the go-sqlite3 driver so that the connections can't attach other database
+// files and the rows report the scan types of their columns.
+const driverName = "sqlite3_datasource"
+
+func init() {
+	sql.Register(driverName, &sqliteDriver{
+		driver: &sqlite3.SQLiteDriver{
+			ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+				conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+				return nil
+			},
+		},
+	})
+	core.RegisterDriver(driverName, &xormDriver{})
+}
+
+// xormDriver lets xorm open engines with the SQLite data source driver.
+type xormDriver struct{}
+
+func (d *xormDriver) Parse(driverName, dataSourceName string) (*core.Uri, error) {
+	if i := strings.Index(dataSourceName, "?"); i >= 0 {
+		dataSourceName = dataSourceName[:i]
+	}
+	return &core.Uri{DbType: core.SQLITE, DbName: dataSourceName}, nil
+}
+
+type sqliteDriver struct {
+	driver *sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.driver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.SQLiteConn.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return newSQLiteRows(rows.(*sqlite3.SQLiteRows))
+}
+
+var (
+	scanTypeInt64   = reflect.TypeOf(int64(0))
+	scanTypeFloat64 = reflect.TypeOf(float64(0))
+	scanTypeString  = reflect.TypeOf("")
+	scanTypeBool    = reflect.TypeOf(false)
+	scanTypeBytes   = reflect.TypeOf([]byte{})
+	scanTypeTime    = reflect.TypeOf(time.Time{})
+)
+
+// sqliteRows reports the column types by the affinity of their declared
+// type, since SQLite itself only knows the type of each value. Columns
+// without a declared type, such as expressions, get the type of their
+// first value.
+type sqliteRows struct {
+	*sqlite3.SQLiteRows
+
+	// first is the first row, read ahead of Next.
+	first    []driver.Value
+	firstErr error
+	peeked   bool
+}
+
+// newSQLiteRows reads the first row of a query ahead, SQLite only running
+// the statement then, so that its errors are returned by the query rather
+// than ending the rows.
+func newSQLiteRows(rows *sqlite3.SQLiteRows) (*sqliteRows, error) {
+	r := &sqliteRows{
+		SQLiteRows: rows,
+		first:      make([]driver.Value, len(rows.Columns())),
+		peeked:     true,
+	}
+	r.firstErr = rows.Next(r.first)
+	if r.firstErr != nil && !errors.Is(r.firstErr, io.EOF) {
+		_ = rows.Close()
+		return nil, r.firstErr
+	}
+	return r, nil
+}
+
+func (r *sqliteRows) Next(dest []driver.Value) error {
+	if r.peeked {
+		r.peeked = false
+		if r.firstErr != nil {
+			return r.firstErr
+		}
+		copy(dest, r.first)
+		return nil
+	}
+	return r.SQLiteRows.Next(dest)
+}
+
+func (r *sqliteRows) ColumnTypeDatabaseTypeName(i int) string {
+	switch r.scanType(i) {
+	case scanTypeInt64:
+		return "INTEGER"
+	case scanTypeFloat64:
+		return "REAL"
+	case scanTypeString:
+		return "TEXT"
+	case scanTypeBool:
+		return "BOOLEAN"
+	case scanTypeBytes:
+		return "BLOB"
+	case scanTypeTime:
+		return "DATETIME"
+	}
+	return ""
+}
+
+func (r *sqliteRows) ColumnTypeScanType(i int) reflect.Type {
+	return r.scanType(i)
+}
+
+func (r *sqliteRows) ColumnTypeNullable(i int) (nullable, ok bool) {
+	return true, true
+}
+
+func (r *sqliteRows) scanType(i int) reflect.Type {
+	if scanType := affinityScanType(r.SQLiteRows.ColumnTypeDatabaseTypeName(i)); scanType != nil {
+		return scanType
+	}
+
+	switch r.first[i].(type) {
+	case int64, float64:
+		// Later values of an expression may have another numeric type.
+		return scanTypeFloat64
+	case []byte:
+		return scanTypeBytes
+	case time.Time:
+		return scanTypeTime
+	default:
+		return scanTypeString
+	}
+}
+
+// affinityScanType returns the scan type of a declared column type, by the
+// rules of https://www.sqlite.org/datatype3.html#determination_of_column_affinity,
+// or nil for the columns without a declared type.
+func affinityScanType(declType string) reflect.Type {
+	declType = strings.ToUpper(declType)
+	if i := strings.Index(declType, "("); i >= 0 {
+		declType = strings.TrimSpace(declType[:i])
+	}
+
+	// The types that go-sqlite3 converts the values of.
+	switch declType {
+	case "":
+		return nil
+	case "DATE", "DATETIME", "TIMESTAMP":
+		return scanTypeTime
+	case "BOOLEAN":
+		return scanTypeBool
+	}
+
+	switch {
+	case strings.Contains(declType, "INT"):
+		return scanTypeInt64
+	case strings.Contains(declType, "CHAR"), strings.Contains(declType, "CLOB"), strings.Contains(declType, "TEXT"):
+		return scanTypeString
+	case strings.Contains(declType, "BLOB"):
+		return scanTypeBytes
+	default:
+		return scanTypeFloat64
+	}
+}
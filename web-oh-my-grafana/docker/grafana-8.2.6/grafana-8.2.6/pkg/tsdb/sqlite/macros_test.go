@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSQLiteMacroEngine()
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	testCases := []struct {
+		sql      string
+		expected string
+	}{
+		{
+			sql:      "select $__time(time_column)",
+			expected: "select CAST(strftime('%s', time_column) AS INTEGER) AS time",
+		},
+		{
+			sql:      "WHERE $__timeFilter(time_column)",
+			expected: fmt.Sprintf("WHERE CAST(strftime('%%s', time_column) AS INTEGER) BETWEEN %d AND %d", from.Unix(), to.Unix()),
+		},
+		{
+			sql:      "select $__timeFrom(), $__timeTo()",
+			expected: fmt.Sprintf("select datetime(%d, 'unixepoch'), datetime(%d, 'unixepoch')", from.Unix(), to.Unix()),
+		},
+		{
+			sql:      "GROUP BY $__timeGroup(time_column , '5m')",
+			expected: "GROUP BY CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300",
+		},
+		{
+			sql:      "select $__timeGroupAlias(time_column, '1h')",
+			expected: "select CAST(strftime('%s', time_column) AS INTEGER) / 3600 * 3600 AS \"time\"",
+		},
+		{
+			sql:      "WHERE $__unixEpochFilter(time)",
+			expected: fmt.Sprintf("WHERE time >= %d AND time <= %d", from.Unix(), to.Unix()),
+		},
+		{
+			sql:      "WHERE $__unixEpochNanoFilter(time)",
+			expected: fmt.Sprintf("WHERE time >= %d AND time <= %d", from.UnixNano(), to.UnixNano()),
+		},
+		{
+			sql:      "select $__unixEpochGroupAlias(time_sec, '5m')",
+			expected: "select time_sec / 300 * 300 AS \"time\"",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.sql, func(t *testing.T) {
+			sql, err := engine.Interpolate(&backend.DataQuery{}, timeRange, tt.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sql)
+		})
+	}
+
+	t.Run("interpolate __timeGroup function with fill", func(t *testing.T) {
+		query := &backend.DataQuery{JSON: []byte("{}")}
+		_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, '5m', NULL)")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"fill": true, "fillInterval": 300, "fillMode": "null"}`, string(query.JSON))
+	})
+
+	t.Run("fails on unknown macros", func(t *testing.T) {
+		_, err := engine.Interpolate(&backend.DataQuery{}, timeRange, "select $__unknown(time)")
+		require.EqualError(t, err, "unknown macro __unknown")
+	})
+}
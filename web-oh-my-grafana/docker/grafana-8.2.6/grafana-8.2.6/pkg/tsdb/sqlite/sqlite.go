@@ -0,0 +1,235 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const pluginID = "sqlite"
+
+var logger = log.New("tsdb.sqlite")
+
+// ErrPathNotAllowed is returned for the database files outside of the
+// allowed_paths of the [plugin.sqlite] settings.
+var ErrPathNotAllowed = errors.New("database file is not in the allowed paths of the SQLite data source")
+
+// The URI parameters of the database files that are passed on to SQLite,
+// the others are rejected.
+var allowedURIParams = map[string]bool{
+	"mode":          true,
+	"immutable":     true,
+	"cache":         true,
+	"_loc":          true,
+	"_busy_timeout": true,
+	"_timeout":      true,
+}
+
+type Service struct {
+	Cfg *setting.Cfg
+	im  instancemgmt.InstanceManager
+}
+
+func ProvideService(cfg *setting.Cfg, manager backendplugin.Manager) (*Service, error) {
+	s := &Service{
+		Cfg: cfg,
+		im:  datasource.NewInstanceManager(newInstanceSettings(cfg)),
+	}
+	factory := coreplugin.New(backend.ServeOpts{
+		QueryDataHandler:    s,
+		CallResourceHandler: sqleng.NewResourceHandler(s.getDataSourceHandler),
+	})
+
+	if err := manager.Register(pluginID, factory); err != nil {
+		logger.Error("Failed to register plugin", "error", err)
+	}
+	return s, nil
+}
+
+func newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
+	return func(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		jsonData := sqleng.JsonData{
+			MaxOpenConns:    0,
+			MaxIdleConns:    2,
+			ConnMaxLifetime: 14400,
+		}
+
+		err := json.Unmarshal(settings.JSONData, &jsonData)
+		if err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                jsonData,
+			URL:                     settings.URL,
+			Database:                settings.Database,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		cnnstr, err := generateConnectionString(dsInfo.URL, allowedPaths(cfg))
+		if err != nil {
+			return nil, err
+		}
+
+		if cfg.Env == setting.Dev {
+			logger.Debug("getEngine", "connection", cnnstr)
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        driverName,
+			ConnectionString:  cnnstr,
+			DSInfo:            dsInfo,
+			TimeColumnNames:   []string{"time", "time_sec"},
+			MetricColumnTypes: []string{"TEXT"},
+			RowLimit:          cfg.DataProxyRowLimit,
+		}
+
+		queryResultTransformer := sqliteQueryResultTransformer{
+			log: logger,
+		}
+
+		return sqleng.NewQueryDataHandler(config, &queryResultTransformer, newSQLiteMacroEngine(), logger)
+	}
+}
+
+// allowedPaths returns the directories and files that SQLite data sources
+// may read, from the allowed_paths of the [plugin.sqlite] settings.
+func allowedPaths(cfg *setting.Cfg) []string {
+	return util.SplitString(cfg.PluginSettings[pluginID]["allowed_paths"])
+}
+
+// generateConnectionString returns the read-only file URI of a database,
+// given as a file path or a file URI.
+func generateConnectionString(location string, allowed []string) (string, error) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return "", errors.New("missing path of the database file")
+	}
+
+	path := location
+	query := url.Values{}
+	if strings.HasPrefix(location, "file:") {
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", fmt.Errorf("invalid database file URI: %w", err)
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return "", fmt.Errorf("invalid database file URI: unsupported host %q", u.Host)
+		}
+		path = u.Path
+		if path == "" {
+			path = u.Opaque
+		}
+		query = u.Query()
+	}
+
+	for name := range query {
+		if !allowedURIParams[name] {
+			return "", fmt.Errorf("unsupported database file URI parameter %q", name)
+		}
+	}
+	if mode := query.Get("mode"); mode != "" && mode != "ro" {
+		return "", fmt.Errorf("unsupported database file mode %q, the data source is read-only", mode)
+	}
+	query.Set("mode", "ro")
+	query.Set("_query_only", "true")
+
+	path, err := checkPath(path, allowed)
+	if err != nil {
+		return "", err
+	}
+
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(path), RawQuery: query.Encode()}
+	return u.String(), nil
+}
+
+// checkPath returns the real path of a database file if it is one of the
+// allowed paths or in one of the allowed directories.
+func checkPath(path string, allowed []string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("path of the database file %q is not absolute", path)
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the database file: %w", err)
+	}
+
+	for _, allowedPath := range allowed {
+		realAllowedPath, err := filepath.EvalSymlinks(allowedPath)
+		if err != nil {
+			logger.Warn("Ignoring an allowed path of the SQLite data source", "path", allowedPath, "error", err)
+			continue
+		}
+		rel, err := filepath.Rel(realAllowedPath, realPath)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return realPath, nil
+		}
+	}
+	return "", ErrPathNotAllowed
+}
+
+func (s *Service) getDataSourceHandler(pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+type sqliteQueryResultTransformer struct {
+	log log.Logger
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(err error) error {
+	var driverErr sqlite3.Error
+	if errors.As(err, &driverErr) {
+		switch driverErr.Code {
+		case sqlite3.ErrError, sqlite3.ErrReadonly, sqlite3.ErrAuth, sqlite3.ErrBusy, sqlite3.ErrLocked:
+			// Syntax errors, unknown tables and columns, writes and busy databases.
+			return err
+		}
+		t.log.Error("query error", "err", err)
+		return errQueryFailed
+	}
+
+	return err
+}
+
+var errQueryFailed = errors.New("query failed - please inspect Grafana server log for details")
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	// The rows of the SQLite data source driver have the scan types of
+	// their values, so there's no string to convert.
+	return nil
+}
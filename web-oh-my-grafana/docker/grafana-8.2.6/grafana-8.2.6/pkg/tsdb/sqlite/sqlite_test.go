@@ -0,0 +1,240 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateConnectionString(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "metrics.db")
+	require.NoError(t, os.WriteFile(dbPath, nil, 0600))
+	otherDir := t.TempDir()
+	otherPath := filepath.Join(otherDir, "other.db")
+	require.NoError(t, os.WriteFile(otherPath, nil, 0600))
+	link := filepath.Join(dir, "link.db")
+	require.NoError(t, os.Symlink(otherPath, link))
+	realDBPath, err := filepath.EvalSymlinks(dbPath)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc        string
+		location    string
+		allowed     []string
+		expConnStr  string
+		expErr      error
+		expErrMatch string
+	}{
+		{
+			desc:       "File path in an allowed directory",
+			location:   dbPath,
+			allowed:    []string{dir},
+			expConnStr: "file://" + realDBPath + "?_query_only=true&mode=ro",
+		},
+		{
+			desc:       "Allowed file path",
+			location:   dbPath,
+			allowed:    []string{otherDir, dbPath},
+			expConnStr: "file://" + realDBPath + "?_query_only=true&mode=ro",
+		},
+		{
+			desc:       "File URI with parameters",
+			location:   "file:" + dbPath + "?mode=ro&immutable=1",
+			allowed:    []string{dir},
+			expConnStr: "file://" + realDBPath + "?_query_only=true&immutable=1&mode=ro",
+		},
+		{
+			desc:     "No allowed paths",
+			location: dbPath,
+			expErr:   ErrPathNotAllowed,
+		},
+		{
+			desc:     "File path outside of the allowed directories",
+			location: otherPath,
+			allowed:  []string{dir},
+			expErr:   ErrPathNotAllowed,
+		},
+		{
+			desc:     "Symbolic link to a file outside of the allowed directories",
+			location: link,
+			allowed:  []string{dir},
+			expErr:   ErrPathNotAllowed,
+		},
+		{
+			desc:        "Relative file path",
+			location:    "metrics.db",
+			allowed:     []string{dir},
+			expErrMatch: "not absolute",
+		},
+		{
+			desc:        "Writable file URI",
+			location:    "file:" + dbPath + "?mode=rw",
+			allowed:     []string{dir},
+			expErrMatch: `unsupported database file mode "rw"`,
+		},
+		{
+			desc:        "File URI with an unsupported parameter",
+			location:    "file:" + dbPath + "?_query_only=false",
+			allowed:     []string{dir},
+			expErrMatch: `unsupported database file URI parameter "_query_only"`,
+		},
+		{
+			desc:        "Missing file",
+			location:    filepath.Join(dir, "missing.db"),
+			allowed:     []string{dir},
+			expErrMatch: "failed to open the database file",
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.desc, func(t *testing.T) {
+			connStr, err := generateConnectionString(tt.location, tt.allowed)
+			switch {
+			case tt.expErr != nil:
+				require.ErrorIs(t, err, tt.expErr)
+			case tt.expErrMatch != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expErrMatch)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.expConnStr, connStr)
+			}
+		})
+	}
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "metrics.db")
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE metric_values (
+			time DATETIME,
+			measurement TEXT,
+			value_int INTEGER,
+			value_real REAL,
+			value_numeric NUMERIC(10,2),
+			flag BOOLEAN
+		);
+		INSERT INTO metric_values VALUES
+			('2018-03-15 13:00:00', 'cpu', 1, 1.5, 10.25, 1),
+			('2018-03-15 13:02:00', 'cpu', 2, 2.5, NULL, 0),
+			('2018-03-15 13:06:00', 'cpu', 3, NULL, 30.75, 1),
+			('2018-03-15 13:01:00', 'mem', 4, 4.5, 40, 0);
+		CREATE TABLE epoch_values (time_sec INTEGER, value REAL);
+		INSERT INTO epoch_values VALUES (1521118800, 1.5), (1521118860, 2.5);
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	cfg := setting.NewCfg()
+	cfg.DataProxyRowLimit = 1000
+	cfg.PluginSettings = setting.PluginSettings{pluginID: {"allowed_paths": dir}}
+	instance, err := newInstanceSettings(cfg)(backend.DataSourceInstanceSettings{
+		ID:       1,
+		JSONData: []byte(`{}`),
+		URL:      dbPath,
+	})
+	require.NoError(t, err)
+	handler := instance.(*sqleng.DataSourceHandler)
+	t.Cleanup(handler.Dispose)
+
+	fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC)
+	query := func(t *testing.T, rawSQL string, format string) *backend.DataResponse {
+		t.Helper()
+		resp, err := handler.QueryData(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{
+				{
+					JSON:  []byte(`{"rawSql": "` + rawSQL + `", "format": "` + format + `"}`),
+					RefID: "A",
+					TimeRange: backend.TimeRange{
+						From: fromStart,
+						To:   fromStart.Add(10 * time.Minute),
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		result := resp.Responses["A"]
+		return &result
+	}
+
+	t.Run("When doing a table query should map SQLite column types to Go types", func(t *testing.T) {
+		result := query(t, "SELECT * FROM metric_values ORDER BY time LIMIT 1", "table")
+		require.NoError(t, result.Error)
+		require.Len(t, result.Frames, 1)
+		frame := result.Frames[0]
+		require.Len(t, frame.Fields, 6)
+
+		assert.Equal(t, fromStart, frame.Fields[0].At(0).(*time.Time).UTC())
+		assert.Equal(t, "cpu", *frame.Fields[1].At(0).(*string))
+		assert.Equal(t, int64(1), *frame.Fields[2].At(0).(*int64))
+		assert.Equal(t, 1.5, *frame.Fields[3].At(0).(*float64))
+		assert.Equal(t, 10.25, *frame.Fields[4].At(0).(*float64))
+		assert.True(t, *frame.Fields[5].At(0).(*bool))
+	})
+
+	t.Run("When doing a time series query with the time macros", func(t *testing.T) {
+		result := query(t,
+			"SELECT $__timeGroupAlias(time, '5m'), measurement AS metric, sum(value_int) AS value "+
+				"FROM metric_values WHERE $__timeFilter(time) GROUP BY 1, 2 ORDER BY 1",
+			"time_series")
+		require.NoError(t, result.Error)
+		require.Len(t, result.Frames, 1)
+		frame := result.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+
+		assert.Equal(t, fromStart, frame.Fields[0].At(0).(time.Time).UTC())
+		assert.Equal(t, fromStart.Add(5*time.Minute), frame.Fields[0].At(1).(time.Time).UTC())
+		assert.Equal(t, "cpu", frame.Fields[1].Name)
+		assert.Equal(t, 3.0, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, 3.0, *frame.Fields[1].At(1).(*float64))
+		assert.Equal(t, "mem", frame.Fields[2].Name)
+		assert.Equal(t, 4.0, *frame.Fields[2].At(0).(*float64))
+		assert.Nil(t, frame.Fields[2].At(1))
+	})
+
+	t.Run("When doing a time series query on Unix timestamps", func(t *testing.T) {
+		result := query(t, "SELECT time_sec, value FROM epoch_values WHERE $__unixEpochFilter(time_sec) ORDER BY 1",
+			"time_series")
+		require.NoError(t, result.Error)
+		require.Len(t, result.Frames, 1)
+		frame := result.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, fromStart, frame.Fields[0].At(0).(*time.Time).UTC())
+		assert.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("When doing a query without rows", func(t *testing.T) {
+		result := query(t, "SELECT 1 AS value WHERE 1 = 0", "table")
+		require.NoError(t, result.Error)
+		require.Len(t, result.Frames, 1)
+		assert.Equal(t, 0, result.Frames[0].Rows())
+	})
+
+	t.Run("Should not modify the database", func(t *testing.T) {
+		result := query(t, "DELETE FROM metric_values", "table")
+		require.Error(t, result.Error)
+
+		result = query(t, "SELECT count(*) AS total FROM metric_values", "table")
+		require.NoError(t, result.Error)
+		assert.Equal(t, 4.0, *result.Frames[0].Fields[0].At(0).(*float64))
+	})
+
+	t.Run("Should not attach other databases", func(t *testing.T) {
+		result := query(t, "ATTACH DATABASE '"+filepath.Join(dir, "other.db")+"' AS other", "table")
+		require.Error(t, result.Error)
+	})
+}
@@ -14,6 +14,12 @@ export interface FormModel {
   email: string;
 }
 
+export interface TwoFactorEnrollment {
+  secret: string;
+  provisioningUri: string;
+  recoveryCodes: string[];
+}
+
 interface Props {
   resetCode?: string;
 
@@ -30,12 +36,17 @@ interface Props {
     isOauthEnabled: boolean;
     loginHint: string;
     passwordHint: string;
+    isTwoFactorRequired: boolean;
+    twoFactorEnrollment?: TwoFactorEnrollment;
+    verifyTwoFactor: (code: string) => void;
   }) => JSX.Element;
 }
 
 interface State {
   isLoggingIn: boolean;
   isChangingPassword: boolean;
+  isTwoFactorRequired: boolean;
+  twoFactorEnrollment?: TwoFactorEnrollment;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
@@ -46,6 +57,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
     this.state = {
       isLoggingIn: false,
       isChangingPassword: false,
+      isTwoFactorRequired: false,
     };
 
     if (config.loginError) {
@@ -89,7 +101,15 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
     getBackendSrv()
       .post('/login', formModel)
-      .then((result: any) => {
+      .then(async (result: any) => {
+        if (result.twoFactorRequired) {
+          const twoFactorEnrollment = result.enrollmentRequired
+            ? await getBackendSrv().post('/login/2fa/enroll')
+            : undefined;
+          this.setState({ isLoggingIn: false, isTwoFactorRequired: true, twoFactorEnrollment });
+          return;
+        }
+
         this.result = result;
         if (formModel.password !== 'admin' || config.ldapEnabled || config.authProxyEnabled) {
           this.toGrafana();
@@ -105,6 +125,24 @@ export class LoginCtrl extends PureComponent<Props, State> {
       });
   };
 
+  verifyTwoFactor = (code: string) => {
+    this.setState({
+      isLoggingIn: true,
+    });
+
+    getBackendSrv()
+      .post('/login/2fa', { code })
+      .then((result: any) => {
+        this.result = result;
+        this.toGrafana();
+      })
+      .catch(() => {
+        this.setState({
+          isLoggingIn: false,
+        });
+      });
+  };
+
   changeView = () => {
     this.setState({
       isChangingPassword: true,
@@ -126,8 +164,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword } = this.state;
-    const { login, toGrafana, changePassword } = this;
+    const { isLoggingIn, isChangingPassword, isTwoFactorRequired, twoFactorEnrollment } = this.state;
+    const { login, toGrafana, changePassword, verifyTwoFactor } = this;
     const { loginHint, passwordHint, disableLoginForm, ldapEnabled, authProxyEnabled, disableUserSignUp } = config;
 
     return (
@@ -145,6 +183,9 @@ export class LoginCtrl extends PureComponent<Props, State> {
           changePassword,
           skipPasswordChange: toGrafana,
           isChangingPassword,
+          isTwoFactorRequired,
+          twoFactorEnrollment,
+          verifyTwoFactor,
         })}
       </>
     );
@@ -7,6 +7,7 @@ import { UserSignup } from './UserSignup';
 import { LoginServiceButtons } from './LoginServiceButtons';
 import LoginCtrl from './LoginCtrl';
 import { LoginForm } from './LoginForm';
+import { TwoFactorForm } from './TwoFactorForm';
 import { ChangePassword } from '../ForgottenPassword/ChangePassword';
 import { Branding } from 'app/core/components/Branding/Branding';
 import { HorizontalGroup, LinkButton } from '@grafana/ui';
@@ -35,9 +36,17 @@ export const LoginPage: FC = () => {
           changePassword,
           skipPasswordChange,
           isChangingPassword,
+          isTwoFactorRequired,
+          twoFactorEnrollment,
+          verifyTwoFactor,
         }) => (
           <>
-            {!isChangingPassword && (
+            {isTwoFactorRequired && (
+              <InnerBox>
+                <TwoFactorForm enrollment={twoFactorEnrollment} isLoggingIn={isLoggingIn} onSubmit={verifyTwoFactor} />
+              </InnerBox>
+            )}
+            {!isChangingPassword && !isTwoFactorRequired && (
               <InnerBox>
                 {!disableLoginForm && (
                   <LoginForm
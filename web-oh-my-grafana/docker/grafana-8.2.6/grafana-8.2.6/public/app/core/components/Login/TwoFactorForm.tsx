@@ -0,0 +1,61 @@
+import React, { FC } from 'react';
+import { css } from '@emotion/css';
+import { Button, Field, Form, Input, VerticalGroup } from '@grafana/ui';
+
+import { TwoFactorEnrollment } from './LoginCtrl';
+import { submitButton } from './LoginForm';
+
+interface Props {
+  enrollment?: TwoFactorEnrollment;
+  isLoggingIn: boolean;
+  onSubmit: (code: string) => void;
+}
+
+interface TwoFactorFormModel {
+  code: string;
+}
+
+const wrapperStyles = css`
+  width: 100%;
+  padding-bottom: 16px;
+`;
+
+const codeStyles = css`
+  word-break: break-all;
+`;
+
+export const TwoFactorForm: FC<Props> = ({ enrollment, isLoggingIn, onSubmit }) => {
+  return (
+    <div className={wrapperStyles}>
+      {enrollment && (
+        <VerticalGroup>
+          <p>
+            Two-factor authentication is required for your account. Add this key to your authenticator app, then enter
+            the code it generates.
+          </p>
+          <code className={codeStyles}>{enrollment.secret}</code>
+          <a href={enrollment.provisioningUri}>Open in authenticator app</a>
+          <p>Keep these recovery codes in a safe place, each of them can be used once instead of a code:</p>
+          <code className={codeStyles}>{enrollment.recoveryCodes.join(' ')}</code>
+        </VerticalGroup>
+      )}
+      <Form onSubmit={(data: TwoFactorFormModel) => onSubmit(data.code)}>
+        {({ register, errors }) => (
+          <>
+            <Field label="Authentication code" invalid={!!errors.code} error={errors.code?.message}>
+              <Input
+                {...register('code', { required: 'Authentication code is required' })}
+                autoFocus
+                autoComplete="one-time-code"
+                placeholder="Code or recovery code"
+              />
+            </Field>
+            <Button className={submitButton} disabled={isLoggingIn}>
+              {isLoggingIn ? 'Verifying...' : 'Verify'}
+            </Button>
+          </>
+        )}
+      </Form>
+    </div>
+  );
+};
@@ -0,0 +1,39 @@
+import React from 'react';
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { DataSourceHttpSettings, FieldSet, InlineField, Input } from '@grafana/ui';
+import { ClickHouseOptions } from './types';
+
+type Props = DataSourcePluginOptionsEditorProps<ClickHouseOptions>;
+
+export const ConfigEditor = ({ options, onOptionsChange }: Props) => {
+  return (
+    <>
+      <DataSourceHttpSettings
+        defaultUrl="http://localhost:8123"
+        dataSourceConfig={options}
+        showAccessOptions={false}
+        onChange={onOptionsChange}
+      />
+      <FieldSet label="ClickHouse Details">
+        <InlineField label="Database" labelWidth={14}>
+          <Input
+            width={40}
+            value={options.database}
+            placeholder="default"
+            onChange={(e) => onOptionsChange({ ...options, database: e.currentTarget.value })}
+          />
+        </InlineField>
+        <InlineField label="Min time interval" labelWidth={14} tooltip="A lower limit for the $__interval macro.">
+          <Input
+            width={40}
+            value={options.jsonData.timeInterval ?? ''}
+            placeholder="1m"
+            onChange={(e) =>
+              onOptionsChange({ ...options, jsonData: { ...options.jsonData, timeInterval: e.currentTarget.value } })
+            }
+          />
+        </InlineField>
+      </FieldSet>
+    </>
+  );
+};
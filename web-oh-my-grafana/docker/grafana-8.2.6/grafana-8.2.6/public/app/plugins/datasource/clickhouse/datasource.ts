@@ -0,0 +1,26 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend } from '@grafana/runtime';
+import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
+import { ClickHouseOptions, ClickHouseQuery } from './types';
+
+export class ClickHouseDatasource extends DataSourceWithBackend<ClickHouseQuery, ClickHouseOptions> {
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<ClickHouseOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+    this.interval = instanceSettings.jsonData?.timeInterval || '1m';
+  }
+
+  filterQuery(query: ClickHouseQuery): boolean {
+    return !query.hide && !!query.rawSql;
+  }
+
+  applyTemplateVariables(query: ClickHouseQuery, scopedVars: ScopedVars): Record<string, any> {
+    return {
+      ...query,
+      format: query.format || 'time_series',
+      rawSql: this.templateSrv.replace(query.rawSql, scopedVars, 'sqlstring'),
+    };
+  }
+}
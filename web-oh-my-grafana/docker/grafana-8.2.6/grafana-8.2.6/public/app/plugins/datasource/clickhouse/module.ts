@@ -0,0 +1,9 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { ClickHouseDatasource } from './datasource';
+import { QueryEditor } from './QueryEditor';
+import { ConfigEditor } from './ConfigEditor';
+import { ClickHouseOptions, ClickHouseQuery } from './types';
+
+export const plugin = new DataSourcePlugin<ClickHouseDatasource, ClickHouseQuery, ClickHouseOptions>(ClickHouseDatasource)
+  .setQueryEditor(QueryEditor)
+  .setConfigEditor(ConfigEditor);
@@ -0,0 +1,12 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type ResultFormat = 'time_series' | 'table';
+
+export interface ClickHouseOptions extends DataSourceJsonData {
+  timeInterval?: string;
+}
+
+export interface ClickHouseQuery extends DataQuery {
+  format?: ResultFormat;
+  rawSql?: string;
+}
@@ -0,0 +1,35 @@
+import React from 'react';
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { FieldSet, InlineField, Input } from '@grafana/ui';
+import { SQLiteOptions } from './types';
+
+type Props = DataSourcePluginOptionsEditorProps<SQLiteOptions>;
+
+export const ConfigEditor = ({ options, onOptionsChange }: Props) => {
+  return (
+    <FieldSet label="SQLite Connection">
+      <InlineField
+        label="Path"
+        labelWidth={14}
+        tooltip="Path or file URI of the database file. It must be under the allowed_paths of the [plugin.sqlite] settings."
+      >
+        <Input
+          width={40}
+          value={options.url}
+          placeholder="/var/lib/grafana/data.db"
+          onChange={(e) => onOptionsChange({ ...options, url: e.currentTarget.value })}
+        />
+      </InlineField>
+      <InlineField label="Min time interval" labelWidth={14} tooltip="A lower limit for the $__interval macro.">
+        <Input
+          width={40}
+          value={options.jsonData.timeInterval ?? ''}
+          placeholder="1m"
+          onChange={(e) =>
+            onOptionsChange({ ...options, jsonData: { ...options.jsonData, timeInterval: e.currentTarget.value } })
+          }
+        />
+      </InlineField>
+    </FieldSet>
+  );
+};
@@ -0,0 +1,39 @@
+import React from 'react';
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { InlineField, RadioButtonGroup, TextArea } from '@grafana/ui';
+import { SQLiteDatasource } from './datasource';
+import { ResultFormat, SQLiteOptions, SQLiteQuery } from './types';
+
+type Props = QueryEditorProps<SQLiteDatasource, SQLiteQuery, SQLiteOptions>;
+
+const formats: Array<SelectableValue<ResultFormat>> = [
+  { label: 'Time series', value: 'time_series' },
+  { label: 'Table', value: 'table' },
+];
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  return (
+    <>
+      <TextArea
+        aria-label="SQL query"
+        rows={6}
+        placeholder="SELECT time, value AS metric FROM measurements WHERE $__timeFilter(time)"
+        defaultValue={query.rawSql ?? ''}
+        onBlur={(e) => {
+          onChange({ ...query, rawSql: e.currentTarget.value });
+          onRunQuery();
+        }}
+      />
+      <InlineField label="Format as" labelWidth={12}>
+        <RadioButtonGroup
+          options={formats}
+          value={query.format ?? 'time_series'}
+          onChange={(format) => {
+            onChange({ ...query, format });
+            onRunQuery();
+          }}
+        />
+      </InlineField>
+    </>
+  );
+};
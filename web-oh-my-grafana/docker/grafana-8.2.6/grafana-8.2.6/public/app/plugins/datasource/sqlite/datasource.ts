@@ -0,0 +1,26 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend } from '@grafana/runtime';
+import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
+import { SQLiteOptions, SQLiteQuery } from './types';
+
+export class SQLiteDatasource extends DataSourceWithBackend<SQLiteQuery, SQLiteOptions> {
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<SQLiteOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+    this.interval = instanceSettings.jsonData?.timeInterval || '1m';
+  }
+
+  filterQuery(query: SQLiteQuery): boolean {
+    return !query.hide && !!query.rawSql;
+  }
+
+  applyTemplateVariables(query: SQLiteQuery, scopedVars: ScopedVars): Record<string, any> {
+    return {
+      ...query,
+      format: query.format || 'time_series',
+      rawSql: this.templateSrv.replace(query.rawSql, scopedVars, 'sqlstring'),
+    };
+  }
+}
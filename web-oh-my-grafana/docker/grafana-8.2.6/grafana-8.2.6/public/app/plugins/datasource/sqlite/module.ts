@@ -0,0 +1,9 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { SQLiteDatasource } from './datasource';
+import { QueryEditor } from './QueryEditor';
+import { ConfigEditor } from './ConfigEditor';
+import { SQLiteOptions, SQLiteQuery } from './types';
+
+export const plugin = new DataSourcePlugin<SQLiteDatasource, SQLiteQuery, SQLiteOptions>(SQLiteDatasource)
+  .setQueryEditor(QueryEditor)
+  .setConfigEditor(ConfigEditor);